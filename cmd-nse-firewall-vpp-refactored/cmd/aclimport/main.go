@@ -0,0 +1,138 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// aclimport 将iptables-save或nft list ruleset的输出转换为防火墙ACL配置文件
+//
+// 转换一个链（默认FORWARD）的规则，按规则顺序输出NSM_ACL_CONFIG_PATH可加载的YAML。
+// 无法转换的构造作为警告输出到标准错误（同时以注释的形式写入输出文件），
+// -strict时转换失败。
+//
+// 用法：
+//
+//	iptables-save | aclimport > acl.yaml
+//	nft list ruleset | aclimport -chain forward -o acl.yaml
+//	aclimport [-format auto|iptables|nft] [-chain FORWARD] [-strict] [-o file] [input|-]
+//
+// 退出码：0表示成功，1表示转换失败，2表示参数错误。
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclimport"
+)
+
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+const usage = `Usage: aclimport [flags] [input|-]
+
+Convert iptables-save or 'nft list ruleset' output into a firewall ACL config
+file. The input is read from stdin when no file (or '-') is given.
+
+Flags:
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 执行转换并返回退出码
+func run(_ context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("aclimport", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "auto", "input format: auto, iptables or nft")
+	chain := fs.String("chain", aclimport.DefaultChain, "chain to convert (nft: the hook of the filter base chain)")
+	strict := fs.Bool("strict", false, "fail on constructs without a VPP ACL equivalent instead of skipping them")
+	output := fs.String("o", "", "write the config to a file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	opts := aclimport.Options{Chain: *chain, Strict: *strict}
+	switch *format {
+	case "auto":
+	case string(aclimport.FormatIPTables), string(aclimport.FormatNFT):
+		opts.Format = aclimport.Format(*format)
+	default:
+		fmt.Fprintf(stderr, "aclimport: unknown input format %q\n", *format)
+		return exitUsage
+	}
+
+	data, err := readInput(fs.Arg(0), stdin)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	result, err := aclimport.Convert(data, opts)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintf(stderr, "warning: %s\n", w)
+	}
+	out, err := result.YAML()
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if *output == "" {
+		if _, err := stdout.Write(out); err != nil {
+			return fail(stderr, err)
+		}
+		return exitOK
+	}
+	if err := os.WriteFile(*output, out, 0o644); err != nil {
+		return fail(stderr, err)
+	}
+	fmt.Fprintf(stderr, "%d rules written to %s\n", len(result.Rules), *output)
+	return exitOK
+}
+
+// readInput 读取输入文件，空或"-"表示标准输入
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "" || path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "aclimport: %v\n", err)
+	return exitFailed
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+const ruleset = `*filter
+:FORWARD DROP [0:0]
+-A FORWARD -s 10.0.0.0/8 -p tcp -m tcp --dport 443 -j ACCEPT
+-A FORWARD -m state --state ESTABLISHED -j ACCEPT
+COMMIT
+`
+
+func runCmd(t *testing.T, stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(context.Background(), args, strings.NewReader(stdin), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestRun_Stdin(t *testing.T) {
+	code, stdout, stderr := runCmd(t, ruleset)
+	require.Equal(t, exitOK, code, stderr)
+	require.Contains(t, stderr, `warning: line 4: match module "state" has no VPP ACL equivalent (rule skipped)`)
+	require.True(t, strings.HasPrefix(stdout, "# Converted from iptables (chain FORWARD)\n"), stdout)
+
+	rules, err := aclrules.Parse([]byte(stdout))
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+}
+
+func TestRun_File(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "rules.v4")
+	output := filepath.Join(dir, "acl.yaml")
+	require.NoError(t, os.WriteFile(input, []byte(ruleset), 0o600))
+
+	code, stdout, stderr := runCmd(t, "", "-format", "iptables", "-o", output, input)
+	require.Equal(t, exitOK, code, stderr)
+	require.Empty(t, stdout)
+	require.Contains(t, stderr, "2 rules written to "+output)
+
+	rules, err := aclrules.Load(output)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+}
+
+func TestRun_Errors(t *testing.T) {
+	code, _, stderr := runCmd(t, ruleset, "-strict")
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stderr, `aclimport: line 4: match module "state"`)
+
+	code, _, _ = runCmd(t, ruleset, "-format", "pf")
+	require.Equal(t, exitUsage, code)
+
+	code, _, _ = runCmd(t, ruleset, "a", "b")
+	require.Equal(t, exitUsage, code)
+
+	code, _, stderr = runCmd(t, "", filepath.Join(t.TempDir(), "missing"))
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stderr, "no such file")
+
+	code, _, _ = runCmd(t, "", "-h")
+	require.Equal(t, exitOK, code)
+}
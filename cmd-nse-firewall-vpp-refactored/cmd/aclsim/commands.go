@@ -0,0 +1,206 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclsim"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/config"
+)
+
+// command 子命令的执行环境
+type command struct {
+	ctx    context.Context
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// parse 解析子命令参数并检查位置参数数量
+// 返回false时应以返回的退出码结束
+func (c *command) parse(fs *flag.FlagSet, args []string, minArgs, maxArgs int) (int, bool) {
+	fs.SetOutput(c.stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if fs.NArg() < minArgs || fs.NArg() > maxArgs {
+		fs.Usage()
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+func (c *command) flagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: aclsim %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (c *command) fail(err error) int {
+	fmt.Fprintf(c.stderr, "aclsim: %v\n", err)
+	return exitFailed
+}
+
+// loadPolicy 按NSE的配置加载逻辑读取ACL策略
+//
+// 配置从NSM_*环境变量读取，path非空时覆盖NSM_ACL_CONFIG_PATH。
+// 加载失败时总是返回错误（忽略NSM_ACL_ON_ERROR），以便在CI中发现无效的规则文件。
+func (c *command) loadPolicy(path string) (*aclrules.Policy, error) {
+	cfg := new(config.Config)
+	if err := envconfig.Process("nsm", cfg); err != nil {
+		return nil, err
+	}
+	if path != "" {
+		cfg.ACLConfigPath = path
+	}
+	cfg.ACLOnError = config.ACLOnErrorFail
+	if err := cfg.LoadACLRules(log.WithLog(c.ctx, log.Empty())); err != nil {
+		return nil, err
+	}
+	return cfg.ACLPolicy, nil
+}
+
+// eval 输出数据包的动作和匹配的规则
+func (c *command) eval(args []string) int {
+	fs := c.flagSet("eval", "[flags] <proto> <src> <dst>")
+	path := fs.String("config", "", "ACL config file (default: NSM_ACL_CONFIG_PATH)")
+	profile := fs.String("profile", "", "profile to use (default: selected by -labels and -spiffe-id)")
+	labels := fs.String("labels", "", "client connection labels used to select the profile (key:value,...)")
+	spiffeID := fs.String("spiffe-id", "", "client SPIFFE ID used to select the profile")
+	expect := fs.String("expect", "", "fail unless the action is this one (permit, deny or permit-reflect)")
+	sport := fs.Uint("sport", 0, "source port")
+	dport := fs.Uint("dport", 0, "destination port")
+	icmpType := fs.Uint("icmp-type", 0, "ICMP type (icmp and icmpv6 only)")
+	icmpCode := fs.Uint("icmp-code", 0, "ICMP code (icmp and icmpv6 only)")
+	tcpFlags := fs.Uint("tcp-flags", 0, "TCP flags (tcp only)")
+	if code, ok := c.parse(fs, args, 3, 3); !ok {
+		return code
+	}
+
+	if *sport > 65535 || *dport > 65535 || *icmpType > 255 || *icmpCode > 255 || *tcpFlags > 255 {
+		fmt.Fprintln(c.stderr, "aclsim: port, ICMP type/code or TCP flags out of range")
+		return exitUsage
+	}
+	// 复用测试表的检查逻辑（端口/ICMP字段与协议是否匹配）
+	tc := aclsim.Case{
+		Profile: *profile, Proto: fs.Arg(0), Src: fs.Arg(1), Dst: fs.Arg(2),
+		Sport: uint16(*sport), Dport: uint16(*dport),
+		ICMPType: uint8(*icmpType), ICMPCode: uint8(*icmpCode), TCPFlags: uint8(*tcpFlags),
+		Expect: *expect,
+	}
+	if tc.Expect == "" {
+		// 未指定-expect时只输出结果，不检查期望
+		tc.Expect = aclrules.ActionDeny
+	}
+	if err := tc.Compile(); err != nil {
+		fmt.Fprintf(c.stderr, "aclsim: %v\n", err)
+		return exitUsage
+	}
+	lbls, err := parseLabels(*labels)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "aclsim: %v\n", err)
+		return exitUsage
+	}
+
+	policy, err := c.loadPolicy(*path)
+	if err != nil {
+		return c.fail(err)
+	}
+	if tc.Profile == "" {
+		tc.Profile = policy.Select(lbls, *spiffeID)
+	}
+	if _, ok := policy.Rules(tc.Profile); !ok {
+		return c.fail(fmt.Errorf("unknown profile %q (available: %s)", tc.Profile, strings.Join(policy.Names(), ", ")))
+	}
+
+	o := aclsim.Run(policy, []aclsim.Case{tc})[0]
+	p := tc.Packet()
+	fmt.Fprintf(c.stdout, "%s: %s (profile %q)\n", p.String(), &o.Result, o.Profile)
+	if *expect != "" && !o.Passed() {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// test 运行测试表
+func (c *command) test(args []string) int {
+	fs := c.flagSet("test", "[-config file] [-v] <test-file>")
+	path := fs.String("config", "", "ACL config file (default: NSM_ACL_CONFIG_PATH)")
+	verbose := fs.Bool("v", false, "print passed test cases as well")
+	if code, ok := c.parse(fs, args, 1, 1); !ok {
+		return code
+	}
+
+	cases, err := aclsim.LoadCases(fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+	policy, err := c.loadPolicy(*path)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	failed := 0
+	for _, o := range aclsim.Run(policy, cases) {
+		if !o.Passed() {
+			failed++
+		}
+		if *verbose || !o.Passed() {
+			fmt.Fprintln(c.stdout, o.String())
+		}
+	}
+	fmt.Fprintf(c.stdout, "%d passed, %d failed\n", len(cases)-failed, failed)
+	if failed > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// parseLabels 解析与NSM_LABELS相同格式的标签（key:value,...）
+func parseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	if s == "" {
+		return labels, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label %q (expected key:value)", pair)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// aclsim 离线模拟防火墙ACL规则对测试数据包的处理
+//
+// ACL规则与NSE一样通过配置加载逻辑读取（NSM_ACL_CONFIG_PATH指定的规则文件或配置集文件，
+// 以及NSM_ACL_CONFIG中的规则），-config可以覆盖规则文件路径。
+// 匹配语义与VPP ACL一致（first-match，末尾隐式拒绝），详见aclsim包。
+//
+// 用法：
+//
+//	aclsim eval [-config file] [-profile name] [-labels k:v,...] [-spiffe-id id] [-expect permit|deny|permit-reflect]
+//	            [-sport n] [-dport n] [-icmp-type n] [-icmp-code n] [-tcp-flags n] <proto> <src> <dst>
+//	aclsim test [-config file] [-v] <test-file>
+//
+// 退出码：0表示成功，1表示结果与期望不符或加载失败，2表示参数错误。
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+const usage = `Usage: aclsim <command> [flags] ...
+
+Commands:
+  eval  print the action and matching rule for a packet
+  test  run a YAML table of packets and expected actions (for CI)
+
+The ACL rules are loaded like the firewall NSE loads them: from the file in
+NSM_ACL_CONFIG_PATH (override with -config) plus the rules in NSM_ACL_CONFIG.
+
+Run 'aclsim <command> -h' for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run 执行子命令并返回退出码
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	cmd := &command{ctx: ctx, stdout: stdout, stderr: stderr}
+	switch args[0] {
+	case "eval":
+		return cmd.eval(args[1:])
+	case "test":
+		return cmd.test(args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "aclsim: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const aclConfig = `
+profiles:
+  default:
+    - name: web
+      action: permit
+      proto: tcp
+      dst: 192.168.1.10
+      dport: 443
+    - name: ping
+      action: permit
+      proto: icmp
+      icmp-type: 8
+  restricted:
+    - name: dns
+      action: permit
+      proto: udp
+      dport: 53
+selectors:
+  - profile: restricted
+    labels:
+      tier: untrusted
+default: default
+`
+
+const testTable = `
+- name: web
+  proto: tcp
+  src: 10.0.0.1
+  dst: 192.168.1.10
+  sport: 40000
+  dport: 443
+  expect: permit
+  rule: web
+- name: untrusted web
+  profile: restricted
+  proto: tcp
+  src: 10.0.0.1
+  dst: 192.168.1.10
+  dport: 443
+  expect: deny
+- name: wrong expectation
+  proto: udp
+  src: 10.0.0.1
+  dst: 192.168.1.10
+  dport: 53
+  expect: permit
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func runCmd(t *testing.T, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(context.Background(), args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestEval(t *testing.T) {
+	cfg := writeFile(t, "acl.yaml", aclConfig)
+
+	code, stdout, stderr := runCmd(t, "eval", "-config", cfg, "-dport", "443", "-expect", "permit", "tcp", "10.0.0.1", "192.168.1.10")
+	require.Equal(t, exitOK, code, stderr)
+	require.Equal(t, "tcp 10.0.0.1:0 -> 192.168.1.10:443: permit by rule #0 \"web\" (profile \"default\")\n", stdout)
+
+	// 按标签选择配置集
+	code, stdout, _ = runCmd(t, "eval", "-config", cfg, "-labels", "tier:untrusted", "-dport", "443", "-expect", "permit", "tcp", "10.0.0.1", "192.168.1.10")
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stdout, `deny (implicit deny) (profile "restricted")`)
+
+	code, stdout, _ = runCmd(t, "eval", "-config", cfg, "-icmp-type", "8", "icmp", "10.0.0.1", "192.168.1.10")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, stdout, `permit by rule #1 "ping"`)
+
+	code, _, stderr = runCmd(t, "eval", "-config", cfg, "-profile", "missing", "tcp", "10.0.0.1", "192.168.1.10")
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stderr, `unknown profile "missing" (available: default, restricted)`)
+
+	code, _, stderr = runCmd(t, "eval", "-config", cfg, "-dport", "8", "icmp", "10.0.0.1", "192.168.1.10")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, "use icmp-type and icmp-code")
+
+	code, _, _ = runCmd(t, "eval", "tcp", "10.0.0.1")
+	require.Equal(t, exitUsage, code)
+}
+
+func TestTest(t *testing.T) {
+	cfg := writeFile(t, "acl.yaml", aclConfig)
+	table := writeFile(t, "acl-tests.yaml", testTable)
+
+	code, stdout, stderr := runCmd(t, "test", "-config", cfg, table)
+	require.Equal(t, exitFailed, code, stderr)
+	require.Equal(t, "FAIL line 17: wrong expectation: expected permit, got deny (implicit deny)\n2 passed, 1 failed\n", stdout)
+
+	code, stdout, _ = runCmd(t, "test", "-config", cfg, "-v", writeFile(t, "ok.yaml", testTable[:strings.Index(testTable, "- name: wrong expectation")]))
+	require.Equal(t, exitOK, code)
+	require.Contains(t, stdout, `PASS web: permit by rule #0 "web"`)
+	require.Contains(t, stdout, "2 passed, 0 failed")
+
+	// 无效的规则文件总是失败（不按NSM_ACL_ON_ERROR以deny-all加载）
+	t.Setenv("NSM_ACL_ON_ERROR", "deny-all")
+	code, _, stderr = runCmd(t, "test", "-config", writeFile(t, "bad.yaml", "- action: maybe\n"), table)
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stderr, "failed to load ACL rules")
+}
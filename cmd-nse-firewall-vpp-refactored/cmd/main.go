@@ -24,30 +24,28 @@
 package main
 
 import (
+	"context"
 	"os"
-	"time"
 
-	"github.com/edwarnicke/grpcfd"
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	"github.com/spiffe/go-spiffe/v2/workloadapi"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"go.fd.io/govpp/adapter/statsclient"
 
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
-	"github.com/networkservicemesh/sdk/pkg/tools/opentelemetry"
-	"github.com/networkservicemesh/sdk/pkg/tools/pprofutils"
-	"github.com/networkservicemesh/sdk/pkg/tools/spiffejwt"
-	"github.com/networkservicemesh/sdk/pkg/tools/token"
-	"github.com/networkservicemesh/sdk/pkg/tools/tracing"
 
 	_ "github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/internal/imports"
 
 	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/internal/firewall"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclserver"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclsession"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclstats"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/admin"
 	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/config"
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/lifecycle"
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/registry"
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/server"
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/vpp"
+	nseconfig "github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/health"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/lifecycle"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/nse"
 )
 
 func main() {
@@ -57,172 +55,114 @@ func main() {
 	ctx, cancel := lifecycle.NotifyContext()
 	defer cancel()
 
-	// ********************************************************************************
-	// 设置日志系统
-	// ********************************************************************************
-	// 注意：暂时使用默认日志级别，稍后从配置加载后再更新
-	ctx = lifecycle.InitializeLogging(ctx, "INFO")
-
-	// 枚举启动阶段
-	log.FromContext(ctx).Infof("there are 6 phases which will be executed followed by a success message:")
-	log.FromContext(ctx).Infof("the phases include:")
-	log.FromContext(ctx).Infof("1: get config from environment")
-	log.FromContext(ctx).Infof("2: retrieve spiffe svid")
-	log.FromContext(ctx).Infof("3: create grpc client options")
-	log.FromContext(ctx).Infof("4: create firewall network service endpoint")
-	log.FromContext(ctx).Infof("5: create grpc and mount nse")
-	log.FromContext(ctx).Infof("6: register nse with nsm")
-	log.FromContext(ctx).Infof("a final success message with start time duration")
-
-	starttime := time.Now()
-
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 1: get config from environment")
-	// ********************************************************************************
-	cfg, err := config.Load(ctx)
-	if err != nil {
-		logrus.Fatal(err.Error())
-	}
-
-	// 加载ACL规则
-	cfg.LoadACLRules(ctx)
-
-	// 验证配置
-	if err := cfg.Validate(); err != nil {
-		logrus.Fatalf("invalid config: %v", err)
+	// 六个启动阶段由nse.Run执行，firewall只提供配置和端点
+	f := new(firewallNSE)
+	if err := nse.Run(ctx, nse.Spec{
+		Name:       "firewall",
+		Config:     f.loadConfig,
+		VPPPlugins: []string{"acl", "memif"},
+		Endpoint:   f.newEndpoint,
+		Args:       os.Args[1:],
+	}); err != nil {
+		logrus.Fatalf("%+v", err)
 	}
+}
 
-	// 使用配置的日志级别重新初始化日志
-	ctx = lifecycle.InitializeLogging(ctx, cfg.LogLevel)
-
-	log.FromContext(ctx).Infof("Config: %#v", cfg)
+// firewallNSE firewall的业务配置和端点
+type firewallNSE struct {
+	cfg *config.Config
 
-	// ********************************************************************************
-	// 配置 OpenTelemetry
-	// ********************************************************************************
-	if opentelemetry.IsEnabled() {
-		collectorAddress := cfg.OpenTelemetryEndpoint
-		spanExporter := opentelemetry.InitSpanExporter(ctx, collectorAddress)
-		metricExporter := opentelemetry.InitOPTLMetricExporter(ctx, collectorAddress, cfg.MetricsExportInterval)
-		o := opentelemetry.Init(ctx, spanExporter, metricExporter, cfg.Name)
-		defer func() {
-			if err = o.Close(); err != nil {
-				log.FromContext(ctx).Error(err.Error())
-			}
-		}()
-	}
+	// envACLRules NSM_ACL_CONFIG中的规则，在每个配置集的规则之前，重新加载时保留
+	envACLRules []acl_types.ACLRule
+}
 
-	// ********************************************************************************
-	// 配置 pprof
-	// ********************************************************************************
-	if cfg.PprofEnabled {
-		go pprofutils.ListenAndServe(ctx, cfg.PprofListenOn)
+// loadConfig 从环境变量加载配置，并加载ACL和MACIP规则
+//
+// ACL规则加载失败时按NSM_ACL_ON_ERROR处理。
+func (f *firewallNSE) loadConfig(ctx context.Context) (nseconfig.Extension, error) {
+	cfg, err := config.Load(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 2: retrieving svid, check spire agent logs if this is the last line you see")
-	// ********************************************************************************
-	source, err := workloadapi.NewX509Source(ctx)
-	if err != nil {
-		logrus.Fatalf("error getting x509 source: %+v", err)
+	f.envACLRules = cfg.ACLConfig
+	if err := cfg.LoadACLRules(ctx); err != nil {
+		return nil, errors.Wrap(err, "invalid ACL config")
 	}
-	svid, err := source.GetX509SVID()
-	if err != nil {
-		logrus.Fatalf("error getting x509 svid: %+v", err)
+	if err := cfg.LoadMACIPRules(ctx); err != nil {
+		return nil, errors.Wrap(err, "invalid MACIP config")
 	}
-	log.FromContext(ctx).Infof("SVID: %q", svid.ID)
 
-	// 创建TLS配置
-	tlsClientConfig := server.CreateTLSClientConfig(source)
-	tlsServerConfig := server.CreateTLSServerConfig(source)
+	f.cfg = cfg
+	return cfg, nil
+}
 
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 3: create grpc client options")
-	// ********************************************************************************
-	clientOptions := append(
-		tracing.WithTracingDial(),
-		grpc.WithDefaultCallOptions(
-			grpc.WaitForReady(true),
-			grpc.PerRPCCredentials(token.NewPerRPCCredentials(spiffejwt.TokenGeneratorFunc(source, cfg.MaxTokenLifetime))),
-		),
-		grpc.WithTransportCredentials(
-			grpcfd.TransportCredentials(
-				credentials.NewTLS(tlsClientConfig),
-			),
-		),
-		grpcfd.WithChainStreamInterceptor(),
-		grpcfd.WithChainUnaryInterceptor(),
-	)
+// newEndpoint 创建firewall端点，并启动ACL重新加载、命中计数和管理接口
+func (f *firewallNSE) newEndpoint(ctx context.Context, env *nse.Env) (nse.Endpoint, error) {
+	cfg := f.cfg
 
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 4: create firewall network service endpoint")
-	// ********************************************************************************
-	vppConn, vppErrCh, err := vpp.StartAndDial(ctx)
-	if err != nil {
-		logrus.Fatalf("error starting VPP: %+v", err)
+	// 配置有状态ACL（permit-reflect）会话表
+	vppCLI := aclsession.NewCLI(env.VPPConn)
+	if err := aclsession.Apply(ctx, vppCLI, cfg.ACLSessionConfig()); err != nil {
+		return nil, errors.Wrap(err, "error configuring ACL sessions")
 	}
-	lifecycle.MonitorErrorChannel(ctx, cancel, vppErrCh)
 
 	// 创建firewall端点
 	firewallEndpoint := firewall.NewEndpoint(ctx, firewall.Options{
 		Name:             cfg.Name,
 		ConnectTo:        &cfg.ConnectTo,
 		Labels:           cfg.Labels,
-		ACLRules:         cfg.ACLConfig,
+		ACLPolicy:        cfg.ACLPolicy,
+		RateLimit:        cfg.RateLimitConfig(),
+		MACIP:            cfg.MACIPConfig(),
 		MaxTokenLifetime: cfg.MaxTokenLifetime,
-		VPPConn:          vppConn,
-		Source:           source,
-		ClientOptions:    clientOptions,
-	})
-
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 5: create grpc server and register firewall-server")
-	// ********************************************************************************
-	srvResult, err := server.New(ctx, server.Options{
-		TLSConfig: tlsServerConfig,
-		Name:      cfg.Name,
-		ListenOn:  cfg.ListenOn,
+		VPPConn:          env.VPPConn,
+		Source:           env.Source,
+		ClientOptions:    env.ClientOptions,
 	})
-	if err != nil {
-		logrus.Fatalf("error creating server: %+v", err)
-	}
-	defer func() { _ = os.RemoveAll(srvResult.TmpDir) }()
-
-	// 注册firewall端点到gRPC服务器
-	firewallEndpoint.Register(srvResult.Server)
 
-	// 监控服务器错误
-	lifecycle.MonitorErrorChannel(ctx, cancel, srvResult.ErrCh)
-	log.FromContext(ctx).Infof("grpc server started")
+	// ACL规则已在第1阶段加载；按NSM_ACL_ON_ERROR回退为deny-all时策略组件报告为不健康，
+	// 直到重新加载成功
+	env.Health.Set(health.ComponentPolicy, !cfg.ACLFallback)
 
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 6: register nse with nsm")
-	// ********************************************************************************
-	registryClient, err := registry.NewClient(ctx, registry.Options{
-		ConnectTo:   &cfg.ConnectTo,
-		Policies:    cfg.RegistryClientPolicies,
-		DialOptions: clientOptions,
-	})
-	if err != nil {
-		logrus.Fatalf("error creating registry client: %+v", err)
+	// ACL配置文件变化时重新加载规则并替换到所有活动连接
+	if cfg.ACLReloadInterval > 0 {
+		log.FromContext(ctx).Infof("watching ACL config file %s for changes every %v", cfg.ACLConfigPath, cfg.ACLReloadInterval)
+		aclserver.NewReloader(firewallEndpoint.ACL(), cfg.ACLConfigPath, f.envACLRules, func(loaded bool) {
+			env.Health.Set(health.ComponentPolicy, loaded)
+		}).Watch(ctx, cfg.ACLReloadInterval)
 	}
 
-	nse, err := registryClient.Register(ctx, registry.RegisterSpec{
-		Name:        cfg.Name,
-		ServiceName: cfg.ServiceName,
-		Labels:      cfg.Labels,
-		URL:         srvResult.ListenURL.String(),
-	})
-	if err != nil {
-		log.FromContext(ctx).Fatalf("unable to register nse %+v", err)
+	// ACL命中计数：启用VPP统计计数器，从统计段读取并导出指标
+	// 命中计数只用于观测，统计段无法连接时记录警告并在没有计数的情况下运行
+	var statsClient *statsclient.StatsClient
+	if cfg.ACLStatsSocket != "" {
+		if err := aclstats.Enable(ctx, env.VPPConn); err != nil {
+			return nil, errors.Wrap(err, "error enabling ACL stats")
+		}
+		statsClient = statsclient.NewStatsClient(cfg.ACLStatsSocket)
+		if err := statsClient.Connect(); err != nil {
+			log.FromContext(ctx).Warnf("error connecting to VPP stats socket %s, running without ACL hit counters: %v", cfg.ACLStatsSocket, err)
+			statsClient = nil
+		}
+	}
+	if statsClient != nil {
+		env.OnClose(func() { _ = statsClient.Disconnect() })
+		if err := aclstats.RegisterMetrics(statsClient, firewallEndpoint.ACL().Bindings); err != nil {
+			return nil, errors.Wrap(err, "error registering ACL stats metrics")
+		}
 	}
-	logrus.Infof("nse: %+v", nse)
 
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("startup completed in %v", time.Since(starttime))
-	// ********************************************************************************
+	// 管理接口：查询连接的ACL会话和命中计数
+	if cfg.AdminListenOn != "" {
+		adminServer := admin.NewServer()
+		adminServer.Handle("/acl/sessions", aclsession.Handler(vppCLI, firewallEndpoint.ACL().Interfaces))
+		if statsClient != nil {
+			adminServer.Handle("/acl/counters", aclstats.Handler(statsClient, firewallEndpoint.ACL().Bindings))
+		}
+		env.Monitor(ctx, adminServer.ListenAndServe(ctx, cfg.AdminListenOn))
+		log.FromContext(ctx).Infof("admin server listening on %s", cfg.AdminListenOn)
+	}
 
-	// 等待服务器退出
-	<-ctx.Done()
-	<-vppErrCh
+	return firewallEndpoint, nil
 }
@@ -27,7 +27,6 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/govpp/binapi/acl_types"
-	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/acl"
 	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/memif"
 	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/up"
 	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/xconnect"
@@ -40,6 +39,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/recvfd"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/sendfd"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanismtranslation"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/null"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/passthrough"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
@@ -47,12 +47,18 @@ import (
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"google.golang.org/grpc"
 
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/vpp"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclserver"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/macip"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ratelimit"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/vpp"
 )
 
 // Endpoint Firewall网络服务端点
 type Endpoint struct {
 	endpoint.Endpoint
+
+	aclServer *aclserver.Server
 }
 
 // Options Firewall端点配置选项
@@ -66,9 +72,18 @@ type Options struct {
 	// Labels 端点标签
 	Labels map[string]string
 
-	// ACLRules ACL规则列表
+	// ACLRules ACL规则列表（ACLPolicy为nil时所有连接使用）
 	ACLRules []acl_types.ACLRule
 
+	// ACLPolicy 按连接标签或客户端SPIFFE ID选择的ACL配置集
+	ACLPolicy *aclrules.Policy
+
+	// RateLimit 请求限流配置（未启用任何维度时不加入限流链元素）
+	RateLimit ratelimit.Config
+
+	// MACIP 客户端接口的MACIP ACL配置（未启用时不加入MACIP链元素）
+	MACIP macip.Config
+
 	// MaxTokenLifetime token最大生命周期
 	MaxTokenLifetime time.Duration
 
@@ -85,7 +100,9 @@ type Options struct {
 // NewEndpoint 创建Firewall网络服务端点
 //
 // 创建包含完整NSM链的firewall端点，包括：
-//   - ACL规则处理
+//   - 请求限流（可选）
+//   - ACL规则处理（支持热更新）
+//   - MACIP ACL（可选）
 //   - VPP xconnect
 //   - Memif机制支持
 //   - 文件描述符传递
@@ -104,18 +121,35 @@ type Options struct {
 //	    Name:             "firewall-server",
 //	    ConnectTo:        &cfg.ConnectTo,
 //	    Labels:           cfg.Labels,
-//	    ACLRules:         cfg.ACLConfig,
+//	    ACLPolicy:        cfg.ACLPolicy,
 //	    MaxTokenLifetime: cfg.MaxTokenLifetime,
 //	    VPPConn:          vppConn,
 //	    Source:           source,
 //	    ClientOptions:    clientOptions,
 //	})
 func NewEndpoint(ctx context.Context, opts Options) *Endpoint {
-	ep := &Endpoint{}
+	ep := &Endpoint{
+		aclServer: aclserver.NewServer(opts.VPPConn, opts.ACLRules),
+	}
+	if opts.ACLPolicy != nil {
+		ep.aclServer = aclserver.NewServerWithPolicy(opts.VPPConn, opts.ACLPolicy)
+	}
 
 	// 创建token生成器
 	tokenGenerator := spiffejwt.TokenGeneratorFunc(opts.Source, opts.MaxTokenLifetime)
 
+	// 请求限流放在链的最前面，避免被拒绝的请求触发VPP编程
+	rateLimitServer := null.NewServer()
+	if opts.RateLimit.Enabled() {
+		rateLimitServer = ratelimit.NewServer(opts.RateLimit)
+	}
+
+	// MACIP ACL绑定客户端的源IP和源MAC，防止地址伪造
+	macipServer := null.NewServer()
+	if opts.MACIP.Enabled() {
+		macipServer = macip.NewServer(opts.VPPConn, opts.MACIP)
+	}
+
 	// 构建端点链
 	ep.Endpoint = endpoint.NewServer(
 		ctx,
@@ -123,6 +157,8 @@ func NewEndpoint(ctx context.Context, opts Options) *Endpoint {
 		endpoint.WithName(opts.Name),
 		endpoint.WithAuthorizeServer(authorize.NewServer()),
 		endpoint.WithAdditionalFunctionality(
+			// 请求限流
+			rateLimitServer,
 			// 接收文件描述符
 			recvfd.NewServer(),
 			// 发送文件描述符
@@ -134,7 +170,9 @@ func NewEndpoint(ctx context.Context, opts Options) *Endpoint {
 			// VPP xconnect
 			xconnect.NewServer(opts.VPPConn),
 			// ACL规则应用
-			acl.NewServer(opts.VPPConn, opts.ACLRules),
+			ep.aclServer,
+			// MACIP ACL（客户端接口）
+			macipServer,
 			// Memif机制支持
 			mechanisms.NewServer(map[string]networkservice.NetworkServiceServer{
 				memif.MECHANISM: chain.NewNetworkServiceServer(
@@ -173,3 +211,8 @@ func NewEndpoint(ctx context.Context, opts Options) *Endpoint {
 
 	return ep
 }
+
+// ACL 返回端点的ACL链元素，用于热更新规则
+func (ep *Endpoint) ACL() *aclserver.Server {
+	return ep.aclServer
+}
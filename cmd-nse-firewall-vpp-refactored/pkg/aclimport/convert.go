@@ -0,0 +1,439 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclimport
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+// Format 输入规则集的格式
+type Format string
+
+const (
+	// FormatIPTables iptables-save / ip6tables-save 输出
+	FormatIPTables Format = "iptables"
+
+	// FormatNFT nft list ruleset 输出
+	FormatNFT Format = "nft"
+)
+
+// DefaultChain 默认转换的链（NSE转发流量，对应FORWARD链）
+const DefaultChain = "FORWARD"
+
+// Options 转换选项
+type Options struct {
+	// Format 输入格式，为空时自动识别
+	Format Format
+
+	// Chain 要转换的链（iptables链名或nft的hook名，不区分大小写），为空时为FORWARD
+	Chain string
+
+	// Strict 遇到没有VPP ACL等价物的规则时返回错误（否则跳过并记录警告）
+	Strict bool
+}
+
+// Rule 转换后的ACL规则（友好语法，字段与aclrules一致）
+type Rule struct {
+	Name     string `yaml:"name"`
+	Action   string `yaml:"action"`
+	Proto    string `yaml:"proto,omitempty"`
+	Src      string `yaml:"src,omitempty"`
+	Dst      string `yaml:"dst,omitempty"`
+	Sport    string `yaml:"sport,omitempty"`
+	Dport    string `yaml:"dport,omitempty"`
+	ICMPType string `yaml:"icmp-type,omitempty"`
+	ICMPCode string `yaml:"icmp-code,omitempty"`
+
+	// Line 规则在输入中的行号
+	Line int `yaml:"-"`
+}
+
+// Warning 转换过程中跳过或近似处理的输入
+type Warning struct {
+	Line    int
+	Message string
+}
+
+// String 返回警告的可读形式
+func (w Warning) String() string {
+	if w.Line == 0 {
+		return w.Message
+	}
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
+
+// Result 转换结果
+type Result struct {
+	Format   Format
+	Chain    string
+	Rules    []Rule
+	Warnings []Warning
+}
+
+// Detect 识别输入格式
+func Detect(data []byte) (Format, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") && !strings.Contains(line, "tables-save"):
+			continue
+		case strings.HasPrefix(line, "*") || strings.HasPrefix(line, ":") || strings.HasPrefix(line, "-A ") ||
+			strings.Contains(line, "tables-save"):
+			return FormatIPTables, nil
+		case strings.HasPrefix(line, "table "):
+			return FormatNFT, nil
+		default:
+			return "", errors.Errorf("unrecognized input format (expected iptables-save or nft list ruleset output): %q", line)
+		}
+	}
+	return "", errors.New("empty input")
+}
+
+// Convert 将iptables-save或nft规则集中一个链的规则转换为防火墙ACL规则
+//
+// 规则保持输入中的顺序，链的默认策略（policy）转换为最后的兜底规则。
+// 没有VPP ACL等价物的规则（接口匹配、连接状态、取反、跳转到其他链等）
+// 在Strict模式下返回带行号的错误，否则跳过并记录警告。
+// 转换结果经过aclrules的解析和校验。
+func Convert(data []byte, opts Options) (*Result, error) {
+	if opts.Chain == "" {
+		opts.Chain = DefaultChain
+	}
+	if opts.Format == "" {
+		format, err := Detect(data)
+		if err != nil {
+			return nil, err
+		}
+		opts.Format = format
+	}
+
+	c := &converter{opts: opts, names: make(map[string]int), otherChains: make(map[string]int)}
+	var err error
+	switch opts.Format {
+	case FormatIPTables:
+		err = c.iptables(data)
+	case FormatNFT:
+		err = c.nft(data)
+	default:
+		return nil, errors.Errorf("unknown format %q (expected iptables or nft)", opts.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.warnSkipped()
+
+	result := &Result{Format: opts.Format, Chain: opts.Chain, Rules: c.rules, Warnings: c.warnings}
+	out, err := result.YAML()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := aclrules.Parse(out); err != nil {
+		return nil, errors.Wrap(err, "converted rules are invalid")
+	}
+	return result, nil
+}
+
+// YAML 返回ACL配置文件（列表形式），警告作为注释写在文件开头
+func (r *Result) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Converted from %s (chain %s)\n", r.Format, r.Chain)
+	for _, w := range r.Warnings {
+		fmt.Fprintf(&buf, "# warning: %s\n", w)
+	}
+	if len(r.Rules) == 0 {
+		buf.WriteString("[]\n")
+		return buf.Bytes(), nil
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(r.Rules); err != nil {
+		return nil, errors.Wrap(err, "failed to encode ACL rules")
+	}
+	if err := enc.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to encode ACL rules")
+	}
+	return buf.Bytes(), nil
+}
+
+// family 地址族（0表示未确定，规则同时匹配IPv4和IPv6）
+type family int
+
+const (
+	familyAny  family = 0
+	familyIPv4 family = 4
+	familyIPv6 family = 6
+)
+
+// match 一条输入规则的匹配条件和动作
+type match struct {
+	line     int
+	chain    string
+	index    int
+	family   family
+	comment  string
+	action   string
+	proto    string
+	srcs     []string
+	dsts     []string
+	sports   []string
+	dports   []string
+	icmpType string
+	icmpCode string
+}
+
+// converter 转换状态
+type converter struct {
+	opts     Options
+	rules    []Rule
+	warnings []Warning
+	names    map[string]int
+
+	// otherChains 未转换的链中的规则数
+	otherChains map[string]int
+}
+
+func (c *converter) warn(line int, format string, args ...interface{}) {
+	c.warnings = append(c.warnings, Warning{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// errSkipped 规则被跳过（警告已记录）
+var errSkipped = errors.New("rule skipped")
+
+// skip 处理没有VPP ACL等价物的规则：Strict模式返回错误，否则记录警告并返回errSkipped
+func (c *converter) skip(line int, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if c.opts.Strict {
+		return errors.Errorf("line %d: %s", line, msg)
+	}
+	c.warn(line, "%s (rule skipped)", msg)
+	return errSkipped
+}
+
+// skipped 忽略errSkipped（规则被跳过时继续转换）
+func skipped(err error) error {
+	if errors.Is(err, errSkipped) {
+		return nil
+	}
+	return err
+}
+
+func (c *converter) selected(chain string) bool {
+	return strings.EqualFold(chain, c.opts.Chain)
+}
+
+func (c *converter) warnSkipped() {
+	for chain, n := range c.otherChains {
+		c.warn(0, "%d rules in chain %s were not converted (only chain %s is converted)", n, chain, c.opts.Chain)
+	}
+}
+
+// policy 将链的默认策略转换为兜底规则
+func (c *converter) policy(line int, chain, policy string, fam family) error {
+	var action string
+	switch strings.ToLower(policy) {
+	case "accept":
+		action = aclrules.ActionPermit
+	case "drop":
+		action = aclrules.ActionDeny
+	default:
+		return c.skip(line, "unsupported policy %q of chain %s", policy, chain)
+	}
+	return c.add(&match{line: line, chain: chain, family: fam, comment: fmt.Sprintf("%s policy", strings.ToLower(chain)), action: action})
+}
+
+// add 展开地址、端口和地址族的组合，追加转换后的规则
+func (c *converter) add(m *match) error {
+	srcs, dsts := orAny(m.srcs), orAny(m.dsts)
+	sports, dports := orAny(m.sports), orAny(m.dports)
+
+	base := m.comment
+	if base == "" {
+		base = fmt.Sprintf("%s-%d", strings.ToLower(m.chain), m.index)
+	}
+
+	var rules []Rule
+	for _, src := range srcs {
+		for _, dst := range dsts {
+			fam, err := addrFamily(m.family, src, dst)
+			if err != nil {
+				return c.skip(m.line, "%v", err)
+			}
+			if (m.proto == "icmp" && fam == familyIPv6) || (m.proto == "icmpv6" && fam == familyIPv4) {
+				continue
+			}
+			for _, sport := range sports {
+				for _, dport := range dports {
+					r := Rule{
+						Action: m.action, Proto: m.proto, Src: src, Dst: dst,
+						Sport: sport, Dport: dport, ICMPType: m.icmpType, ICMPCode: m.icmpCode, Line: m.line,
+					}
+					// 友好语法中未指定地址的规则同时匹配IPv4和IPv6，单一地址族的表需要显式的通配前缀
+					if r.Src == "" && r.Dst == "" && fam != familyAny {
+						r.Src = wildcard(fam)
+					}
+					rules = append(rules, r)
+				}
+			}
+		}
+	}
+
+	for i := range rules {
+		name := base
+		if len(rules) > 1 {
+			name = fmt.Sprintf("%s #%d", base, i+1)
+		}
+		rules[i].Name = c.uniqueName(name)
+	}
+	c.rules = append(c.rules, rules...)
+	return nil
+}
+
+func (c *converter) uniqueName(name string) string {
+	c.names[name]++
+	if n := c.names[name]; n > 1 {
+		return c.uniqueName(fmt.Sprintf("%s (%d)", name, n))
+	}
+	return name
+}
+
+// orAny 空列表表示不限制，展开时作为一个空值
+func orAny(values []string) []string {
+	if len(values) == 0 {
+		return []string{""}
+	}
+	return values
+}
+
+func wildcard(f family) string {
+	if f == familyIPv6 {
+		return "::/0"
+	}
+	return "0.0.0.0/0"
+}
+
+// addrFamily 根据地址确定规则的地址族
+func addrFamily(fam family, addrs ...string) (family, error) {
+	for _, a := range addrs {
+		if a == "" {
+			continue
+		}
+		f := familyIPv4
+		if prefix, err := netip.ParsePrefix(a); err == nil && prefix.Addr().Is6() {
+			f = familyIPv6
+		} else if addr, err := netip.ParseAddr(a); err == nil && addr.Is6() {
+			f = familyIPv6
+		}
+		if fam != familyAny && fam != f {
+			return fam, errors.Errorf("address %s does not match the address family of the rule", a)
+		}
+		fam = f
+	}
+	return fam, nil
+}
+
+// checkAddr 检查地址是否为IP或CIDR
+func checkAddr(value string) error {
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return nil
+	}
+	if _, err := netip.ParseAddr(value); err == nil {
+		return nil
+	}
+	return errors.Errorf("invalid address %q", value)
+}
+
+// protocol 规范化协议名称
+func protocol(value string) (string, bool) {
+	switch v := strings.ToLower(value); v {
+	case "all", "any", "0":
+		return "", true
+	case "tcp", "udp", "icmp":
+		return v, true
+	case "icmpv6", "ipv6-icmp", "icmp6", "58":
+		return "icmpv6", true
+	case "6":
+		return "tcp", true
+	case "17":
+		return "udp", true
+	case "1":
+		return "icmp", true
+	}
+	return "", false
+}
+
+// icmpTypes 常用ICMP类型名称
+var icmpTypes = map[string]map[string]string{
+	"icmp": {
+		"echo-reply":              "0",
+		"destination-unreachable": "3",
+		"echo-request":            "8",
+		"time-exceeded":           "11",
+	},
+	"icmpv6": {
+		"destination-unreachable": "1",
+		"packet-too-big":          "2",
+		"time-exceeded":           "3",
+		"echo-request":            "128",
+		"echo-reply":              "129",
+	},
+}
+
+// tokenize 按空白切分一行，支持双引号和反斜杠转义
+func tokenize(line string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	inQuote, escaped, inToken := false, false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped, inToken = true, true
+		case r == '"':
+			inQuote, inToken = !inQuote, true
+		case !inQuote && (r == ' ' || r == '\t'):
+			if inToken {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				inToken = false
+			}
+		default:
+			cur.WriteRune(r)
+			inToken = true
+		}
+	}
+	if inQuote {
+		return nil, errors.New("unterminated quote")
+	}
+	if inToken {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
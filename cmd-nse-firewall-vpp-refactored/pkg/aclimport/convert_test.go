@@ -0,0 +1,230 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclimport_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclimport"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+const iptablesSave = `# Generated by iptables-save v1.8.7 on Mon Oct 19 10:00:00 2026
+*nat
+:PREROUTING ACCEPT [0:0]
+-A PREROUTING -p tcp --dport 80 -j DNAT --to-destination 10.0.0.1
+COMMIT
+*filter
+:INPUT ACCEPT [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT ACCEPT [0:0]
+-A INPUT -i lo -j ACCEPT
+-A FORWARD -s 10.0.0.0/8 -d 192.168.1.10/32 -p tcp -m tcp --dport 443 -m comment --comment "web from corp" -j ACCEPT
+-A FORWARD -p udp -m multiport --dports 53,123 -j ACCEPT
+-A FORWARD -p tcp -m tcp --sport 1024:65535 --dport 8000:8080 -j ACCEPT
+-A FORWARD -p icmp -m icmp --icmp-type echo-request -j ACCEPT
+-A FORWARD -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT
+-A FORWARD -i eth0 -j DROP
+-A FORWARD -s 172.16.0.0/12 -j REJECT --reject-with icmp-port-unreachable
+COMMIT
+# Generated by ip6tables-save v1.8.7 on Mon Oct 19 10:00:00 2026
+*filter
+:FORWARD ACCEPT [0:0]
+-A FORWARD -p ipv6-icmp -j ACCEPT
+-A FORWARD ! -s fd00::/8 -j DROP
+COMMIT
+`
+
+func names(rules []aclimport.Rule) []string {
+	result := make([]string, 0, len(rules))
+	for _, r := range rules {
+		result = append(result, r.Name)
+	}
+	return result
+}
+
+func TestConvert_IPTables(t *testing.T) {
+	result, err := aclimport.Convert([]byte(iptablesSave), aclimport.Options{})
+	require.NoError(t, err)
+	require.Equal(t, aclimport.FormatIPTables, result.Format)
+	require.Equal(t, []string{
+		"web from corp",
+		"forward-2 #1",
+		"forward-2 #2",
+		"forward-3",
+		"forward-4",
+		"forward-7",
+		"forward policy",
+		"forward-8",
+		"forward policy (2)",
+	}, names(result.Rules))
+
+	require.Equal(t, aclimport.Rule{
+		Name: "web from corp", Action: aclrules.ActionPermit, Proto: "tcp",
+		Src: "10.0.0.0/8", Dst: "192.168.1.10/32", Dport: "443", Line: 11,
+	}, result.Rules[0])
+	require.Equal(t, "123", result.Rules[2].Dport)
+	require.Equal(t, "1024-65535", result.Rules[3].Sport)
+	require.Equal(t, "8000-8080", result.Rules[3].Dport)
+	require.Equal(t, "8", result.Rules[4].ICMPType)
+	require.Equal(t, aclrules.ActionDeny, result.Rules[5].Action, "REJECT转换为deny")
+	require.Equal(t, aclrules.ActionDeny, result.Rules[6].Action, "FORWARD DROP策略转换为兜底规则")
+	require.Equal(t, "::/0", result.Rules[7].Src, "ip6tables规则为IPv6")
+	require.Equal(t, "icmpv6", result.Rules[7].Proto)
+	require.Equal(t, aclrules.ActionPermit, result.Rules[8].Action)
+
+	warnings := make([]string, 0, len(result.Warnings))
+	for _, w := range result.Warnings {
+		warnings = append(warnings, w.String())
+	}
+	require.Equal(t, []string{
+		"line 4: table nat skipped (only the filter table is converted)",
+		`line 15: match module "conntrack" has no VPP ACL equivalent (rule skipped)`,
+		"line 16: option -i has no VPP ACL equivalent (rule skipped)",
+		"line 17: REJECT converted to deny (VPP ACL drops packets without a reply)",
+		"line 23: negated match ! -s fd00::/8 has no VPP ACL equivalent (rule skipped)",
+		"1 rules in chain INPUT were not converted (only chain FORWARD is converted)",
+	}, warnings)
+
+	// 输出可以被ACL配置文件的加载逻辑读回
+	out, err := result.YAML()
+	require.NoError(t, err)
+	require.Contains(t, string(out), "# warning: line 16: option -i has no VPP ACL equivalent (rule skipped)")
+	rules, err := aclrules.Parse(out)
+	require.NoError(t, err)
+	require.Len(t, rules, len(result.Rules))
+	require.Equal(t, uint16(443), rules[0].DstportOrIcmpcodeFirst)
+}
+
+func TestConvert_Strict(t *testing.T) {
+	_, err := aclimport.Convert([]byte(iptablesSave), aclimport.Options{Strict: true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 15")
+
+	result, err := aclimport.Convert([]byte(iptablesSave), aclimport.Options{Chain: "input", Strict: true, Format: aclimport.FormatIPTables})
+	require.Error(t, err, "INPUT链的-i lo同样无法转换")
+	require.Nil(t, result)
+}
+
+const nftRuleset = `table inet filter {
+	set blocked {
+		type ipv4_addr
+		elements = { 198.51.100.1, 198.51.100.2 }
+	}
+
+	chain input {
+		type filter hook input priority filter; policy accept;
+		iifname "lo" accept
+	}
+
+	chain forward {
+		type filter hook forward priority filter; policy drop;
+		ip saddr 10.0.0.0/8 tcp dport { 80, 443 } counter packets 12 bytes 3400 accept comment "web"
+		ip6 daddr fd00::/8 udp dport 53 accept
+		tcp dport 8000-8080 drop
+		icmp type echo-request accept
+		ct state established,related accept
+		ip saddr @blocked drop
+		ip saddr != 10.0.0.0/8 drop
+		reject with icmpx type admin-prohibited
+	}
+}
+table bridge br {
+	chain forward {
+		type filter hook forward priority 0; policy accept;
+	}
+}
+`
+
+func TestConvert_NFT(t *testing.T) {
+	result, err := aclimport.Convert([]byte(nftRuleset), aclimport.Options{Chain: "forward"})
+	require.NoError(t, err)
+	require.Equal(t, aclimport.FormatNFT, result.Format)
+	require.Equal(t, []string{
+		"web #1",
+		"web #2",
+		"forward-2",
+		"forward-3",
+		"forward-4",
+		"forward-8",
+		"forward policy",
+	}, names(result.Rules))
+
+	require.Equal(t, "10.0.0.0/8", result.Rules[0].Src)
+	require.Equal(t, "80", result.Rules[0].Dport)
+	require.Equal(t, "443", result.Rules[1].Dport)
+	require.Equal(t, "fd00::/8", result.Rules[2].Dst)
+	require.Empty(t, result.Rules[3].Src, "inet表中没有地址的规则不指定地址族")
+	require.Empty(t, result.Rules[3].Dst)
+	require.Equal(t, "8", result.Rules[4].ICMPType)
+	require.Empty(t, result.Rules[4].Src)
+	require.Equal(t, aclrules.ActionDeny, result.Rules[6].Action)
+
+	var warnings []string
+	for _, w := range result.Warnings {
+		warnings = append(warnings, w.String())
+	}
+	require.Contains(t, warnings, `line 18: statement "ct" has no VPP ACL equivalent (rule skipped)`)
+	require.Contains(t, warnings, "line 19: named set @blocked has no VPP ACL equivalent (rule skipped)")
+	require.Contains(t, warnings, "line 20: negated match has no VPP ACL equivalent (rule skipped)")
+	require.Contains(t, warnings, "line 24: table bridge br skipped (only ip, ip6 and inet tables are converted)")
+	require.Contains(t, warnings, "1 rules in chain input were not converted (only chain forward is converted)")
+
+	// 加载时没有地址的规则展开为IPv4和IPv6，icmp规则只匹配IPv4
+	out, err := result.YAML()
+	require.NoError(t, err)
+	rules, err := aclrules.Parse(out)
+	require.NoError(t, err)
+	parsed := make([]string, 0, len(rules))
+	for _, r := range rules {
+		parsed = append(parsed, r.Name+" "+r.SrcPrefix.String())
+	}
+	require.Equal(t, []string{
+		"web #1 10.0.0.0/8",
+		"web #2 10.0.0.0/8",
+		"forward-2 ::/0",
+		"forward-3 0.0.0.0/0",
+		"forward-3#ipv6 ::/0",
+		"forward-4 0.0.0.0/0",
+		"forward-8 0.0.0.0/0",
+		"forward-8#ipv6 ::/0",
+		"forward policy 0.0.0.0/0",
+		"forward policy#ipv6 ::/0",
+	}, parsed)
+}
+
+func TestDetect(t *testing.T) {
+	format, err := aclimport.Detect([]byte(iptablesSave))
+	require.NoError(t, err)
+	require.Equal(t, aclimport.FormatIPTables, format)
+
+	format, err = aclimport.Detect([]byte("\n" + nftRuleset))
+	require.NoError(t, err)
+	require.Equal(t, aclimport.FormatNFT, format)
+
+	_, err = aclimport.Detect([]byte("hello world"))
+	require.Error(t, err)
+	_, err = aclimport.Convert([]byte(strings.Repeat("\n", 3)), aclimport.Options{})
+	require.Error(t, err)
+}
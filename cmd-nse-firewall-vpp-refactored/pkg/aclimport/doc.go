@@ -0,0 +1,27 @@
+// Package aclimport 将内核防火墙规则集转换为防火墙ACL配置文件
+//
+// 从内核防火墙迁移时，通常已有iptables-save或nft list ruleset的输出。
+// 本包转换其中一个链（默认FORWARD，nft中为hook forward的filter基础链）的规则，
+// 按规则顺序生成aclrules的友好语法（列表形式）：
+//   - iptables: -s/-d、-p、--sport/--dport、-m multiport --sports/--dports、
+//     --icmp-type、-m comment --comment、-j ACCEPT/DROP/REJECT
+//   - nft: ip/ip6 saddr/daddr、tcp/udp sport/dport、icmp/icmpv6 type/code、
+//     meta l4proto、匿名集合"{ a, b }"、comment、accept/drop/reject
+//
+// 链的默认策略转换为最后的兜底规则（VPP ACL末尾隐式拒绝，accept策略需要显式的permit规则）。
+// 规则注释作为规则名称，没有注释时名称为"<链>-<序号>"；地址或端口列表展开为多条规则。
+//
+// 没有VPP ACL等价物的构造（接口匹配、连接状态、取反、命名集合、跳转到其他链、
+// LOG等目标）默认跳过并记录警告，Strict模式下返回错误。REJECT转换为deny并记录警告。
+//
+// 使用示例：
+//
+//	result, err := aclimport.Convert(data, aclimport.Options{Chain: "FORWARD"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, w := range result.Warnings {
+//	    log.Println(w)
+//	}
+//	out, _ := result.YAML()
+package aclimport
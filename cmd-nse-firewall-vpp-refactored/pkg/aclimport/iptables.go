@@ -0,0 +1,256 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclimport
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+// iptablesModules 可以转换的匹配模块（-m）
+var iptablesModules = map[string]bool{
+	"tcp":       true,
+	"udp":       true,
+	"icmp":      true,
+	"icmp6":     true,
+	"multiport": true,
+	"comment":   true,
+}
+
+// chainPolicy 链的默认策略，在链的所有规则之后生效
+type chainPolicy struct {
+	line   int
+	chain  string
+	policy string
+	family family
+}
+
+// iptables 转换iptables-save / ip6tables-save输出的filter表
+//
+// 文件头注释"Generated by ip6tables-save"表示后续规则为IPv6。
+// 链的默认策略在表的COMMIT处转换为兜底规则。
+func (c *converter) iptables(data []byte) error {
+	fam := familyIPv4
+	table := ""
+	index := make(map[string]int)
+	skippedTables := make(map[string]bool)
+	var policies []chainPolicy
+
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			if strings.Contains(line, "ip6tables-save") {
+				fam = familyIPv6
+			} else if strings.Contains(line, "iptables-save") {
+				fam = familyIPv4
+			}
+		case strings.HasPrefix(line, "*"):
+			table = strings.TrimPrefix(line, "*")
+		case line == "COMMIT":
+			for _, p := range policies {
+				if err := skipped(c.policy(p.line, p.chain, p.policy, p.family)); err != nil {
+					return err
+				}
+			}
+			policies = nil
+			table = ""
+		case table != "filter":
+			if strings.HasPrefix(line, "-A ") && !skippedTables[table] {
+				skippedTables[table] = true
+				c.warn(lineNo, "table %s skipped (only the filter table is converted)", table)
+			}
+		case strings.HasPrefix(line, ":"):
+			fields := strings.Fields(strings.TrimPrefix(line, ":"))
+			if len(fields) >= 2 && c.selected(fields[0]) && fields[1] != "-" {
+				policies = append(policies, chainPolicy{line: lineNo, chain: fields[0], policy: fields[1], family: fam})
+			}
+		case strings.HasPrefix(line, "-A "):
+			tokens, err := tokenize(line)
+			if err != nil {
+				return errors.Errorf("line %d: %v", lineNo, err)
+			}
+			if len(tokens) < 2 {
+				return errors.Errorf("line %d: missing chain name", lineNo)
+			}
+			chain := tokens[1]
+			if !c.selected(chain) {
+				c.otherChains[chain]++
+				continue
+			}
+			index[chain]++
+			m := &match{line: lineNo, chain: chain, index: index[chain], family: fam}
+			err = c.iptablesRule(m, tokens[2:])
+			if err == nil {
+				err = c.add(m)
+			}
+			if err := skipped(err); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("line %d: unexpected line %q", lineNo, line)
+		}
+	}
+	if table != "" {
+		return errors.Errorf("table %s has no COMMIT", table)
+	}
+	return nil
+}
+
+// iptablesRule 解析-A之后的选项
+//
+// 规则被跳过时返回errSkipped（警告已记录）。
+func (c *converter) iptablesRule(m *match, tokens []string) error {
+	value := func(i int) (string, error) {
+		if i+1 >= len(tokens) {
+			return "", errors.Errorf("line %d: option %s requires a value", m.line, tokens[i])
+		}
+		return tokens[i+1], nil
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		opt := tokens[i]
+		if opt == "!" {
+			return c.skip(m.line, "negated match %s has no VPP ACL equivalent", strings.Join(tokens[i:min(i+3, len(tokens))], " "))
+		}
+		if !strings.HasPrefix(opt, "-") {
+			return errors.Errorf("line %d: unexpected argument %q", m.line, opt)
+		}
+		v, err := value(i)
+		if err != nil {
+			return err
+		}
+		i++
+
+		switch opt {
+		case "-s", "--source":
+			if m.srcs, err = addrList(m.line, v); err != nil {
+				return err
+			}
+		case "-d", "--destination":
+			if m.dsts, err = addrList(m.line, v); err != nil {
+				return err
+			}
+		case "-p", "--protocol":
+			proto, ok := protocol(v)
+			if !ok {
+				return c.skip(m.line, "protocol %q has no VPP ACL equivalent", v)
+			}
+			m.proto = proto
+		case "-m", "--match":
+			if !iptablesModules[v] {
+				return c.skip(m.line, "match module %q has no VPP ACL equivalent", v)
+			}
+		case "--dport", "--destination-port":
+			m.dports = []string{portRange(v)}
+		case "--sport", "--source-port":
+			m.sports = []string{portRange(v)}
+		case "--dports", "--destination-ports":
+			m.dports = portList(v)
+		case "--sports", "--source-ports":
+			m.sports = portList(v)
+		case "--icmp-type", "--icmpv6-type":
+			if err := c.icmpType(m, v); err != nil {
+				return err
+			}
+		case "--comment":
+			m.comment = v
+		case "-j", "--jump":
+			switch v {
+			case "ACCEPT":
+				m.action = aclrules.ActionPermit
+			case "DROP":
+				m.action = aclrules.ActionDeny
+			case "REJECT":
+				m.action = aclrules.ActionDeny
+				c.warn(m.line, "REJECT converted to deny (VPP ACL drops packets without a reply)")
+			default:
+				return c.skip(m.line, "target %s has no VPP ACL equivalent", v)
+			}
+		case "--reject-with":
+		default:
+			return c.skip(m.line, "option %s has no VPP ACL equivalent", opt)
+		}
+	}
+
+	if m.action == "" {
+		return c.skip(m.line, "rule has no ACCEPT or DROP target")
+	}
+	return nil
+}
+
+// icmpType 解析ICMP类型（数字、"类型/代码"或常用名称）
+func (c *converter) icmpType(m *match, v string) error {
+	if t, ok := icmpTypes[m.proto][v]; ok {
+		m.icmpType = t
+		return nil
+	}
+	t, code, hasCode := strings.Cut(v, "/")
+	if !isNumber(t) || (hasCode && !isNumber(code)) {
+		return c.skip(m.line, "unknown ICMP type %q", v)
+	}
+	m.icmpType = t
+	if hasCode {
+		m.icmpCode = code
+	}
+	return nil
+}
+
+// addrList 解析逗号分隔的地址列表
+func addrList(line int, v string) ([]string, error) {
+	addrs := strings.Split(v, ",")
+	for _, a := range addrs {
+		if err := checkAddr(a); err != nil {
+			return nil, errors.Errorf("line %d: %v", line, err)
+		}
+	}
+	return addrs, nil
+}
+
+// portRange 将iptables的端口范围（80:90）转换为友好语法（80-90）
+func portRange(v string) string {
+	return strings.Replace(v, ":", "-", 1)
+}
+
+func portList(v string) []string {
+	var ports []string
+	for _, p := range strings.Split(v, ",") {
+		ports = append(ports, portRange(p))
+	}
+	return ports
+}
+
+func isNumber(v string) bool {
+	if v == "" {
+		return false
+	}
+	for _, r := range v {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
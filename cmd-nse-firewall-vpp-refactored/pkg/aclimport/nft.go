@@ -0,0 +1,336 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclimport
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+// nftFamilies 可以转换的nft表地址族
+var nftFamilies = map[string]family{
+	"ip":   familyIPv4,
+	"ip6":  familyIPv6,
+	"inet": familyAny,
+}
+
+// nftChain 正在解析的nft链
+type nftChain struct {
+	name     string
+	selected bool
+	policy   *chainPolicy
+	index    int
+}
+
+// nft 转换nft list ruleset输出中hook为Options.Chain的filter基础链
+//
+// 只转换ip、ip6和inet表；set、map等定义块被忽略，引用命名集合的规则被跳过。
+// 多个表中有同一hook的基础链时规则按出现顺序拼接，并记录警告。
+func (c *converter) nft(data []byte) error {
+	depth := 0
+	skipUntil := -1
+	fam := familyAny
+	var chain *nftChain
+	chains := 0
+
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		opens, closes := strings.Count(line, "{"), strings.Count(line, "}")
+
+		// 忽略的块（不支持的表、set、map等）
+		if skipUntil >= 0 {
+			depth += opens - closes
+			if depth <= skipUntil {
+				skipUntil = -1
+			}
+			continue
+		}
+
+		tokens, err := tokenize(line)
+		if err != nil {
+			return errors.Errorf("line %d: %v", lineNo, err)
+		}
+
+		switch {
+		case line == "}":
+			if depth == 2 && chain != nil {
+				if chain.policy != nil {
+					if err := skipped(c.policy(chain.policy.line, chain.name, chain.policy.policy, chain.policy.family)); err != nil {
+						return err
+					}
+				}
+				chain = nil
+			}
+			depth--
+		case depth == 0 && tokens[0] == "table":
+			if len(tokens) < 3 {
+				return errors.Errorf("line %d: invalid table %q", lineNo, line)
+			}
+			f, ok := nftFamilies[tokens[1]]
+			if !ok {
+				c.warn(lineNo, "table %s %s skipped (only ip, ip6 and inet tables are converted)", tokens[1], tokens[2])
+				skipUntil = depth
+			}
+			fam = f
+			depth += opens - closes
+		case depth == 1 && tokens[0] == "chain":
+			if len(tokens) < 2 {
+				return errors.Errorf("line %d: invalid chain %q", lineNo, line)
+			}
+			chain = &nftChain{name: tokens[1]}
+			depth += opens - closes
+		case depth == 1:
+			// set、map、flowtable等定义
+			if opens > closes {
+				skipUntil = depth
+				depth += opens - closes
+			}
+		case depth == 2 && chain != nil && tokens[0] == "type":
+			hook, policy := nftChainHeader(line)
+			if strings.Contains(line, "type filter") && c.selected(hook) {
+				chain.selected = true
+				chains++
+				if chains == 2 {
+					c.warn(lineNo, "multiple base chains on hook %s: rules are concatenated in order, which is stricter or looser than nftables where every base chain must accept", hook)
+				}
+				if policy == "" {
+					policy = "accept"
+				}
+				chain.policy = &chainPolicy{line: lineNo, chain: chain.name, policy: policy, family: fam}
+			}
+		case depth == 2 && chain != nil:
+			if !chain.selected {
+				c.otherChains[chain.name]++
+				continue
+			}
+			chain.index++
+			m := &match{line: lineNo, chain: chain.name, index: chain.index, family: fam}
+			err := c.nftRule(m, tokens)
+			if err == nil {
+				err = c.add(m)
+			}
+			if err := skipped(err); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("line %d: unexpected line %q", lineNo, line)
+		}
+	}
+	if depth != 0 {
+		return errors.New("unbalanced braces in ruleset")
+	}
+	return nil
+}
+
+// nftChainHeader 解析"type filter hook forward priority filter; policy drop;"
+func nftChainHeader(line string) (hook, policy string) {
+	for _, part := range strings.Split(line, ";") {
+		fields := strings.Fields(part)
+		for i := 0; i+1 < len(fields); i++ {
+			switch fields[i] {
+			case "hook":
+				hook = fields[i+1]
+			case "policy":
+				policy = fields[i+1]
+			}
+		}
+	}
+	return hook, policy
+}
+
+// nftRule 解析nft规则语句
+//
+// 规则被跳过时返回errSkipped（警告已记录）。
+func (c *converter) nftRule(m *match, tokens []string) error {
+	for i := 0; i < len(tokens); {
+		t := tokens[i]
+		switch t {
+		case "ip", "ip6":
+			f := familyIPv4
+			if t == "ip6" {
+				f = familyIPv6
+			}
+			if m.family != familyAny && m.family != f {
+				return c.skip(m.line, "%s match in a rule of another address family", t)
+			}
+			m.family = f
+			if i+1 >= len(tokens) {
+				return errors.Errorf("line %d: incomplete %s match", m.line, t)
+			}
+			field := tokens[i+1]
+			values, next, err := c.nftValues(m, tokens, i+2)
+			if err != nil {
+				return err
+			}
+			switch field {
+			case "saddr", "daddr":
+				for _, v := range values {
+					if checkAddr(v) != nil {
+						return c.skip(m.line, "address %q has no VPP ACL equivalent", v)
+					}
+				}
+				if field == "saddr" {
+					m.srcs = values
+				} else {
+					m.dsts = values
+				}
+			case "protocol", "nexthdr":
+				if err := c.nftProto(m, values); err != nil {
+					return err
+				}
+			default:
+				return c.skip(m.line, "match %s %s has no VPP ACL equivalent", t, field)
+			}
+			i = next
+		case "tcp", "udp":
+			if i+1 >= len(tokens) || (tokens[i+1] != "dport" && tokens[i+1] != "sport") {
+				return c.skip(m.line, "match %s has no VPP ACL equivalent", strings.Join(tokens[i:min(i+2, len(tokens))], " "))
+			}
+			values, next, err := c.nftValues(m, tokens, i+2)
+			if err != nil {
+				return err
+			}
+			m.proto = t
+			if tokens[i+1] == "dport" {
+				m.dports = values
+			} else {
+				m.sports = values
+			}
+			i = next
+		case "icmp", "icmpv6":
+			if i+1 >= len(tokens) || (tokens[i+1] != "type" && tokens[i+1] != "code") {
+				return c.skip(m.line, "match %s has no VPP ACL equivalent", strings.Join(tokens[i:min(i+2, len(tokens))], " "))
+			}
+			values, next, err := c.nftValues(m, tokens, i+2)
+			if err != nil {
+				return err
+			}
+			if len(values) != 1 {
+				return c.skip(m.line, "ICMP %s set has no VPP ACL equivalent", tokens[i+1])
+			}
+			m.proto = t
+			if tokens[i+1] == "type" {
+				if err := c.icmpType(m, values[0]); err != nil {
+					return err
+				}
+			} else if isNumber(values[0]) {
+				m.icmpCode = values[0]
+			} else {
+				return c.skip(m.line, "unknown ICMP code %q", values[0])
+			}
+			i = next
+		case "meta":
+			if i+1 >= len(tokens) || tokens[i+1] != "l4proto" {
+				return c.skip(m.line, "match %s has no VPP ACL equivalent", strings.Join(tokens[i:min(i+2, len(tokens))], " "))
+			}
+			values, next, err := c.nftValues(m, tokens, i+2)
+			if err != nil {
+				return err
+			}
+			if err := c.nftProto(m, values); err != nil {
+				return err
+			}
+			i = next
+		case "counter":
+			i++
+			if i+3 < len(tokens) && tokens[i] == "packets" && tokens[i+2] == "bytes" {
+				i += 4
+			}
+		case "comment":
+			if i+1 >= len(tokens) {
+				return errors.Errorf("line %d: comment requires a value", m.line)
+			}
+			m.comment = tokens[i+1]
+			i += 2
+		case "accept":
+			m.action = aclrules.ActionPermit
+			i++
+		case "drop":
+			m.action = aclrules.ActionDeny
+			i++
+		case "reject":
+			m.action = aclrules.ActionDeny
+			c.warn(m.line, "reject converted to deny (VPP ACL drops packets without a reply)")
+			// reject with icmp type ...
+			i = len(tokens)
+		default:
+			return c.skip(m.line, "statement %q has no VPP ACL equivalent", t)
+		}
+	}
+
+	if m.action == "" {
+		return c.skip(m.line, "rule has no accept or drop verdict")
+	}
+	return nil
+}
+
+// nftProto 设置协议（meta l4proto / ip protocol）
+func (c *converter) nftProto(m *match, values []string) error {
+	if len(values) != 1 {
+		return c.skip(m.line, "protocol set has no VPP ACL equivalent")
+	}
+	proto, ok := protocol(values[0])
+	if !ok {
+		return c.skip(m.line, "protocol %q has no VPP ACL equivalent", values[0])
+	}
+	m.proto = proto
+	return nil
+}
+
+// nftValues 解析位置i的值：单个值或匿名集合"{ a, b }"，返回值和下一个语句的位置
+func (c *converter) nftValues(m *match, tokens []string, i int) ([]string, int, error) {
+	if i >= len(tokens) {
+		return nil, i, errors.Errorf("line %d: missing value", m.line)
+	}
+	switch {
+	case tokens[i] == "!=" || strings.HasPrefix(tokens[i], "!"):
+		return nil, i, c.skip(m.line, "negated match has no VPP ACL equivalent")
+	case tokens[i] == "==":
+		return c.nftValues(m, tokens, i+1)
+	case strings.HasPrefix(tokens[i], "@"):
+		return nil, i, c.skip(m.line, "named set %s has no VPP ACL equivalent", tokens[i])
+	case !strings.HasPrefix(tokens[i], "{"):
+		return []string{tokens[i]}, i + 1, nil
+	}
+
+	var joined strings.Builder
+	for j := i; j < len(tokens); j++ {
+		joined.WriteString(tokens[j])
+		joined.WriteString(" ")
+		if strings.HasSuffix(tokens[j], "}") {
+			var values []string
+			for _, v := range strings.Split(strings.Trim(strings.TrimSpace(joined.String()), "{}"), ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+			return values, j + 1, nil
+		}
+	}
+	return nil, i, errors.Errorf("line %d: unterminated set", m.line)
+}
@@ -0,0 +1,66 @@
+// Package aclrules 提供防火墙ACL规则文件的解析和排序功能
+//
+// VPP ACL按顺序匹配（first-match），规则的顺序决定了最终的行为。
+// 本包保证规则文件加载后的顺序是确定的：
+//   - 列表形式：按文件中的书写顺序
+//   - 映射形式（规则名称作为键）：按规则名称排序
+//   - 任一形式中，所有规则都设置priority时按priority从小到大排序
+//
+// 重复的规则名称和重复的priority会被拒绝；部分规则设置priority、
+// 部分规则未设置也会被拒绝，以免顺序产生歧义。
+//
+// 每条规则可以使用原始的VPP ACL字段（proto/ispermit/srcprefix/...），
+// 也可以使用友好语法，由本包编译为acl_types.ACLRule：
+//   - action: permit | deny | permit-reflect（必填）
+//   - proto: tcp | udp | icmp | icmpv6 | any 或协议号（默认any）
+//   - src/dst: CIDR或单个IP（默认同一地址族的通配前缀；都省略时icmp为0.0.0.0/0、
+//     icmpv6为::/0，其他协议同时生成0.0.0.0/0和::/0两条规则，IPv6规则名称为"原名称#ipv6"）
+//   - sport/dport: 80 或 8000-8080（仅TCP/UDP，默认0-65535）
+//   - icmp-type/icmp-code: 8 或 0-255（仅ICMP，默认0-255）
+//
+// 友好语法和原始字段不能在同一条规则中混用，编译错误包含行号、规则名称和字段。
+//
+// 解析后的每条规则都经过Rule.Validate校验（端口范围、ICMP type/code、
+// 地址族和前缀长度），任何一条规则无效时整个文件被拒绝。
+// 文件无法使用时可以用DenyAll生成拒绝所有流量的规则。
+//
+// 列表形式示例（友好语法）：
+//
+//	# /etc/firewall/config.yaml
+//	- name: allow tcp5201
+//	  action: permit
+//	  proto: tcp
+//	  dport: 5201
+//	- name: forbid tcp80
+//	  proto: 6
+//	  srcportoricmptypelast: 65535
+//	  dstportoricmpcodefirst: 80
+//	  dstportoricmpcodelast: 80
+//
+// 映射形式示例（显式priority）：
+//
+//	allow tcp5201:
+//	  priority: 10
+//	  proto: 6
+//	  ...
+//	forbid tcp80:
+//	  priority: 20
+//	  ...
+//
+// 一个防火墙服务多个租户时，文件可以在profiles中定义命名的配置集，
+// 并通过selectors按连接标签或客户端SPIFFE ID为每个连接选择配置集（LoadPolicy）。
+// 没有选择器匹配时使用default；未设置default时使用内置的deny-all配置集。
+// 普通规则文件等同于只有一个default配置集的策略。
+//
+// 使用示例：
+//
+//	rules, err := aclrules.Load("/etc/firewall/config.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	vppRules := aclrules.ACLRules(rules)
+//
+//	policy, err := aclrules.LoadPolicy("/etc/firewall/config.yaml")
+//	profile := policy.Select(conn.GetLabels(), spiffeID)
+//	rules, _ := policy.Rules(profile)
+package aclrules
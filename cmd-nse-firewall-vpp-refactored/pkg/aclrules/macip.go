@@ -0,0 +1,199 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ethernet_types"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// MACIP规则的字段名称
+const (
+	fieldIP      = "ip"
+	fieldMAC     = "mac"
+	fieldMACMask = "mac-mask"
+)
+
+// fullMACMask 匹配完整MAC地址的掩码
+var fullMACMask = ethernet_types.MacAddress{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// MACIPRule 带名称的MACIP ACL规则
+//
+// MACIP ACL检查从接口进入的数据包的源MAC和源IP，用于防止地址伪造。
+type MACIPRule struct {
+	// Name 规则名称
+	Name string
+
+	// Line 规则在文件中的行号（从1开始，用于错误信息）
+	Line int
+
+	// MacipACLRule VPP MACIP ACL规则
+	acl_types.MacipACLRule
+}
+
+// String 返回规则的可读形式（用于日志）
+func (r *MACIPRule) String() string {
+	return fmt.Sprintf("%q action=%s ip=%s mac=%s/%s", r.Name, ActionName(r.IsPermit), r.SrcPrefix, r.SrcMac, r.SrcMacMask)
+}
+
+// LoadMACIP 读取并解析MACIP规则文件
+func LoadMACIP(path string) ([]MACIPRule, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read MACIP config file")
+	}
+	rules, err := ParseMACIP(raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid MACIP config file %s", path)
+	}
+	return rules, nil
+}
+
+// ParseMACIP 解析MACIP规则文件内容
+//
+// 文件是规则列表，按顺序匹配：
+//
+//	# /etc/firewall/macip.yaml
+//	- name: client-a
+//	  action: permit               # permit或deny
+//	  ip: 172.16.1.100             # 源IP或CIDR，省略或any表示任意IPv4地址（IPv6使用::/0）
+//	  mac: 02:fe:aa:bb:cc:dd       # 源MAC，省略表示任意MAC
+//	  mac-mask: ff:ff:ff:00:00:00  # 可选，默认匹配完整MAC
+//
+// 错误信息包含行号。空文件返回空列表。
+func ParseMACIP(data []byte) ([]MACIPRule, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		return nil, errors.Errorf("line %d: MACIP config must be a list of rules", root.Line)
+	}
+	rules := make([]MACIPRule, 0, len(root.Content))
+	seen := make(map[string]int, len(root.Content))
+	for _, node := range root.Content {
+		rule, err := compileMACIPRule(node)
+		if err != nil {
+			return nil, err
+		}
+		if line, ok := seen[rule.Name]; ok {
+			return nil, errors.Errorf("line %d: duplicate rule name %q (first defined at line %d)", rule.Line, rule.Name, line)
+		}
+		seen[rule.Name] = rule.Line
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// compileMACIPRule 将友好语法的MACIP规则编译为VPP MACIP ACL规则
+func compileMACIPRule(node *yaml.Node) (MACIPRule, error) {
+	if node.Kind != yaml.MappingNode {
+		return MACIPRule{}, errors.Errorf("line %d: rule must be a map of fields", node.Line)
+	}
+	fields := make(map[string]*yaml.Node, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fields[node.Content[i].Value] = node.Content[i+1]
+	}
+	rule := MACIPRule{Line: node.Line}
+	if v, ok := fields[fieldName]; ok {
+		rule.Name = v.Value
+	}
+	if rule.Name == "" {
+		return MACIPRule{}, errors.Errorf("line %d: rule name is required", node.Line)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case fieldName, fieldAction, fieldIP, fieldMAC, fieldMACMask:
+		default:
+			return MACIPRule{}, fieldError(key, rule.Name, key.Value, "unknown field (expected name, action, ip, mac and mac-mask)")
+		}
+		if value.Kind != yaml.ScalarNode {
+			return MACIPRule{}, fieldError(value, rule.Name, key.Value, "must be a scalar value")
+		}
+	}
+
+	v, ok := fields[fieldAction]
+	if !ok {
+		return MACIPRule{}, fieldError(node, rule.Name, fieldAction, "is required (permit or deny)")
+	}
+	// MACIP ACL没有会话，不支持permit-reflect
+	action, ok := actions[strings.ToLower(v.Value)]
+	if !ok || action == acl_types.ACL_ACTION_API_PERMIT_REFLECT {
+		return MACIPRule{}, fieldError(v, rule.Name, fieldAction, "invalid value %q (expected permit or deny)", v.Value)
+	}
+	rule.IsPermit = action
+
+	prefix := netip.PrefixFrom(netip.IPv4Unspecified(), 0)
+	if v, ok := fields[fieldIP]; ok && !strings.EqualFold(v.Value, "any") {
+		p, err := parsePrefix(v.Value)
+		if err != nil {
+			return MACIPRule{}, fieldError(v, rule.Name, fieldIP, "%v", err)
+		}
+		prefix = p
+	}
+	rule.SrcPrefix = toPrefix(prefix)
+
+	if v, ok := fields[fieldMAC]; ok && !strings.EqualFold(v.Value, "any") {
+		mac, err := ethernet_types.ParseMacAddress(v.Value)
+		if err != nil {
+			return MACIPRule{}, fieldError(v, rule.Name, fieldMAC, "invalid MAC address %q", v.Value)
+		}
+		rule.SrcMac, rule.SrcMacMask = mac, fullMACMask
+	}
+	if v, ok := fields[fieldMACMask]; ok {
+		if _, hasMAC := fields[fieldMAC]; !hasMAC {
+			return MACIPRule{}, fieldError(v, rule.Name, fieldMACMask, "requires mac")
+		}
+		mask, err := ethernet_types.ParseMacAddress(v.Value)
+		if err != nil {
+			return MACIPRule{}, fieldError(v, rule.Name, fieldMACMask, "invalid MAC mask %q", v.Value)
+		}
+		rule.SrcMacMask = mask
+	}
+	for i := range rule.SrcMac {
+		rule.SrcMac[i] &= rule.SrcMacMask[i]
+	}
+	return rule, nil
+}
+
+// MACIPRules 提取VPP MACIP ACL规则
+func MACIPRules(rules []MACIPRule) []acl_types.MacipACLRule {
+	result := make([]acl_types.MacipACLRule, 0, len(rules))
+	for i := range rules {
+		result = append(result, rules[i].MacipACLRule)
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules_test
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+func TestParseMACIP(t *testing.T) {
+	rules, err := aclrules.ParseMACIP([]byte(`
+- name: client a
+  action: permit
+  ip: 172.16.1.100
+  mac: 02:FE:AA:BB:CC:DD
+- name: vendor
+  action: permit
+  ip: fd00::/64
+  mac: 02:fe:aa:bb:cc:dd
+  mac-mask: ff:ff:ff:00:00:00
+- name: others
+  action: deny
+`))
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+
+	require.Equal(t, acl_types.ACL_ACTION_API_PERMIT, rules[0].IsPermit)
+	require.Equal(t, "172.16.1.100/32", rules[0].SrcPrefix.String())
+	require.Equal(t, "02:fe:aa:bb:cc:dd", rules[0].SrcMac.String())
+	require.Equal(t, "ff:ff:ff:ff:ff:ff", rules[0].SrcMacMask.String())
+	require.Equal(t, 2, rules[0].Line)
+
+	require.Equal(t, "fd00::/64", rules[1].SrcPrefix.String())
+	require.Equal(t, "02:fe:aa:00:00:00", rules[1].SrcMac.String(), "MAC按掩码清零")
+
+	require.Equal(t, acl_types.ACL_ACTION_API_DENY, rules[2].IsPermit)
+	require.Equal(t, "0.0.0.0/0", rules[2].SrcPrefix.String())
+	require.Equal(t, "00:00:00:00:00:00", rules[2].SrcMacMask.String(), "未指定MAC时匹配任意MAC")
+
+	require.Len(t, aclrules.MACIPRules(rules), 3)
+
+	empty, err := aclrules.ParseMACIP(nil)
+	require.NoError(t, err)
+	require.Empty(t, empty)
+}
+
+func TestParseMACIP_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		yaml string
+		err  string
+	}{
+		{"name: a\n", "line 1: MACIP config must be a list of rules"},
+		{"- action: permit\n", "line 1: rule name is required"},
+		{"- name: a\n  action: permit-reflect\n", `line 2: rule "a": action: invalid value "permit-reflect" (expected permit or deny)`},
+		{"- name: a\n  ip: 10.0.0.1\n", `line 1: rule "a": action: is required`},
+		{"- name: a\n  action: permit\n  mac: 02:fe\n", `line 3: rule "a": mac: invalid MAC address "02:fe"`},
+		{"- name: a\n  action: permit\n  mac-mask: ff:ff:ff:00:00:00\n", `line 3: rule "a": mac-mask: requires mac`},
+		{"- name: a\n  action: permit\n  ip: 10.0.0.0/33\n", `line 3: rule "a": ip: invalid CIDR`},
+		{"- name: a\n  action: permit\n  proto: tcp\n", `line 3: rule "a": proto: unknown field`},
+		{"- name: a\n  action: permit\n- name: a\n  action: deny\n", `line 3: duplicate rule name "a" (first defined at line 1)`},
+	} {
+		_, err := aclrules.ParseMACIP([]byte(tc.yaml))
+		require.Error(t, err, tc.yaml)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
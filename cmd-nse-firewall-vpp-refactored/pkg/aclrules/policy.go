@@ -0,0 +1,340 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultProfile 普通规则文件（没有profiles）加载后的配置集名称
+	DefaultProfile = "default"
+
+	// DenyAllProfile 内置的拒绝所有流量的配置集，没有选择器匹配且未设置default时使用
+	DenyAllProfile = "deny-all"
+)
+
+// Selector 根据连接标签或客户端SPIFFE ID选择配置集
+//
+// Labels中的所有标签都必须与连接标签相同；SpiffeID是path.Match模式（*不匹配"/"）。
+// 同时设置两者时都必须匹配。
+type Selector struct {
+	// Profile 匹配时使用的配置集名称
+	Profile string `yaml:"profile"`
+
+	// Labels 连接必须带有的标签
+	Labels map[string]string `yaml:"labels"`
+
+	// SpiffeID 客户端SPIFFE ID的匹配模式，例如 spiffe://example.org/ns/tenant-a/sa/*
+	SpiffeID string `yaml:"spiffeID"`
+
+	// Line 选择器在文件中的行号
+	Line int `yaml:"-"`
+}
+
+// Matches 判断连接是否匹配选择器
+func (s *Selector) Matches(labels map[string]string, spiffeID string) bool {
+	for k, v := range s.Labels {
+		if actual, ok := labels[k]; !ok || actual != v {
+			return false
+		}
+	}
+	if s.SpiffeID != "" {
+		if ok, _ := path.Match(s.SpiffeID, spiffeID); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Policy 按名称的ACL配置集以及选择配置集的规则
+type Policy struct {
+	// Profiles 配置集名称到规则的映射（规则按匹配顺序排列）
+	Profiles map[string][]Rule
+
+	// Selectors 按顺序检查的选择器，第一个匹配的生效
+	Selectors []Selector
+
+	// Default 没有选择器匹配时使用的配置集（未设置时为DenyAllProfile）
+	Default string
+}
+
+// NewPolicy 创建只有一个default配置集的策略，所有连接使用相同的规则
+func NewPolicy(rules []Rule) *Policy {
+	return &Policy{
+		Profiles: map[string][]Rule{DefaultProfile: rules},
+		Default:  DefaultProfile,
+	}
+}
+
+// LoadPolicy 读取并解析ACL配置文件
+//
+// 文件可以是普通规则文件（作为default配置集），也可以是配置集文件：
+//
+//	profiles:
+//	  tenant-a:
+//	    - {name: allow https, action: permit, proto: tcp, dport: 443}
+//	  tenant-b:
+//	    - {name: allow dns, action: permit, proto: udp, dport: 53}
+//	selectors:
+//	  - profile: tenant-a
+//	    labels: {tenant: a}
+//	  - profile: tenant-b
+//	    spiffeID: spiffe://example.org/ns/tenant-b/sa/*
+//	default: tenant-a
+func LoadPolicy(path string) (*Policy, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read ACL config file")
+	}
+	policy, err := ParsePolicy(raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid ACL config file %s", path)
+	}
+	return policy, nil
+}
+
+// ParsePolicy 解析ACL配置文件内容
+//
+// 每个配置集按Parse的规则解析和排序，且至少包含一条规则。
+// 选择器和default引用的配置集必须存在（DenyAllProfile是内置的）。
+func ParsePolicy(data []byte) (*Policy, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || !isPolicy(doc.Content[0]) {
+		rules, err := Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		return NewPolicy(rules), nil
+	}
+
+	root := doc.Content[0]
+	policy := &Policy{Profiles: make(map[string][]Rule), Default: DenyAllProfile}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		var err error
+		switch key.Value {
+		case "profiles":
+			err = policy.parseProfiles(value)
+		case "selectors":
+			err = policy.parseSelectors(value)
+		case "default":
+			if value.Kind != yaml.ScalarNode || value.Value == "" {
+				err = errors.Errorf("line %d: default must be a profile name", value.Line)
+			}
+			policy.Default = value.Value
+		default:
+			err = errors.Errorf("line %d: unknown field %q (expected profiles, selectors or default)", key.Line, key.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !policy.has(policy.Default) {
+		return nil, errors.Errorf("default profile %q is not defined", policy.Default)
+	}
+	for _, s := range policy.Selectors {
+		if !policy.has(s.Profile) {
+			return nil, errors.Errorf("line %d: selector refers to undefined profile %q", s.Line, s.Profile)
+		}
+	}
+	return policy, nil
+}
+
+// Select 返回连接使用的配置集名称
+//
+// 按顺序检查选择器，第一个匹配的生效；没有匹配时返回Default。
+func (p *Policy) Select(labels map[string]string, spiffeID string) string {
+	for i := range p.Selectors {
+		if p.Selectors[i].Matches(labels, spiffeID) {
+			return p.Selectors[i].Profile
+		}
+	}
+	if p.Default == "" {
+		return DenyAllProfile
+	}
+	return p.Default
+}
+
+// Rules 返回配置集的规则，配置集不存在时返回false
+//
+// DenyAllProfile（未被文件覆盖时）返回DenyAll的规则。
+func (p *Policy) Rules(profile string) ([]Rule, bool) {
+	if rules, ok := p.Profiles[profile]; ok {
+		return rules, true
+	}
+	if profile == DenyAllProfile {
+		return DenyAll(), true
+	}
+	return nil, false
+}
+
+// Names 返回按名称排序的配置集名称
+func (p *Policy) Names() []string {
+	names := make([]string, 0, len(p.Profiles))
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithBase 返回在每个配置集前面加上base规则的新策略
+//
+// 用于NSM_ACL_CONFIG中的规则：它们对Profiles中的所有配置集生效，
+// 内置的deny-all配置集（DenyAllProfile）不在Profiles中，不受影响。
+// 加载失败时的兜底策略NewPolicy(DenyAll())的规则在default配置集中，不应调用WithBase。
+func (p *Policy) WithBase(base []Rule) *Policy {
+	result := &Policy{
+		Profiles:  make(map[string][]Rule, len(p.Profiles)),
+		Selectors: p.Selectors,
+		Default:   p.Default,
+	}
+	for name, rules := range p.Profiles {
+		merged := make([]Rule, 0, len(base)+len(rules))
+		merged = append(merged, base...)
+		result.Profiles[name] = append(merged, rules...)
+	}
+	return result
+}
+
+func (p *Policy) has(profile string) bool {
+	_, ok := p.Rules(profile)
+	return ok
+}
+
+func (p *Policy) parseProfiles(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.Errorf("line %d: profiles must be a map of profile names to rules", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch {
+		case key.Value == "":
+			return errors.Errorf("line %d: profile name is required", key.Line)
+		case key.Value == DenyAllProfile:
+			return errors.Errorf("line %d: profile name %q is reserved", key.Line, DenyAllProfile)
+		}
+		if _, ok := p.Profiles[key.Value]; ok {
+			return errors.Errorf("line %d: duplicate profile %q", key.Line, key.Value)
+		}
+		rules, err := parseRules(value)
+		if err != nil {
+			return errors.Wrapf(err, "profile %q", key.Value)
+		}
+		if len(rules) == 0 {
+			return errors.Errorf("line %d: profile %q has no rules", key.Line, key.Value)
+		}
+		p.Profiles[key.Value] = rules
+	}
+	return nil
+}
+
+func (p *Policy) parseSelectors(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		return errors.Errorf("line %d: selectors must be a list", node.Line)
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return errors.Errorf("line %d: selector must be a map of fields", item.Line)
+		}
+		for i := 0; i < len(item.Content); i += 2 {
+			switch item.Content[i].Value {
+			case "profile", "labels", "spiffeID":
+			default:
+				return errors.Errorf("line %d: unknown selector field %q", item.Content[i].Line, item.Content[i].Value)
+			}
+		}
+
+		var s Selector
+		if err := item.Decode(&s); err != nil {
+			return errors.Wrapf(err, "line %d", item.Line)
+		}
+		s.Line = item.Line
+		switch {
+		case s.Profile == "":
+			return errors.Errorf("line %d: selector profile is required", s.Line)
+		case len(s.Labels) == 0 && s.SpiffeID == "":
+			return errors.Errorf("line %d: selector must match labels or spiffeID", s.Line)
+		}
+		if _, err := path.Match(s.SpiffeID, ""); err != nil {
+			return errors.Errorf("line %d: invalid spiffeID pattern %q", s.Line, s.SpiffeID)
+		}
+		p.Selectors = append(p.Selectors, s)
+	}
+	return nil
+}
+
+// isPolicy 判断顶层节点是否为配置集文件：profiles键的值必须是配置集名到规则列表或
+// 映射形式规则的映射，这样映射形式中名为profiles的普通规则不会被当成配置集文件
+func isPolicy(root *yaml.Node) bool {
+	if root.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "profiles" {
+			return isProfiles(root.Content[i+1])
+		}
+	}
+	return false
+}
+
+// isProfiles 判断节点是否为非空的配置集映射，且每个值都是一组规则
+func isProfiles(node *yaml.Node) bool {
+	if node.Kind != yaml.MappingNode || len(node.Content) == 0 {
+		return false
+	}
+	for i := 1; i < len(node.Content); i += 2 {
+		if !isRuleSet(node.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isRuleSet 判断节点是否为规则列表，或者每个值都是规则字段映射的映射形式规则
+func isRuleSet(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return true
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			return false
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			if node.Content[i].Kind != yaml.MappingNode {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
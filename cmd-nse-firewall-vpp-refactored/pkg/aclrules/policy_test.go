@@ -0,0 +1,128 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+const policyYAML = `
+profiles:
+  tenant-a:
+    - {name: allow https, action: permit, proto: tcp, dport: 443}
+  tenant-b:
+    allow dns: {action: permit, proto: udp, dport: 53}
+    allow ntp: {action: permit, proto: udp, dport: 123}
+selectors:
+  - profile: tenant-a
+    labels: {tenant: a}
+  - profile: tenant-b
+    spiffeID: spiffe://example.org/ns/tenant-b/sa/*
+  - profile: tenant-a
+    labels: {tenant: b, tier: gold}
+`
+
+func TestParsePolicy_Select(t *testing.T) {
+	policy, err := aclrules.ParsePolicy([]byte(policyYAML))
+	require.NoError(t, err)
+	require.Equal(t, []string{"tenant-a", "tenant-b"}, policy.Names())
+
+	rules, ok := policy.Rules("tenant-b")
+	require.True(t, ok)
+	require.Equal(t, []string{"allow dns", "allow dns#ipv6", "allow ntp", "allow ntp#ipv6"}, names(rules))
+
+	require.Equal(t, "tenant-a", policy.Select(map[string]string{"tenant": "a"}, ""))
+	require.Equal(t, "tenant-b", policy.Select(nil, "spiffe://example.org/ns/tenant-b/sa/client"))
+	require.Equal(t, "tenant-a", policy.Select(map[string]string{"tenant": "b", "tier": "gold"}, ""), "所有标签都必须匹配")
+	require.Equal(t, aclrules.DenyAllProfile, policy.Select(map[string]string{"tenant": "b"}, "spiffe://example.org/ns/other/sa/client"), "没有匹配且未设置default时拒绝所有流量")
+
+	denyAll, ok := policy.Rules(aclrules.DenyAllProfile)
+	require.True(t, ok)
+	require.Equal(t, names(aclrules.DenyAll()), names(denyAll))
+}
+
+func TestParsePolicy_Default(t *testing.T) {
+	policy, err := aclrules.ParsePolicy([]byte(policyYAML + "default: tenant-b\n"))
+	require.NoError(t, err)
+	require.Equal(t, "tenant-b", policy.Select(nil, ""))
+}
+
+func TestParsePolicy_PlainRules(t *testing.T) {
+	policy, err := aclrules.ParsePolicy([]byte("- {name: allow ssh, action: permit, proto: tcp, dport: 22}\n"))
+	require.NoError(t, err)
+	require.Equal(t, aclrules.DefaultProfile, policy.Select(map[string]string{"tenant": "a"}, "spiffe://example.org/x"))
+	rules, ok := policy.Rules(aclrules.DefaultProfile)
+	require.True(t, ok)
+	require.Equal(t, []string{"allow ssh", "allow ssh#ipv6"}, names(rules))
+
+	_, err = aclrules.Parse([]byte(policyYAML))
+	require.Error(t, err, "普通规则解析不应该静默忽略配置集")
+}
+
+func TestParsePolicy_LegacyRuleNamedProfiles(t *testing.T) {
+	for name, data := range map[string]string{
+		"friendly": "profiles: {action: permit, proto: tcp, dport: 443}\nallow dns: {action: permit, proto: udp, dport: 53}\n",
+		"raw":      "profiles: {ispermit: 1, proto: 6}\n",
+	} {
+		policy, err := aclrules.ParsePolicy([]byte(data))
+		require.NoError(t, err, name)
+		require.Equal(t, []string{aclrules.DefaultProfile}, policy.Names(), name)
+		rules, ok := policy.Rules(aclrules.DefaultProfile)
+		require.True(t, ok, name)
+		require.Contains(t, names(rules), "profiles", name)
+	}
+}
+
+func TestParsePolicy_WithBase(t *testing.T) {
+	policy, err := aclrules.ParsePolicy([]byte(policyYAML))
+	require.NoError(t, err)
+
+	base := aclrules.FromACLRules("NSM_ACL_CONFIG", aclrules.ACLRules(aclrules.DenyAll()[:1]))
+	merged := policy.WithBase(base)
+	rules, _ := merged.Rules("tenant-a")
+	require.Equal(t, []string{"NSM_ACL_CONFIG#0", "allow https", "allow https#ipv6"}, names(rules))
+	rules, _ = merged.Rules(aclrules.DenyAllProfile)
+	require.Len(t, rules, 2, "内置的deny-all不加基础规则")
+
+	rules, _ = policy.Rules("tenant-a")
+	require.Len(t, rules, 2, "原策略不变")
+}
+
+func TestParsePolicy_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"undefined default":  "profiles: {a: [{name: r, action: permit}]}\ndefault: b\n",
+		"undefined selector": "profiles: {a: [{name: r, action: permit}]}\nselectors: [{profile: b, labels: {x: y}}]\n",
+		"empty selector":     "profiles: {a: [{name: r, action: permit}]}\nselectors: [{profile: a}]\n",
+		"unknown selector":   "profiles: {a: [{name: r, action: permit}]}\nselectors: [{profile: a, label: {x: y}}]\n",
+		"bad pattern":        "profiles: {a: [{name: r, action: permit}]}\nselectors: [{profile: a, spiffeID: \"spiffe://[\"}]\n",
+		"empty profile":      "profiles: {a: []}\n",
+		"reserved name":      "profiles: {deny-all: [{name: r, action: permit}]}\n",
+		"invalid rule":       "profiles: {a: [{name: r, action: permit, proto: tcp, dport: 90-80}]}\n",
+		"unknown field":      "profiles: {a: [{name: r, action: permit}]}\nselector: []\n",
+	} {
+		_, err := aclrules.ParsePolicy([]byte(data))
+		require.Error(t, err, name)
+	}
+}
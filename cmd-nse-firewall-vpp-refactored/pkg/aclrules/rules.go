@@ -0,0 +1,298 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Form 规则文件的形式
+type Form string
+
+const (
+	// FormList 列表形式，每条规则带name字段
+	FormList Form = "list"
+
+	// FormMap 映射形式，规则名称作为键
+	FormMap Form = "map"
+)
+
+// Rule 带名称和顺序信息的ACL规则
+type Rule struct {
+	// Name 规则名称（列表形式的name字段或映射形式的键）
+	Name string
+
+	// Priority 显式优先级（数值越小越先匹配），nil表示未设置
+	Priority *int
+
+	// Line 规则在文件中的行号（从1开始，用于错误信息）
+	Line int
+
+	// ACLRule VPP ACL规则
+	acl_types.ACLRule
+
+	// dualStack 友好语法中未指定地址且与地址族无关的规则，解析后补充一条IPv6规则
+	dualStack bool
+}
+
+// String 返回规则的可读形式（用于日志）
+func (r *Rule) String() string {
+	s := fmt.Sprintf("%q", r.Name)
+	if r.Priority != nil {
+		s += fmt.Sprintf(" priority=%d", *r.Priority)
+	}
+	return fmt.Sprintf("%s action=%s proto=%d src=%s dst=%s sport=%d-%d dport=%d-%d", s,
+		r.IsPermit, r.Proto, r.SrcPrefix, r.DstPrefix,
+		r.SrcportOrIcmptypeFirst, r.SrcportOrIcmptypeLast, r.DstportOrIcmpcodeFirst, r.DstportOrIcmpcodeLast)
+}
+
+// ruleFields 规则在YAML中的字段
+type ruleFields struct {
+	Name              string `yaml:"name"`
+	Priority          *int   `yaml:"priority"`
+	acl_types.ACLRule `yaml:",inline"`
+}
+
+// Load 读取并解析规则文件
+func Load(path string) ([]Rule, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read ACL config file")
+	}
+	rules, err := Parse(raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid ACL config file %s", path)
+	}
+	return rules, nil
+}
+
+// Parse 解析规则文件内容，返回按匹配顺序排列的规则
+//
+// 每条规则都经过Validate检查，错误信息包含行号。空文件返回空列表。
+func Parse(data []byte) ([]Rule, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if isPolicy(root) {
+		return nil, errors.Errorf("line %d: ACL config contains profiles, load it with LoadPolicy", root.Line)
+	}
+	return parseRules(root)
+}
+
+// parseRules 解析规则列表或规则映射节点，校验并排序
+func parseRules(root *yaml.Node) ([]Rule, error) {
+	var rules []Rule
+	var form Form
+	var err error
+	switch root.Kind {
+	case yaml.SequenceNode:
+		form = FormList
+		rules, err = parseList(root)
+	case yaml.MappingNode:
+		form = FormMap
+		rules, err = parseMap(root)
+	default:
+		return nil, errors.Errorf("line %d: ACL config must be a list or a map of rules", root.Line)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := sortRules(rules, form); err != nil {
+		return nil, err
+	}
+	rules = expandDualStack(rules)
+	if err := checkNames(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// expandDualStack 在每条同时匹配IPv4和IPv6的规则之后插入对应的IPv6规则
+//
+// VPP ACL规则只能属于一个地址族，编译结果为0.0.0.0/0的规则补充一条::/0规则，
+// 名称为"原名称#ipv6"，与原规则相邻以保持匹配顺序。
+func expandDualStack(rules []Rule) []Rule {
+	result := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		if !r.dualStack {
+			result = append(result, r)
+			continue
+		}
+		r.dualStack = false
+		v6 := r
+		v6.Name = r.Name + "#ipv6"
+		v6.SrcPrefix = toPrefix(netip.PrefixFrom(netip.IPv6Unspecified(), 0))
+		v6.DstPrefix = v6.SrcPrefix
+		result = append(result, r, v6)
+	}
+	return result
+}
+
+func parseList(root *yaml.Node) ([]Rule, error) {
+	rules := make([]Rule, 0, len(root.Content))
+	for _, node := range root.Content {
+		rule, err := decodeRule(node, "")
+		if err != nil {
+			return nil, err
+		}
+		if rule.Name == "" {
+			return nil, errors.Errorf("line %d: rule name is required", node.Line)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseMap(root *yaml.Node) ([]Rule, error) {
+	rules := make([]Rule, 0, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		rule, err := decodeRule(value, key.Value)
+		if err != nil {
+			return nil, err
+		}
+		if rule.Name != "" && rule.Name != key.Value {
+			return nil, errors.Errorf("line %d: rule %q has a different name field %q", value.Line, key.Value, rule.Name)
+		}
+		rule.Name = key.Value
+		rule.Line = key.Line
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// decodeRule 解析单条规则，name为映射形式的键（列表形式为空）
+//
+// 规则可以使用友好语法（action/proto/src/dst/...）或原始的VPP ACL字段。
+func decodeRule(node *yaml.Node, name string) (Rule, error) {
+	if node.Kind != yaml.MappingNode {
+		return Rule{}, errors.Errorf("line %d: rule must be a map of fields", node.Line)
+	}
+	if isFriendly(node) {
+		return compileRule(node, name)
+	}
+	var fields ruleFields
+	if err := node.Decode(&fields); err != nil {
+		return Rule{}, errors.Wrapf(err, "line %d", node.Line)
+	}
+	return Rule{
+		Name:     fields.Name,
+		Priority: fields.Priority,
+		Line:     node.Line,
+		ACLRule:  fields.ACLRule,
+	}, nil
+}
+
+// checkNames 拒绝重复的规则名称
+func checkNames(rules []Rule) error {
+	seen := make(map[string]int, len(rules))
+	for _, r := range rules {
+		if line, ok := seen[r.Name]; ok {
+			return errors.Errorf("line %d: duplicate rule name %q (first defined at line %d)", r.Line, r.Name, line)
+		}
+		seen[r.Name] = r.Line
+	}
+	return nil
+}
+
+// sortRules 按priority或规则文件的形式确定规则顺序
+func sortRules(rules []Rule, form Form) error {
+	withPriority := 0
+	for _, r := range rules {
+		if r.Priority != nil {
+			withPriority++
+		}
+	}
+
+	switch {
+	case withPriority == len(rules):
+		seen := make(map[int]string, len(rules))
+		for _, r := range rules {
+			if name, ok := seen[*r.Priority]; ok {
+				return errors.Errorf("line %d: rule %q has the same priority %d as rule %q", r.Line, r.Name, *r.Priority, name)
+			}
+			seen[*r.Priority] = r.Name
+		}
+		sort.SliceStable(rules, func(i, j int) bool { return *rules[i].Priority < *rules[j].Priority })
+	case withPriority > 0:
+		for _, r := range rules {
+			if r.Priority == nil {
+				return errors.Errorf("line %d: rule %q has no priority while other rules do (set priority on all rules or none)", r.Line, r.Name)
+			}
+		}
+	case form == FormMap:
+		sort.SliceStable(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+	}
+	return nil
+}
+
+// FromACLRules 为没有名称的VPP ACL规则生成"prefix#序号"形式的名称
+//
+// 用于NSM_ACL_CONFIG等不来自规则文件的规则，序号从0开始。
+func FromACLRules(prefix string, rules []acl_types.ACLRule) []Rule {
+	result := make([]Rule, 0, len(rules))
+	for i := range rules {
+		result = append(result, Rule{Name: fmt.Sprintf("%s#%d", prefix, i), ACLRule: rules[i]})
+	}
+	return result
+}
+
+// ValidateACLRules 与FromACLRules相同地为VPP ACL规则命名，并逐条执行Rule.Validate的检查
+//
+// 用于NSM_ACL_CONFIG等不经过规则文件解析的规则，返回的错误包含生成的规则名称。
+func ValidateACLRules(prefix string, rules []acl_types.ACLRule) ([]Rule, error) {
+	result := FromACLRules(prefix, rules)
+	for i := range result {
+		if err := result[i].validate(); err != nil {
+			return nil, errors.Errorf("rule %q: %v", result[i].Name, err)
+		}
+	}
+	return result, nil
+}
+
+// ACLRules 返回规则对应的VPP ACL规则（保持顺序）
+func ACLRules(rules []Rule) []acl_types.ACLRule {
+	result := make([]acl_types.ACLRule, 0, len(rules))
+	for i := range rules {
+		result = append(result, rules[i].ACLRule)
+	}
+	return result
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+func names(rules []aclrules.Rule) []string {
+	result := make([]string, 0, len(rules))
+	for _, r := range rules {
+		result = append(result, r.Name)
+	}
+	return result
+}
+
+func TestParse_ListKeepsOrder(t *testing.T) {
+	rules, err := aclrules.Parse([]byte(`
+- name: forbid tcp80
+  proto: 6
+  dstportoricmpcodefirst: 80
+  dstportoricmpcodelast: 80
+- name: allow all
+  ispermit: 1
+  srcprefix: 10.0.0.0/8
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"forbid tcp80", "allow all"}, names(rules))
+	require.Equal(t, 2, rules[0].Line)
+	require.Equal(t, uint16(80), rules[0].DstportOrIcmpcodeFirst)
+	require.Equal(t, acl_types.ACL_ACTION_API_PERMIT, rules[1].IsPermit)
+	require.Equal(t, "10.0.0.0/8", rules[1].SrcPrefix.String())
+
+	vpp := aclrules.ACLRules(rules)
+	require.Len(t, vpp, 2)
+	require.Equal(t, rules[0].ACLRule, vpp[0])
+}
+
+func TestParse_MapSortedByName(t *testing.T) {
+	data := []byte(`
+forbid tcp80:
+  proto: 6
+allow udp5201:
+  proto: 17
+  ispermit: 1
+allow icmp:
+  proto: 1
+  ispermit: 1
+`)
+	for i := 0; i < 10; i++ {
+		rules, err := aclrules.Parse(data)
+		require.NoError(t, err)
+		require.Equal(t, []string{"allow icmp", "allow udp5201", "forbid tcp80"}, names(rules), "映射形式必须按名称确定顺序")
+	}
+}
+
+func TestParse_Priority(t *testing.T) {
+	rules, err := aclrules.Parse([]byte(`
+allow all:
+  priority: 100
+  ispermit: 1
+forbid tcp80:
+  priority: 10
+  proto: 6
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"forbid tcp80", "allow all"}, names(rules))
+	require.Equal(t, 10, *rules[0].Priority)
+
+	rules, err = aclrules.Parse([]byte(`
+- {name: b, priority: 2}
+- {name: a, priority: 1}
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, names(rules), "列表形式设置priority时按priority排序")
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "duplicate map key",
+			data: "a:\n  proto: 6\nb:\n  proto: 17\na:\n  proto: 1\n",
+			want: `line 5: duplicate rule name "a" (first defined at line 1)`,
+		},
+		{
+			name: "duplicate list name",
+			data: "- name: a\n- name: b\n- name: a\n",
+			want: `line 3: duplicate rule name "a"`,
+		},
+		{
+			name: "duplicate priority",
+			data: "a:\n  priority: 1\nb:\n  priority: 1\n",
+			want: `rule "b" has the same priority 1 as rule "a"`,
+		},
+		{
+			name: "partial priority",
+			data: "- {name: a, priority: 1}\n- {name: b}\n",
+			want: `line 2: rule "b" has no priority`,
+		},
+		{
+			name: "missing list name",
+			data: "- proto: 6\n",
+			want: "line 1: rule name is required",
+		},
+		{
+			name: "scalar document",
+			data: "allow everything\n",
+			want: "must be a list or a map of rules",
+		},
+		{
+			name: "invalid field",
+			data: "a:\n  proto: 6\n  ispermit: permit\n",
+			want: "line 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := aclrules.Parse([]byte(tt.data))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	rules, err := aclrules.Parse([]byte("# no rules\n"))
+	require.NoError(t, err)
+	require.Empty(t, rules)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- name: a\n  ispermit: 1\n"), 0o600))
+
+	rules, err := aclrules.Load(path)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+
+	_, err = aclrules.Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
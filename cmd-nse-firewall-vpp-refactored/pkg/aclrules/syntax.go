@@ -0,0 +1,319 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// 友好语法的动作取值
+const (
+	ActionPermit        = "permit"
+	ActionDeny          = "deny"
+	ActionPermitReflect = "permit-reflect"
+)
+
+// 友好语法的字段名称
+const (
+	fieldName     = "name"
+	fieldPriority = "priority"
+	fieldAction   = "action"
+	fieldProto    = "proto"
+	fieldSrc      = "src"
+	fieldDst      = "dst"
+	fieldSport    = "sport"
+	fieldDport    = "dport"
+	fieldICMPType = "icmp-type"
+	fieldICMPCode = "icmp-code"
+)
+
+// friendlyOnly 只在友好语法中出现的字段，用于识别规则使用的语法
+var friendlyOnly = map[string]bool{
+	fieldAction:   true,
+	fieldSrc:      true,
+	fieldDst:      true,
+	fieldSport:    true,
+	fieldDport:    true,
+	fieldICMPType: true,
+	fieldICMPCode: true,
+}
+
+var actions = map[string]acl_types.ACLAction{
+	ActionPermit:        acl_types.ACL_ACTION_API_PERMIT,
+	ActionDeny:          acl_types.ACL_ACTION_API_DENY,
+	ActionPermitReflect: acl_types.ACL_ACTION_API_PERMIT_REFLECT,
+}
+
+// ActionName 返回ACL动作在友好语法中的名称（用于日志和指标）
+func ActionName(action acl_types.ACLAction) string {
+	for name, a := range actions {
+		if a == action {
+			return name
+		}
+	}
+	return action.String()
+}
+
+var protocols = map[string]ip_types.IPProto{
+	"any":    ip_types.IP_API_PROTO_HOPOPT,
+	"tcp":    ip_types.IP_API_PROTO_TCP,
+	"udp":    ip_types.IP_API_PROTO_UDP,
+	"icmp":   ip_types.IP_API_PROTO_ICMP,
+	"icmpv6": ip_types.IP_API_PROTO_ICMP6,
+	"icmp6":  ip_types.IP_API_PROTO_ICMP6,
+}
+
+// 端口和ICMP type/code的通配范围
+const (
+	maxPort = 65535
+	maxICMP = 255
+)
+
+// isFriendly 判断规则节点是否使用友好语法
+//
+// 出现友好语法特有的字段，或proto使用协议名称时视为友好语法。
+func isFriendly(node *yaml.Node) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1].Value
+		if friendlyOnly[key] {
+			return true
+		}
+		if _, err := strconv.Atoi(value); key == fieldProto && err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldError 返回带行号、规则名称和字段名称的错误
+func fieldError(node *yaml.Node, rule, field, format string, args ...interface{}) error {
+	return errors.Errorf("line %d: rule %q: %s: %s", node.Line, rule, field, fmt.Sprintf(format, args...))
+}
+
+// compileRule 将友好语法的规则编译为VPP ACL规则
+//
+// name为映射形式的键，列表形式传空字符串（从name字段读取）。
+// 未指定的地址、端口和ICMP type/code编译为对应的通配范围。
+// src和dst都未指定时，icmp规则只匹配IPv4、icmpv6规则只匹配IPv6，
+// 其他协议的规则同时匹配IPv4和IPv6（解析后由expandDualStack补充IPv6规则）。
+func compileRule(node *yaml.Node, name string) (Rule, error) {
+	fields := make(map[string]*yaml.Node, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fields[node.Content[i].Value] = node.Content[i+1]
+	}
+	if v, ok := fields[fieldName]; ok && name == "" {
+		name = v.Value
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch key.Value {
+		case fieldName, fieldPriority, fieldAction, fieldProto, fieldSrc, fieldDst,
+			fieldSport, fieldDport, fieldICMPType, fieldICMPCode:
+		default:
+			return Rule{}, fieldError(key, name, key.Value, "unknown field (raw ACL fields cannot be mixed with action/src/dst/sport/dport)")
+		}
+		if value.Kind != yaml.ScalarNode {
+			return Rule{}, fieldError(value, name, key.Value, "must be a scalar value")
+		}
+	}
+
+	rule := Rule{Name: name, Line: node.Line}
+	if v, ok := fields[fieldName]; ok && v.Value != name {
+		return Rule{}, fieldError(v, name, fieldName, "differs from the rule key %q", name)
+	}
+	if v, ok := fields[fieldPriority]; ok {
+		var priority int
+		if err := v.Decode(&priority); err != nil {
+			return Rule{}, fieldError(v, name, fieldPriority, "must be an integer")
+		}
+		rule.Priority = &priority
+	}
+
+	v, ok := fields[fieldAction]
+	if !ok {
+		return Rule{}, fieldError(node, name, fieldAction, "is required (permit, deny or permit-reflect)")
+	}
+	action, ok := actions[strings.ToLower(v.Value)]
+	if !ok {
+		return Rule{}, fieldError(v, name, fieldAction, "invalid value %q (expected permit, deny or permit-reflect)", v.Value)
+	}
+	rule.IsPermit = action
+
+	rule.Proto = ip_types.IP_API_PROTO_HOPOPT
+	if v, ok := fields[fieldProto]; ok {
+		proto, err := ParseProto(v.Value)
+		if err != nil {
+			return Rule{}, fieldError(v, name, fieldProto, "%v", err)
+		}
+		rule.Proto = proto
+	}
+
+	src, dst, anyAddr, err := parseAddresses(name, rule.Proto, fields[fieldSrc], fields[fieldDst])
+	if err != nil {
+		return Rule{}, err
+	}
+	rule.SrcPrefix, rule.DstPrefix = src, dst
+
+	icmp := rule.Proto == ip_types.IP_API_PROTO_ICMP || rule.Proto == ip_types.IP_API_PROTO_ICMP6
+	rule.dualStack = anyAddr && !icmp
+	if icmp && (rule.Proto == ip_types.IP_API_PROTO_ICMP) != (src.Address.Af == ip_types.ADDRESS_IP4) {
+		return Rule{}, fieldError(fields[fieldProto], name, fieldProto, "%s does not match the address family of src/dst (use icmp for IPv4 and icmpv6 for IPv6)", fields[fieldProto].Value)
+	}
+
+	// 端口字段只对TCP/UDP有意义，ICMP规则复用端口字段存放type/code
+	first, last := [2]uint16{0, 0}, [2]uint16{maxPort, maxPort}
+	if icmp {
+		last = [2]uint16{maxICMP, maxICMP}
+	}
+	for i, f := range [][2]string{{fieldSport, fieldICMPType}, {fieldDport, fieldICMPCode}} {
+		port, icmpField := fields[f[0]], fields[f[1]]
+		switch {
+		case port != nil && rule.Proto != ip_types.IP_API_PROTO_TCP && rule.Proto != ip_types.IP_API_PROTO_UDP:
+			return Rule{}, fieldError(port, name, f[0], "ports require proto tcp or udp")
+		case icmpField != nil && !icmp:
+			return Rule{}, fieldError(icmpField, name, f[1], "requires proto icmp or icmpv6")
+		case port != nil:
+			if first[i], last[i], err = parseRange(port.Value, maxPort); err != nil {
+				return Rule{}, fieldError(port, name, f[0], "%v", err)
+			}
+		case icmpField != nil:
+			if first[i], last[i], err = parseRange(icmpField.Value, maxICMP); err != nil {
+				return Rule{}, fieldError(icmpField, name, f[1], "%v", err)
+			}
+		}
+	}
+	rule.SrcportOrIcmptypeFirst, rule.SrcportOrIcmptypeLast = first[0], last[0]
+	rule.DstportOrIcmpcodeFirst, rule.DstportOrIcmpcodeLast = first[1], last[1]
+	return rule, nil
+}
+
+// ParseProto 解析协议名称（tcp、udp、icmp、icmpv6、any）或协议号
+func ParseProto(s string) (ip_types.IPProto, error) {
+	if proto, ok := protocols[strings.ToLower(s)]; ok {
+		return proto, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, errors.Errorf("invalid value %q (expected tcp, udp, icmp, icmpv6, any or a protocol number)", s)
+	}
+	return ip_types.IPProto(n), nil
+}
+
+// parseAddresses 解析源/目的地址，未指定的一侧使用同一地址族的通配前缀
+//
+// 两侧都未指定时anyAddr为true，通配前缀的地址族由协议决定：icmpv6为::/0，其他为0.0.0.0/0。
+func parseAddresses(name string, proto ip_types.IPProto, srcNode, dstNode *yaml.Node) (src, dst ip_types.Prefix, anyAddr bool, err error) {
+	var prefixes [2]*netip.Prefix
+	for i, f := range []struct {
+		node  *yaml.Node
+		field string
+	}{{srcNode, fieldSrc}, {dstNode, fieldDst}} {
+		if f.node == nil || strings.EqualFold(f.node.Value, "any") {
+			continue
+		}
+		p, err := parsePrefix(f.node.Value)
+		if err != nil {
+			return src, dst, false, fieldError(f.node, name, f.field, "%v", err)
+		}
+		prefixes[i] = &p
+	}
+
+	if prefixes[0] != nil && prefixes[1] != nil && prefixes[0].Addr().Is4() != prefixes[1].Addr().Is4() {
+		return src, dst, false, fieldError(dstNode, name, fieldDst, "address family differs from src %s", prefixes[0])
+	}
+
+	anyAddr = prefixes[0] == nil && prefixes[1] == nil
+	wildcard := netip.PrefixFrom(netip.IPv4Unspecified(), 0)
+	if proto == ip_types.IP_API_PROTO_ICMP6 {
+		wildcard = netip.PrefixFrom(netip.IPv6Unspecified(), 0)
+	}
+	for _, p := range prefixes {
+		if p != nil {
+			wildcard = netip.PrefixFrom(netip.IPv4Unspecified(), 0)
+			if p.Addr().Is6() {
+				wildcard = netip.PrefixFrom(netip.IPv6Unspecified(), 0)
+			}
+		}
+	}
+	for i := range prefixes {
+		if prefixes[i] == nil {
+			prefixes[i] = &wildcard
+		}
+	}
+	return toPrefix(*prefixes[0]), toPrefix(*prefixes[1]), anyAddr, nil
+}
+
+// parsePrefix 解析CIDR或单个IP地址，主机位被清零
+func parsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, errors.Errorf("invalid address %q", s)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, errors.Errorf("invalid CIDR %q", s)
+	}
+	return p.Masked(), nil
+}
+
+func toPrefix(p netip.Prefix) ip_types.Prefix {
+	return ip_types.NewPrefix(net.IPNet{
+		IP:   net.IP(p.Addr().AsSlice()),
+		Mask: net.CIDRMask(p.Bits(), p.Addr().BitLen()),
+	})
+}
+
+// parseRange 解析单个值（"80"）、范围（"8000-8080"）或"any"
+func parseRange(s string, limit uint16) (first, last uint16, err error) {
+	if strings.EqualFold(s, "any") {
+		return 0, limit, nil
+	}
+	lo, hi, isRange := strings.Cut(s, "-")
+	if !isRange {
+		hi = lo
+	}
+	values := [2]uint16{}
+	for i, v := range []string{lo, hi} {
+		n, err := strconv.ParseUint(strings.TrimSpace(v), 10, 16)
+		if err != nil || n > uint64(limit) {
+			return 0, 0, errors.Errorf("invalid value %q (expected a number or range within 0-%d)", s, limit)
+		}
+		values[i] = uint16(n)
+	}
+	if values[0] > values[1] {
+		return 0, 0, errors.Errorf("invalid range %q (first is greater than last)", s)
+	}
+	return values[0], values[1], nil
+}
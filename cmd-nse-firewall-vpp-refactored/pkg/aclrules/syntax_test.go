@@ -0,0 +1,224 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules_test
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+func TestParse_Friendly(t *testing.T) {
+	rules, err := aclrules.Parse([]byte(`
+- name: allow web
+  action: permit
+  proto: tcp
+  dst: 10.0.0.0/8
+  dport: 8000-8080
+- name: allow dns
+  action: permit-reflect
+  proto: udp
+  src: 192.168.1.10
+  dport: 53
+- name: allow ping
+  action: permit
+  proto: icmp
+  icmp-type: 8
+- name: allow ping6
+  action: permit
+  proto: icmpv6
+  src: fd00::/64
+- name: deny all
+  action: deny
+  proto: any
+`))
+	require.NoError(t, err)
+	require.Len(t, rules, 6)
+
+	web := rules[0].ACLRule
+	require.Equal(t, acl_types.ACL_ACTION_API_PERMIT, web.IsPermit)
+	require.Equal(t, ip_types.IP_API_PROTO_TCP, web.Proto)
+	require.Equal(t, "0.0.0.0/0", web.SrcPrefix.String())
+	require.Equal(t, "10.0.0.0/8", web.DstPrefix.String())
+	require.Equal(t, [4]uint16{0, 65535, 8000, 8080},
+		[4]uint16{web.SrcportOrIcmptypeFirst, web.SrcportOrIcmptypeLast, web.DstportOrIcmpcodeFirst, web.DstportOrIcmpcodeLast})
+
+	dns := rules[1].ACLRule
+	require.Equal(t, acl_types.ACL_ACTION_API_PERMIT_REFLECT, dns.IsPermit)
+	require.Equal(t, "192.168.1.10/32", dns.SrcPrefix.String())
+	require.Equal(t, [2]uint16{53, 53}, [2]uint16{dns.DstportOrIcmpcodeFirst, dns.DstportOrIcmpcodeLast})
+
+	ping := rules[2].ACLRule
+	require.Equal(t, ip_types.IP_API_PROTO_ICMP, ping.Proto)
+	require.Equal(t, "0.0.0.0/0", ping.SrcPrefix.String())
+	require.Equal(t, [4]uint16{8, 8, 0, 255},
+		[4]uint16{ping.SrcportOrIcmptypeFirst, ping.SrcportOrIcmptypeLast, ping.DstportOrIcmpcodeFirst, ping.DstportOrIcmpcodeLast})
+
+	ping6 := rules[3].ACLRule
+	require.Equal(t, ip_types.IP_API_PROTO_ICMP6, ping6.Proto)
+	require.Equal(t, "fd00::/64", ping6.SrcPrefix.String())
+	require.Equal(t, "::/0", ping6.DstPrefix.String(), "未指定的一侧使用同一地址族的通配前缀")
+
+	deny := rules[4].ACLRule
+	require.Equal(t, acl_types.ACL_ACTION_API_DENY, deny.IsPermit)
+	require.Equal(t, ip_types.IP_API_PROTO_HOPOPT, deny.Proto)
+	require.Equal(t, "0.0.0.0/0", deny.SrcPrefix.String())
+
+	deny6 := rules[5]
+	require.Equal(t, "deny all#ipv6", deny6.Name, "未指定地址的规则同时生成IPv6规则")
+	require.Equal(t, acl_types.ACL_ACTION_API_DENY, deny6.IsPermit)
+	require.Equal(t, "::/0", deny6.SrcPrefix.String())
+	require.Equal(t, "::/0", deny6.DstPrefix.String())
+}
+
+func TestParse_FriendlyWildcardFamily(t *testing.T) {
+	rules, err := aclrules.Parse([]byte(`
+- name: allow ping6
+  action: permit
+  proto: icmpv6
+- name: allow ssh
+  action: permit
+  proto: tcp
+  src: any
+  dport: 22
+- name: allow corp
+  action: permit
+  src: 10.0.0.0/8
+- name: deny rest
+  action: deny
+`))
+	require.NoError(t, err)
+
+	var got []string
+	for _, r := range rules {
+		got = append(got, r.Name+" "+r.SrcPrefix.String()+" "+r.DstPrefix.String())
+	}
+	require.Equal(t, []string{
+		"allow ping6 ::/0 ::/0",
+		"allow ssh 0.0.0.0/0 0.0.0.0/0",
+		"allow ssh#ipv6 ::/0 ::/0",
+		"allow corp 10.0.0.0/8 0.0.0.0/0",
+		"deny rest 0.0.0.0/0 0.0.0.0/0",
+		"deny rest#ipv6 ::/0 ::/0",
+	}, got)
+
+	ssh6 := rules[2]
+	require.Equal(t, ip_types.IP_API_PROTO_TCP, ssh6.Proto)
+	require.Equal(t, [2]uint16{22, 22}, [2]uint16{ssh6.DstportOrIcmpcodeFirst, ssh6.DstportOrIcmpcodeLast})
+}
+
+func TestParse_FriendlyMapAndPriority(t *testing.T) {
+	rules, err := aclrules.Parse([]byte(`
+forbid tcp80:
+  priority: 20
+  action: deny
+  proto: tcp
+  dport: 80
+allow tcp5201:
+  priority: 10
+  action: permit
+  proto: tcp
+  dport: 5201
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"allow tcp5201", "allow tcp5201#ipv6", "forbid tcp80", "forbid tcp80#ipv6"}, names(rules))
+	require.Equal(t, uint16(5201), rules[0].DstportOrIcmpcodeFirst)
+}
+
+func TestParse_FriendlyErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "missing action",
+			data: "- name: a\n  proto: tcp\n  dport: 80\n",
+			want: `line 1: rule "a": action: is required`,
+		},
+		{
+			name: "invalid action",
+			data: "a:\n  action: allow\n",
+			want: `line 2: rule "a": action: invalid value "allow"`,
+		},
+		{
+			name: "invalid proto",
+			data: "a:\n  action: permit\n  proto: sctpp\n",
+			want: `line 3: rule "a": proto: invalid value "sctpp"`,
+		},
+		{
+			name: "reversed port range",
+			data: "a:\n  action: permit\n  proto: tcp\n  dport: 9000-8000\n",
+			want: `line 4: rule "a": dport: invalid range "9000-8000"`,
+		},
+		{
+			name: "port out of range",
+			data: "a:\n  action: permit\n  proto: udp\n  sport: 70000\n",
+			want: `rule "a": sport: invalid value "70000"`,
+		},
+		{
+			name: "port without tcp/udp",
+			data: "a:\n  action: permit\n  dport: 80\n",
+			want: `rule "a": dport: ports require proto tcp or udp`,
+		},
+		{
+			name: "icmp type without icmp",
+			data: "a:\n  action: permit\n  proto: tcp\n  icmp-type: 8\n",
+			want: `rule "a": icmp-type: requires proto icmp or icmpv6`,
+		},
+		{
+			name: "icmp code out of range",
+			data: "a:\n  action: permit\n  proto: icmp\n  icmp-code: 300\n",
+			want: `rule "a": icmp-code: invalid value "300"`,
+		},
+		{
+			name: "icmp with ipv6",
+			data: "a:\n  action: permit\n  proto: icmp\n  dst: fd00::1\n",
+			want: `rule "a": proto: icmp does not match the address family`,
+		},
+		{
+			name: "mixed address families",
+			data: "a:\n  action: permit\n  src: 10.0.0.0/8\n  dst: fd00::/8\n",
+			want: `line 4: rule "a": dst: address family differs from src 10.0.0.0/8`,
+		},
+		{
+			name: "invalid cidr",
+			data: "a:\n  action: permit\n  src: 10.0.0.0/33\n",
+			want: `rule "a": src: invalid CIDR "10.0.0.0/33"`,
+		},
+		{
+			name: "mixed syntax",
+			data: "a:\n  action: permit\n  ispermit: 1\n",
+			want: `line 3: rule "a": ispermit: unknown field`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := aclrules.Parse([]byte(tt.data))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
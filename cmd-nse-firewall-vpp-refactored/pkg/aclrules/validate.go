@@ -0,0 +1,138 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules
+
+import (
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/pkg/errors"
+)
+
+// Validate 检查规则是否可以被VPP按预期执行
+//
+// 检查内容：
+//   - 动作取值（deny/permit/permit-reflect）
+//   - 地址族和前缀长度（IPv4不超过32，IPv6不超过128）
+//   - 源/目的地址属于同一地址族
+//   - 端口范围不能颠倒，ICMP type/code不能超过255
+//   - 非TCP/UDP/ICMP协议不能限定端口范围
+//
+// 返回的错误包含规则的行号和名称。
+func (r *Rule) Validate() error {
+	if err := r.validate(); err != nil {
+		return errors.Errorf("line %d: rule %q: %v", r.Line, r.Name, err)
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	if _, ok := acl_types.ACLAction_name[uint8(r.IsPermit)]; !ok {
+		return errors.Errorf("invalid action %d (expected 0=deny, 1=permit or 2=permit-reflect)", r.IsPermit)
+	}
+
+	for _, p := range []struct {
+		field  string
+		prefix ip_types.Prefix
+	}{{"source prefix", r.SrcPrefix}, {"destination prefix", r.DstPrefix}} {
+		var maxLen uint8
+		switch p.prefix.Address.Af {
+		case ip_types.ADDRESS_IP4:
+			maxLen = 32
+		case ip_types.ADDRESS_IP6:
+			maxLen = 128
+		default:
+			return errors.Errorf("%s has an invalid address family %d", p.field, p.prefix.Address.Af)
+		}
+		if p.prefix.Len > maxLen {
+			return errors.Errorf("%s length %d is out of range (0-%d)", p.field, p.prefix.Len, maxLen)
+		}
+	}
+	if r.SrcPrefix.Address.Af != r.DstPrefix.Address.Af {
+		return errors.Errorf("source prefix %s and destination prefix %s have different address families (use 0.0.0.0/0 or ::/0 for the unrestricted side)",
+			r.SrcPrefix, r.DstPrefix)
+	}
+
+	switch r.Proto {
+	case ip_types.IP_API_PROTO_ICMP, ip_types.IP_API_PROTO_ICMP6:
+		if (r.Proto == ip_types.IP_API_PROTO_ICMP) != (r.SrcPrefix.Address.Af == ip_types.ADDRESS_IP4) {
+			return errors.Errorf("protocol %d does not match the address family of %s (use 1 for IPv4 and 58 for IPv6)", r.Proto, r.SrcPrefix)
+		}
+		if err := checkRange("ICMP type", r.SrcportOrIcmptypeFirst, r.SrcportOrIcmptypeLast, maxICMP); err != nil {
+			return err
+		}
+		return checkRange("ICMP code", r.DstportOrIcmpcodeFirst, r.DstportOrIcmpcodeLast, maxICMP)
+	case ip_types.IP_API_PROTO_TCP, ip_types.IP_API_PROTO_UDP:
+		if err := checkRange("source port", r.SrcportOrIcmptypeFirst, r.SrcportOrIcmptypeLast, maxPort); err != nil {
+			return err
+		}
+		return checkRange("destination port", r.DstportOrIcmpcodeFirst, r.DstportOrIcmpcodeLast, maxPort)
+	default:
+		// 其他协议不匹配端口，只允许未设置（0-0）或通配（0-65535）
+		for _, rng := range [][2]uint16{
+			{r.SrcportOrIcmptypeFirst, r.SrcportOrIcmptypeLast},
+			{r.DstportOrIcmpcodeFirst, r.DstportOrIcmpcodeLast},
+		} {
+			if rng[0] != 0 || (rng[1] != 0 && rng[1] != maxPort) {
+				return errors.Errorf("port or ICMP type/code range %d-%d has no effect for protocol %d (use tcp, udp, icmp or icmpv6)", rng[0], rng[1], r.Proto)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRange 检查范围的起止顺序；ICMP范围的起点不能超过255
+//
+// 原始配置中ICMP通配范围常写作0-65535，因此只限制起点。
+func checkRange(field string, first, last, limit uint16) error {
+	if first > last {
+		return errors.Errorf("%s range %d-%d is reversed", field, first, last)
+	}
+	if first > limit {
+		return errors.Errorf("%s %d is out of range (0-%d)", field, first, limit)
+	}
+	return nil
+}
+
+// DenyAll 返回拒绝所有IPv4和IPv6流量的规则
+//
+// 用于ACL配置文件缺失或无效、且策略要求以deny-all启动的场景。
+func DenyAll() []Rule {
+	families := []struct {
+		name string
+		af   ip_types.AddressFamily
+	}{{"deny-all-ipv4", ip_types.ADDRESS_IP4}, {"deny-all-ipv6", ip_types.ADDRESS_IP6}}
+
+	rules := make([]Rule, 0, len(families))
+	for _, f := range families {
+		prefix := ip_types.Prefix{Address: ip_types.Address{Af: f.af}}
+		rules = append(rules, Rule{
+			Name: f.name,
+			ACLRule: acl_types.ACLRule{
+				IsPermit:              acl_types.ACL_ACTION_API_DENY,
+				SrcPrefix:             prefix,
+				DstPrefix:             prefix,
+				SrcportOrIcmptypeLast: maxPort,
+				DstportOrIcmpcodeLast: maxPort,
+			},
+		})
+	}
+	return rules
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules_test
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+func TestParse_ValidateRaw(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "reversed port range",
+			data: "a:\n  proto: 6\n  dstportoricmpcodefirst: 8080\n  dstportoricmpcodelast: 80\n",
+			want: `line 1: rule "a": destination port range 8080-80 is reversed`,
+		},
+		{
+			name: "icmp type out of range",
+			data: "- name: a\n  proto: 1\n  srcportoricmptypefirst: 300\n  srcportoricmptypelast: 300\n",
+			want: `line 1: rule "a": ICMP type 300 is out of range (0-255)`,
+		},
+		{
+			name: "ports on non-port protocol",
+			data: "- name: a\n  proto: 47\n  dstportoricmpcodefirst: 80\n  dstportoricmpcodelast: 80\n",
+			want: `rule "a": port or ICMP type/code range 80-80 has no effect for protocol 47`,
+		},
+		{
+			name: "mixed address families",
+			data: "- name: a\n- name: b\n  srcprefix: fd00::/8\n",
+			want: `line 2: rule "b": source prefix fd00::/8 and destination prefix 0.0.0.0/0 have different address families`,
+		},
+		{
+			name: "icmp on ipv6",
+			data: "- name: a\n  proto: 1\n  srcprefix: fd00::/8\n  dstprefix: ::/0\n",
+			want: `rule "a": protocol 1 does not match the address family of fd00::/8`,
+		},
+		{
+			name: "prefix length out of range",
+			data: "- name: a\n  srcprefix: {address: {af: 0}, len: 33}\n",
+			want: `rule "a": source prefix length 33 is out of range (0-32)`,
+		},
+		{
+			name: "invalid action",
+			data: "- name: a\n  ispermit: 3\n",
+			want: `rule "a": invalid action 3`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := aclrules.Parse([]byte(tt.data))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestParse_ValidateAccepts(t *testing.T) {
+	// 旧版配置中ICMP的通配范围写作0-65535，仍然有效
+	_, err := aclrules.Parse([]byte(`
+allow icmp:
+  ispermit: 1
+  proto: 1
+  srcportoricmptypelast: 65535
+  dstportoricmpcodelast: 65535
+allow ipv6:
+  ispermit: 1
+  srcprefix: fd00::/8
+  dstprefix: ::/0
+`))
+	require.NoError(t, err)
+}
+
+func TestDenyAll(t *testing.T) {
+	rules := aclrules.DenyAll()
+	require.Equal(t, []string{"deny-all-ipv4", "deny-all-ipv6"}, names(rules))
+	for i := range rules {
+		require.NoError(t, rules[i].Validate())
+		require.Equal(t, acl_types.ACL_ACTION_API_DENY, rules[i].IsPermit)
+	}
+	require.Equal(t, "0.0.0.0/0", rules[0].SrcPrefix.String())
+	require.Equal(t, ip_types.ADDRESS_IP6, rules[1].DstPrefix.Address.Af)
+}
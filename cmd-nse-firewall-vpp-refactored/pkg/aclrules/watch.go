@@ -0,0 +1,91 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Watcher 定期检查规则文件的内容，发生变化时调用回调
+//
+// 文件通过内容摘要比较，因此Kubernetes ConfigMap的符号链接切换
+// 以及文件的删除和重新创建都能被检测到。
+type Watcher struct {
+	interval time.Duration
+	path     string
+	onChange func()
+	sum      string
+}
+
+// NewWatcher 创建规则文件监控器，并记录文件的当前状态
+//
+// 参数：
+//   - interval: 检查间隔
+//   - path: 规则文件路径
+//   - onChange: 文件内容变化时的回调
+func NewWatcher(interval time.Duration, path string, onChange func()) *Watcher {
+	w := &Watcher{
+		interval: interval,
+		path:     path,
+		onChange: onChange,
+	}
+	w.sum = w.snapshot()
+	return w
+}
+
+// Run 按检查间隔监控文件直到ctx结束
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if w.Changed() {
+				w.onChange()
+			}
+		}
+	}
+}
+
+// Changed 检查文件自上次检查以来是否发生变化
+func (w *Watcher) Changed() bool {
+	sum := w.snapshot()
+	changed := sum != w.sum
+	w.sum = sum
+	return changed
+}
+
+func (w *Watcher) snapshot() string {
+	data, err := os.ReadFile(filepath.Clean(w.path))
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
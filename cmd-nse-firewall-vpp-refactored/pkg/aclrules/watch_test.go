@@ -0,0 +1,51 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclrules_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+func TestWatcher_Changed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- {name: a, action: permit}\n"), 0600))
+
+	w := aclrules.NewWatcher(time.Second, path, func() {})
+	require.False(t, w.Changed())
+
+	// ConfigMap更新通过原子替换文件完成
+	tmp := filepath.Join(dir, "config.yaml.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("- {name: a, action: deny}\n"), 0600))
+	require.NoError(t, os.Rename(tmp, path))
+	require.True(t, w.Changed())
+	require.False(t, w.Changed())
+
+	require.NoError(t, os.Remove(path))
+	require.True(t, w.Changed(), "文件被删除也视为变化")
+}
@@ -0,0 +1,20 @@
+// Package aclserver 提供可热更新的VPP ACL链元素
+//
+// 与sdk-vpp的acl.NewServer相同，本包在每个连接的接口上安装入向和出向两个ACL。
+// 不同之处在于本包记录每个连接的接口和ACL索引，规则变化时通过acl_add_replace
+// 原地替换所有活动连接的ACL，ACL索引保持不变，连接不需要重建。
+//
+// 替换是全有或全无的：任何一个连接替换失败时，已经替换的连接会回滚到旧规则。
+//
+// 使用示例：
+//
+//	aclServer := aclserver.NewServer(vppConn, cfg.ACLConfig)
+//	endpoint.WithAdditionalFunctionality(aclServer, ...)
+//
+//	// 配置文件变化后
+//	if n, err := aclServer.Replace(ctx, newRules); err != nil {
+//	    log.Errorf("ACL reload failed, keeping current rules: %v", err)
+//	} else {
+//	    log.Infof("ACL rules replaced on %d connections", n)
+//	}
+package aclserver
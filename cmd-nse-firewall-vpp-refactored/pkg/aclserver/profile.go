@@ -0,0 +1,59 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclserver
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ratelimit"
+)
+
+type profileKey struct{}
+
+// storeProfile 将连接使用的ACL配置集记录到连接元数据
+func storeProfile(ctx context.Context, isClient bool, profile string) {
+	metadata.Map(ctx, isClient).Store(profileKey{}, profile)
+}
+
+// LoadProfile 从连接元数据中读取连接使用的ACL配置集名称
+func LoadProfile(ctx context.Context, isClient bool) (string, bool) {
+	v, ok := metadata.Map(ctx, isClient).Load(profileKey{})
+	if !ok {
+		return "", false
+	}
+	profile, ok := v.(string)
+	return profile, ok
+}
+
+func deleteProfile(ctx context.Context, isClient bool) {
+	metadata.Map(ctx, isClient).Delete(profileKey{})
+}
+
+// ClientSpiffeID 返回发起连接的客户端的SPIFFE ID
+//
+// 客户端身份在路径第一个段的token中（token的subject），与请求限流使用相同的身份，
+// 见ratelimit.ClientSpiffeID。无法解析时返回空字符串。
+func ClientSpiffeID(conn *networkservice.Connection) string {
+	return ratelimit.ClientSpiffeID(conn)
+}
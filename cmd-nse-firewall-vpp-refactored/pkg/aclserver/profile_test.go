@@ -0,0 +1,150 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclserver_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclserver"
+)
+
+const tenantPolicy = `
+profiles:
+  tenant-a:
+    - {name: allow https, action: permit, proto: tcp, dport: 443}
+  tenant-b:
+    - {name: allow dns, action: permit, proto: udp, dport: 53}
+selectors:
+  - profile: tenant-a
+    labels: {tenant: a}
+  - profile: tenant-b
+    spiffeID: spiffe://example.org/ns/tenant-b/sa/*
+`
+
+// profileServer 记录下游链元素看到的连接元数据中的配置集
+type profileServer struct {
+	profile string
+}
+
+func (s *profileServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	s.profile, _ = aclserver.LoadProfile(ctx, false)
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (s *profileServer) Close(ctx context.Context, conn *networkservice.Connection) (*emptypb.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func tokenFor(t *testing.T, spiffeID string) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: spiffeID}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func connWithClient(t *testing.T, id, spiffeID string) *networkservice.Connection {
+	return &networkservice.Connection{
+		Id: id,
+		Path: &networkservice.Path{
+			PathSegments: []*networkservice.PathSegment{{Name: "nsc", Token: tokenFor(t, spiffeID)}},
+		},
+	}
+}
+
+func TestServer_ProfileSelection(t *testing.T) {
+	p, err := aclrules.ParsePolicy([]byte(tenantPolicy))
+	require.NoError(t, err)
+
+	vpp := newFakeVPP()
+	srv := aclserver.NewServerWithPolicy(vpp, p)
+
+	recorder := &profileServer{}
+	server := chain.NewNetworkServiceServer(metadata.NewServer(), srv, recorder, &ifindexServer{index: 1})
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "conn-a", Labels: map[string]string{"tenant": "a"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "tenant-a", recorder.profile, "配置集记录在连接元数据中")
+
+	requestConn(t, srv, connWithClient(t, "conn-b", "spiffe://example.org/ns/tenant-b/sa/client"), 2)
+	requestConn(t, srv, connWithClient(t, "conn-c", "spiffe://example.org/ns/other/sa/client"), 3)
+
+	require.Equal(t, map[string]string{"conn-a": "tenant-a", "conn-b": "tenant-b", "conn-c": aclrules.DenyAllProfile}, srv.Profiles())
+	bindings := srv.Bindings()
+	require.Len(t, bindings, 3)
+	require.Equal(t, "conn-b", bindings[1].Connection)
+	require.Len(t, bindings[1].ACLs, 2)
+	require.Equal(t, "allow dns", bindings[1].Rules[0].Name, "规则名称用于映射命中计数")
+	require.Equal(t, uint16(443), vpp.rulesOf(1)[0][0].DstportOrIcmpcodeFirst)
+	require.Equal(t, uint16(53), vpp.rulesOf(2)[0][0].DstportOrIcmpcodeFirst)
+	require.Equal(t, acl_types.ACL_ACTION_API_DENY, vpp.rulesOf(3)[0][0].IsPermit, "没有匹配的连接拒绝所有流量")
+
+	_, err = server.Close(context.Background(), &networkservice.Connection{Id: "conn-a"})
+	require.NoError(t, err)
+	require.Empty(t, vpp.rulesOf(1))
+	require.Len(t, vpp.rulesOf(2), 2, "关闭连接不影响其他配置集")
+}
+
+func TestServer_ReplaceKeepsProfiles(t *testing.T) {
+	p, err := aclrules.ParsePolicy([]byte(tenantPolicy))
+	require.NoError(t, err)
+
+	vpp := newFakeVPP()
+	srv := aclserver.NewServerWithPolicy(vpp, p)
+	requestConn(t, srv, &networkservice.Connection{Id: "conn-a", Labels: map[string]string{"tenant": "a"}}, 1)
+	requestConn(t, srv, connWithClient(t, "conn-b", "spiffe://example.org/ns/tenant-b/sa/client"), 2)
+
+	// 新策略修改tenant-a的规则并删除tenant-b
+	next, err := aclrules.ParsePolicy([]byte(`
+profiles:
+  tenant-a:
+    - {name: allow ssh, action: permit, proto: tcp, dport: 22}
+selectors:
+  - profile: tenant-a
+    labels: {tenant: b}
+`))
+	require.NoError(t, err)
+	n, err := srv.Replace(context.Background(), next)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	require.Equal(t, uint16(22), vpp.rulesOf(1)[0][0].DstportOrIcmpcodeFirst, "连接保持Request时选择的配置集")
+	require.Equal(t, acl_types.ACL_ACTION_API_DENY, vpp.rulesOf(2)[0][0].IsPermit, "配置集被删除时拒绝所有流量")
+	require.Equal(t, map[string]string{"conn-a": "tenant-a", "conn-b": aclrules.DenyAllProfile}, srv.Profiles())
+}
+
+func TestClientSpiffeID(t *testing.T) {
+	require.Equal(t, "spiffe://example.org/ns/a/sa/nsc", aclserver.ClientSpiffeID(connWithClient(t, "id", "spiffe://example.org/ns/a/sa/nsc")))
+	require.Empty(t, aclserver.ClientSpiffeID(&networkservice.Connection{}))
+	require.Empty(t, aclserver.ClientSpiffeID(&networkservice.Connection{
+		Path: &networkservice.Path{PathSegments: []*networkservice.PathSegment{{Token: "not a token"}}},
+	}))
+}
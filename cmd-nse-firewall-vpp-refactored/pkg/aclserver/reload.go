@@ -0,0 +1,146 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+// 重新加载结果（指标的result属性）
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Reloader 从ACL配置文件重新加载策略并替换到所有活动连接
+type Reloader struct {
+	server *Server
+	path   string
+	base   []acl_types.ACLRule
+
+	onHealth func(loaded bool)
+	reloads  metric.Int64Counter
+	rules    metric.Int64Gauge
+}
+
+// NewReloader 创建ACL规则重新加载器
+//
+// 参数：
+//   - server: 安装ACL的链元素
+//   - path: ACL配置文件路径
+//   - base: 不来自配置文件的规则（NSM_ACL_CONFIG），重新加载时保持在每个配置集的规则之前
+//   - onHealth: 每次重新加载后调用，成功时为true，失败（保留当前规则）时为false，可以为nil
+//
+// 指标通过全局OpenTelemetry MeterProvider导出：
+//   - firewall_acl_reload_total{result="success|failure"}: 重新加载次数
+//   - firewall_acl_rules{profile}: 每个配置集当前生效的规则数
+func NewReloader(server *Server, path string, base []acl_types.ACLRule, onHealth func(loaded bool)) *Reloader {
+	meter := otel.Meter("github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclserver")
+	reloads, _ := meter.Int64Counter("firewall_acl_reload_total",
+		metric.WithDescription("Number of ACL config reloads by result"))
+	rules, _ := meter.Int64Gauge("firewall_acl_rules",
+		metric.WithDescription("Number of ACL rules currently installed per profile"))
+
+	if onHealth == nil {
+		onHealth = func(bool) {}
+	}
+
+	return &Reloader{
+		server:   server,
+		path:     path,
+		base:     base,
+		onHealth: onHealth,
+		reloads:  reloads,
+		rules:    rules,
+	}
+}
+
+// Reload 读取配置文件、校验规则（包括NSM_ACL_CONFIG中的规则）并替换所有活动连接的ACL
+//
+// 任何一步失败时保留当前规则，记录错误日志和失败指标、以false调用onHealth并返回错误。
+func (r *Reloader) Reload(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithField("acl", "reload")
+
+	policy, err := aclrules.LoadPolicy(r.path)
+	if err != nil {
+		r.record(ctx, ResultFailure)
+		logger.Errorf("ACL reload failed, keeping current rules: %v", err)
+		return err
+	}
+	base, err := aclrules.ValidateACLRules("NSM_ACL_CONFIG", r.base)
+	if err != nil {
+		r.record(ctx, ResultFailure)
+		logger.Errorf("ACL reload failed, keeping current rules: invalid NSM_ACL_CONFIG: %v", err)
+		return errors.Wrap(err, "invalid NSM_ACL_CONFIG")
+	}
+	policy = policy.WithBase(base)
+
+	now := time.Now()
+	n, err := r.server.Replace(ctx, policy)
+	if err != nil {
+		r.record(ctx, ResultFailure)
+		logger.Errorf("ACL reload failed, keeping current rules: %v", err)
+		return err
+	}
+
+	r.record(ctx, ResultSuccess)
+	r.recordRules(ctx, policy)
+	logger.Infof("ACL reloaded: %d profiles replaced on %d connections in %v", len(policy.Profiles), n, time.Since(now))
+	for _, name := range policy.Names() {
+		rules, _ := policy.Rules(name)
+		for i := range rules {
+			logger.Infof("ACL profile %q rule #%d: %s", name, i, &rules[i])
+		}
+	}
+	return nil
+}
+
+// Watch 在后台监控配置文件，变化时调用Reload，直到ctx结束
+//
+// 示例：
+//
+//	aclserver.NewReloader(aclServer, cfg.ACLConfigPath, envRules, nil).Watch(ctx, 30*time.Second)
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	r.recordRules(ctx, r.server.Policy())
+	go aclrules.NewWatcher(interval, r.path, func() { _ = r.Reload(ctx) }).Run(ctx)
+}
+
+func (r *Reloader) record(ctx context.Context, result string) {
+	r.onHealth(result == ResultSuccess)
+	r.reloads.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+}
+
+func (r *Reloader) recordRules(ctx context.Context, policy *aclrules.Policy) {
+	for _, name := range policy.Names() {
+		rules, _ := policy.Rules(name)
+		r.rules.Record(ctx, int64(len(rules)), metric.WithAttributes(attribute.String("profile", name)))
+	}
+}
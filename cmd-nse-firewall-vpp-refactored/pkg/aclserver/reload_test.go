@@ -0,0 +1,123 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclserver_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclserver"
+)
+
+// reloadCounts 读取重新加载计数器，按result属性分组
+func reloadCounts(t *testing.T, reader *sdkmetric.ManualReader) map[string]int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	counts := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || m.Name != "firewall_acl_reload_total" {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				result, _ := dp.Attributes.Value(attribute.Key("result"))
+				counts[result.AsString()] = dp.Value
+			}
+		}
+	}
+	return counts
+}
+
+func TestReloader_Reload(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	base := []acl_types.ACLRule{rule(22)}
+	vpp := newFakeVPP()
+	srv := aclserver.NewServer(vpp, append(base, rule(80)))
+	request(t, srv, "conn-1", 1)
+	indices := vpp.ifaces[1]
+
+	var loaded []bool
+	reloader := aclserver.NewReloader(srv, path, base, func(ok bool) { loaded = append(loaded, ok) })
+
+	// 有效的新配置：替换到已有连接，基础规则保持在前面
+	write("- {name: allow https, action: permit, proto: tcp, dport: 443}\n")
+	require.NoError(t, reloader.Reload(context.Background()))
+	rules := vpp.rulesOf(1)[0]
+	require.Len(t, rules, 3)
+	require.Equal(t, uint16(22), rules[0].DstportOrIcmpcodeFirst)
+	require.Equal(t, uint16(443), rules[1].DstportOrIcmpcodeFirst)
+	require.Equal(t, "::/0", rules[2].SrcPrefix.String(), "未指定地址的规则同时生成IPv6规则")
+	require.Equal(t, indices, vpp.ifaces[1])
+
+	// 无效配置：保留当前规则
+	write("- {name: broken, action: permit, proto: tcp, dport: 9000-80}\n")
+	err := reloader.Reload(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 1")
+	require.Equal(t, uint16(443), vpp.rulesOf(1)[0][1].DstportOrIcmpcodeFirst)
+
+	require.Equal(t, map[string]int64{aclserver.ResultSuccess: 1, aclserver.ResultFailure: 1}, reloadCounts(t, reader))
+	require.Equal(t, []bool{true, false}, loaded, "重新加载失败时策略组件报告为不健康")
+
+	// 配置文件被删除：失败，修复后恢复健康
+	require.NoError(t, os.Remove(path))
+	require.Error(t, reloader.Reload(context.Background()))
+	write("- {name: allow https, action: permit, proto: tcp, dport: 443}\n")
+	require.NoError(t, reloader.Reload(context.Background()))
+	require.Equal(t, []bool{true, false, false, true}, loaded)
+}
+
+func TestReloader_ReloadInvalidBaseRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- {name: allow https, action: permit, proto: tcp, dport: 443}\n"), 0600))
+
+	vpp := newFakeVPP()
+	srv := aclserver.NewServer(vpp, []acl_types.ACLRule{rule(80)})
+	request(t, srv, "conn-1", 1)
+
+	// NSM_ACL_CONFIG中的规则端口范围颠倒：每次重新加载都失败，保留当前规则
+	base := []acl_types.ACLRule{rule(22)}
+	base[0].DstportOrIcmpcodeLast = 21
+	reloader := aclserver.NewReloader(srv, path, base, nil)
+
+	err := reloader.Reload(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `rule "NSM_ACL_CONFIG#0": destination port range 22-21 is reversed`)
+	require.Equal(t, uint16(80), vpp.rulesOf(1)[0][0].DstportOrIcmpcodeFirst)
+}
@@ -0,0 +1,425 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclserver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/govpp/binapi/acl"
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+	"github.com/pkg/errors"
+	"go.fd.io/govpp/api"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+// aclTag ACL标签前缀，与sdk-vpp保持一致
+const aclTag = "nsm-acl-from-config"
+
+// binding 一个连接在VPP中安装的ACL
+type binding struct {
+	swIfIndex interface_types.InterfaceIndex
+	tag       string
+
+	// profile 连接使用的ACL配置集
+	profile string
+
+	// acls 入向和出向ACL索引，nil表示未安装ACL（规则为空）
+	acls []uint32
+}
+
+// Binding 一个活动连接安装的ACL（用于统计和排障）
+type Binding struct {
+	// Connection 连接ID
+	Connection string
+
+	// SwIfIndex 安装ACL的接口
+	SwIfIndex interface_types.InterfaceIndex
+
+	// Profile 连接使用的ACL配置集
+	Profile string
+
+	// ACLs 入向和出向ACL索引，为空表示未安装ACL
+	ACLs []uint32
+
+	// Rules ACL中的规则，顺序与VPP中的规则序号一致
+	Rules []aclrules.Rule
+}
+
+// Server 可热更新的ACL链元素
+//
+// 每个连接在Request时根据连接标签和客户端SPIFFE ID选择一个ACL配置集，
+// 选择结果记录在连接元数据中（LoadProfile），刷新、Close和Replace都使用该配置集。
+type Server struct {
+	vppConn api.Connection
+
+	mu       sync.Mutex
+	policy   *aclrules.Policy
+	bindings map[string]*binding
+}
+
+// NewServer 创建所有连接使用相同规则的ACL链元素
+//
+// 参数：
+//   - vppConn: VPP API连接
+//   - rules: 初始ACL规则（为空时不安装ACL，后续Replace会为所有连接安装）
+//
+// 示例：
+//
+//	aclServer := aclserver.NewServer(vppConn, cfg.ACLConfig)
+func NewServer(vppConn api.Connection, rules []acl_types.ACLRule) *Server {
+	return NewServerWithPolicy(vppConn, aclrules.NewPolicy(aclrules.FromACLRules("rule", rules)))
+}
+
+// NewServerWithPolicy 创建按配置集为每个连接安装ACL的链元素
+//
+// 示例：
+//
+//	aclServer := aclserver.NewServerWithPolicy(vppConn, cfg.ACLPolicy)
+func NewServerWithPolicy(vppConn api.Connection, policy *aclrules.Policy) *Server {
+	return &Server{
+		vppConn:  vppConn,
+		policy:   policy,
+		bindings: make(map[string]*binding),
+	}
+}
+
+// Request 在下游链元素创建接口后按连接的配置集安装ACL
+func (s *Server) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	// 在下游链元素之前记录配置集，下游可以通过LoadProfile读取
+	profile := s.selectProfile(request.GetConnection())
+	storeProfile(ctx, metadata.IsClient(s), profile)
+
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attach(ctx, conn.GetId(), profile); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := s.Close(closeCtx, conn); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Close 从接口上移除并删除连接的ACL
+func (s *Server) Close(ctx context.Context, conn *networkservice.Connection) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	b, ok := s.bindings[conn.GetId()]
+	delete(s.bindings, conn.GetId())
+	s.mu.Unlock()
+	deleteProfile(ctx, metadata.IsClient(s))
+
+	if ok && b.acls != nil {
+		if err := s.uninstall(ctx, b); err != nil {
+			log.FromContext(ctx).WithField("acl", "server").Debugf("error deleting acls: %v", err)
+		}
+	}
+
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+// Replace 按新策略替换所有活动连接的ACL
+//
+// 每个连接保持Request时选择的配置集，使用新策略中同名配置集的规则；
+// 配置集在新策略中被删除时，连接改为使用deny-all配置集。
+// 已安装ACL的连接通过acl_add_replace原地替换，ACL索引保持不变；
+// 规则为空、尚未安装ACL的连接会安装新的ACL。
+// 任何一个连接失败时，已处理的连接回滚到旧规则，并返回错误。
+//
+// 返回值为更新的连接数。任一配置集为空时返回错误（空ACL在VPP中会拒绝所有流量）。
+func (s *Server) Replace(ctx context.Context, policy *aclrules.Policy) (int, error) {
+	for _, name := range policy.Names() {
+		if rules, _ := policy.Rules(name); len(rules) == 0 {
+			return 0, errors.Errorf("refusing to replace ACLs with an empty rule set (profile %q)", name)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.bindings))
+	for id := range s.bindings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var done []*binding
+	var created []bool
+	profiles := make(map[*binding]string, len(ids))
+	for _, id := range ids {
+		b := s.bindings[id]
+		profile := b.profile
+		rules, ok := policy.Rules(profile)
+		if !ok {
+			log.FromContext(ctx).WithField("acl", "server").Warnf("ACL profile %q of connection %s was removed, using %q", profile, id, aclrules.DenyAllProfile)
+			profile = aclrules.DenyAllProfile
+			rules, _ = policy.Rules(profile)
+		}
+		profiles[b] = profile
+
+		var err error
+		if b.acls != nil {
+			err = s.replaceACLs(ctx, b, aclrules.ACLRules(rules))
+			// 部分替换（入向成功、出向失败）也需要回滚
+			done, created = append(done, b), append(created, false)
+		} else {
+			var acls []uint32
+			if acls, err = s.install(ctx, b.swIfIndex, b.tag, aclrules.ACLRules(rules)); err == nil {
+				b.acls = acls
+				done, created = append(done, b), append(created, true)
+			}
+		}
+		if err != nil {
+			s.rollback(ctx, done, created)
+			return 0, errors.Wrapf(err, "failed to replace ACLs of connection %s (rolled back)", id)
+		}
+	}
+
+	for b, profile := range profiles {
+		b.profile = profile
+	}
+	s.policy = policy
+	return len(ids), nil
+}
+
+// Policy 返回当前生效的策略
+func (s *Server) Policy() *aclrules.Policy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.policy
+}
+
+// Profiles 返回活动连接ID到ACL配置集名称的映射
+func (s *Server) Profiles() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]string, len(s.bindings))
+	for id, b := range s.bindings {
+		result[id] = b.profile
+	}
+	return result
+}
+
+// Bindings 返回按连接ID排序的活动连接ACL
+func (s *Server) Bindings() []Binding {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Binding, 0, len(s.bindings))
+	for id, b := range s.bindings {
+		rules, _ := s.policy.Rules(b.profile)
+		result = append(result, Binding{
+			Connection: id,
+			SwIfIndex:  b.swIfIndex,
+			Profile:    b.profile,
+			ACLs:       append([]uint32(nil), b.acls...),
+			Rules:      rules,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Connection < result[j].Connection })
+	return result
+}
+
+// Connections 返回已记录的活动连接数
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bindings)
+}
+
+// Interfaces 返回活动连接ID到接口索引的映射
+func (s *Server) Interfaces() map[string]interface_types.InterfaceIndex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]interface_types.InterfaceIndex, len(s.bindings))
+	for id, b := range s.bindings {
+		result[id] = b.swIfIndex
+	}
+	return result
+}
+
+// selectProfile 返回连接使用的配置集
+//
+// 刷新时保持已选择的配置集（Replace可能已将其改为deny-all），
+// 新连接根据连接标签和客户端SPIFFE ID选择。
+func (s *Server) selectProfile(conn *networkservice.Connection) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, ok := s.bindings[conn.GetId()]; ok {
+		return b.profile
+	}
+	return s.policy.Select(conn.GetLabels(), ClientSpiffeID(conn))
+}
+
+func (s *Server) attach(ctx context.Context, id, profile string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.bindings[id]; ok {
+		return nil
+	}
+
+	rules, ok := s.policy.Rules(profile)
+	if !ok {
+		// 选择后、安装前策略被替换且删除了该配置集
+		profile = aclrules.DenyAllProfile
+		rules, _ = s.policy.Rules(profile)
+		storeProfile(ctx, metadata.IsClient(s), profile)
+	}
+
+	swIfIndex, ok := ifindex.Load(ctx, metadata.IsClient(s))
+	if !ok {
+		if len(rules) == 0 {
+			return nil
+		}
+		return errors.New("swIfIndex not found")
+	}
+
+	b := &binding{swIfIndex: swIfIndex, tag: fmt.Sprintf("%s-%s", aclTag, id), profile: profile}
+	if len(rules) > 0 {
+		acls, err := s.install(ctx, swIfIndex, b.tag, aclrules.ACLRules(rules))
+		if err != nil {
+			return err
+		}
+		b.acls = acls
+	}
+	s.bindings[id] = b
+	log.FromContext(ctx).WithField("acl", "server").Infof("connection %s uses ACL profile %q", id, profile)
+	return nil
+}
+
+// rollback 将已处理的连接恢复到当前策略中配置集的规则
+func (s *Server) rollback(ctx context.Context, done []*binding, created []bool) {
+	logger := log.FromContext(ctx).WithField("acl", "server")
+	for i, b := range done {
+		if created[i] {
+			if err := s.uninstall(ctx, b); err != nil {
+				logger.Errorf("rollback: failed to remove ACLs from interface %d: %v", b.swIfIndex, err)
+			}
+			b.acls = nil
+			continue
+		}
+		rules, _ := s.policy.Rules(b.profile)
+		if err := s.replaceACLs(ctx, b, aclrules.ACLRules(rules)); err != nil {
+			logger.Errorf("rollback: failed to restore ACLs %v on interface %d: %v", b.acls, b.swIfIndex, err)
+		}
+	}
+}
+
+// install 创建入向和出向ACL并绑定到接口
+func (s *Server) install(ctx context.Context, swIfIndex interface_types.InterfaceIndex, tag string, rules []acl_types.ACLRule) ([]uint32, error) {
+	client := acl.NewServiceClient(s.vppConn)
+
+	var acls []uint32
+	for _, egress := range []bool{false, true} {
+		rsp, err := client.ACLAddReplace(ctx, aclAddReplace(^uint32(0), tag, egress, rules))
+		if err != nil {
+			s.deleteACLs(ctx, acls)
+			return nil, errors.Wrap(err, "vppapi ACLAddReplace returned error")
+		}
+		acls = append(acls, rsp.ACLIndex)
+	}
+
+	_, err := client.ACLInterfaceSetACLList(ctx, &acl.ACLInterfaceSetACLList{
+		SwIfIndex: swIfIndex,
+		Count:     uint8(len(acls)),
+		NInput:    1,
+		Acls:      acls,
+	})
+	if err != nil {
+		s.deleteACLs(ctx, acls)
+		return nil, errors.Wrap(err, "vppapi ACLInterfaceSetACLList returned error")
+	}
+	return acls, nil
+}
+
+// uninstall 从接口上解绑并删除ACL
+func (s *Server) uninstall(ctx context.Context, b *binding) error {
+	_, err := acl.NewServiceClient(s.vppConn).ACLInterfaceSetACLList(ctx, &acl.ACLInterfaceSetACLList{
+		SwIfIndex: b.swIfIndex,
+	})
+	if err != nil {
+		return errors.Wrap(err, "vppapi ACLInterfaceSetACLList returned error")
+	}
+	s.deleteACLs(ctx, b.acls)
+	return nil
+}
+
+// replaceACLs 原地替换连接的入向和出向ACL
+func (s *Server) replaceACLs(ctx context.Context, b *binding, rules []acl_types.ACLRule) error {
+	client := acl.NewServiceClient(s.vppConn)
+	for i, index := range b.acls {
+		if _, err := client.ACLAddReplace(ctx, aclAddReplace(index, b.tag, i > 0, rules)); err != nil {
+			return errors.Wrapf(err, "vppapi ACLAddReplace of ACL %d returned error", index)
+		}
+	}
+	return nil
+}
+
+func (s *Server) deleteACLs(ctx context.Context, acls []uint32) {
+	for _, index := range acls {
+		if _, err := acl.NewServiceClient(s.vppConn).ACLDel(ctx, &acl.ACLDel{ACLIndex: index}); err != nil {
+			log.FromContext(ctx).WithField("acl", "server").Debugf("error deleting acl %d: %v", index, err)
+		}
+	}
+}
+
+// aclAddReplace 构造ACL添加/替换请求，出向ACL交换源和目的字段
+//
+// index为^uint32(0)时创建新ACL，否则替换已有ACL。
+func aclAddReplace(index uint32, tag string, egress bool, rules []acl_types.ACLRule) *acl.ACLAddReplace {
+	r := make([]acl_types.ACLRule, len(rules))
+	copy(r, rules)
+	if egress {
+		for i := range r {
+			r[i].SrcPrefix, r[i].DstPrefix = r[i].DstPrefix, r[i].SrcPrefix
+			r[i].SrcportOrIcmptypeFirst, r[i].DstportOrIcmpcodeFirst = r[i].DstportOrIcmpcodeFirst, r[i].SrcportOrIcmptypeFirst
+			r[i].SrcportOrIcmptypeLast, r[i].DstportOrIcmpcodeLast = r[i].DstportOrIcmpcodeLast, r[i].SrcportOrIcmptypeLast
+		}
+	}
+	return &acl.ACLAddReplace{
+		ACLIndex: index,
+		Tag:      tag,
+		Count:    uint32(len(r)),
+		R:        r,
+	}
+}
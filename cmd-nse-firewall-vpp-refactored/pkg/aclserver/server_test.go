@@ -0,0 +1,220 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclserver_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/govpp/binapi/acl"
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.fd.io/govpp/api"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclserver"
+)
+
+// fakeVPP 记录ACL和接口绑定的VPP API模拟
+type fakeVPP struct {
+	api.Connection
+
+	mu        sync.Mutex
+	next      uint32
+	acls      map[uint32][]acl_types.ACLRule
+	ifaces    map[interface_types.InterfaceIndex][]uint32
+	failAfter int // 第N次替换已有ACL时失败（0表示不失败）
+	replaces  int
+}
+
+func newFakeVPP() *fakeVPP {
+	return &fakeVPP{
+		acls:   make(map[uint32][]acl_types.ACLRule),
+		ifaces: make(map[interface_types.InterfaceIndex][]uint32),
+	}
+}
+
+func (f *fakeVPP) Invoke(_ context.Context, req, reply api.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r := req.(type) {
+	case *acl.ACLAddReplace:
+		index := r.ACLIndex
+		if index == ^uint32(0) {
+			index = f.next
+			f.next++
+		} else {
+			if _, ok := f.acls[index]; !ok {
+				return errors.Errorf("acl %d not found", index)
+			}
+			f.replaces++
+			if f.failAfter > 0 && f.replaces == f.failAfter {
+				return errors.New("injected failure")
+			}
+		}
+		f.acls[index] = r.R
+		reply.(*acl.ACLAddReplaceReply).ACLIndex = index
+	case *acl.ACLInterfaceSetACLList:
+		if len(r.Acls) == 0 {
+			delete(f.ifaces, r.SwIfIndex)
+		} else {
+			f.ifaces[r.SwIfIndex] = r.Acls
+		}
+	case *acl.ACLDel:
+		delete(f.acls, r.ACLIndex)
+	default:
+		return errors.Errorf("unexpected message %s", req.GetMessageName())
+	}
+	return nil
+}
+
+func (f *fakeVPP) rulesOf(iface interface_types.InterfaceIndex) [][]acl_types.ACLRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result [][]acl_types.ACLRule
+	for _, index := range f.ifaces[iface] {
+		result = append(result, f.acls[index])
+	}
+	return result
+}
+
+// ifindexServer 模拟下游的接口创建链元素
+type ifindexServer struct {
+	index interface_types.InterfaceIndex
+}
+
+func (s *ifindexServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	ifindex.Store(ctx, false, s.index)
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (s *ifindexServer) Close(ctx context.Context, conn *networkservice.Connection) (*emptypb.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func rule(port uint16) acl_types.ACLRule {
+	return acl_types.ACLRule{
+		IsPermit:               acl_types.ACL_ACTION_API_PERMIT,
+		Proto:                  6,
+		SrcportOrIcmptypeLast:  65535,
+		DstportOrIcmpcodeFirst: port,
+		DstportOrIcmpcodeLast:  port,
+	}
+}
+
+func policy(rules ...acl_types.ACLRule) *aclrules.Policy {
+	return aclrules.NewPolicy(aclrules.FromACLRules("rule", rules))
+}
+
+func request(t *testing.T, srv *aclserver.Server, id string, index interface_types.InterfaceIndex) networkservice.NetworkServiceServer {
+	return requestConn(t, srv, &networkservice.Connection{Id: id}, index)
+}
+
+func requestConn(t *testing.T, srv *aclserver.Server, conn *networkservice.Connection, index interface_types.InterfaceIndex) networkservice.NetworkServiceServer {
+	server := chain.NewNetworkServiceServer(metadata.NewServer(), srv, &ifindexServer{index: index})
+	_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	return server
+}
+
+func TestServer_ReplaceKeepsIndices(t *testing.T) {
+	vpp := newFakeVPP()
+	srv := aclserver.NewServer(vpp, []acl_types.ACLRule{rule(80)})
+	request(t, srv, "conn-1", 1)
+	request(t, srv, "conn-2", 2)
+
+	before := map[interface_types.InterfaceIndex][]uint32{1: vpp.ifaces[1], 2: vpp.ifaces[2]}
+	require.Len(t, before[1], 2, "入向和出向各一个ACL")
+	require.Equal(t, uint16(80), vpp.rulesOf(1)[0][0].DstportOrIcmpcodeFirst)
+	require.Equal(t, uint16(80), vpp.rulesOf(1)[1][0].SrcportOrIcmptypeFirst, "出向ACL交换源和目的端口")
+
+	n, err := srv.Replace(context.Background(), policy(rule(443), rule(8443)))
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	for iface, acls := range before {
+		require.Equal(t, acls, vpp.ifaces[iface], "ACL索引保持不变")
+		rules := vpp.rulesOf(iface)
+		require.Len(t, rules[0], 2)
+		require.Equal(t, uint16(443), rules[0][0].DstportOrIcmpcodeFirst)
+	}
+	require.Len(t, vpp.acls, 4, "替换不应该创建新ACL")
+	require.Len(t, srv.Policy().Profiles[aclrules.DefaultProfile], 2)
+}
+
+func TestServer_ReplaceRollsBack(t *testing.T) {
+	vpp := newFakeVPP()
+	srv := aclserver.NewServer(vpp, []acl_types.ACLRule{rule(80)})
+	request(t, srv, "conn-1", 1)
+	request(t, srv, "conn-2", 2)
+
+	// 第3次替换（conn-2的入向ACL）失败
+	vpp.failAfter = 3
+	_, err := srv.Replace(context.Background(), policy(rule(443)))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "conn-2")
+
+	for _, iface := range []interface_types.InterfaceIndex{1, 2} {
+		for _, rules := range vpp.rulesOf(iface) {
+			require.Len(t, rules, 1)
+			require.Contains(t, []uint16{rules[0].DstportOrIcmpcodeFirst, rules[0].SrcportOrIcmptypeFirst}, uint16(80), "失败后回滚到旧规则")
+		}
+	}
+	require.Equal(t, uint16(80), srv.Policy().Profiles[aclrules.DefaultProfile][0].DstportOrIcmpcodeFirst)
+}
+
+func TestServer_ReplaceInstallsWhenStartedEmpty(t *testing.T) {
+	vpp := newFakeVPP()
+	srv := aclserver.NewServer(vpp, nil)
+	request(t, srv, "conn-1", 1)
+	require.Empty(t, vpp.acls, "规则为空时不安装ACL")
+
+	n, err := srv.Replace(context.Background(), policy(rule(22)))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Len(t, vpp.ifaces[1], 2)
+
+	_, err = srv.Replace(context.Background(), policy())
+	require.Error(t, err, "空规则集会拒绝所有流量，应该被拒绝")
+}
+
+func TestServer_Close(t *testing.T) {
+	vpp := newFakeVPP()
+	srv := aclserver.NewServer(vpp, []acl_types.ACLRule{rule(80)})
+	server := request(t, srv, "conn-1", 1)
+	require.Equal(t, 1, srv.Connections())
+
+	_, err := server.Close(context.Background(), &networkservice.Connection{Id: "conn-1"})
+	require.NoError(t, err)
+	require.Empty(t, vpp.ifaces)
+	require.Empty(t, vpp.acls)
+	require.Zero(t, srv.Connections())
+}
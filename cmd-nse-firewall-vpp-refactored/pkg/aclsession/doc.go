@@ -0,0 +1,20 @@
+// Package aclsession 提供VPP ACL插件有状态会话（permit+reflect）的配置和查询功能
+//
+// action为permit-reflect的规则在VPP中为匹配的流量创建会话，
+// 回程流量按会话放行，不需要为每个允许的流量编写镜像规则。
+//
+// 主要功能：
+//   - 配置会话表容量和空闲超时（UDP空闲、TCP空闲、TCP握手/关闭阶段）
+//   - 按接口列出活动会话的5元组和空闲时间
+//
+// VPP没有提供会话查询的二进制API，本包通过CLI（cli_inband）执行
+// "show acl-plugin sessions"并解析输出。
+//
+// 使用示例：
+//
+//	cli := aclsession.NewCLI(vppConn)
+//	if err := aclsession.Apply(ctx, cli, aclsession.Config{MaxSessions: 100000, UDPIdle: time.Minute}); err != nil {
+//	    log.Fatal(err)
+//	}
+//	sessions, err := aclsession.List(ctx, cli, swIfIndex)
+package aclsession
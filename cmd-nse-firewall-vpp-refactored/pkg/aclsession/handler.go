@@ -0,0 +1,81 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclsession
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+)
+
+// ConnectionSessions 一个连接的会话列表（管理接口的响应）
+type ConnectionSessions struct {
+	Connection string    `json:"connection"`
+	SwIfIndex  uint32    `json:"swIfIndex"`
+	Sessions   []Session `json:"sessions"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Handler 返回列出连接会话的HTTP处理器
+//
+// GET ?connection=<id> 返回指定连接的会话，未指定时返回所有连接的会话。
+// interfaces返回当前活动连接ID到接口索引的映射。
+//
+// 示例：
+//
+//	admin.Handle("/acl/sessions", aclsession.Handler(cli, aclServer.Interfaces))
+func Handler(cli CLI, interfaces func() map[string]interface_types.InterfaceIndex) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conns := interfaces()
+		ids := make([]string, 0, len(conns))
+		if id := r.URL.Query().Get("connection"); id != "" {
+			if _, ok := conns[id]; !ok {
+				http.Error(w, "connection not found: "+id, http.StatusNotFound)
+				return
+			}
+			ids = append(ids, id)
+		} else {
+			for id := range conns {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+		}
+
+		result := make([]ConnectionSessions, 0, len(ids))
+		for _, id := range ids {
+			cs := ConnectionSessions{Connection: id, SwIfIndex: uint32(conns[id]), Sessions: []Session{}}
+			sessions, err := List(r.Context(), cli, conns[id])
+			if err != nil {
+				cs.Error = err.Error()
+			} else {
+				cs.Sessions = sessions
+			}
+			result = append(result, cs)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(result)
+	})
+}
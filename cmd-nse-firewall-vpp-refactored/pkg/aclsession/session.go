@@ -0,0 +1,275 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclsession
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/govpp/binapi/vlib"
+	"github.com/pkg/errors"
+	"go.fd.io/govpp/api"
+)
+
+// CLI 执行VPP CLI命令并返回输出
+type CLI func(ctx context.Context, cmd string) (string, error)
+
+// NewCLI 返回通过cli_inband执行命令的CLI
+func NewCLI(vppConn api.Connection) CLI {
+	client := vlib.NewServiceClient(vppConn)
+	return func(ctx context.Context, cmd string) (string, error) {
+		rsp, err := client.CliInband(ctx, &vlib.CliInband{Cmd: cmd})
+		if err != nil {
+			return "", errors.Wrapf(err, "vppapi CliInband %q returned error", cmd)
+		}
+		return rsp.Reply, nil
+	}
+}
+
+// Config 会话表配置（零值表示使用VPP默认值）
+type Config struct {
+	// MaxSessions 会话表最大条目数
+	MaxSessions uint64
+
+	// UDPIdle UDP会话空闲超时
+	UDPIdle time.Duration
+
+	// TCPIdle 已建立的TCP会话空闲超时
+	TCPIdle time.Duration
+
+	// TCPTransient TCP握手和关闭阶段的超时
+	TCPTransient time.Duration
+}
+
+// Commands 返回应用配置所需的VPP CLI命令
+func (c Config) Commands() []string {
+	var cmds []string
+	if c.MaxSessions > 0 {
+		cmds = append(cmds, fmt.Sprintf("set acl-plugin session table max-entries %d", c.MaxSessions))
+	}
+	for _, t := range []struct {
+		name  string
+		value time.Duration
+	}{{"udp idle", c.UDPIdle}, {"tcp idle", c.TCPIdle}, {"tcp transient", c.TCPTransient}} {
+		if t.value > 0 {
+			cmds = append(cmds, fmt.Sprintf("set acl-plugin session timeout %s %d", t.name, int64(t.value.Round(time.Second)/time.Second)))
+		}
+	}
+	return cmds
+}
+
+// Validate 检查配置的有效性
+func (c Config) Validate() error {
+	for _, t := range []struct {
+		name  string
+		value time.Duration
+	}{{"UDP idle", c.UDPIdle}, {"TCP idle", c.TCPIdle}, {"TCP transient", c.TCPTransient}} {
+		if t.value < 0 {
+			return errors.Errorf("ACL session %s timeout must not be negative: %v", t.name, t.value)
+		}
+		if t.value > 0 && t.value < time.Second {
+			return errors.Errorf("ACL session %s timeout must be at least 1s: %v", t.name, t.value)
+		}
+	}
+	return nil
+}
+
+// Apply 将会话表配置应用到VPP
+//
+// VPP CLI的错误通过输出文本返回，输出非空时视为失败。
+func Apply(ctx context.Context, cli CLI, cfg Config) error {
+	for _, cmd := range cfg.Commands() {
+		out, err := cli(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		if out = strings.TrimSpace(out); out != "" {
+			return errors.Errorf("VPP rejected %q: %s", cmd, out)
+		}
+	}
+	return nil
+}
+
+// Session 一个ACL会话
+type Session struct {
+	// Src/Dst 创建会话的数据包的源/目的地址
+	Src netip.Addr `json:"src"`
+	Dst netip.Addr `json:"dst"`
+
+	// Proto IP协议号
+	Proto uint8 `json:"proto"`
+
+	// SrcPort/DstPort 源/目的端口（ICMP为type/code）
+	SrcPort uint16 `json:"srcPort"`
+	DstPort uint16 `json:"dstPort"`
+
+	// SwIfIndex 会话所在的接口
+	SwIfIndex uint32 `json:"swIfIndex"`
+
+	// Input 会话是否由入向ACL创建
+	Input bool `json:"input"`
+
+	// Idle 空闲时间，VPP未提供时钟信息时为-1（JSON中为idleSeconds）
+	Idle time.Duration `json:"-"`
+
+	// Thread/Index 会话在VPP中的工作线程和索引
+	Thread uint32 `json:"thread"`
+	Index  uint32 `json:"index"`
+}
+
+// String 返回会话的可读形式
+func (s *Session) String() string {
+	idle := "unknown"
+	if s.Idle >= 0 {
+		idle = s.Idle.Round(time.Millisecond).String()
+	}
+	direction := "output"
+	if s.Input {
+		direction = "input"
+	}
+	return fmt.Sprintf("proto %d %s -> %s idle %s (%s, sw_if_index %d)",
+		s.Proto, netip.AddrPortFrom(s.Src, s.SrcPort), netip.AddrPortFrom(s.Dst, s.DstPort), idle, direction, s.SwIfIndex)
+}
+
+// MarshalJSON 将空闲时间输出为秒数（未知时为-1）
+func (s Session) MarshalJSON() ([]byte, error) {
+	type session Session
+	idle := -1.0
+	if s.Idle >= 0 {
+		idle = s.Idle.Seconds()
+	}
+	return json.Marshal(struct {
+		session
+		IdleSeconds float64 `json:"idleSeconds"`
+	}{session(s), idle})
+}
+
+var (
+	// 会话哈希表条目，例如：
+	// l3 10.0.0.1 -> 10.0.0.2 lsb_of_sw_if_index 1 proto 6 l4_is_input 1 l4_slow_path 0 l4_flags 0x01 port 40000 -> 80 | sess id thread 0 idx 3
+	sessionKeyRe = regexp.MustCompile(`l3 (\S+) -> (\S+) lsb_of_sw_if_index (\d+) proto (\d+) l4_is_input (\d+).*? port (\d+) -> (\d+).*?sess id thread (\d+) idx (\d+)`)
+	swIfIndexRe  = regexp.MustCompile(`sw_if_index: (\d+)`)
+	lastActiveRe = regexp.MustCompile(`last active time: (\d+)`)
+	clockRe      = regexp.MustCompile(`now: (\d+) clocks per second: (\d+)`)
+)
+
+// List 列出指定接口上的活动会话，按空闲时间从小到大排序
+func List(ctx context.Context, cli CLI, swIfIndex interface_types.InterfaceIndex) ([]Session, error) {
+	out, err := cli(ctx, "show acl-plugin sessions verbose 1")
+	if err != nil {
+		return nil, err
+	}
+
+	now, clocksPerSecond := parseClock(out)
+	sessions := parseSessionKeys(out, uint32(swIfIndex))
+
+	result := make([]Session, 0, len(sessions))
+	for _, s := range sessions {
+		detail, err := cli(ctx, fmt.Sprintf("show acl-plugin sessions thread %d index %d", s.Thread, s.Index))
+		if err != nil {
+			return nil, err
+		}
+		// 低16位相同的其他接口
+		if m := swIfIndexRe.FindStringSubmatch(detail); m != nil && m[1] != strconv.FormatUint(uint64(swIfIndex), 10) {
+			continue
+		}
+		s.SwIfIndex = uint32(swIfIndex)
+		s.Idle = -1
+		if m := lastActiveRe.FindStringSubmatch(detail); m != nil && clocksPerSecond > 0 {
+			lastActive, _ := strconv.ParseUint(m[1], 10, 64)
+			if now >= lastActive {
+				s.Idle = time.Duration(float64(now-lastActive) / float64(clocksPerSecond) * float64(time.Second))
+			}
+		}
+		result = append(result, s)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Idle < result[j].Idle })
+	return result, nil
+}
+
+// parseSessionKeys 解析会话哈希表，返回接口索引低16位匹配的会话（按thread/index去重）
+func parseSessionKeys(out string, swIfIndex uint32) []Session {
+	seen := make(map[[2]uint32]bool)
+	var sessions []Session
+	for _, m := range sessionKeyRe.FindAllStringSubmatch(out, -1) {
+		lsb, _ := strconv.ParseUint(m[3], 10, 16)
+		if uint32(lsb) != swIfIndex&0xffff {
+			continue
+		}
+		src, err1 := netip.ParseAddr(m[1])
+		dst, err2 := netip.ParseAddr(m[2])
+		n, ok := parseUints(m[4:], []int{8, 1, 16, 16, 32, 32})
+		if err1 != nil || err2 != nil || !ok {
+			continue
+		}
+		proto, input, srcPort, dstPort, thread, index := n[0], n[1], n[2], n[3], n[4], n[5]
+
+		key := [2]uint32{uint32(thread), uint32(index)}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		sessions = append(sessions, Session{
+			Src:     src,
+			Dst:     dst,
+			Proto:   uint8(proto),
+			SrcPort: uint16(srcPort),
+			DstPort: uint16(dstPort),
+			Input:   input == 1,
+			Thread:  uint32(thread),
+			Index:   uint32(index),
+		})
+	}
+	return sessions
+}
+
+// parseUints 按位宽解析一组无符号整数
+func parseUints(values []string, bits []int) ([]uint64, bool) {
+	result := make([]uint64, len(bits))
+	for i, b := range bits {
+		n, err := strconv.ParseUint(values[i], 10, b)
+		if err != nil {
+			return nil, false
+		}
+		result[i] = n
+	}
+	return result, true
+}
+
+// parseClock 解析VPP当前时钟和每秒时钟数，未找到时返回0
+func parseClock(out string) (now, clocksPerSecond uint64) {
+	m := clockRe.FindStringSubmatch(out)
+	if m == nil {
+		return 0, 0
+	}
+	now, _ = strconv.ParseUint(m[1], 10, 64)
+	clocksPerSecond, _ = strconv.ParseUint(m[2], 10, 64)
+	return now, clocksPerSecond
+}
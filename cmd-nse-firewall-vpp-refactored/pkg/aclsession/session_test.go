@@ -0,0 +1,177 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclsession_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclsession"
+)
+
+// 摘自VPP "show acl-plugin sessions verbose 1" 的输出
+const sessionsOutput = `Sessions total: add 3 - del 0 = 3
+Sessions active: add 3 - deact 0 = 3
+Sessions being purged: deact 0 - del 0 = 0
+now: 5000000000 clocks per second: 1000000000
+
+IPv4 Session lookup hash table:
+Hash table ACL plugin FA IPv4 session bihash
+    [17]: heap offset 1024, elts 1, normal
+        0: l3 10.0.0.1 -> 172.16.1.100 lsb_of_sw_if_index 1 proto 6 l4_is_input 1 l4_slow_path 0 l4_flags 0x01 port 40000 -> 5201 tcp flags (valid) 02 rsvd 0 | sess id thread 0 idx 0
+    [42]: heap offset 1088, elts 1, normal
+        0: l3 10.0.0.1 -> 172.16.1.100 lsb_of_sw_if_index 1 proto 17 l4_is_input 1 l4_slow_path 0 l4_flags 0x00 port 53000 -> 53 tcp flags (invalid) 00 rsvd 0 | sess id thread 1 idx 4
+    [77]: heap offset 1152, elts 1, normal
+        0: l3 10.0.0.9 -> 172.16.1.100 lsb_of_sw_if_index 2 proto 6 l4_is_input 1 l4_slow_path 0 l4_flags 0x01 port 41000 -> 80 tcp flags (valid) 02 rsvd 0 | sess id thread 0 idx 1
+`
+
+func fakeCLI(t *testing.T) aclsession.CLI {
+	return func(_ context.Context, cmd string) (string, error) {
+		switch cmd {
+		case "show acl-plugin sessions verbose 1":
+			return sessionsOutput, nil
+		case "show acl-plugin sessions thread 0 index 0":
+			return "  session index 0:\n    sw_if_index: 1\n    last active time: 4000000000\n", nil
+		case "show acl-plugin sessions thread 1 index 4":
+			return "  session index 4:\n    sw_if_index: 1\n    last active time: 4750000000\n", nil
+		}
+		t.Fatalf("unexpected command %q", cmd)
+		return "", nil
+	}
+}
+
+func TestList(t *testing.T) {
+	sessions, err := aclsession.List(context.Background(), fakeCLI(t), interface_types.InterfaceIndex(1))
+	require.NoError(t, err)
+	require.Len(t, sessions, 2, "只返回指定接口的会话")
+
+	// 按空闲时间排序
+	udp := sessions[0]
+	require.Equal(t, uint8(17), udp.Proto)
+	require.Equal(t, "10.0.0.1", udp.Src.String())
+	require.Equal(t, uint16(53), udp.DstPort)
+	require.Equal(t, 250*time.Millisecond, udp.Idle)
+	require.True(t, udp.Input)
+	require.Equal(t, uint32(1), udp.Thread)
+	require.Equal(t, uint32(4), udp.Index)
+
+	tcp := sessions[1]
+	require.Equal(t, uint8(6), tcp.Proto)
+	require.Equal(t, uint16(40000), tcp.SrcPort)
+	require.Equal(t, uint16(5201), tcp.DstPort)
+	require.Equal(t, time.Second, tcp.Idle)
+	require.Equal(t, "proto 6 10.0.0.1:40000 -> 172.16.1.100:5201 idle 1s (input, sw_if_index 1)", tcp.String())
+}
+
+func TestList_UnknownClock(t *testing.T) {
+	cli := func(_ context.Context, cmd string) (string, error) {
+		if strings.HasSuffix(cmd, "verbose 1") {
+			return strings.Replace(sessionsOutput, "now: 5000000000 clocks per second: 1000000000\n", "", 1), nil
+		}
+		return "sw_if_index: 2\nlast active time: 1\n", nil
+	}
+	sessions, err := aclsession.List(context.Background(), cli, interface_types.InterfaceIndex(2))
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, time.Duration(-1), sessions[0].Idle)
+	require.Contains(t, sessions[0].String(), "idle unknown")
+}
+
+func TestApply(t *testing.T) {
+	cfg := aclsession.Config{
+		MaxSessions:  100000,
+		UDPIdle:      time.Minute,
+		TCPIdle:      time.Hour,
+		TCPTransient: 30 * time.Second,
+	}
+	require.Equal(t, []string{
+		"set acl-plugin session table max-entries 100000",
+		"set acl-plugin session timeout udp idle 60",
+		"set acl-plugin session timeout tcp idle 3600",
+		"set acl-plugin session timeout tcp transient 30",
+	}, cfg.Commands())
+	require.Empty(t, aclsession.Config{}.Commands(), "零值不修改VPP默认值")
+
+	var executed []string
+	err := aclsession.Apply(context.Background(), func(_ context.Context, cmd string) (string, error) {
+		executed = append(executed, cmd)
+		return "", nil
+	}, cfg)
+	require.NoError(t, err)
+	require.Equal(t, cfg.Commands(), executed)
+
+	// VPP CLI通过输出文本报告错误
+	err = aclsession.Apply(context.Background(), func(_ context.Context, cmd string) (string, error) {
+		return "unknown input `max-entries 100000'", nil
+	}, cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "VPP rejected")
+
+	err = aclsession.Apply(context.Background(), func(_ context.Context, cmd string) (string, error) {
+		return "", errors.New("connection lost")
+	}, cfg)
+	require.Error(t, err)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, aclsession.Config{UDPIdle: time.Second}.Validate())
+	require.Error(t, aclsession.Config{TCPIdle: -time.Second}.Validate())
+	require.Error(t, aclsession.Config{TCPTransient: 500 * time.Millisecond}.Validate())
+}
+
+func TestHandler(t *testing.T) {
+	interfaces := func() map[string]interface_types.InterfaceIndex {
+		return map[string]interface_types.InterfaceIndex{"conn-1": 1}
+	}
+	handler := aclsession.Handler(fakeCLI(t), interfaces)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/acl/sessions?connection=conn-1", http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var result []struct {
+		Connection string `json:"connection"`
+		SwIfIndex  uint32 `json:"swIfIndex"`
+		Sessions   []struct {
+			Src         string  `json:"src"`
+			DstPort     uint16  `json:"dstPort"`
+			IdleSeconds float64 `json:"idleSeconds"`
+		} `json:"sessions"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	require.Len(t, result, 1)
+	require.Equal(t, "conn-1", result[0].Connection)
+	require.Len(t, result[0].Sessions, 2)
+	require.Equal(t, 0.25, result[0].Sessions[0].IdleSeconds)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/acl/sessions?connection=missing", http.NoBody))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclsim
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+// Case 测试表中的一条用例
+//
+// 字段名称与ACL规则文件的友好语法一致：
+//
+//   - name: corp web
+//     proto: tcp
+//     src: 10.1.2.3
+//     dst: 192.168.1.10
+//     sport: 40000
+//     dport: 443
+//     expect: permit
+//     rule: web          # 可选，期望匹配的规则名称
+//   - proto: icmp
+//     src: 10.1.2.3
+//     dst: 192.168.1.10
+//     icmp-type: 8
+//     expect: deny
+//     profile: restricted  # 可选，默认使用策略的默认配置集
+type Case struct {
+	// Name 用例名称，未设置时使用数据包的可读形式
+	Name string `yaml:"name"`
+
+	// Profile 使用的配置集，未设置时为策略的默认配置集
+	Profile string `yaml:"profile"`
+
+	Proto    string `yaml:"proto"`
+	Src      string `yaml:"src"`
+	Dst      string `yaml:"dst"`
+	Sport    uint16 `yaml:"sport"`
+	Dport    uint16 `yaml:"dport"`
+	ICMPType uint8  `yaml:"icmp-type"`
+	ICMPCode uint8  `yaml:"icmp-code"`
+	TCPFlags uint8  `yaml:"tcp-flags"`
+
+	// Expect 期望的动作：permit、deny或permit-reflect
+	Expect string `yaml:"expect"`
+
+	// Rule 期望匹配的规则名称（可选），隐式拒绝为"(implicit deny)"
+	Rule string `yaml:"rule"`
+
+	// Line 用例在文件中的行号
+	Line int `yaml:"-"`
+
+	packet Packet
+	expect acl_types.ACLAction
+}
+
+// Packet 返回用例描述的数据包
+func (c *Case) Packet() Packet {
+	return c.packet
+}
+
+// LoadCases 从YAML文件加载测试表
+func LoadCases(path string) ([]Case, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read ACL test file")
+	}
+	cases, err := ParseCases(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid ACL test file %s", path)
+	}
+	return cases, nil
+}
+
+// ParseCases 解析YAML格式的测试表（用例列表）
+//
+// 未知字段、无效的地址或协议、缺少expect时返回带行号的错误。
+func ParseCases(data []byte) ([]Case, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	list := root.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, errors.Errorf("line %d: expected a list of test cases", list.Line)
+	}
+
+	var cases []Case
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cases); err != nil {
+		return nil, err
+	}
+	for i := range cases {
+		c := &cases[i]
+		c.Line = list.Content[i].Line
+		if err := c.Compile(); err != nil {
+			return nil, errors.Errorf("line %d: test case %q: %v", c.Line, c.Name, err)
+		}
+	}
+	return cases, nil
+}
+
+// Compile 检查用例并生成数据包和期望动作
+//
+// ParseCases对每条用例调用；直接构造的用例在Run之前需要调用。
+func (c *Case) Compile() error {
+	if c.Proto == "" {
+		return errors.New("proto is required")
+	}
+	var ok bool
+	if c.expect, ok = parseAction(c.Expect); !ok {
+		return errors.Errorf("expect: invalid action %q (expected permit, deny or permit-reflect)", c.Expect)
+	}
+
+	p, err := NewPacket(c.Proto, c.Src, c.Dst, c.Sport, c.Dport)
+	if err != nil {
+		return err
+	}
+	isICMP := p.Proto == ip_types.IP_API_PROTO_ICMP || p.Proto == ip_types.IP_API_PROTO_ICMP6
+	switch {
+	case isICMP && (c.Sport != 0 || c.Dport != 0):
+		return errors.New("sport and dport are not allowed for icmp (use icmp-type and icmp-code)")
+	case !isICMP && (c.ICMPType != 0 || c.ICMPCode != 0):
+		return errors.New("icmp-type and icmp-code are only allowed for icmp and icmpv6")
+	case c.TCPFlags != 0 && p.Proto != ip_types.IP_API_PROTO_TCP:
+		return errors.New("tcp-flags is only allowed for tcp")
+	}
+	if isICMP {
+		p.Sport, p.Dport = uint16(c.ICMPType), uint16(c.ICMPCode)
+	}
+	p.TCPFlags = c.TCPFlags
+	c.packet = p
+
+	if c.Name == "" {
+		c.Name = p.String()
+	}
+	return nil
+}
+
+func parseAction(s string) (acl_types.ACLAction, bool) {
+	for _, a := range []acl_types.ACLAction{
+		acl_types.ACL_ACTION_API_DENY, acl_types.ACL_ACTION_API_PERMIT, acl_types.ACL_ACTION_API_PERMIT_REFLECT,
+	} {
+		if strings.EqualFold(s, aclrules.ActionName(a)) {
+			return a, true
+		}
+	}
+	return 0, false
+}
+
+// Outcome 一条用例的运行结果
+type Outcome struct {
+	// Case 用例
+	Case Case
+
+	// Profile 实际使用的配置集
+	Profile string
+
+	// Result 模拟结果
+	Result Result
+
+	// Failure 失败原因，通过时为空
+	Failure string
+}
+
+// Passed 用例是否通过
+func (o *Outcome) Passed() bool {
+	return o.Failure == ""
+}
+
+// String 返回运行结果的可读形式
+func (o *Outcome) String() string {
+	if o.Passed() {
+		return fmt.Sprintf("PASS %s: %s", o.Case.Name, &o.Result)
+	}
+	return fmt.Sprintf("FAIL line %d: %s: %s", o.Case.Line, o.Case.Name, o.Failure)
+}
+
+// Run 用策略运行测试表
+//
+// 用例的动作和（如果指定了）匹配规则名称都与期望一致时通过。
+func Run(policy *aclrules.Policy, cases []Case) []Outcome {
+	outcomes := make([]Outcome, 0, len(cases))
+	for i := range cases {
+		c := &cases[i]
+		o := Outcome{Case: *c, Profile: c.Profile}
+		if o.Profile == "" {
+			o.Profile = policy.Default
+		}
+		rules, ok := policy.Rules(o.Profile)
+		if !ok {
+			o.Failure = fmt.Sprintf("unknown profile %q", o.Profile)
+			outcomes = append(outcomes, o)
+			continue
+		}
+
+		o.Result = Evaluate(rules, c.packet)
+		switch {
+		case o.Result.Action != c.expect:
+			o.Failure = fmt.Sprintf("expected %s, got %s", aclrules.ActionName(c.expect), &o.Result)
+		case c.Rule != "" && o.Result.Rule != c.Rule:
+			o.Failure = fmt.Sprintf("expected rule %q, got %s", c.Rule, &o.Result)
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes
+}
@@ -0,0 +1,23 @@
+// Package aclsim 离线模拟防火墙ACL规则对数据包的处理
+//
+// 在发布防火墙ConfigMap之前，用测试数据包检查规则的效果。
+// 匹配语义与VPP ACL插件一致：
+//   - 规则按顺序匹配，第一条匹配的规则决定动作（first-match）
+//   - 地址族必须相同，源/目的地址按前缀匹配
+//   - proto为0的规则匹配任意协议且不检查端口；否则协议必须相同，
+//     并检查源/目的端口范围（ICMP/ICMPv6为type/code范围）和TCP标志
+//   - 没有规则匹配时隐式拒绝
+//
+// permit-reflect规则按permit处理（返回流量由VPP会话表放行，不在模拟范围内）。
+//
+// 测试表（Case列表）描述数据包和期望的动作（以及可选的匹配规则名称），
+// 可在CI中批量运行：
+//
+//	policy, _ := aclrules.LoadPolicy("acl.yaml")
+//	cases, _ := aclsim.LoadCases("acl-tests.yaml")
+//	for _, o := range aclsim.Run(policy, cases) {
+//	    if !o.Passed() {
+//	        fmt.Println(o)
+//	    }
+//	}
+package aclsim
@@ -0,0 +1,175 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclsim
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+// ImplicitDeny 没有规则匹配时Result.Rule的取值
+const ImplicitDeny = "(implicit deny)"
+
+// Packet 待模拟的数据包（5元组）
+type Packet struct {
+	// Proto IP协议号
+	Proto ip_types.IPProto
+
+	// Src 源地址
+	Src netip.Addr
+
+	// Dst 目的地址
+	Dst netip.Addr
+
+	// Sport 源端口，ICMP/ICMPv6为type
+	Sport uint16
+
+	// Dport 目的端口，ICMP/ICMPv6为code
+	Dport uint16
+
+	// TCPFlags TCP标志（只对TCP规则的标志掩码生效）
+	TCPFlags uint8
+}
+
+// NewPacket 从协议名称（或协议号）和地址字符串创建数据包
+//
+// ICMP/ICMPv6数据包的sport和dport分别为type和code。
+func NewPacket(proto, src, dst string, sport, dport uint16) (Packet, error) {
+	p := Packet{Sport: sport, Dport: dport}
+	var err error
+	if p.Proto, err = aclrules.ParseProto(proto); err != nil {
+		return p, errors.Wrap(err, "proto")
+	}
+	if p.Src, err = netip.ParseAddr(src); err != nil {
+		return p, errors.Errorf("src: invalid address %q", src)
+	}
+	if p.Dst, err = netip.ParseAddr(dst); err != nil {
+		return p, errors.Errorf("dst: invalid address %q", dst)
+	}
+	p.Src, p.Dst = p.Src.Unmap(), p.Dst.Unmap()
+	if p.Src.Is4() != p.Dst.Is4() {
+		return p, errors.Errorf("src %s and dst %s have different address families", p.Src, p.Dst)
+	}
+	return p, nil
+}
+
+// String 返回数据包的可读形式
+func (p *Packet) String() string {
+	proto := fmt.Sprintf("proto %d", p.Proto)
+	switch p.Proto {
+	case ip_types.IP_API_PROTO_TCP:
+		proto = "tcp"
+	case ip_types.IP_API_PROTO_UDP:
+		proto = "udp"
+	case ip_types.IP_API_PROTO_ICMP, ip_types.IP_API_PROTO_ICMP6:
+		name := "icmp"
+		if p.Proto == ip_types.IP_API_PROTO_ICMP6 {
+			name = "icmpv6"
+		}
+		return fmt.Sprintf("%s %s -> %s type %d code %d", name, p.Src, p.Dst, p.Sport, p.Dport)
+	}
+	return fmt.Sprintf("%s %s -> %s",
+		proto, netip.AddrPortFrom(p.Src, p.Sport), netip.AddrPortFrom(p.Dst, p.Dport))
+}
+
+// Result 模拟结果
+type Result struct {
+	// Action 动作（没有规则匹配时为deny）
+	Action acl_types.ACLAction
+
+	// Index 匹配规则在规则列表中的序号，隐式拒绝时为-1
+	Index int
+
+	// Rule 匹配规则的名称，隐式拒绝时为ImplicitDeny
+	Rule string
+}
+
+// Permitted 数据包是否被放行（permit或permit-reflect）
+func (r *Result) Permitted() bool {
+	return r.Action != acl_types.ACL_ACTION_API_DENY
+}
+
+// String 返回结果的可读形式
+func (r *Result) String() string {
+	if r.Index < 0 {
+		return fmt.Sprintf("%s %s", aclrules.ActionName(r.Action), r.Rule)
+	}
+	return fmt.Sprintf("%s by rule #%d %q", aclrules.ActionName(r.Action), r.Index, r.Rule)
+}
+
+// Evaluate 按VPP first-match语义用规则列表处理数据包
+func Evaluate(rules []aclrules.Rule, p Packet) Result {
+	for i := range rules {
+		if Match(&rules[i].ACLRule, p) {
+			return Result{Action: rules[i].IsPermit, Index: i, Rule: rules[i].Name}
+		}
+	}
+	return Result{Action: acl_types.ACL_ACTION_API_DENY, Index: -1, Rule: ImplicitDeny}
+}
+
+// Match 判断单条VPP ACL规则是否匹配数据包
+func Match(r *acl_types.ACLRule, p Packet) bool {
+	if !matchPrefix(r.SrcPrefix, p.Src) || !matchPrefix(r.DstPrefix, p.Dst) {
+		return false
+	}
+	// proto为0的规则匹配任意协议，不检查端口
+	if r.Proto == ip_types.IP_API_PROTO_HOPOPT {
+		return true
+	}
+	if r.Proto != p.Proto {
+		return false
+	}
+	if p.Sport < r.SrcportOrIcmptypeFirst || p.Sport > r.SrcportOrIcmptypeLast ||
+		p.Dport < r.DstportOrIcmpcodeFirst || p.Dport > r.DstportOrIcmpcodeLast {
+		return false
+	}
+	if r.Proto == ip_types.IP_API_PROTO_TCP && p.TCPFlags&r.TCPFlagsMask != r.TCPFlagsValue {
+		return false
+	}
+	return true
+}
+
+// matchPrefix 判断地址是否属于前缀（地址族不同时不匹配）
+func matchPrefix(prefix ip_types.Prefix, addr netip.Addr) bool {
+	var base netip.Addr
+	switch prefix.Address.Af {
+	case ip_types.ADDRESS_IP4:
+		base = netip.AddrFrom4(prefix.Address.Un.GetIP4())
+	case ip_types.ADDRESS_IP6:
+		base = netip.AddrFrom16(prefix.Address.Un.GetIP6())
+	default:
+		return false
+	}
+	if base.Is4() != addr.Is4() {
+		return false
+	}
+	bits := int(prefix.Len)
+	if bits > base.BitLen() {
+		return false
+	}
+	return netip.PrefixFrom(base, bits).Masked().Contains(addr)
+}
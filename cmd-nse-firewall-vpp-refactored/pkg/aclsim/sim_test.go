@@ -0,0 +1,212 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclsim_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclsim"
+)
+
+const rulesYAML = `
+- name: block host
+  action: deny
+  src: 10.1.2.66
+- name: web
+  action: permit
+  proto: tcp
+  src: 10.0.0.0/8
+  dst: 192.168.1.10
+  dport: 443
+- name: high ports
+  action: permit-reflect
+  proto: udp
+  sport: 1024-65535
+  dport: 5000-5010
+- name: ping
+  action: permit
+  proto: icmp
+  icmp-type: 8
+  icmp-code: 0
+- name: ping6
+  action: permit
+  proto: icmpv6
+  src: ::/0
+- name: any v6
+  action: deny
+  dst: fd00::/8
+`
+
+func rules(t *testing.T) []aclrules.Rule {
+	r, err := aclrules.Parse([]byte(rulesYAML))
+	require.NoError(t, err)
+	return r
+}
+
+func packet(t *testing.T, proto, src, dst string, sport, dport uint16) aclsim.Packet {
+	p, err := aclsim.NewPacket(proto, src, dst, sport, dport)
+	require.NoError(t, err)
+	return p
+}
+
+func TestEvaluate(t *testing.T) {
+	r := rules(t)
+	for _, tc := range []struct {
+		packet aclsim.Packet
+		action acl_types.ACLAction
+		index  int
+	}{
+		{packet(t, "tcp", "10.1.2.66", "192.168.1.10", 40000, 443), acl_types.ACL_ACTION_API_DENY, 0},
+		{packet(t, "tcp", "10.1.2.3", "192.168.1.10", 40000, 443), acl_types.ACL_ACTION_API_PERMIT, 1},
+		{packet(t, "tcp", "11.1.2.3", "192.168.1.10", 40000, 443), acl_types.ACL_ACTION_API_DENY, -1},
+		{packet(t, "tcp", "10.1.2.3", "192.168.1.10", 40000, 80), acl_types.ACL_ACTION_API_DENY, -1},
+		{packet(t, "udp", "10.1.2.3", "192.168.1.10", 1024, 5010), acl_types.ACL_ACTION_API_PERMIT_REFLECT, 2},
+		{packet(t, "udp", "10.1.2.3", "192.168.1.10", 1023, 5010), acl_types.ACL_ACTION_API_DENY, -1},
+		// 未指定地址的规则同时匹配IPv6（"high ports#ipv6"）
+		{packet(t, "udp", "2001:db8::1", "fd00::2", 1024, 5010), acl_types.ACL_ACTION_API_PERMIT_REFLECT, 3},
+		{packet(t, "icmp", "10.1.2.3", "192.168.1.10", 8, 0), acl_types.ACL_ACTION_API_PERMIT, 4},
+		{packet(t, "icmp", "10.1.2.3", "192.168.1.10", 0, 0), acl_types.ACL_ACTION_API_DENY, -1},
+		{packet(t, "icmpv6", "fd00::1", "fd00::2", 128, 0), acl_types.ACL_ACTION_API_PERMIT, 5},
+		// proto为0的规则匹配任意协议且不检查端口
+		{packet(t, "udp", "2001:db8::1", "fd00::2", 53, 53), acl_types.ACL_ACTION_API_DENY, 6},
+		// IPv4映射的IPv6地址按IPv4处理，IPv4规则不匹配IPv6数据包
+		{packet(t, "tcp", "::ffff:10.1.2.3", "::ffff:192.168.1.10", 40000, 443), acl_types.ACL_ACTION_API_PERMIT, 1},
+		{packet(t, "tcp", "2001:db8::1", "2001:db8::2", 40000, 443), acl_types.ACL_ACTION_API_DENY, -1},
+	} {
+		result := aclsim.Evaluate(r, tc.packet)
+		require.Equal(t, tc.action, result.Action, tc.packet.String())
+		require.Equal(t, tc.index, result.Index, tc.packet.String())
+		if tc.index < 0 {
+			require.Equal(t, aclsim.ImplicitDeny, result.Rule)
+		} else {
+			require.Equal(t, r[tc.index].Name, result.Rule)
+		}
+	}
+}
+
+func TestMatch_TCPFlags(t *testing.T) {
+	rule := &acl_types.ACLRule{
+		IsPermit:              acl_types.ACL_ACTION_API_PERMIT,
+		SrcPrefix:             ip_types.Prefix{Address: ip_types.Address{Af: ip_types.ADDRESS_IP4}},
+		DstPrefix:             ip_types.Prefix{Address: ip_types.Address{Af: ip_types.ADDRESS_IP4}},
+		Proto:                 ip_types.IP_API_PROTO_TCP,
+		SrcportOrIcmptypeLast: 65535,
+		DstportOrIcmpcodeLast: 65535,
+		TCPFlagsMask:          0x12,
+		TCPFlagsValue:         0x02,
+	}
+	p := aclsim.Packet{
+		Proto: ip_types.IP_API_PROTO_TCP,
+		Src:   netip.MustParseAddr("10.0.0.1"),
+		Dst:   netip.MustParseAddr("10.0.0.2"),
+	}
+	p.TCPFlags = 0x02 // SYN
+	require.True(t, aclsim.Match(rule, p))
+	p.TCPFlags = 0x12 // SYN+ACK
+	require.False(t, aclsim.Match(rule, p))
+}
+
+func TestNewPacket_Invalid(t *testing.T) {
+	_, err := aclsim.NewPacket("sctp", "10.0.0.1", "10.0.0.2", 0, 0)
+	require.Error(t, err)
+	_, err = aclsim.NewPacket("tcp", "10.0.0.300", "10.0.0.2", 0, 0)
+	require.Error(t, err)
+	_, err = aclsim.NewPacket("tcp", "10.0.0.1", "fd00::1", 0, 0)
+	require.Error(t, err)
+}
+
+const casesYAML = `
+- name: corp web
+  proto: tcp
+  src: 10.1.2.3
+  dst: 192.168.1.10
+  sport: 40000
+  dport: 443
+  expect: permit
+  rule: web
+- proto: icmp
+  src: 10.1.2.3
+  dst: 192.168.1.10
+  icmp-type: 8
+  expect: permit
+- proto: tcp
+  src: 10.1.2.66
+  dst: 192.168.1.10
+  dport: 443
+  expect: permit
+- proto: tcp
+  src: 11.1.2.3
+  dst: 192.168.1.10
+  dport: 443
+  expect: deny
+  rule: web
+- proto: udp
+  src: 10.1.2.3
+  dst: 192.168.1.10
+  dport: 53
+  expect: deny
+  profile: missing
+`
+
+func TestRun(t *testing.T) {
+	cases, err := aclsim.ParseCases([]byte(casesYAML))
+	require.NoError(t, err)
+	require.Len(t, cases, 5)
+	require.Equal(t, "icmp 10.1.2.3 -> 192.168.1.10 type 8 code 0", cases[1].Name)
+	require.Equal(t, uint16(8), cases[1].Packet().Sport)
+
+	outcomes := aclsim.Run(aclrules.NewPolicy(rules(t)), cases)
+	var report []string
+	for i := range outcomes {
+		report = append(report, outcomes[i].String())
+	}
+	require.Equal(t, []string{
+		`PASS corp web: permit by rule #1 "web"`,
+		`PASS icmp 10.1.2.3 -> 192.168.1.10 type 8 code 0: permit by rule #4 "ping"`,
+		`FAIL line 15: tcp 10.1.2.66:0 -> 192.168.1.10:443: expected permit, got deny by rule #0 "block host"`,
+		`FAIL line 20: tcp 11.1.2.3:0 -> 192.168.1.10:443: expected rule "web", got deny (implicit deny)`,
+		`FAIL line 26: udp 10.1.2.3:0 -> 192.168.1.10:53: unknown profile "missing"`,
+	}, report)
+}
+
+func TestParseCases_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		yaml string
+		err  string
+	}{
+		{"proto: tcp\n", "expected a list of test cases"},
+		{"- proto: tcp\n  src: 10.0.0.1\n  dst: 10.0.0.2\n  expect: allow\n", `line 1: test case "": expect: invalid action "allow"`},
+		{"- proto: tcp\n  src: 10.0.0.1\n  dst: 10.0.0.2\n  expect: deny\n  port: 80\n", "field port not found"},
+		{"- proto: icmp\n  src: 10.0.0.1\n  dst: 10.0.0.2\n  dport: 8\n  expect: deny\n", "use icmp-type and icmp-code"},
+		{"- proto: udp\n  src: 10.0.0.1\n  dst: 10.0.0.2\n  tcp-flags: 2\n  expect: deny\n", "tcp-flags is only allowed for tcp"},
+		{"- src: 10.0.0.1\n  dst: 10.0.0.2\n  expect: deny\n", "proto is required"},
+	} {
+		_, err := aclsim.ParseCases([]byte(tc.yaml))
+		require.Error(t, err, tc.yaml)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
@@ -0,0 +1,25 @@
+// Package aclstats 提供防火墙ACL规则命中计数的采集、指标导出和查询功能
+//
+// 启用ACL统计后（Enable），VPP在统计段中为每个ACL维护按规则序号的
+// 命中计数（/acl/<ACL索引>/matches，包数和字节数）。每个连接安装自己的
+// 入向和出向ACL，因此计数可以映射回连接、方向和配置文件中的规则名称。
+//
+// 主要功能：
+//   - 启用VPP ACL统计计数器
+//   - 从VPP统计段读取连接ACL的计数并映射到规则名称（Collect）
+//   - 导出OpenTelemetry指标（RegisterMetrics）：
+//     firewall_acl_hits_total、firewall_acl_hit_bytes_total，
+//     属性为rule、action、connection、profile和direction
+//   - 按需查询的HTTP处理器（Handler）
+//
+// 使用示例：
+//
+//	if err := aclstats.Enable(ctx, vppConn); err != nil {
+//	    log.Fatal(err)
+//	}
+//	stats := statsclient.NewStatsClient("/var/run/vpp/stats.sock")
+//	if err := stats.Connect(); err != nil {
+//	    log.Fatal(err)
+//	}
+//	counters, err := aclstats.Collect(stats, aclServer.Bindings())
+package aclstats
@@ -0,0 +1,73 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclstats
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclserver"
+)
+
+// Handler 返回查询ACL命中计数的HTTP处理器
+//
+// GET ?connection=<id> 返回指定连接的计数，未指定时返回所有连接的计数；
+// ?hits=1 只返回有命中的规则。
+//
+// 示例：
+//
+//	admin.Handle("/acl/counters", aclstats.Handler(stats, aclServer.Bindings))
+func Handler(dumper Dumper, bindings func() []aclserver.Binding) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		selected := bindings()
+		if id := r.URL.Query().Get("connection"); id != "" {
+			var found []aclserver.Binding
+			for _, b := range selected {
+				if b.Connection == id {
+					found = append(found, b)
+				}
+			}
+			if len(found) == 0 {
+				http.Error(w, "connection not found: "+id, http.StatusNotFound)
+				return
+			}
+			selected = found
+		}
+
+		counters, err := Collect(dumper, selected)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		result := make([]Counter, 0, len(counters))
+		for _, c := range counters {
+			if r.URL.Query().Get("hits") != "" && c.Packets == 0 {
+				continue
+			}
+			result = append(result, c)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(result)
+	})
+}
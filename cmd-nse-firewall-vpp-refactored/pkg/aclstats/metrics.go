@@ -0,0 +1,88 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclstats
+
+import (
+	"context"
+	"math"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclserver"
+)
+
+// RegisterMetrics 通过全局OpenTelemetry MeterProvider导出ACL命中计数
+//
+// 每次指标导出时读取VPP统计段（轮询间隔即NSM_METRICS_EXPORT_INTERVAL）：
+//   - firewall_acl_hits_total: 命中的包数
+//   - firewall_acl_hit_bytes_total: 命中的字节数
+//
+// 属性为rule、action、connection、profile和direction。连接关闭后其计数不再导出。
+//
+// 示例：
+//
+//	if err := aclstats.RegisterMetrics(stats, aclServer.Bindings); err != nil {
+//	    log.Fatal(err)
+//	}
+func RegisterMetrics(dumper Dumper, bindings func() []aclserver.Binding) error {
+	meter := otel.Meter("github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclstats")
+	hits, err := meter.Int64ObservableCounter("firewall_acl_hits_total",
+		metric.WithDescription("Number of packets matched by each ACL rule"))
+	if err != nil {
+		return errors.Wrap(err, "failed to create firewall_acl_hits_total")
+	}
+	hitBytes, err := meter.Int64ObservableCounter("firewall_acl_hit_bytes_total",
+		metric.WithDescription("Number of bytes matched by each ACL rule"), metric.WithUnit("By"))
+	if err != nil {
+		return errors.Wrap(err, "failed to create firewall_acl_hit_bytes_total")
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		counters, err := Collect(dumper, bindings())
+		if err != nil {
+			return err
+		}
+		for i := range counters {
+			c := &counters[i]
+			attrs := metric.WithAttributes(
+				attribute.String("rule", c.Rule),
+				attribute.String("action", c.Action),
+				attribute.String("connection", c.Connection),
+				attribute.String("profile", c.Profile),
+				attribute.String("direction", c.Direction),
+			)
+			o.ObserveInt64(hits, toInt64(c.Packets), attrs)
+			o.ObserveInt64(hitBytes, toInt64(c.Bytes), attrs)
+		}
+		return nil
+	}, hits, hitBytes)
+	return errors.Wrap(err, "failed to register ACL stats callback")
+}
+
+func toInt64(v uint64) int64 {
+	if v > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(v)
+}
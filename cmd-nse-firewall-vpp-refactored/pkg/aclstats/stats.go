@@ -0,0 +1,142 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclstats
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/networkservicemesh/govpp/binapi/acl"
+	"github.com/pkg/errors"
+	"go.fd.io/govpp/adapter"
+	"go.fd.io/govpp/api"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclserver"
+)
+
+// 计数的方向（ACL在连接中的位置）
+const (
+	DirectionIngress = "ingress"
+	DirectionEgress  = "egress"
+)
+
+// statsPattern VPP统计段中ACL命中计数的名称
+const statsPattern = `^/acl/[0-9]+/matches$`
+
+var statsName = regexp.MustCompile(`^/acl/([0-9]+)/matches$`)
+
+// Dumper 读取VPP统计段（statsclient.StatsClient实现了该接口）
+type Dumper interface {
+	DumpStats(patterns ...string) ([]adapter.StatEntry, error)
+}
+
+// Counter 一条规则在一个连接的一个方向上的命中计数
+type Counter struct {
+	Connection string `json:"connection"`
+	Profile    string `json:"profile"`
+	Direction  string `json:"direction"`
+	ACL        uint32 `json:"acl"`
+	Index      int    `json:"index"`
+	Rule       string `json:"rule"`
+	Action     string `json:"action"`
+	Packets    uint64 `json:"packets"`
+	Bytes      uint64 `json:"bytes"`
+}
+
+// String 返回计数的可读形式（用于日志）
+func (c *Counter) String() string {
+	return fmt.Sprintf("connection=%s profile=%s %s acl=%d rule #%d %q action=%s packets=%d bytes=%d",
+		c.Connection, c.Profile, c.Direction, c.ACL, c.Index, c.Rule, c.Action, c.Packets, c.Bytes)
+}
+
+// Enable 启用VPP ACL统计计数器
+//
+// 计数器对所有ACL生效，会带来少量转发开销。
+func Enable(ctx context.Context, vppConn api.Connection) error {
+	_, err := acl.NewServiceClient(vppConn).ACLStatsIntfCountersEnable(ctx, &acl.ACLStatsIntfCountersEnable{Enable: true})
+	if err != nil {
+		return errors.Wrap(err, "vppapi ACLStatsIntfCountersEnable returned error")
+	}
+	return nil
+}
+
+// Collect 读取连接ACL的命中计数，按连接、方向和规则顺序返回
+//
+// 计数按VPP工作线程求和。统计段中还没有计数的ACL（刚创建）计为0。
+func Collect(dumper Dumper, bindings []aclserver.Binding) ([]Counter, error) {
+	entries, err := dumper.DumpStats(statsPattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to dump VPP ACL stats")
+	}
+
+	matches := make(map[uint32]adapter.CombinedCounterStat, len(entries))
+	for _, e := range entries {
+		m := statsName.FindSubmatch(e.Name)
+		if m == nil {
+			continue
+		}
+		var index uint32
+		if _, err := fmt.Sscan(string(m[1]), &index); err != nil {
+			continue
+		}
+		if data, ok := e.Data.(adapter.CombinedCounterStat); ok {
+			matches[index] = data
+		}
+	}
+
+	var counters []Counter
+	for _, b := range bindings {
+		for i, index := range b.ACLs {
+			direction := DirectionIngress
+			if i > 0 {
+				direction = DirectionEgress
+			}
+			for j := range b.Rules {
+				packets, bytes := reduce(matches[index], j)
+				counters = append(counters, Counter{
+					Connection: b.Connection,
+					Profile:    b.Profile,
+					Direction:  direction,
+					ACL:        index,
+					Index:      j,
+					Rule:       b.Rules[j].Name,
+					Action:     aclrules.ActionName(b.Rules[j].IsPermit),
+					Packets:    packets,
+					Bytes:      bytes,
+				})
+			}
+		}
+	}
+	return counters, nil
+}
+
+// reduce 对所有工作线程的第i个计数求和
+func reduce(stat adapter.CombinedCounterStat, i int) (packets, bytes uint64) {
+	for _, worker := range stat {
+		if i < len(worker) {
+			packets += worker[i].Packets()
+			bytes += worker[i].Bytes()
+		}
+	}
+	return packets, bytes
+}
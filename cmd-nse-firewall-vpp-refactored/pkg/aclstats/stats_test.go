@@ -0,0 +1,138 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aclstats_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.fd.io/govpp/adapter"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclserver"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclstats"
+)
+
+// fakeDumper 返回固定的VPP统计段内容
+type fakeDumper map[string]adapter.CombinedCounterStat
+
+func (f fakeDumper) DumpStats(_ ...string) ([]adapter.StatEntry, error) {
+	var entries []adapter.StatEntry
+	for name, data := range f {
+		entries = append(entries, adapter.StatEntry{
+			StatIdentifier: adapter.StatIdentifier{Name: []byte(name)},
+			Type:           adapter.CombinedCounterVector,
+			Data:           data,
+		})
+	}
+	return entries, nil
+}
+
+func bindings(t *testing.T) []aclserver.Binding {
+	rules, err := aclrules.Parse([]byte(`
+allow https: {action: permit, proto: tcp, dst: 0.0.0.0/0, dport: 443}
+deny rest: {action: deny, src: 0.0.0.0/0}
+`))
+	require.NoError(t, err)
+	return []aclserver.Binding{
+		{Connection: "conn-1", Profile: aclrules.DefaultProfile, ACLs: []uint32{4, 5}, Rules: rules},
+		{Connection: "conn-2", Profile: aclrules.DefaultProfile, ACLs: []uint32{6, 7}, Rules: rules},
+	}
+}
+
+var stats = fakeDumper{
+	// 两个工作线程的计数按规则序号求和
+	"/acl/4/matches": {{{10, 1000}, {1, 60}}, {{5, 500}, {2, 120}}},
+	"/acl/5/matches": {{{7, 700}, {0, 0}}},
+	"/acl/9/matches": {{{99, 99}}},
+}
+
+func TestCollect(t *testing.T) {
+	counters, err := aclstats.Collect(stats, bindings(t))
+	require.NoError(t, err)
+	require.Len(t, counters, 8, "2个连接 x 2个方向 x 2条规则")
+
+	require.Equal(t, aclstats.Counter{
+		Connection: "conn-1", Profile: aclrules.DefaultProfile, Direction: aclstats.DirectionIngress,
+		ACL: 4, Index: 0, Rule: "allow https", Action: aclrules.ActionPermit, Packets: 15, Bytes: 1500,
+	}, counters[0])
+	require.Equal(t, "deny rest", counters[1].Rule)
+	require.Equal(t, aclrules.ActionDeny, counters[1].Action)
+	require.Equal(t, uint64(3), counters[1].Packets)
+	require.Equal(t, aclstats.DirectionEgress, counters[2].Direction)
+	require.Equal(t, uint64(7), counters[2].Packets)
+	require.Zero(t, counters[4].Packets, "统计段中还没有计数的ACL计为0")
+}
+
+func TestHandler(t *testing.T) {
+	handler := aclstats.Handler(stats, func() []aclserver.Binding { return bindings(t) })
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/acl/counters?connection=conn-1&hits=1", http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var counters []aclstats.Counter
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &counters))
+	require.Len(t, counters, 3, "只返回有命中的规则")
+	for _, c := range counters {
+		require.Equal(t, "conn-1", c.Connection)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/acl/counters?connection=unknown", http.NoBody))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	require.NoError(t, aclstats.RegisterMetrics(stats, func() []aclserver.Binding { return bindings(t) }))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	hits := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || m.Name != "firewall_acl_hits_total" {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				conn, _ := dp.Attributes.Value(attribute.Key("connection"))
+				direction, _ := dp.Attributes.Value(attribute.Key("direction"))
+				rule, _ := dp.Attributes.Value(attribute.Key("rule"))
+				action, _ := dp.Attributes.Value(attribute.Key("action"))
+				hits[conn.AsString()+"/"+direction.AsString()+"/"+rule.AsString()+"/"+action.AsString()] = dp.Value
+			}
+		}
+	}
+	require.Len(t, hits, 8)
+	require.Equal(t, int64(15), hits["conn-1/ingress/allow https/permit"])
+	require.Equal(t, int64(3), hits["conn-1/ingress/deny rest/deny"])
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Server 管理接口HTTP服务器
+type Server struct {
+	mux      *http.ServeMux
+	patterns []string
+}
+
+// NewServer 创建管理接口服务器
+//
+// 根路径返回已注册的路径列表。
+func NewServer() *Server {
+	s := &Server{mux: http.NewServeMux()}
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(strings.Join(s.patterns, "\n") + "\n"))
+	})
+	return s
+}
+
+// Handle 注册处理器
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+	s.patterns = append(s.patterns, pattern)
+	sort.Strings(s.patterns)
+}
+
+// ServeHTTP 实现http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe 在addr上启动服务器，ctx结束时关闭
+//
+// 返回的错误通道在监听失败或服务器异常退出时收到错误。
+func (s *Server) ListenAndServe(ctx context.Context, addr string) <-chan error {
+	errCh := make(chan error, 1)
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		errCh <- errors.Wrapf(err, "failed to listen on admin address %s", addr)
+		close(errCh)
+		return errCh
+	}
+
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	go func() {
+		defer close(errCh)
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- errors.Wrap(err, "admin server failed")
+		}
+	}()
+	return errCh
+}
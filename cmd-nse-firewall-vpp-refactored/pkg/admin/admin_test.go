@@ -0,0 +1,66 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/admin"
+)
+
+func TestServer(t *testing.T) {
+	srv := admin.NewServer()
+	srv.Handle("/acl/sessions", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("[]"))
+	}))
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	require.Equal(t, "/acl/sessions\n", rec.Body.String(), "根路径列出已注册的路径")
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/acl/sessions", http.NoBody))
+	require.Equal(t, "[]", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unknown", http.NoBody))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_ListenAndServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := admin.NewServer().ListenAndServe(ctx, "127.0.0.1:0")
+	cancel()
+	for err := range errCh {
+		require.NoError(t, err)
+	}
+
+	// 地址无效时通过错误通道返回
+	errCh = admin.NewServer().ListenAndServe(context.Background(), "256.0.0.1:0")
+	err := <-errCh
+	require.Error(t, err)
+}
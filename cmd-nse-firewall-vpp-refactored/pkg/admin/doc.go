@@ -0,0 +1,11 @@
+// Package admin 提供NSE的管理接口HTTP服务器
+//
+// 管理接口用于排障查询（例如ACL会话列表），只应该监听本地地址或
+// 通过kubectl port-forward访问，不提供认证。
+//
+// 使用示例：
+//
+//	srv := admin.NewServer()
+//	srv.Handle("/acl/sessions", aclsession.Handler(cli, aclServer.Interfaces))
+//	errCh := srv.ListenAndServe(ctx, "localhost:9090")
+package admin
@@ -22,40 +22,69 @@ package config
 
 import (
 	"context"
-	"net/url"
-	"os"
-	"path/filepath"
 	"time"
 
-	"github.com/kelseyhightower/envconfig"
 	"github.com/networkservicemesh/govpp/binapi/acl_types"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v2"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclsession"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/macip"
+	nseconfig "github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ratelimit"
+)
+
+// ACL配置文件缺失或无效时的处理策略（NSM_ACL_ON_ERROR）
+const (
+	// ACLOnErrorFail 拒绝启动
+	ACLOnErrorFail = "fail"
+
+	// ACLOnErrorDenyAll 以拒绝所有流量的规则启动
+	ACLOnErrorDenyAll = "deny-all"
 )
 
 // Config 包含从环境变量加载的配置参数
 type Config struct {
-	Name                   string              `default:"firewall-server" desc:"Name of Firewall Server"`
-	ListenOn               string              `default:"listen.on.sock" desc:"listen on socket" split_words:"true"`
-	ConnectTo              url.URL             `default:"unix:///var/lib/networkservicemesh/nsm.io.sock" desc:"url to connect to" split_words:"true"`
-	MaxTokenLifetime       time.Duration       `default:"10m" desc:"maximum lifetime of tokens" split_words:"true"`
-	RegistryClientPolicies []string            `default:"etc/nsm/opa/common/.*.rego,etc/nsm/opa/registry/.*.rego,etc/nsm/opa/client/.*.rego" desc:"paths to files and directories that contain registry client policies" split_words:"true"`
-	ServiceName            string              `default:"" desc:"Name of providing service" split_words:"true"`
-	Labels                 map[string]string   `default:"" desc:"Endpoint labels"`
-	ACLConfigPath          string              `default:"/etc/firewall/config.yaml" desc:"Path to ACL config file" split_words:"true"`
-	ACLConfig              []acl_types.ACLRule `default:"" desc:"configured acl rules" split_words:"true"`
-	LogLevel               string              `default:"INFO" desc:"Log level" split_words:"true"`
-	OpenTelemetryEndpoint  string              `default:"otel-collector.observability.svc.cluster.local:4317" desc:"OpenTelemetry Collector Endpoint" split_words:"true"`
-	MetricsExportInterval  time.Duration       `default:"10s" desc:"interval between mertics exports" split_words:"true"`
-	PprofEnabled           bool                `default:"false" desc:"is pprof enabled" split_words:"true"`
-	PprofListenOn          string              `default:"localhost:6060" desc:"pprof URL to ListenAndServe" split_words:"true"`
+	// 通用NSE配置（名称、监听地址、NSM连接、日志和可观测性等）
+	nseconfig.Base
+
+	ACLConfigPath     string              `default:"/etc/firewall/config.yaml" desc:"Path to ACL config file" split_words:"true"`
+	ACLOnError        string              `default:"fail" desc:"What to do when the ACL config file is missing or invalid: fail (refuse to start) or deny-all" split_words:"true"`
+	ACLReloadInterval time.Duration       `default:"30s" desc:"Interval between checks of the ACL config file for changes (0 disables reloading)" split_words:"true"`
+	ACLConfig         []acl_types.ACLRule `default:"" desc:"configured acl rules" split_words:"true"`
+	ACLPolicy         *aclrules.Policy    `ignored:"true"`
+	ACLFallback       bool                `ignored:"true"`
+	AdminListenOn     string              `default:"" desc:"Address of the admin HTTP server for troubleshooting queries (empty disables)" split_words:"true"`
+	ACLStatsSocket    string              `default:"" desc:"VPP stats socket used to read ACL hit counters, e.g. /var/run/vpp/stats.sock (empty disables ACL hit counters)" split_words:"true"`
+
+	// MACIP ACL（源IP与源MAC绑定）配置
+	MACIPConfigPath string               `default:"" desc:"Path to the MACIP rule file binding client source IPs to source MACs (empty disables)" split_words:"true"`
+	MACIPPin        bool                 `default:"false" desc:"Pin the source IPs and MAC NSM assigned to each client connection with a MACIP ACL" split_words:"true"`
+	MACIPRules      []aclrules.MACIPRule `ignored:"true"`
+
+	// 有状态ACL（permit-reflect）会话表配置（0表示使用VPP默认值）
+	ACLMaxSessions         uint64        `default:"0" desc:"Maximum number of ACL sessions in VPP (0 keeps the VPP default)" split_words:"true"`
+	ACLSessionUDPIdle      time.Duration `default:"0" desc:"Idle timeout of UDP ACL sessions (0 keeps the VPP default)" envconfig:"ACL_SESSION_UDP_IDLE"`
+	ACLSessionTCPIdle      time.Duration `default:"0" desc:"Idle timeout of established TCP ACL sessions (0 keeps the VPP default)" envconfig:"ACL_SESSION_TCP_IDLE"`
+	ACLSessionTCPTransient time.Duration `default:"0" desc:"Timeout of TCP ACL sessions during handshake and teardown (0 keeps the VPP default)" envconfig:"ACL_SESSION_TCP_TRANSIENT"`
+
+	// 请求限流相关配置（速率单位：请求/秒，0表示不限制）
+	RateLimitPerIP            float64       `default:"0" desc:"Request rate limit per source IP (requests per second, 0 disables)" split_words:"true"`
+	RateLimitPerIPBurst       int           `default:"0" desc:"Burst size of the per source IP rate limit" split_words:"true"`
+	RateLimitPerSpiffeID      float64       `default:"0" desc:"Request rate limit per client SPIFFE ID (requests per second, 0 disables)" split_words:"true"`
+	RateLimitPerSpiffeIDBurst int           `default:"0" desc:"Burst size of the per SPIFFE ID rate limit" split_words:"true"`
+	RateLimitGlobal           float64       `default:"0" desc:"Global request rate limit (requests per second, 0 disables)" split_words:"true"`
+	RateLimitGlobalBurst      int           `default:"0" desc:"Burst size of the global rate limit" split_words:"true"`
+	RateLimitIdleTTL          time.Duration `default:"10m" desc:"Idle time after which per-client rate limit state is dropped" split_words:"true"`
 }
 
-// Load 从环境变量加载配置，返回配置实例
+// Load 从配置文件和环境变量加载配置，返回配置实例
 //
 // 使用envconfig库从环境变量中读取配置，所有配置项使用"NSM_"前缀。
-// 例如：NSM_NAME, NSM_CONNECT_TO, NSM_SERVICE_NAME 等
+// 例如：NSM_NAME, NSM_CONNECT_TO, NSM_SERVICE_NAME 等（通用配置项见nseconfig.Base）。
+// 未设置环境变量的配置项使用NSM_CONFIG_FILE配置文件中的值（见nseconfig.Load）。
+// NSM_NAME未设置时NSE名称为firewall-server。
 //
 // 示例：
 //
@@ -66,15 +95,8 @@ type Config struct {
 //	}
 func Load(ctx context.Context) (*Config, error) {
 	c := new(Config)
-
-	// 打印环境变量使用说明
-	if err := envconfig.Usage("nsm", c); err != nil {
-		return nil, errors.Wrap(err, "cannot show usage of envconfig nsm")
-	}
-
-	// 从环境变量加载配置
-	if err := envconfig.Process("nsm", c); err != nil {
-		return nil, errors.Wrap(err, "cannot process envconfig nsm")
+	if err := nseconfig.Load(ctx, c, "firewall-server"); err != nil {
+		return nil, err
 	}
 
 	return c, nil
@@ -82,40 +104,134 @@ func Load(ctx context.Context) (*Config, error) {
 
 // LoadACLRules 从YAML配置文件加载ACL规则
 //
-// 读取ACLConfigPath指定的YAML文件，解析ACL规则并追加到Config.ACLConfig中。
-// 如果文件不存在或解析失败，会记录错误日志但不中断程序运行。
+// 读取ACLConfigPath指定的YAML文件，生成Config.ACLPolicy：
+// 普通规则文件作为所有连接使用的default配置集，配置集文件（profiles）
+// 按连接标签或客户端SPIFFE ID为每个连接选择配置集，详见aclrules包。
+// NSM_ACL_CONFIG中的规则加在每个配置集的规则之前。
+// 默认配置集的规则追加到Config.ACLConfig中。
+//
+// 文件不存在、解析失败或规则（包括NSM_ACL_CONFIG中的规则）校验失败时按ACLOnError处理：
+// fail返回带行号的错误，deny-all记录错误、设置ACLFallback并只加载拒绝所有流量的规则
+// （NSM_ACL_CONFIG中的permit规则也不生效，以免排在deny-all之前放行流量）。
 //
 // 示例：
 //
 //	cfg, _ := config.Load(ctx)
-//	cfg.LoadACLRules(ctx)
-//	fmt.Printf("Loaded %d ACL rules\n", len(cfg.ACLConfig))
-func (c *Config) LoadACLRules(ctx context.Context) {
+//	if err := cfg.LoadACLRules(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("Loaded %d ACL profiles\n", len(cfg.ACLPolicy.Profiles))
+func (c *Config) LoadACLRules(ctx context.Context) error {
 	logger := log.FromContext(ctx).WithField("acl", "config")
 
-	// 读取ACL配置文件
-	raw, err := os.ReadFile(filepath.Clean(c.ACLConfigPath))
+	policy, err := aclrules.LoadPolicy(c.ACLConfigPath)
+	var base []aclrules.Rule
+	if err == nil {
+		base, err = aclrules.ValidateACLRules("NSM_ACL_CONFIG", c.ACLConfig)
+		err = errors.Wrap(err, "invalid NSM_ACL_CONFIG")
+	}
 	if err != nil {
-		logger.Errorf("Error reading config file: %v", err)
-		return
+		if c.ACLOnError != ACLOnErrorDenyAll {
+			return errors.Wrap(err, "failed to load ACL rules")
+		}
+		logger.Errorf("Error loading ACL rules, starting with deny-all rules: %v", err)
+		policy = aclrules.NewPolicy(aclrules.DenyAll())
+		c.ACLFallback = true
+	} else {
+		logger.Infof("Parsed %d acl profiles successfully", len(policy.Profiles))
+		if rules, _ := policy.Rules(aclrules.DefaultProfile); len(policy.Profiles) == 1 && len(rules) == 0 {
+			logger.Warnf("ACL config file %s contains no rules", c.ACLConfigPath)
+		}
+		policy = policy.WithBase(base)
+	}
+
+	// 记录每个配置集最终的匹配顺序和选择器
+	for _, name := range policy.Names() {
+		rules, _ := policy.Rules(name)
+		for i := range rules {
+			logger.Infof("ACL profile %q rule #%d: %s", name, i, &rules[i])
+		}
+	}
+	for i := range policy.Selectors {
+		sel := &policy.Selectors[i]
+		logger.Infof("ACL selector #%d: profile=%q labels=%v spiffeID=%q", i, sel.Profile, sel.Labels, sel.SpiffeID)
 	}
-	logger.Infof("Read config file successfully")
+	logger.Infof("ACL default profile: %q", policy.Default)
+
+	c.ACLPolicy = policy
+	rules, _ := policy.Rules(policy.Default)
+	c.ACLConfig = aclrules.ACLRules(rules)
+	return nil
+}
 
-	// 解析YAML格式的ACL规则
-	var rv map[string]acl_types.ACLRule
-	err = yaml.Unmarshal(raw, &rv)
+// LoadMACIPRules 从MACIPConfigPath指定的YAML文件加载MACIP规则
+//
+// 路径为空时不加载。文件不存在、解析失败时返回带行号的错误
+// （MACIP规则用于防止地址伪造，不按NSM_ACL_ON_ERROR降级）。
+//
+// 示例：
+//
+//	if err := cfg.LoadMACIPRules(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Config) LoadMACIPRules(ctx context.Context) error {
+	if c.MACIPConfigPath == "" {
+		return nil
+	}
+	rules, err := aclrules.LoadMACIP(c.MACIPConfigPath)
 	if err != nil {
-		logger.Errorf("Error parsing config file: %v", err)
-		return
+		return errors.Wrap(err, "failed to load MACIP rules")
 	}
-	logger.Infof("Parsed acl rules successfully")
 
-	// 追加规则到配置
-	for _, v := range rv {
-		c.ACLConfig = append(c.ACLConfig, v)
+	logger := log.FromContext(ctx).WithField("macip", "config")
+	for i := range rules {
+		logger.Infof("MACIP rule #%d: %s", i, &rules[i])
 	}
+	c.MACIPRules = rules
+	return nil
+}
+
+// MACIPConfig 返回MACIP ACL配置
+//
+// 示例：
+//
+//	if mc := cfg.MACIPConfig(); mc.Enabled() {
+//	    server := macip.NewServer(vppConn, mc)
+//	}
+func (c *Config) MACIPConfig() macip.Config {
+	return macip.Config{Rules: c.MACIPRules, Pin: c.MACIPPin}
+}
 
-	logger.Infof("Result rules:%v", c.ACLConfig)
+// RateLimitConfig 返回请求限流配置
+//
+// 示例：
+//
+//	if rl := cfg.RateLimitConfig(); rl.Enabled() {
+//	    server := ratelimit.NewServer(rl)
+//	}
+func (c *Config) RateLimitConfig() ratelimit.Config {
+	return ratelimit.Config{
+		PerIP:       ratelimit.Limit{Rate: c.RateLimitPerIP, Burst: c.RateLimitPerIPBurst},
+		PerSpiffeID: ratelimit.Limit{Rate: c.RateLimitPerSpiffeID, Burst: c.RateLimitPerSpiffeIDBurst},
+		Global:      ratelimit.Limit{Rate: c.RateLimitGlobal, Burst: c.RateLimitGlobalBurst},
+		IdleTTL:     c.RateLimitIdleTTL,
+	}
+}
+
+// ACLSessionConfig 返回有状态ACL会话表配置
+//
+// 示例：
+//
+//	if err := aclsession.Apply(ctx, aclsession.NewCLI(vppConn), cfg.ACLSessionConfig()); err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Config) ACLSessionConfig() aclsession.Config {
+	return aclsession.Config{
+		MaxSessions:  c.ACLMaxSessions,
+		UDPIdle:      c.ACLSessionUDPIdle,
+		TCPIdle:      c.ACLSessionTCPIdle,
+		TCPTransient: c.ACLSessionTCPTransient,
+	}
 }
 
 // Validate 验证配置的完整性和有效性
@@ -130,17 +246,30 @@ func (c *Config) LoadACLRules(ctx context.Context) {
 //	    log.Fatalf("Invalid config: %v", err)
 //	}
 func (c *Config) Validate() error {
-	// 检查必填字段
-	if c.Name == "" {
-		return errors.New("Name is required")
+	// 验证通用配置
+	if err := c.Base.Validate(); err != nil {
+		return err
+	}
+
+	// 验证ACL错误处理策略（未设置时按fail处理）
+	switch c.ACLOnError {
+	case "", ACLOnErrorFail, ACLOnErrorDenyAll:
+	default:
+		return errors.Errorf("invalid ACLOnError %q (expected %s or %s)", c.ACLOnError, ACLOnErrorFail, ACLOnErrorDenyAll)
 	}
-	if c.ServiceName == "" {
-		return errors.New("ServiceName is required")
+
+	if c.ACLReloadInterval < 0 {
+		return errors.Errorf("invalid ACLReloadInterval %v (must not be negative)", c.ACLReloadInterval)
+	}
+
+	// 验证ACL会话表配置
+	if err := c.ACLSessionConfig().Validate(); err != nil {
+		return err
 	}
 
-	// 验证ConnectTo URL格式
-	if c.ConnectTo.String() == "" {
-		return errors.New("ConnectTo URL is required")
+	// 验证限流配置
+	if err := c.RateLimitConfig().Validate(); err != nil {
+		return err
 	}
 
 	return nil
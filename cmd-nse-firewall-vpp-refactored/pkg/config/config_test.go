@@ -28,7 +28,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
 	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/config"
+	nseconfig "github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -49,6 +51,12 @@ func TestLoad_DefaultValues(t *testing.T) {
 	require.Equal(t, 10*time.Minute, cfg.MaxTokenLifetime)
 	require.Equal(t, "INFO", cfg.LogLevel)
 	require.Equal(t, "/etc/firewall/config.yaml", cfg.ACLConfigPath)
+	require.Equal(t, config.ACLOnErrorFail, cfg.ACLOnError)
+	require.Equal(t, 30*time.Second, cfg.ACLReloadInterval)
+	require.Empty(t, cfg.ACLStatsSocket, "命中计数默认不启用")
+	require.Empty(t, cfg.MACIPConfigPath)
+	require.False(t, cfg.MACIPPin)
+	require.False(t, cfg.MACIPConfig().Enabled())
 	require.Equal(t, 10*time.Second, cfg.MetricsExportInterval)
 	require.False(t, cfg.PprofEnabled)
 	require.Equal(t, "localhost:6060", cfg.PprofListenOn)
@@ -72,11 +80,44 @@ func TestLoad_CustomValues(t *testing.T) {
 	require.Equal(t, 5*time.Minute, cfg.MaxTokenLifetime)
 }
 
+func TestLoad_ConfigFile(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "firewall.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+serviceNames: [firewall, audit]
+labels: {app: firewall}
+aclConfigPath: /etc/firewall/acl.yaml
+aclOnError: deny-all
+aclSessionUdpIdle: 2m
+rateLimitPerIp: 10
+logLevel: DEBUG
+`), 0o600))
+	t.Setenv(nseconfig.ConfigFileEnv, path)
+	t.Setenv("NSM_LOG_LEVEL", "WARN")
+
+	cfg, err := config.Load(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, "firewall-server", cfg.Name)
+	require.Equal(t, []string{"firewall", "audit"}, cfg.ServiceNames)
+	require.Equal(t, map[string]string{"app": "firewall"}, cfg.Labels)
+	require.Equal(t, "/etc/firewall/acl.yaml", cfg.ACLConfigPath)
+	require.Equal(t, config.ACLOnErrorDenyAll, cfg.ACLOnError)
+	require.Equal(t, 2*time.Minute, cfg.ACLSessionUDPIdle)
+	require.InDelta(t, 10.0, cfg.RateLimitPerIP, 0)
+	require.Equal(t, "WARN", cfg.LogLevel, "环境变量优先于配置文件")
+	require.Equal(t, nseconfig.SourceFile, cfg.Origin("NSM_ACL_SESSION_UDP_IDLE").Source)
+	require.Equal(t, nseconfig.SourceEnv, cfg.Origin("NSM_LOG_LEVEL").Source)
+	require.Equal(t, nseconfig.SourceDefault, cfg.Origin("NSM_ACL_RELOAD_INTERVAL").Source)
+}
+
 func TestValidate_Success(t *testing.T) {
 	cfg := &config.Config{
-		Name:        "test-server",
-		ServiceName: "test-service",
-		ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		Base: nseconfig.Base{
+			Name:        "test-server",
+			ServiceName: "test-service",
+			ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		},
 	}
 
 	err := cfg.Validate()
@@ -85,9 +126,11 @@ func TestValidate_Success(t *testing.T) {
 
 func TestValidate_MissingName(t *testing.T) {
 	cfg := &config.Config{
-		Name:        "", // 缺失
-		ServiceName: "test-service",
-		ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		Base: nseconfig.Base{
+			Name:        "", // 缺失
+			ServiceName: "test-service",
+			ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		},
 	}
 
 	err := cfg.Validate()
@@ -97,9 +140,11 @@ func TestValidate_MissingName(t *testing.T) {
 
 func TestValidate_MissingServiceName(t *testing.T) {
 	cfg := &config.Config{
-		Name:        "test-server",
-		ServiceName: "", // 缺失
-		ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		Base: nseconfig.Base{
+			Name:        "test-server",
+			ServiceName: "", // 缺失
+			ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		},
 	}
 
 	err := cfg.Validate()
@@ -109,9 +154,11 @@ func TestValidate_MissingServiceName(t *testing.T) {
 
 func TestValidate_MissingConnectTo(t *testing.T) {
 	cfg := &config.Config{
-		Name:        "test-server",
-		ServiceName: "test-service",
-		ConnectTo:   url.URL{}, // 空URL
+		Base: nseconfig.Base{
+			Name:        "test-server",
+			ServiceName: "test-service",
+			ConnectTo:   url.URL{}, // 空URL
+		},
 	}
 
 	err := cfg.Validate()
@@ -119,6 +166,39 @@ func TestValidate_MissingConnectTo(t *testing.T) {
 	require.Contains(t, err.Error(), "ConnectTo URL is required")
 }
 
+func TestLoad_RateLimitValues(t *testing.T) {
+	clearEnv(t)
+	os.Setenv("NSM_RATE_LIMIT_PER_IP", "2.5")
+	os.Setenv("NSM_RATE_LIMIT_PER_IP_BURST", "5")
+	os.Setenv("NSM_RATE_LIMIT_GLOBAL", "100")
+
+	cfg, err := config.Load(context.Background())
+	require.NoError(t, err)
+
+	rl := cfg.RateLimitConfig()
+	require.True(t, rl.Enabled())
+	require.Equal(t, 2.5, rl.PerIP.Rate)
+	require.Equal(t, 5, rl.PerIP.Burst)
+	require.False(t, rl.PerSpiffeID.Enabled())
+	require.Equal(t, 100.0, rl.Global.Rate)
+	require.Equal(t, 10*time.Minute, rl.IdleTTL)
+}
+
+func TestValidate_InvalidRateLimit(t *testing.T) {
+	cfg := &config.Config{
+		Base: nseconfig.Base{
+			Name:        "test-server",
+			ServiceName: "test-service",
+			ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		},
+		RateLimitPerIP: -1,
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err, "负的限流速率应该返回错误")
+	require.Contains(t, err.Error(), "rate limit")
+}
+
 func TestLoadACLRules_ValidFile(t *testing.T) {
 	// 创建临时YAML文件
 	tmpDir := t.TempDir()
@@ -148,7 +228,7 @@ rule2:
 	cfg := &config.Config{
 		ACLConfigPath: aclFile,
 	}
-	cfg.LoadACLRules(ctx)
+	require.NoError(t, cfg.LoadACLRules(ctx))
 
 	// 验证加载了规则（ACLRule的具体结构由外部库定义，这里只验证数量）
 	require.Len(t, cfg.ACLConfig, 2, "应该加载2条ACL规则")
@@ -161,10 +241,10 @@ func TestLoadACLRules_FileNotFound(t *testing.T) {
 		ACLConfigPath: "/nonexistent/path/acl.yaml",
 	}
 
-	// 调用LoadACLRules不应该panic，只是记录错误
-	require.NotPanics(t, func() {
-		cfg.LoadACLRules(ctx)
-	})
+	// 默认策略（fail）返回错误
+	err := cfg.LoadACLRules(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read ACL config file")
 
 	// 没有加载任何规则
 	require.Empty(t, cfg.ACLConfig)
@@ -188,10 +268,10 @@ this is not
 		ACLConfigPath: aclFile,
 	}
 
-	// 调用LoadACLRules不应该panic
-	require.NotPanics(t, func() {
-		cfg.LoadACLRules(ctx)
-	})
+	// 错误信息包含行号
+	err = cfg.LoadACLRules(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 3")
 
 	// 没有成功加载规则
 	require.Empty(t, cfg.ACLConfig)
@@ -210,15 +290,223 @@ func TestLoadACLRules_EmptyFile(t *testing.T) {
 		ACLConfigPath: aclFile,
 	}
 
-	cfg.LoadACLRules(ctx)
+	require.NoError(t, cfg.LoadACLRules(ctx))
 
 	// 空文件不应该加载任何规则
 	require.Empty(t, cfg.ACLConfig)
 }
 
+func TestLoadACLRules_Ordered(t *testing.T) {
+	tmpDir := t.TempDir()
+	aclFile := filepath.Join(tmpDir, "acl.yaml")
+
+	// 映射形式通过priority显式指定顺序
+	yamlContent := `
+deny-all:
+  priority: 100
+  proto: 0
+allow-http:
+  priority: 10
+  ispermit: 1
+  proto: 6
+  dstportoricmpcodefirst: 80
+  dstportoricmpcodelast: 80
+`
+	require.NoError(t, os.WriteFile(aclFile, []byte(yamlContent), 0600))
+
+	cfg := &config.Config{ACLConfigPath: aclFile}
+	require.NoError(t, cfg.LoadACLRules(context.Background()))
+
+	require.Len(t, cfg.ACLConfig, 2)
+	require.EqualValues(t, 1, cfg.ACLConfig[0].IsPermit, "priority较小的规则应该排在前面")
+	require.EqualValues(t, 80, cfg.ACLConfig[0].DstportOrIcmpcodeFirst)
+	require.EqualValues(t, 0, cfg.ACLConfig[1].IsPermit)
+}
+
+func TestLoadACLRules_Profiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	aclFile := filepath.Join(tmpDir, "acl.yaml")
+
+	yamlContent := `
+profiles:
+  tenant-a:
+    - {name: allow https, action: permit, proto: tcp, dport: 443}
+  tenant-b:
+    - {name: allow dns, action: permit, proto: udp, dport: 53}
+selectors:
+  - profile: tenant-a
+    labels: {tenant: a}
+default: tenant-b
+`
+	require.NoError(t, os.WriteFile(aclFile, []byte(yamlContent), 0600))
+
+	// NSM_ACL_CONFIG中的规则加在每个配置集之前
+	cfg := &config.Config{ACLConfigPath: aclFile, ACLConfig: []acl_types.ACLRule{{Proto: 1}}}
+	require.NoError(t, cfg.LoadACLRules(context.Background()))
+
+	require.Equal(t, []string{"tenant-a", "tenant-b"}, cfg.ACLPolicy.Names())
+	require.Equal(t, "tenant-a", cfg.ACLPolicy.Select(map[string]string{"tenant": "a"}, ""))
+	rules, ok := cfg.ACLPolicy.Rules("tenant-a")
+	require.True(t, ok)
+	require.Len(t, rules, 3)
+	require.EqualValues(t, 1, rules[0].Proto)
+	require.EqualValues(t, 443, rules[1].DstportOrIcmpcodeFirst)
+	require.Equal(t, "allow https#ipv6", rules[2].Name)
+
+	require.Len(t, cfg.ACLConfig, 3, "ACLConfig是默认配置集的规则")
+	require.EqualValues(t, 53, cfg.ACLConfig[1].DstportOrIcmpcodeFirst)
+}
+
+func TestLoadACLRules_DuplicateName(t *testing.T) {
+	tmpDir := t.TempDir()
+	aclFile := filepath.Join(tmpDir, "acl.yaml")
+
+	yamlContent := `
+- name: allow-http
+  ispermit: 1
+- name: allow-http
+  ispermit: 0
+`
+	require.NoError(t, os.WriteFile(aclFile, []byte(yamlContent), 0600))
+
+	cfg := &config.Config{ACLConfigPath: aclFile}
+	err := cfg.LoadACLRules(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `line 4: duplicate rule name "allow-http"`)
+
+	// 名称重复时整个文件被拒绝
+	require.Empty(t, cfg.ACLConfig)
+}
+
+func TestLoadACLRules_DenyAllOnError(t *testing.T) {
+	tmpDir := t.TempDir()
+	aclFile := filepath.Join(tmpDir, "acl.yaml")
+
+	// 端口范围颠倒
+	yamlContent := `
+- name: allow-web
+  action: permit
+  proto: tcp
+  dport: 8080-8000
+`
+	require.NoError(t, os.WriteFile(aclFile, []byte(yamlContent), 0600))
+
+	cfg := &config.Config{ACLConfigPath: aclFile, ACLOnError: config.ACLOnErrorDenyAll}
+	require.NoError(t, cfg.LoadACLRules(context.Background()))
+	require.True(t, cfg.ACLFallback)
+
+	// 以拒绝所有IPv4和IPv6流量的规则启动
+	require.Len(t, cfg.ACLConfig, 2)
+	for _, rule := range cfg.ACLConfig {
+		require.Equal(t, acl_types.ACL_ACTION_API_DENY, rule.IsPermit)
+		require.Zero(t, rule.SrcPrefix.Len)
+		require.Zero(t, rule.DstPrefix.Len)
+	}
+	require.NotEqual(t, cfg.ACLConfig[0].SrcPrefix.Address.Af, cfg.ACLConfig[1].SrcPrefix.Address.Af)
+}
+
+func TestLoadACLRules_DenyAllIgnoresBaseRules(t *testing.T) {
+	// NSM_ACL_CONFIG中的permit规则不能排在兜底的deny-all规则之前
+	cfg := &config.Config{
+		ACLConfigPath: filepath.Join(t.TempDir(), "missing.yaml"),
+		ACLOnError:    config.ACLOnErrorDenyAll,
+		ACLConfig:     []acl_types.ACLRule{{IsPermit: acl_types.ACL_ACTION_API_PERMIT, Proto: 6}},
+	}
+	require.NoError(t, cfg.LoadACLRules(context.Background()))
+
+	require.Len(t, cfg.ACLConfig, 2)
+	rules, ok := cfg.ACLPolicy.Rules(cfg.ACLPolicy.Default)
+	require.True(t, ok)
+	require.Len(t, rules, 2)
+	for i := range rules {
+		require.Equal(t, acl_types.ACL_ACTION_API_DENY, rules[i].IsPermit)
+	}
+}
+
+func TestLoadACLRules_InvalidBaseRule(t *testing.T) {
+	aclFile := filepath.Join(t.TempDir(), "acl.yaml")
+	require.NoError(t, os.WriteFile(aclFile, []byte("- {name: allow https, action: permit, proto: tcp, dport: 443}\n"), 0600))
+
+	// NSM_ACL_CONFIG中的第二条规则ICMP type超出范围
+	base := []acl_types.ACLRule{
+		{IsPermit: acl_types.ACL_ACTION_API_PERMIT, Proto: 6},
+		{IsPermit: acl_types.ACL_ACTION_API_PERMIT, Proto: 1, SrcportOrIcmptypeFirst: 300, SrcportOrIcmptypeLast: 300},
+	}
+
+	cfg := &config.Config{ACLConfigPath: aclFile, ACLConfig: base}
+	err := cfg.LoadACLRules(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `invalid NSM_ACL_CONFIG: rule "NSM_ACL_CONFIG#1": ICMP type 300 is out of range`)
+	require.Nil(t, cfg.ACLPolicy)
+
+	// deny-all：与配置文件无效时相同，只加载拒绝所有流量的规则
+	cfg = &config.Config{ACLConfigPath: aclFile, ACLConfig: base, ACLOnError: config.ACLOnErrorDenyAll}
+	require.NoError(t, cfg.LoadACLRules(context.Background()))
+	require.True(t, cfg.ACLFallback)
+	require.Len(t, cfg.ACLConfig, 2)
+	for _, rule := range cfg.ACLConfig {
+		require.Equal(t, acl_types.ACL_ACTION_API_DENY, rule.IsPermit)
+	}
+}
+
+func TestLoadMACIPRules(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "macip.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- name: client\n  action: permit\n  ip: 172.16.1.100\n  mac: 02:fe:aa:bb:cc:dd\n"), 0o600))
+	t.Setenv("NSM_MACIP_CONFIG_PATH", path)
+	t.Setenv("NSM_MACIP_PIN", "true")
+
+	ctx := context.Background()
+	cfg, err := config.Load(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cfg.LoadMACIPRules(ctx))
+	mc := cfg.MACIPConfig()
+	require.True(t, mc.Pin)
+	require.Len(t, mc.Rules, 1)
+	require.Equal(t, "client", mc.Rules[0].Name)
+
+	cfg.MACIPConfigPath = filepath.Join(t.TempDir(), "missing.yaml")
+	err = cfg.LoadMACIPRules(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to load MACIP rules")
+}
+
+func TestLoad_ACLSessionValues(t *testing.T) {
+	clearEnv(t)
+	os.Setenv("NSM_ACL_MAX_SESSIONS", "100000")
+	os.Setenv("NSM_ACL_SESSION_UDP_IDLE", "1m")
+	os.Setenv("NSM_ACL_SESSION_TCP_IDLE", "1h")
+	os.Setenv("NSM_ACL_SESSION_TCP_TRANSIENT", "30s")
+
+	cfg, err := config.Load(context.Background())
+	require.NoError(t, err)
+
+	sc := cfg.ACLSessionConfig()
+	require.Equal(t, uint64(100000), sc.MaxSessions)
+	require.Equal(t, time.Minute, sc.UDPIdle)
+	require.Equal(t, time.Hour, sc.TCPIdle)
+	require.Equal(t, 30*time.Second, sc.TCPTransient)
+}
+
+func TestValidate_InvalidACLOnError(t *testing.T) {
+	cfg := &config.Config{
+		Base: nseconfig.Base{
+			Name:        "test-server",
+			ServiceName: "test-service",
+			ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		},
+		ACLOnError: "ignore",
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid ACLOnError")
+}
+
 // 辅助函数：清理环境变量
 func clearEnv(t *testing.T) {
 	envVars := []string{
+		"NSM_CONFIG_FILE",
 		"NSM_NAME",
 		"NSM_LISTEN_ON",
 		"NSM_CONNECT_TO",
@@ -227,12 +515,29 @@ func clearEnv(t *testing.T) {
 		"NSM_SERVICE_NAME",
 		"NSM_LABELS",
 		"NSM_ACL_CONFIG_PATH",
+		"NSM_ACL_ON_ERROR",
+		"NSM_ACL_RELOAD_INTERVAL",
+		"NSM_ACL_MAX_SESSIONS",
+		"NSM_ACL_SESSION_UDP_IDLE",
+		"NSM_ACL_SESSION_TCP_IDLE",
+		"NSM_ACL_SESSION_TCP_TRANSIENT",
+		"NSM_ADMIN_LISTEN_ON",
+		"NSM_ACL_STATS_SOCKET",
+		"NSM_MACIP_CONFIG_PATH",
+		"NSM_MACIP_PIN",
 		"NSM_ACL_CONFIG",
 		"NSM_LOG_LEVEL",
 		"NSM_OPEN_TELEMETRY_ENDPOINT",
 		"NSM_METRICS_EXPORT_INTERVAL",
 		"NSM_PPROF_ENABLED",
 		"NSM_PPROF_LISTEN_ON",
+		"NSM_RATE_LIMIT_PER_IP",
+		"NSM_RATE_LIMIT_PER_IP_BURST",
+		"NSM_RATE_LIMIT_PER_SPIFFE_ID",
+		"NSM_RATE_LIMIT_PER_SPIFFE_ID_BURST",
+		"NSM_RATE_LIMIT_GLOBAL",
+		"NSM_RATE_LIMIT_GLOBAL_BURST",
+		"NSM_RATE_LIMIT_IDLE_TTL",
 	}
 
 	for _, v := range envVars {
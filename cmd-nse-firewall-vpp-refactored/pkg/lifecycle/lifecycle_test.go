@@ -1,162 +0,0 @@
-// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
-//
-// Copyright (c) 2023-2024 Cisco and/or its affiliates.
-//
-// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
-//
-// SPDX-License-Identifier: Apache-2.0
-//
-// Licensed under the Apache License, Version 2.0 (the "License");
-// you may not use this file except in compliance with the License.
-// You may obtain a copy of the License at:
-//
-//     http://www.apache.org/licenses/LICENSE-2.0
-//
-// Unless required by applicable law or agreed to in writing, software
-// distributed under the License is distributed on an "AS IS" BASIS,
-// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-// See the License for the specific language governing permissions and
-// limitations under the License.
-
-package lifecycle_test
-
-import (
-	"context"
-	"errors"
-	"testing"
-	"time"
-
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/lifecycle"
-	"github.com/stretchr/testify/require"
-)
-
-func TestNotifyContext(t *testing.T) {
-	ctx, cancel := lifecycle.NotifyContext()
-	defer cancel()
-
-	require.NotNil(t, ctx, "上下文不应该为nil")
-	require.NotNil(t, cancel, "cancel函数不应该为nil")
-
-	// 验证上下文在创建时未被取消
-	select {
-	case <-ctx.Done():
-		t.Fatal("新创建的上下文不应该立即被取消")
-	default:
-		// 正确：上下文未被取消
-	}
-
-	// 调用cancel应该取消上下文
-	cancel()
-
-	// 等待上下文被取消
-	select {
-	case <-ctx.Done():
-		// 正确：上下文已被取消
-	case <-time.After(100 * time.Millisecond):
-		t.Fatal("调用cancel后上下文应该被取消")
-	}
-}
-
-func TestInitializeLogging_ValidLevel(t *testing.T) {
-	testCases := []struct {
-		name     string
-		logLevel string
-	}{
-		{"INFO级别", "INFO"},
-		{"DEBUG级别", "DEBUG"},
-		{"WARN级别", "WARN"},
-		{"ERROR级别", "ERROR"},
-		{"TRACE级别", "TRACE"},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			ctx := context.Background()
-
-			// 初始化日志不应该panic
-			require.NotPanics(t, func() {
-				resultCtx := lifecycle.InitializeLogging(ctx, tc.logLevel)
-				require.NotNil(t, resultCtx, "返回的上下文不应该为nil")
-			})
-		})
-	}
-}
-
-func TestInitializeLogging_InvalidLevel(t *testing.T) {
-	// 无效的日志级别应该导致Fatal（在测试中会panic）
-	// 由于我们不能捕获Fatal，这里只是记录行为
-	// 实际使用中应该提供有效的日志级别
-	t.Skip("跳过：无效日志级别会导致Fatal退出")}
-
-
-func TestMonitorErrorChannel_ImmediateError(t *testing.T) {
-	errCh := make(chan error, 1)
-	testErr := errors.New("测试错误")
-	errCh <- testErr
-
-	// MonitorErrorChannel检测到立即错误应该调用Fatal
-	// 由于Fatal会退出程序，我们无法在测试中完全验证
-	// 这里只是确保函数不会panic
-	t.Skip("跳过：立即错误会导致Fatal退出")
-}
-
-func TestMonitorErrorChannel_DelayedError(t *testing.T) {
-	ctx := context.Background()
-	cancelCalled := false
-	cancel := func() {
-		cancelCalled = true
-	}
-
-	errCh := make(chan error, 1)
-
-	// 启动监控
-	lifecycle.MonitorErrorChannel(ctx, cancel, errCh)
-
-	// 验证初始状态下cancel未被调用
-	require.False(t, cancelCalled, "初始状态下cancel不应该被调用")
-
-	// 发送延迟错误
-	testErr := errors.New("延迟测试错误")
-	go func() {
-		time.Sleep(10 * time.Millisecond)
-		errCh <- testErr
-	}()
-
-	// 等待cancel被调用
-	timeout := time.After(200 * time.Millisecond)
-	for {
-		select {
-		case <-timeout:
-			t.Fatal("超时：cancel应该在接收到错误后被调用")
-		default:
-			if cancelCalled {
-				return // 测试通过
-			}
-			time.Sleep(10 * time.Millisecond)
-		}
-	}
-}
-
-func TestMonitorErrorChannel_NoError(t *testing.T) {
-	ctx := context.Background()
-	cancelCalled := false
-	cancel := func() {
-		cancelCalled = true
-	}
-
-	errCh := make(chan error, 1)
-
-	// 启动监控
-	lifecycle.MonitorErrorChannel(ctx, cancel, errCh)
-
-	// 等待一段时间，确保没有错误时cancel不会被调用
-	time.Sleep(50 * time.Millisecond)
-
-	require.False(t, cancelCalled, "没有错误时cancel不应该被调用")
-}
-
-func TestMonitorErrorChannel_ClosedChannel(t *testing.T) {
-	// 关闭的通道会返回nil错误，导致Fatal退出
-	// 跳过此测试
-	t.Skip("跳过：关闭的通道返回nil错误会导致Fatal退出")
-}
@@ -0,0 +1,20 @@
+// Package macip 提供在客户端接口上安装VPP MACIP ACL的链元素
+//
+// MACIP ACL检查从接口进入的数据包的源MAC和源IP（包括ARP），只放行与规则绑定的地址，
+// 防止客户端通过memif xconnect伪造地址。每个接口只能绑定一个MACIP ACL。
+//
+// 规则来源：
+//   - 固定模式（Config.Pin）：为每个连接生成permit规则，绑定NSM分配给客户端的
+//     源IP（IPContext.SrcIpAddrs）和源MAC（EthernetContext.SrcMac，未分配时不限制MAC）；
+//     固定了IPv6地址且MAC已知时，同时放行该MAC的链路本地地址（fe80::/10，邻居发现需要）
+//   - 配置文件中的规则（aclrules.LoadMACIP），对所有连接生效，追加在固定规则之后
+//
+// 规则在Request时（下游链元素创建接口之后）安装，刷新时地址变化会原地替换，Close时移除。
+// MACIP ACL末尾隐式拒绝：没有任何规则的地址族的流量会被丢弃。
+//
+// 使用示例：
+//
+//	rules, _ := aclrules.LoadMACIP("/etc/firewall/macip.yaml")
+//	macipServer := macip.NewServer(vppConn, macip.Config{Rules: rules, Pin: true})
+//	endpoint.WithAdditionalFunctionality(macipServer, ...)
+package macip
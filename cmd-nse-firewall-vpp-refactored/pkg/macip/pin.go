@@ -0,0 +1,83 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package macip
+
+import (
+	"net"
+	"net/netip"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ethernet_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+// linkLocal IPv6链路本地前缀（邻居发现使用）
+var linkLocal = netip.MustParsePrefix("fe80::/10")
+
+// PinRules 返回固定连接中NSM分配给客户端的源IP和源MAC的permit规则
+//
+// 每个源IP一条规则（主机前缀）；源MAC未分配时规则不限制MAC。
+// 地址无效时返回错误。
+func PinRules(conn *networkservice.Connection) ([]aclrules.MACIPRule, error) {
+	var mac, mask ethernet_types.MacAddress
+	if s := conn.GetContext().GetEthernetContext().GetSrcMac(); s != "" {
+		var err error
+		if mac, err = ethernet_types.ParseMacAddress(s); err != nil {
+			return nil, errors.Errorf("invalid source MAC %q of connection %s", s, conn.GetId())
+		}
+		mask = ethernet_types.MacAddress{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	}
+
+	var rules []aclrules.MACIPRule
+	hasIPv6 := false
+	for _, s := range conn.GetContext().GetIpContext().GetSrcIpAddrs() {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, errors.Errorf("invalid source IP %q of connection %s", s, conn.GetId())
+		}
+		addr := p.Addr().Unmap()
+		hasIPv6 = hasIPv6 || addr.Is6()
+		rules = append(rules, pinRule("pinned "+addr.String(), netip.PrefixFrom(addr, addr.BitLen()), mac, mask))
+	}
+	if hasIPv6 && mask != (ethernet_types.MacAddress{}) {
+		rules = append(rules, pinRule("pinned "+linkLocal.String(), linkLocal, mac, mask))
+	}
+	return rules, nil
+}
+
+func pinRule(name string, prefix netip.Prefix, mac, mask ethernet_types.MacAddress) aclrules.MACIPRule {
+	return aclrules.MACIPRule{
+		Name: name,
+		MacipACLRule: acl_types.MacipACLRule{
+			IsPermit:   acl_types.ACL_ACTION_API_PERMIT,
+			SrcMac:     mac,
+			SrcMacMask: mask,
+			SrcPrefix: ip_types.NewPrefix(net.IPNet{
+				IP:   net.IP(prefix.Addr().AsSlice()),
+				Mask: net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen()),
+			}),
+		},
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package macip
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/govpp/binapi/acl"
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/postpone"
+	"github.com/pkg/errors"
+	"go.fd.io/govpp/api"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+)
+
+// macipTag MACIP ACL标签前缀
+const macipTag = "nsm-macip"
+
+// Config MACIP ACL配置
+type Config struct {
+	// Rules 配置文件中的MACIP规则，对所有连接生效（在固定规则之后匹配）
+	Rules []aclrules.MACIPRule
+
+	// Pin 为每个连接固定NSM分配给客户端的源IP和源MAC
+	Pin bool
+}
+
+// Enabled 判断是否需要安装MACIP ACL
+func (c Config) Enabled() bool {
+	return c.Pin || len(c.Rules) > 0
+}
+
+// binding 一个连接在VPP中安装的MACIP ACL
+type binding struct {
+	swIfIndex interface_types.InterfaceIndex
+	acl       uint32
+	rules     []acl_types.MacipACLRule
+}
+
+// Server 在客户端接口上安装MACIP ACL的链元素
+type Server struct {
+	vppConn api.Connection
+	config  Config
+
+	mu       sync.Mutex
+	bindings map[string]*binding
+}
+
+// NewServer 创建MACIP ACL链元素
+//
+// 示例：
+//
+//	macipServer := macip.NewServer(vppConn, cfg.MACIPConfig())
+func NewServer(vppConn api.Connection, config Config) *Server {
+	return &Server{
+		vppConn:  vppConn,
+		config:   config,
+		bindings: make(map[string]*binding),
+	}
+}
+
+// Request 在下游链元素创建接口后安装或更新连接的MACIP ACL
+func (s *Server) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	postponeCtxFunc := postpone.ContextWithValues(ctx)
+
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, conn); err != nil {
+		closeCtx, cancelClose := postponeCtxFunc()
+		defer cancelClose()
+
+		if _, closeErr := s.Close(closeCtx, conn); closeErr != nil {
+			err = errors.Wrapf(err, "connection closed with error: %s", closeErr.Error())
+		}
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Close 从接口上移除并删除连接的MACIP ACL
+func (s *Server) Close(ctx context.Context, conn *networkservice.Connection) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	b, ok := s.bindings[conn.GetId()]
+	delete(s.bindings, conn.GetId())
+	s.mu.Unlock()
+
+	if ok {
+		if err := s.uninstall(ctx, b); err != nil {
+			log.FromContext(ctx).WithField("macip", "server").Debugf("error deleting MACIP ACL: %v", err)
+		}
+	}
+
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+// ACLs 返回活动连接ID到MACIP ACL索引的映射
+func (s *Server) ACLs() map[string]uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]uint32, len(s.bindings))
+	for id, b := range s.bindings {
+		result[id] = b.acl
+	}
+	return result
+}
+
+// rules 返回连接的MACIP规则：固定规则在前，配置文件中的规则在后
+func (s *Server) rules(conn *networkservice.Connection) ([]aclrules.MACIPRule, error) {
+	var rules []aclrules.MACIPRule
+	if s.config.Pin {
+		pinned, err := PinRules(conn)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, pinned...)
+	}
+	return append(rules, s.config.Rules...), nil
+}
+
+// apply 安装连接的MACIP ACL；刷新时规则变化则原地替换
+func (s *Server) apply(ctx context.Context, conn *networkservice.Connection) error {
+	logger := log.FromContext(ctx).WithField("macip", "server")
+
+	named, err := s.rules(conn)
+	if err != nil {
+		return err
+	}
+	rules := aclrules.MACIPRules(named)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := conn.GetId()
+	if b, ok := s.bindings[id]; ok {
+		switch {
+		case slices.Equal(b.rules, rules):
+			return nil
+		case len(rules) == 0:
+			delete(s.bindings, id)
+			return s.uninstall(ctx, b)
+		}
+		if _, err := acl.NewServiceClient(s.vppConn).MacipACLAddReplace(ctx, macipAddReplace(b.acl, id, rules)); err != nil {
+			return errors.Wrapf(err, "vppapi MacipACLAddReplace of MACIP ACL %d returned error", b.acl)
+		}
+		b.rules = rules
+		logger.Infof("connection %s: MACIP ACL %d replaced with %d rules", id, b.acl, len(rules))
+		return nil
+	}
+
+	if len(rules) == 0 {
+		logger.Warnf("connection %s has no addresses to pin, no MACIP ACL installed", id)
+		return nil
+	}
+	swIfIndex, ok := ifindex.Load(ctx, metadata.IsClient(s))
+	if !ok {
+		return errors.New("swIfIndex not found")
+	}
+	b, err := s.install(ctx, swIfIndex, id, rules)
+	if err != nil {
+		return err
+	}
+	s.bindings[id] = b
+	for i := range named {
+		logger.Infof("connection %s: MACIP ACL %d rule #%d: %s", id, b.acl, i, &named[i])
+	}
+	return nil
+}
+
+// install 创建MACIP ACL并绑定到接口
+func (s *Server) install(ctx context.Context, swIfIndex interface_types.InterfaceIndex, id string, rules []acl_types.MacipACLRule) (*binding, error) {
+	client := acl.NewServiceClient(s.vppConn)
+	rsp, err := client.MacipACLAddReplace(ctx, macipAddReplace(^uint32(0), id, rules))
+	if err != nil {
+		return nil, errors.Wrap(err, "vppapi MacipACLAddReplace returned error")
+	}
+	b := &binding{swIfIndex: swIfIndex, acl: rsp.ACLIndex, rules: rules}
+
+	if _, err := client.MacipACLInterfaceAddDel(ctx, &acl.MacipACLInterfaceAddDel{
+		IsAdd:     true,
+		SwIfIndex: swIfIndex,
+		ACLIndex:  b.acl,
+	}); err != nil {
+		s.deleteACL(ctx, b.acl)
+		return nil, errors.Wrap(err, "vppapi MacipACLInterfaceAddDel returned error")
+	}
+	return b, nil
+}
+
+// uninstall 从接口上解绑并删除MACIP ACL
+func (s *Server) uninstall(ctx context.Context, b *binding) error {
+	_, err := acl.NewServiceClient(s.vppConn).MacipACLInterfaceAddDel(ctx, &acl.MacipACLInterfaceAddDel{
+		IsAdd:     false,
+		SwIfIndex: b.swIfIndex,
+		ACLIndex:  b.acl,
+	})
+	if err != nil {
+		return errors.Wrap(err, "vppapi MacipACLInterfaceAddDel returned error")
+	}
+	s.deleteACL(ctx, b.acl)
+	return nil
+}
+
+func (s *Server) deleteACL(ctx context.Context, index uint32) {
+	if _, err := acl.NewServiceClient(s.vppConn).MacipACLDel(ctx, &acl.MacipACLDel{ACLIndex: index}); err != nil {
+		log.FromContext(ctx).WithField("macip", "server").Debugf("error deleting MACIP ACL %d: %v", index, err)
+	}
+}
+
+// macipAddReplace 构造MACIP ACL添加/替换请求
+//
+// index为^uint32(0)时创建新ACL，否则替换已有ACL。
+func macipAddReplace(index uint32, id string, rules []acl_types.MacipACLRule) *acl.MacipACLAddReplace {
+	return &acl.MacipACLAddReplace{
+		ACLIndex: index,
+		Tag:      fmt.Sprintf("%s-%s", macipTag, id),
+		Count:    uint32(len(rules)),
+		R:        rules,
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package macip_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/govpp/binapi/acl"
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/interface_types"
+	"github.com/networkservicemesh/sdk-vpp/pkg/tools/ifindex"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.fd.io/govpp/api"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/aclrules"
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-firewall-vpp-refactored/pkg/macip"
+)
+
+// fakeVPP 记录MACIP ACL和接口绑定的VPP API模拟
+type fakeVPP struct {
+	api.Connection
+
+	mu     sync.Mutex
+	next   uint32
+	acls   map[uint32][]acl_types.MacipACLRule
+	ifaces map[interface_types.InterfaceIndex]uint32
+}
+
+func newFakeVPP() *fakeVPP {
+	return &fakeVPP{
+		acls:   make(map[uint32][]acl_types.MacipACLRule),
+		ifaces: make(map[interface_types.InterfaceIndex]uint32),
+	}
+}
+
+func (f *fakeVPP) Invoke(_ context.Context, req, reply api.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r := req.(type) {
+	case *acl.MacipACLAddReplace:
+		index := r.ACLIndex
+		if index == ^uint32(0) {
+			index = f.next
+			f.next++
+		} else if _, ok := f.acls[index]; !ok {
+			return errors.Errorf("MACIP ACL %d not found", index)
+		}
+		f.acls[index] = r.R
+		reply.(*acl.MacipACLAddReplaceReply).ACLIndex = index
+	case *acl.MacipACLInterfaceAddDel:
+		if r.IsAdd {
+			if _, ok := f.ifaces[r.SwIfIndex]; ok {
+				return errors.Errorf("interface %d already has a MACIP ACL", r.SwIfIndex)
+			}
+			f.ifaces[r.SwIfIndex] = r.ACLIndex
+		} else {
+			delete(f.ifaces, r.SwIfIndex)
+		}
+	case *acl.MacipACLDel:
+		delete(f.acls, r.ACLIndex)
+	default:
+		return errors.Errorf("unexpected message %s", req.GetMessageName())
+	}
+	return nil
+}
+
+func (f *fakeVPP) rulesOf(iface interface_types.InterfaceIndex) []acl_types.MacipACLRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	index, ok := f.ifaces[iface]
+	if !ok {
+		return nil
+	}
+	return f.acls[index]
+}
+
+// ifindexServer 模拟下游的接口创建链元素
+type ifindexServer struct {
+	index interface_types.InterfaceIndex
+}
+
+func (s *ifindexServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	ifindex.Store(ctx, false, s.index)
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (s *ifindexServer) Close(ctx context.Context, conn *networkservice.Connection) (*emptypb.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func connWith(id, mac string, ips ...string) *networkservice.Connection {
+	return &networkservice.Connection{
+		Id: id,
+		Context: &networkservice.ConnectionContext{
+			IpContext:       &networkservice.IPContext{SrcIpAddrs: ips},
+			EthernetContext: &networkservice.EthernetContext{SrcMac: mac},
+		},
+	}
+}
+
+func TestServer_Pin(t *testing.T) {
+	vpp := newFakeVPP()
+	srv := macip.NewServer(vpp, macip.Config{Pin: true})
+	server := chain.NewNetworkServiceServer(metadata.NewServer(), srv, &ifindexServer{index: 1})
+
+	conn := connWith("conn-1", "02:fe:aa:bb:cc:dd", "172.16.1.101/32")
+	_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+
+	rules := vpp.rulesOf(1)
+	require.Len(t, rules, 1)
+	require.Equal(t, acl_types.ACL_ACTION_API_PERMIT, rules[0].IsPermit)
+	require.Equal(t, "172.16.1.101/32", rules[0].SrcPrefix.String())
+	require.Equal(t, "02:fe:aa:bb:cc:dd", rules[0].SrcMac.String())
+	require.Equal(t, "ff:ff:ff:ff:ff:ff", rules[0].SrcMacMask.String())
+	index := srv.ACLs()["conn-1"]
+
+	// 刷新时地址变化：原地替换，ACL索引不变；IPv6地址同时放行链路本地地址
+	conn = connWith("conn-1", "02:fe:aa:bb:cc:dd", "172.16.1.101/32", "fd00::101/128")
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	rules = vpp.rulesOf(1)
+	require.Len(t, rules, 3)
+	require.Equal(t, "fd00::101/128", rules[1].SrcPrefix.String())
+	require.Equal(t, "fe80::/10", rules[2].SrcPrefix.String())
+	require.Equal(t, index, srv.ACLs()["conn-1"])
+	require.Len(t, vpp.acls, 1)
+
+	_, err = server.Close(context.Background(), conn)
+	require.NoError(t, err)
+	require.Empty(t, vpp.ifaces)
+	require.Empty(t, vpp.acls)
+	require.Empty(t, srv.ACLs())
+}
+
+func TestServer_ConfiguredRules(t *testing.T) {
+	configured, err := aclrules.ParseMACIP([]byte(`
+- name: gateway
+  action: permit
+  ip: 172.16.1.1
+  mac: 02:fe:00:00:00:01
+`))
+	require.NoError(t, err)
+
+	vpp := newFakeVPP()
+	srv := macip.NewServer(vpp, macip.Config{Pin: true, Rules: configured})
+	server := chain.NewNetworkServiceServer(metadata.NewServer(), srv, &ifindexServer{index: 2})
+
+	// 没有分配MAC时固定规则不限制MAC，配置文件中的规则在固定规则之后
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: connWith("conn-2", "", "172.16.1.102/32")})
+	require.NoError(t, err)
+	rules := vpp.rulesOf(2)
+	require.Len(t, rules, 2)
+	require.Equal(t, "00:00:00:00:00:00", rules[0].SrcMacMask.String())
+	require.Equal(t, "172.16.1.1/32", rules[1].SrcPrefix.String())
+}
+
+func TestServer_NoRules(t *testing.T) {
+	vpp := newFakeVPP()
+	srv := macip.NewServer(vpp, macip.Config{Pin: true})
+	server := chain.NewNetworkServiceServer(metadata.NewServer(), srv, &ifindexServer{index: 3})
+
+	_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: &networkservice.Connection{Id: "conn-3"}})
+	require.NoError(t, err)
+	require.Empty(t, vpp.acls, "没有地址可固定时不安装MACIP ACL")
+
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: connWith("conn-4", "bogus", "172.16.1.104/32")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `invalid source MAC "bogus"`)
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-gateway-vpp/internal/gateway"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/analyzer"
+)
+
+// command 子命令的执行环境
+type command struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// parse 解析子命令参数并检查位置参数数量（maxArgs<0表示不限）
+// 返回false时应以返回的退出码结束
+func (c *command) parse(fs *flag.FlagSet, args []string, minArgs, maxArgs int) (int, bool) {
+	fs.SetOutput(c.stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if fs.NArg() < minArgs || (maxArgs >= 0 && fs.NArg() > maxArgs) {
+		fs.Usage()
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+func (c *command) flagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: policyctl %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (c *command) fail(err error) int {
+	fmt.Fprintf(c.stderr, "policyctl: %v\n", err)
+	return exitFailed
+}
+
+// validate 加载策略文件并报告错误和策略问题
+// 订阅源中被跳过的无效条目总是导致失败，-strict只对静态分析的警告生效
+func (c *command) validate(args []string) int {
+	fs := c.flagSet("validate", "[-strict] [-o text|json] <policy.yaml>")
+	strict := fs.Bool("strict", false, "also fail on warnings (shadowed or redundant rules)")
+	output := fs.String("o", "text", "output format: text or json")
+	if code, ok := c.parse(fs, args, 1, 1); !ok {
+		return code
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(c.stderr, "policyctl: unknown output format %q\n", *output)
+		return exitUsage
+	}
+
+	p, err := loadPolicy(fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+	report := p.config.Analyze()
+
+	if *output == "json" {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(struct {
+			File          string             `json:"file"`
+			AllowList     int                `json:"allowList"`
+			DenyList      int                `json:"denyList"`
+			DefaultAction string             `json:"defaultAction"`
+			Feeds         int                `json:"feeds"`
+			Errors        []string           `json:"errors,omitempty"`
+			Findings      []analyzer.Finding `json:"findings"`
+		}{p.path, len(p.config.AllowList), len(p.config.DenyList), p.config.DefaultAction, len(p.config.Feeds), p.invalid, report.Findings}); err != nil {
+			return c.fail(err)
+		}
+	} else {
+		fmt.Fprintf(c.stdout, "%s: allowList=%d rules, denyList=%d rules, defaultAction=%s, feeds=%d\n",
+			p.path, len(p.config.AllowList), len(p.config.DenyList), p.config.DefaultAction, len(p.config.Feeds))
+		for _, e := range p.invalid {
+			fmt.Fprintf(c.stdout, "error: %s\n", e)
+		}
+		fmt.Fprintln(c.stdout, report.String())
+	}
+
+	if len(p.invalid) > 0 || report.AtLeast(analyzer.SeverityError) {
+		return exitFailed
+	}
+	if *strict && report.AtLeast(analyzer.SeverityWarning) {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// eval 输出IP地址的判定结果和匹配的规则
+func (c *command) eval(args []string) int {
+	fs := c.flagSet("eval", "[-expect allow|deny] [-f ip-file] <policy.yaml> [ip...]")
+	expect := fs.String("expect", "", "fail unless every address gets this decision (allow or deny)")
+	file := fs.String("f", "", "read addresses from a file, one per line ('-' for stdin)")
+	if code, ok := c.parse(fs, args, 1, -1); !ok {
+		return code
+	}
+	if *expect != "" && *expect != string(analyzer.ActionAllow) && *expect != string(analyzer.ActionDeny) {
+		fmt.Fprintf(c.stderr, "policyctl: -expect must be allow or deny, got %q\n", *expect)
+		return exitUsage
+	}
+
+	addrs := fs.Args()[1:]
+	if *file != "" {
+		lines, err := c.readLines(*file)
+		if err != nil {
+			return c.fail(err)
+		}
+		addrs = append(addrs, lines...)
+	}
+	if len(addrs) == 0 {
+		fmt.Fprintln(c.stderr, "policyctl: no addresses to evaluate")
+		return exitUsage
+	}
+
+	p, err := loadPolicy(fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	code := exitOK
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	for _, s := range addrs {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			fmt.Fprintf(w, "%s\terror\tinvalid IP address\n", s)
+			code = exitFailed
+			continue
+		}
+		match := p.config.Explain(ip)
+		decision := string(analyzer.ActionDeny)
+		if match.Allowed {
+			decision = string(analyzer.ActionAllow)
+		}
+		reason := match.String()
+		if *expect != "" && decision != *expect {
+			reason += fmt.Sprintf(" (expected %s)", *expect)
+			code = exitFailed
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s, decision, reason)
+	}
+	if err := w.Flush(); err != nil {
+		return c.fail(err)
+	}
+	return code
+}
+
+// diff 输出两个策略版本之间判定结果发生变化的地址范围
+func (c *command) diff(args []string) int {
+	fs := c.flagSet("diff", "[-exit-code] <old-policy.yaml> <new-policy.yaml>")
+	exitCode := fs.Bool("exit-code", false, "exit with 1 if any decision changes")
+	if code, ok := c.parse(fs, args, 2, 2); !ok {
+		return code
+	}
+
+	before, err := loadPolicy(fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+	after, err := loadPolicy(fs.Arg(1))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	changes := gateway.DiffPolicies(before.config, after.config)
+	if len(changes) == 0 {
+		fmt.Fprintln(c.stdout, "no decision changes")
+		return exitOK
+	}
+	for _, change := range changes {
+		fmt.Fprintln(c.stdout, change)
+	}
+	if *exitCode {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// aclRule ACL规则的输出格式
+type aclRule struct {
+	Priority int    `json:"priority"`
+	Action   string `json:"action"`
+	Source   string `json:"src"`
+}
+
+// acl 输出策略对应的VPP ACL规则
+func (c *command) acl(args []string) int {
+	fs := c.flagSet("acl", "[-o text|json] <policy.yaml>")
+	output := fs.String("o", "text", "output format: text or json")
+	if code, ok := c.parse(fs, args, 1, 1); !ok {
+		return code
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(c.stderr, "policyctl: unknown output format %q\n", *output)
+		return exitUsage
+	}
+
+	p, err := loadPolicy(fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	var rules []aclRule
+	for _, r := range p.config.ACLRules() {
+		action := "deny"
+		if r.IsPermit {
+			action = "permit"
+		}
+		src := net.IPNet{IP: r.SrcIPAddr, Mask: r.SrcIPMask}
+		rules = append(rules, aclRule{Priority: r.Priority, Action: action, Source: src.String()})
+	}
+
+	if *output == "json" {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rules); err != nil {
+			return c.fail(err)
+		}
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PRIORITY\tACTION\tSRC")
+	for _, r := range rules {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", r.Priority, r.Action, r.Source)
+	}
+	if err := w.Flush(); err != nil {
+		return c.fail(err)
+	}
+	return exitOK
+}
+
+// readLines 读取文件中的非空行（忽略#注释），path为"-"时读取标准输入
+func (c *command) readLines(path string) ([]string, error) {
+	r := c.stdin
+	if path != "-" {
+		f, err := os.Open(path) // #nosec G304 -- 路径由用户在命令行指定
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
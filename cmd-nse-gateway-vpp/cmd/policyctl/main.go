@@ -0,0 +1,67 @@
+// policyctl 离线检查Gateway NSE的IP策略文件
+//
+// 使用与NSE相同的LoadIPPolicy和IPPolicyConfig.Validate加载策略，
+// 因此可以在CI中检查策略ConfigMap，无需部署NSE。
+//
+// 用法：
+//
+//	policyctl validate [-strict] [-o text|json] <policy.yaml>
+//	policyctl eval [-expect allow|deny] [-f ip-file] <policy.yaml> [ip...]
+//	policyctl diff [-exit-code] <old-policy.yaml> <new-policy.yaml>
+//	policyctl acl [-o text|json] <policy.yaml>
+//
+// 退出码：0表示成功，1表示检查未通过或加载失败，2表示参数错误。
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+const usage = `Usage: policyctl <command> [flags] <policy.yaml> ...
+
+Commands:
+  validate  load the policy file and report errors and policy issues
+  eval      print the decision and matched rule for IP addresses
+  diff      print the address ranges whose decision changes between two policies
+  acl       print the VPP ACL rules that would be programmed for the policy
+
+Run 'policyctl <command> -h' for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 执行子命令并返回退出码
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	cmd := &command{stdin: stdin, stdout: stdout, stderr: stderr}
+	switch args[0] {
+	case "validate":
+		return cmd.validate(args[1:])
+	case "eval":
+		return cmd.eval(args[1:])
+	case "diff":
+		return cmd.diff(args[1:])
+	case "acl":
+		return cmd.acl(args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "policyctl: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-gateway-vpp/internal/gateway"
+)
+
+// policy 加载后的IP策略
+type policy struct {
+	path   string
+	config *gateway.IPPolicyConfig
+
+	// invalid 加载过程中被跳过的无效条目（例如订阅源中的无效行，validate视为错误）
+	invalid []string
+}
+
+// warningHook 收集加载策略时的警告日志
+// 静态分析的结果（带kind字段）由命令自己输出，这里不重复收集
+type warningHook struct {
+	messages []string
+}
+
+func (h *warningHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+func (h *warningHook) Fire(entry *logrus.Entry) error {
+	if _, ok := entry.Data["kind"]; ok {
+		return nil
+	}
+	msg := entry.Message
+	if src, ok := entry.Data["feed"]; ok {
+		msg = fmt.Sprintf("%v: %s", src, msg)
+	}
+	h.messages = append(h.messages, msg)
+	return nil
+}
+
+// loadPolicy 使用LoadIPPolicy加载并验证策略文件
+func loadPolicy(path string) (*policy, error) {
+	hook := &warningHook{}
+	logger := logrus.StandardLogger()
+	logger.SetOutput(io.Discard)
+	logger.ReplaceHooks(logrus.LevelHooks{})
+	logger.AddHook(hook)
+
+	config, err := gateway.LoadIPPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+	return &policy{path: path, config: config, invalid: hook.messages}, nil
+}
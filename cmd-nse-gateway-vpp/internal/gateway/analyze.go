@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"net"
+	"net/netip"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/analyzer"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ipset"
+)
+
+// Analyze 对IP策略进行静态分析
+// 基于Validate解析后的网段，调用前必须先成功调用Validate
+func (p *IPPolicyConfig) Analyze() *analyzer.Report {
+	return analyzer.Analyze(p.analyzerPolicy())
+}
+
+// DiffPolicies 比较两个IP策略，返回判定结果发生变化的地址范围
+// 判定使用Check，与运行时的匹配逻辑一致；两个策略都必须已通过Validate
+func DiffPolicies(before, after *IPPolicyConfig) []analyzer.Change {
+	boundaries := append(before.analyzerPolicy().Prefixes(), after.analyzerPolicy().Prefixes()...)
+	return analyzer.Diff(boundaries, before.decide, after.decide)
+}
+
+// decide 按Check对地址求值（实现analyzer.Decider）
+func (p *IPPolicyConfig) decide(addr netip.Addr) analyzer.Action {
+	if p.Check(net.IP(addr.AsSlice())) {
+		return analyzer.ActionAllow
+	}
+	return analyzer.ActionDeny
+}
+
+func (p *IPPolicyConfig) analyzerPolicy() analyzer.Policy {
+	defaultAction := analyzer.ActionDeny
+	if p.DefaultAction == "allow" {
+		defaultAction = analyzer.ActionAllow
+	}
+
+	return analyzer.Policy{
+		Allow:         toAnalyzerRules(p.allowNets, p.AllowList),
+		Deny:          toAnalyzerRules(p.denyNets, p.DenyList),
+		DefaultAction: defaultAction,
+	}
+}
+
+// logReport 按严重程度记录分析报告中的问题
+func logReport(report *analyzer.Report) {
+	for _, f := range report.Findings {
+		entry := logrus.WithFields(logrus.Fields{
+			"kind":  f.Kind,
+			"list":  f.List,
+			"index": f.Index,
+			"rule":  f.Rule,
+		})
+		switch f.Severity {
+		case analyzer.SeverityError:
+			entry.Error(f.Message)
+		case analyzer.SeverityWarning:
+			entry.Warn(f.Message)
+		default:
+			entry.Info(f.Message)
+		}
+	}
+}
+
+// toAnalyzerRules 将解析后的网段转换为分析规则，texts为配置中的原始写法（与nets一一对应）
+func toAnalyzerRules(nets []net.IPNet, texts []string) []analyzer.Rule {
+	rules := make([]analyzer.Rule, 0, len(nets))
+	for i, n := range nets {
+		prefix, ok := ipset.FromIPNet(n)
+		if !ok {
+			continue
+		}
+		rule := analyzer.Rule{Prefix: prefix}
+		if len(texts) == len(nets) {
+			rule.Text = texts[i]
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
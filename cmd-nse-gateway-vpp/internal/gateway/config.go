@@ -1,83 +1,110 @@
 package gateway
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
-	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/feed"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ipset"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ratelimit"
 )
 
-// GatewayConfig 网关配置（适配firewall-vpp的Config结构，替换ACLConfig为IPPolicyConfig）
+// GatewayConfig 网关配置（嵌入NSE通用配置，添加IP策略和请求限流配置）
+//
+// 所有配置项从"NSM_"前缀的环境变量或NSM_CONFIG_FILE配置文件读取，由LoadConfig加载。
 type GatewayConfig struct {
-	// === 通用NSM配置（从firewall-vpp复用） ===
-	Name             string            `envconfig:"NSM_NAME" default:"gateway-server"`
-	ConnectTo        url.URL           `envconfig:"NSM_CONNECT_TO" default:"unix:///var/lib/networkservicemesh/nsm.io.sock"`
-	MaxTokenLifetime time.Duration     `envconfig:"NSM_MAX_TOKEN_LIFETIME" default:"10m"`
-	ServiceName      string            `envconfig:"NSM_SERVICE_NAME" required:"true"`
-	Labels           map[string]string `envconfig:"NSM_LABELS"`
-
-	// === IP策略配置（新增） ===
-	IPPolicyConfigPath string         `envconfig:"NSM_IP_POLICY_CONFIG_PATH" default:"/etc/gateway/policy.yaml"`
-	IPPolicy           IPPolicyConfig `envconfig:"NSM_IP_POLICY"`
-
-	// === 日志和可观测性（从firewall-vpp复用） ===
-	LogLevel              string        `envconfig:"NSM_LOG_LEVEL" default:"INFO"`
-	OpenTelemetryEndpoint string        `envconfig:"NSM_OPEN_TELEMETRY_ENDPOINT" default:"otel-collector.observability.svc.cluster.local:4317"`
-	MetricsExportInterval time.Duration `envconfig:"NSM_METRICS_EXPORT_INTERVAL" default:"10s"`
-
-	// === 性能分析（从firewall-vpp复用） ===
-	PprofEnabled  bool   `envconfig:"NSM_PPROF_ENABLED" default:"false"`
-	PprofListenOn string `envconfig:"NSM_PPROF_LISTEN_ON" default:"localhost:6060"`
-
-	// === NSM注册表策略（从firewall-vpp复用） ===
-	RegistryClientPolicies []string `envconfig:"NSM_REGISTRY_CLIENT_POLICIES" default:"etc/nsm/opa/common/.*.rego,etc/nsm/opa/registry/.*.rego,etc/nsm/opa/client/.*.rego"`
+	// === 通用NSM配置（NSM_NAME、NSM_CONNECT_TO、NSM_SERVICE_NAME、NSM_LABELS、日志和可观测性等） ===
+	config.Base
+
+	// === IP策略配置 ===
+	IPPolicyConfigPath string `default:"/etc/gateway/policy.yaml" desc:"Path to IP policy file" split_words:"true"`
+	// 由NSM_IP_POLICY（JSON）或策略文件加载
+	IPPolicy *IPPolicyConfig `ignored:"true"`
+	// 策略文件和订阅源的变更检查间隔（0表示不重新加载）
+	IPPolicyReloadInterval time.Duration `default:"30s" desc:"Interval between checks of the IP policy files and feeds for changes (0 disables reloading)" split_words:"true"`
+
+	// === 请求限流（速率单位：请求/秒，0表示不限制） ===
+	RateLimitPerIP            float64       `default:"0" desc:"Request rate limit per source IP (requests per second, 0 disables)" split_words:"true"`
+	RateLimitPerIPBurst       int           `default:"0" desc:"Burst size of the per source IP rate limit" split_words:"true"`
+	RateLimitPerSpiffeID      float64       `default:"0" desc:"Request rate limit per client SPIFFE ID (requests per second, 0 disables)" split_words:"true"`
+	RateLimitPerSpiffeIDBurst int           `default:"0" desc:"Burst size of the per SPIFFE ID rate limit" split_words:"true"`
+	RateLimitGlobal           float64       `default:"0" desc:"Global request rate limit (requests per second, 0 disables)" split_words:"true"`
+	RateLimitGlobalBurst      int           `default:"0" desc:"Burst size of the global rate limit" split_words:"true"`
+	RateLimitIdleTTL          time.Duration `default:"10m" desc:"Idle time after which per-client rate limit state is dropped" split_words:"true"`
 }
 
 // IPPolicyConfig IP访问策略配置
+//
+// AllowList和DenyList中可以使用"@名称"引用IPSets中定义的命名IP集合，
+// Validate会将引用展开为去重后的CIDR列表。
+// Feeds声明的外部订阅源在加载时被解析、聚合并合并到目标列表，
+// 合并的前缀不计入1000条规则上限（威胁情报黑名单通常远超此数量）。
 type IPPolicyConfig struct {
-	AllowList     []string `yaml:"allowList" json:"allowList"`         // IP白名单（CIDR或单个IP）
-	DenyList      []string `yaml:"denyList" json:"denyList"`           // IP黑名单（CIDR或单个IP）
-	DefaultAction string   `yaml:"defaultAction" json:"defaultAction"` // 默认动作："allow"或"deny"
+	AllowList     []string                    `yaml:"allowList" json:"allowList"`               // IP白名单（CIDR、单个IP或@集合引用）
+	DenyList      []string                    `yaml:"denyList" json:"denyList"`                 // IP黑名单（CIDR、单个IP或@集合引用）
+	DefaultAction string                      `yaml:"defaultAction" json:"defaultAction"`       // 默认动作："allow"或"deny"
+	IPSets        map[string]ipset.Definition `yaml:"ipSets,omitempty" json:"ipSets,omitempty"` // 命名IP集合定义
+	Feeds         []feed.Source               `yaml:"feeds,omitempty" json:"feeds,omitempty"`   // 外部订阅源（黑名单/白名单文件）
 
 	// 解析后的网络对象（内部使用，不序列化）
 	allowNets []net.IPNet `yaml:"-" json:"-"`
 	denyNets  []net.IPNet `yaml:"-" json:"-"`
+
+	// 由订阅源合并到AllowList/DenyList的前缀（CIDR），不计入规则上限
+	feedAllow map[string]bool `yaml:"-" json:"-"`
+	feedDeny  map[string]bool `yaml:"-" json:"-"`
 }
 
 // Validate 验证GatewayConfig的所有字段
 func (c *GatewayConfig) Validate() error {
-	// 1. 必填字段检查
-	if c.ServiceName == "" {
-		return fmt.Errorf("NSM_SERVICE_NAME is required")
+	// 1. 通用配置检查（名称、服务名称、NSM连接地址）
+	if err := c.Base.Validate(); err != nil {
+		return err
 	}
 
-	// 2. URL格式验证
-	if c.ConnectTo.Scheme == "" {
-		return fmt.Errorf("NSM_CONNECT_TO must be a valid URL")
+	// 2. IP策略验证
+	if c.IPPolicy == nil {
+		return fmt.Errorf("IP policy is not loaded")
 	}
-
-	// 3. IP策略验证
 	if err := c.IPPolicy.Validate(); err != nil {
 		return fmt.Errorf("invalid IP policy: %w", err)
 	}
 
-	// 4. 日志级别验证
-	validLogLevels := []string{"DEBUG", "INFO", "WARN", "ERROR"}
-	if !contains(validLogLevels, c.LogLevel) {
-		return fmt.Errorf("invalid log level: %s (must be one of: DEBUG, INFO, WARN, ERROR)", c.LogLevel)
+	// 3. 限流配置验证
+	if err := c.RateLimitConfig().Validate(); err != nil {
+		return fmt.Errorf("invalid rate limit: %w", err)
+	}
+
+	if c.IPPolicyReloadInterval < 0 {
+		return fmt.Errorf("invalid IP policy reload interval: %v", c.IPPolicyReloadInterval)
 	}
 
 	return nil
 }
 
+// RateLimitConfig 将限流字段转换为ratelimit.Config
+func (c *GatewayConfig) RateLimitConfig() ratelimit.Config {
+	return ratelimit.Config{
+		PerIP:       ratelimit.Limit{Rate: c.RateLimitPerIP, Burst: c.RateLimitPerIPBurst},
+		PerSpiffeID: ratelimit.Limit{Rate: c.RateLimitPerSpiffeID, Burst: c.RateLimitPerSpiffeIDBurst},
+		Global:      ratelimit.Limit{Rate: c.RateLimitGlobal, Burst: c.RateLimitGlobalBurst},
+		IdleTTL:     c.RateLimitIdleTTL,
+	}
+}
+
 // Validate 验证IPPolicyConfig的配置
 // 实现详细错误报告：收集所有验证错误，而非遇到第一个错误就停止
+// 重复调用的结果相同（引用展开后列表中不再包含引用），静态分析报告由加载函数记录
 func (p *IPPolicyConfig) Validate() error {
 	var errors []string
 
@@ -86,9 +113,15 @@ func (p *IPPolicyConfig) Validate() error {
 		errors = append(errors, fmt.Sprintf("defaultAction must be 'allow' or 'deny', got: '%s'", p.DefaultAction))
 	}
 
-	// 2. 解析并验证allowList
+	// 2. 展开命名IP集合引用（未定义引用和循环引用视为错误）
+	errors = append(errors, p.expandIPSets()...)
+
+	// 3. 解析并验证allowList
 	p.allowNets = make([]net.IPNet, 0, len(p.AllowList))
 	for i, ipStr := range p.AllowList {
+		if ipset.IsRef(ipStr) {
+			continue // 引用展开失败，错误已在步骤2中记录
+		}
 		ipNet, err := parseIPOrCIDR(ipStr)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("allowList[%d]: invalid IP '%s' - %s", i, ipStr, err.Error()))
@@ -97,9 +130,12 @@ func (p *IPPolicyConfig) Validate() error {
 		}
 	}
 
-	// 3. 解析并验证denyList
+	// 4. 解析并验证denyList
 	p.denyNets = make([]net.IPNet, 0, len(p.DenyList))
 	for i, ipStr := range p.DenyList {
+		if ipset.IsRef(ipStr) {
+			continue // 引用展开失败，错误已在步骤2中记录
+		}
 		ipNet, err := parseIPOrCIDR(ipStr)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("denyList[%d]: invalid IP '%s' - %s", i, ipStr, err.Error()))
@@ -108,9 +144,8 @@ func (p *IPPolicyConfig) Validate() error {
 		}
 	}
 
-	// 4. 检查规则数量限制（最多1000条）
-	totalRules := len(p.AllowList) + len(p.DenyList)
-	if totalRules > 1000 {
+	// 5. 检查规则数量限制（在引用展开和去重后计数，最多1000条，订阅源合并的前缀不计入）
+	if totalRules := p.ruleCount(); totalRules > 1000 {
 		errors = append(errors, fmt.Sprintf("total rules (%d) exceeds maximum allowed (1000)", totalRules))
 	}
 
@@ -120,13 +155,101 @@ func (p *IPPolicyConfig) Validate() error {
 			len(errors), strings.Join(errors, "\n  - "))
 	}
 
-	// 5. 警告冲突（同一IP同时在允许和禁止列表中）
-	// 这不是错误，只是警告，所以放在错误检查之后
-	conflicts := findConflicts(p.allowNets, p.denyNets)
-	if len(conflicts) > 0 {
-		logrus.Warnf("IP conflicts detected (deny will take precedence): %v", conflicts)
+	return nil
+}
+
+// ruleCount 返回手写规则的数量：IP集合展开和去重后AllowList/DenyList中不来自订阅源的条目
+func (p *IPPolicyConfig) ruleCount() int {
+	count := 0
+	for _, l := range []struct {
+		list []string
+		feed map[string]bool
+	}{{p.AllowList, p.feedAllow}, {p.DenyList, p.feedDeny}} {
+		for _, entry := range l.list {
+			if !l.feed[entry] {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// expandIPSets 展开AllowList/DenyList中的"@名称"引用
+// 包含引用的列表被替换为展开并去重后的CIDR列表，不含引用的列表保持不变
+func (p *IPPolicyConfig) expandIPSets() []string {
+	var errors []string
+
+	resolver := ipset.NewResolver(p.IPSets)
+	if err := resolver.ResolveAll(); err != nil {
+		return append(errors, fmt.Sprintf("ipSets: %s", err.Error()))
+	}
+
+	lists := []struct {
+		name string
+		list *[]string
+	}{
+		{"allowList", &p.AllowList},
+		{"denyList", &p.DenyList},
+	}
+	for _, l := range lists {
+		if !ipset.HasRefs(*l.list) {
+			continue
+		}
+		entries, err := resolver.Expand(*l.list)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %s", l.name, err.Error()))
+			continue
+		}
+
+		entries = ipset.Dedupe(entries)
+		expanded := make([]string, 0, len(entries))
+		for _, e := range entries {
+			expanded = append(expanded, e.Prefix.String())
+		}
+		logrus.Debugf("Expanded %s ip set references: %d entries -> %d prefixes", l.name, len(*l.list), len(expanded))
+		*l.list = expanded
 	}
 
+	return errors
+}
+
+// loadFeeds 加载Feeds声明的订阅源，将聚合后的前缀追加到AllowList/DenyList
+// 相对路径相对于baseDir解析，解析后的路径写回Feeds（用于变更监控）
+// 订阅源中的无效行只记录警告，文件无法读取时返回错误
+func (p *IPPolicyConfig) loadFeeds(baseDir string) error {
+	p.feedAllow, p.feedDeny = make(map[string]bool), make(map[string]bool)
+	for i, src := range p.Feeds {
+		src = src.WithBase(baseDir)
+		p.Feeds[i] = src
+
+		result, err := feed.Load(src)
+		if err != nil {
+			return err
+		}
+
+		log := logrus.WithFields(logrus.Fields{
+			"feed":     src.Path,
+			"format":   src.Format,
+			"target":   src.Target,
+			"entries":  result.Entries,
+			"invalid":  result.Invalid,
+			"prefixes": len(result.Prefixes),
+		})
+		for _, msg := range result.Errors {
+			log.Warnf("订阅源条目无效（已跳过）: %s", msg)
+		}
+		log.Info("订阅源加载成功")
+
+		for _, prefix := range result.Prefixes {
+			if src.Target == feed.TargetAllow {
+				p.AllowList = append(p.AllowList, prefix.String())
+				p.feedAllow[prefix.String()] = true
+			} else {
+				p.DenyList = append(p.DenyList, prefix.String())
+				p.feedDeny[prefix.String()] = true
+			}
+		}
+	}
 	return nil
 }
 
@@ -147,32 +270,44 @@ func parseIPOrCIDR(s string) (net.IPNet, error) {
 	return *ipNet, nil
 }
 
-// contains 辅助函数：检查字符串切片是否包含指定字符串
-func contains(slice []string, str string) bool {
-	for _, s := range slice {
-		if s == str {
-			return true
-		}
+// 网关默认的NSE名称和服务名称（对应环境变量未设置时使用）
+const (
+	defaultName        = "gateway-server"
+	defaultServiceName = "ip-gateway"
+)
+
+// LoadConfig 从配置文件和环境变量加载网关配置和IP策略
+//
+// 配置项优先级见config.Load（环境变量优先于NSM_CONFIG_FILE配置文件）。
+//
+// IP策略优先级：
+//  1. NSM_IP_POLICY环境变量（JSON格式，内联配置），此时IPPolicyConfigPath被清空
+//  2. NSM_IP_POLICY_CONFIG_PATH指定的YAML文件（默认/etc/gateway/policy.yaml）
+func LoadConfig(ctx context.Context) (*GatewayConfig, error) {
+	c := new(GatewayConfig)
+	if err := config.Load(ctx, c, defaultName); err != nil {
+		return nil, err
+	}
+	// 未通过NSM_SERVICE_NAME或NSM_SERVICE_NAMES指定任何服务时提供默认服务
+	if len(c.Services()) == 0 {
+		c.ServiceName = defaultServiceName
+	}
+	if len(c.Labels) == 0 {
+		c.Labels = map[string]string{"app": "gateway"}
 	}
-	return false
-}
 
-// findConflicts 检测allow和deny列表中的冲突IP段
-func findConflicts(allowNets, denyNets []net.IPNet) []string {
-	conflicts := []string{}
-	for _, allowNet := range allowNets {
-		for _, denyNet := range denyNets {
-			if netsOverlap(allowNet, denyNet) {
-				conflicts = append(conflicts, fmt.Sprintf("%s overlaps with %s", allowNet.String(), denyNet.String()))
-			}
-		}
+	policy, found, err := LoadIPPolicyFromEnv()
+	if err != nil {
+		return nil, err
 	}
-	return conflicts
-}
+	if found {
+		c.IPPolicyConfigPath = ""
+	} else if policy, err = LoadIPPolicy(c.IPPolicyConfigPath); err != nil {
+		return nil, err
+	}
+	c.IPPolicy = policy
 
-// netsOverlap 检查两个网络是否重叠
-func netsOverlap(net1, net2 net.IPNet) bool {
-	return net1.Contains(net2.IP) || net2.Contains(net1.IP)
+	return c, nil
 }
 
 // LoadIPPolicy 从YAML文件加载IP策略配置
@@ -189,11 +324,19 @@ func LoadIPPolicy(path string) (*IPPolicyConfig, error) {
 		return nil, fmt.Errorf("failed to parse IP policy YAML: %w", err)
 	}
 
+	// 加载外部订阅源（相对路径相对于策略文件所在目录）
+	if err := policy.loadFeeds(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("failed to load IP policy feeds: %w", err)
+	}
+
 	// 验证配置
 	if err := policy.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid IP policy configuration: %w", err)
 	}
 
+	// 静态分析（被覆盖、冗余、冲突和可聚合的规则），只记录日志
+	logReport(policy.Analyze())
+
 	// 记录加载信息
 	logrus.Infof("Loaded IP policy from %s: %d allow rules, %d deny rules, default action: %s",
 		path, len(policy.AllowList), len(policy.DenyList), policy.DefaultAction)
@@ -220,11 +363,19 @@ func LoadIPPolicyFromEnv() (*IPPolicyConfig, bool, error) {
 		return nil, true, fmt.Errorf("failed to parse NSM_IP_POLICY JSON: %w (value: %s)", err, envPolicy)
 	}
 
+	// 加载外部订阅源（相对路径相对于当前工作目录）
+	if err := policy.loadFeeds(""); err != nil {
+		return nil, true, fmt.Errorf("failed to load NSM_IP_POLICY feeds: %w", err)
+	}
+
 	// 验证配置
 	if err := policy.Validate(); err != nil {
 		return nil, true, fmt.Errorf("invalid IP policy from NSM_IP_POLICY: %w", err)
 	}
 
+	// 静态分析（被覆盖、冗余、冲突和可聚合的规则），只记录日志
+	logReport(policy.Analyze())
+
 	// 记录加载信息
 	logrus.Infof("Loaded IP policy from NSM_IP_POLICY environment variable: %d allow rules, %d deny rules, default action: %s",
 		len(policy.AllowList), len(policy.DenyList), policy.DefaultAction)
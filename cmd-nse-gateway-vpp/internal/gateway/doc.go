@@ -51,7 +51,7 @@
 //   - 启动并注册到NSM < 2秒
 //   - 处理100条IP规则启动时间 < 5秒
 //   - 网络吞吐量 ≥ 1Gbps（基于VPP）
-//   - 最多支持1000条规则（allowList + denyList）
+//   - 最多支持1000条规则（allowList + denyList，订阅源合并的前缀不计入）
 //
 // # 与防火墙NSE的区别
 //
@@ -67,18 +67,20 @@
 //
 // ## Gateway特定逻辑（不可复用）
 //
-//   - ipfilter.go - IP过滤核心算法（Check、Validate、findConflicts）
+//   - ipfilter.go - IP过滤核心算法（Check、Explain、ToFilterRules）
+//   - analyze.go - IP策略静态分析和版本比较（Analyze、DiffPolicies）
 //   - endpoint.go - NSE Request/Close处理器（extractSourceIP、applyVPPRule）
 //   - vppacl.go - VPP ACL规则转换（toVPPACLRule、buildACLRules）
-//   - config.go - IP策略配置验证（LoadIPPolicy、LoadIPPolicyFromEnv）
+//   - config.go - 网关配置和IP策略加载验证（LoadConfig、LoadIPPolicy、LoadIPPolicyFromEnv）
 //   - interfaces.go - Gateway特定接口定义（IPPolicyChecker、GatewayEndpoint）
 //
-// ## 复用的通用功能（位于internal/其他包）
+// ## 复用的通用功能（位于nse-framework模块，与firewall、ipfilter共用）
 //
-//   - internal/lifecycle/ - 信号处理、日志初始化、错误监控
-//   - internal/vppmanager/ - VPP进程启动、连接管理、超时控制
-//   - internal/servermanager/ - gRPC服务器创建、Unix socket支持、优雅关闭
-//   - internal/registryclient/ - NSM注册/注销、参数验证、状态跟踪
+//   - pkg/config - 通用配置（GatewayConfig嵌入config.Base）
+//   - pkg/lifecycle - 信号处理、日志初始化、错误监控
+//   - pkg/server - gRPC服务器创建、mTLS、Unix socket
+//   - pkg/registry - NSM注册/注销
+//   - pkg/nse - 六阶段启动流程（nse.Run）
 //
 // ## 依赖方向规则
 //
@@ -88,9 +90,9 @@
 //
 // ## 职责划分示例
 //
-//	通用职责（lifecycle.Manager）:
+//	通用职责（nse.Run）:
 //	  - 监听SIGTERM/SIGINT信号 → 触发context.Done()
-//	  - 初始化logrus → 设置JSON格式和日志级别
+//	  - 初始化日志级别、获取SVID、启动gRPC服务器、注册到NSM
 //
 //	Gateway特定职责（gateway.Endpoint.Request）:
 //	  - 提取NSM请求中的源IP地址
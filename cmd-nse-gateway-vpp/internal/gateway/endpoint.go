@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync/atomic"
 	"time"
 
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ratelimit"
 )
 
 // GatewayEndpoint 网关端点结构体
@@ -18,8 +23,11 @@ type GatewayEndpoint struct {
 	connectTo string            // 连接目标（NSM注册表地址）
 	labels    map[string]string // NSE标签（用于服务发现）
 
-	// IP策略配置
-	ipPolicy *IPPolicyConfig // IP过滤策略
+	// IP策略配置（策略重新加载时原子替换）
+	ipPolicy atomic.Pointer[IPPolicyConfig] // IP过滤策略
+
+	// 请求限流链元素（与firewall、ipfilter使用同一个ratelimit.NewServer，nil表示不限流）
+	rateLimit networkservice.NetworkServiceServer
 
 	// VPP连接
 	vppConn VPPConnection // VPP数据平面连接
@@ -45,6 +53,7 @@ type EndpointOptions struct {
 
 	// 可选参数
 	Labels           map[string]string // NSE标签
+	RateLimit        ratelimit.Config  // 请求限流配置（未启用时不限流）
 	MaxTokenLifetime time.Duration     // 最大令牌生命周期（默认24h）
 	Source           interface{}       // SPIFFE证书源
 	ClientOptions    []interface{}     // NSM客户端选项
@@ -60,8 +69,9 @@ type Connection struct {
 // NetworkServiceRequest 简化的NSM请求表示（mock实现）
 // 真实实现将使用 networkservice.NetworkServiceRequest
 type NetworkServiceRequest struct {
-	ConnectionID string            // 连接ID
-	Labels       map[string]string // 请求标签（包含源IP等信息）
+	ConnectionID string               // 连接ID
+	Labels       map[string]string    // 请求标签（包含源IP等信息）
+	Path         *networkservice.Path // 连接路径（第一个段的token标识客户端SPIFFE ID）
 }
 
 // Empty 空响应（mock实现）
@@ -86,13 +96,18 @@ func NewEndpoint(ctx context.Context, opts EndpointOptions) *GatewayEndpoint {
 		name:             opts.Name,
 		connectTo:        opts.ConnectTo,
 		labels:           opts.Labels,
-		ipPolicy:         opts.IPPolicy,
 		vppConn:          opts.VPPConn,
 		maxTokenLifetime: opts.MaxTokenLifetime,
 		source:           opts.Source,
 		clientOptions:    opts.ClientOptions,
 	}
 
+	endpoint.ipPolicy.Store(opts.IPPolicy)
+
+	if opts.RateLimit.Enabled() {
+		endpoint.rateLimit = chain.NewNetworkServiceServer(ratelimit.NewServer(opts.RateLimit))
+	}
+
 	log.WithFields(log.Fields{
 		"name":       endpoint.name,
 		"connect_to": endpoint.connectTo,
@@ -113,8 +128,21 @@ func (e *GatewayEndpoint) Register(server *grpc.Server) {
 	}).Info("Gateway端点已注册到gRPC服务器（当前为模拟模式）")
 }
 
+// UpdatePolicy 替换端点使用的IP策略（线程安全）
+// 已建立的连接不受影响，新的请求使用新策略
+func (e *GatewayEndpoint) UpdatePolicy(policy *IPPolicyConfig) {
+	e.ipPolicy.Store(policy)
+
+	log.WithFields(log.Fields{
+		"endpoint":       e.name,
+		"allow_count":    len(policy.AllowList),
+		"deny_count":     len(policy.DenyList),
+		"default_action": policy.DefaultAction,
+	}).Info("IP策略已更新")
+}
+
 // Request 处理NSM连接请求
-// 流程: 提取源IP → IP策略检查 → 向VPP下发规则 → 建立连接
+// 流程: 提取源IP → 请求限流 → IP策略检查 → 向VPP下发规则 → 建立连接
 // ctx: 请求上下文
 // request: NSM网络服务请求
 // 返回: 连接对象或错误
@@ -138,8 +166,20 @@ func (e *GatewayEndpoint) Request(ctx context.Context, request *NetworkServiceRe
 		"source_ip":     srcIP.String(),
 	}).Debug("已提取源IP地址")
 
-	// 步骤2: IP策略检查
-	allowed := e.ipPolicy.Check(srcIP)
+	// 步骤2: 请求限流检查（在策略检查和VPP编程之前）
+	if e.rateLimit != nil {
+		if _, err := e.rateLimit.Request(ctx, toNSMRequest(request, srcIP)); err != nil {
+			log.WithFields(log.Fields{
+				"connection_id": request.ConnectionID,
+				"source_ip":     srcIP.String(),
+				"error":         err.Error(),
+			}).Warn("请求超出限流")
+			return nil, err
+		}
+	}
+
+	// 步骤3: IP策略检查
+	allowed := e.ipPolicy.Load().Check(srcIP)
 	if !allowed {
 		log.WithFields(log.Fields{
 			"connection_id": request.ConnectionID,
@@ -153,7 +193,7 @@ func (e *GatewayEndpoint) Request(ctx context.Context, request *NetworkServiceRe
 		"source_ip":     srcIP.String(),
 	}).Info("IP策略检查通过")
 
-	// 步骤3: 向VPP下发ACL规则
+	// 步骤4: 向VPP下发ACL规则
 	if err := e.applyVPPRule(srcIP); err != nil {
 		log.WithFields(log.Fields{
 			"connection_id": request.ConnectionID,
@@ -168,7 +208,7 @@ func (e *GatewayEndpoint) Request(ctx context.Context, request *NetworkServiceRe
 		"source_ip":     srcIP.String(),
 	}).Debug("VPP ACL规则已下发")
 
-	// 步骤4: 建立连接
+	// 步骤5: 建立连接
 	conn := &Connection{
 		ID:       request.ConnectionID,
 		SourceIP: srcIP,
@@ -182,6 +222,20 @@ func (e *GatewayEndpoint) Request(ctx context.Context, request *NetworkServiceRe
 	return conn, nil
 }
 
+// toNSMRequest 将模拟请求转换为NSM请求，供NSM链元素（如限流）处理
+func toNSMRequest(request *NetworkServiceRequest, srcIP net.IP) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id:     request.ConnectionID,
+			Labels: request.Labels,
+			Path:   request.Path,
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{SrcIpAddrs: []string{srcIP.String()}},
+			},
+		},
+	}
+}
+
 // extractSourceIP 从NSM请求中提取源IP地址
 // request: NSM网络服务请求
 // 返回: 源IP地址或错误
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"fmt"
 	"net"
 )
 
@@ -26,6 +27,29 @@ func (r *IPFilterRule) Matches(srcIP net.IP) bool {
 	return r.SourceNet.Contains(srcIP)
 }
 
+// Match 策略匹配结果
+type Match struct {
+	// Allowed 是否允许访问
+	Allowed bool
+
+	// List 匹配的列表："denyList"、"allowList"或"defaultAction"
+	List string
+
+	// Index 匹配的规则在列表中的位置（默认策略为-1）
+	Index int
+
+	// Rule 匹配的规则（配置中的原始写法，默认策略为defaultAction的值）
+	Rule string
+}
+
+// String 返回匹配结果的可读形式，例如 "denyList[0] 10.0.0.0/8"
+func (m Match) String() string {
+	if m.Index < 0 {
+		return fmt.Sprintf("%s %s", m.List, m.Rule)
+	}
+	return fmt.Sprintf("%s[%d] %s", m.List, m.Index, m.Rule)
+}
+
 // Check 检查源IP是否允许访问
 // 返回true表示允许，false表示拒绝
 //
@@ -34,28 +58,56 @@ func (r *IPFilterRule) Matches(srcIP net.IP) bool {
 //  2. 白名单检查（中等优先级）：如果源IP在allowList中 → 返回true
 //  3. 默认策略（最低优先级）：如果都不匹配 → 根据defaultAction决定
 func (p *IPPolicyConfig) Check(srcIP net.IP) bool {
+	return p.Explain(srcIP).Allowed
+}
+
+// Explain 检查源IP是否允许访问，并返回匹配的规则
+// 匹配顺序与Check相同
+func (p *IPPolicyConfig) Explain(srcIP net.IP) Match {
 	// 1. 黑名单检查（优先级最高）
-	for _, denyNet := range p.denyNets {
+	for i, denyNet := range p.denyNets {
 		if denyNet.Contains(srcIP) {
-			return false // 拒绝
+			return Match{Allowed: false, List: "denyList", Index: i, Rule: ruleText(p.DenyList, p.denyNets, i)}
 		}
 	}
 
 	// 2. 白名单检查
-	for _, allowNet := range p.allowNets {
+	for i, allowNet := range p.allowNets {
 		if allowNet.Contains(srcIP) {
-			return true // 允许
+			return Match{Allowed: true, List: "allowList", Index: i, Rule: ruleText(p.AllowList, p.allowNets, i)}
 		}
 	}
 
 	// 3. 默认策略
-	return p.DefaultAction == "allow"
+	return Match{Allowed: p.DefaultAction == "allow", List: "defaultAction", Index: -1, Rule: p.DefaultAction}
+}
+
+// ruleText 返回第i条规则在配置中的原始写法（列表与解析结果不对应时使用网段）
+func ruleText(list []string, nets []net.IPNet, i int) string {
+	if len(list) == len(nets) {
+		return list[i]
+	}
+	return nets[i].String()
+}
+
+// priorities 返回Allow规则的起始优先级和默认规则的优先级
+// 通常为1001和9999；订阅源使规则超出各自的区间时依次顺延，保证优先级单调递增
+func priorities(denyCount, allowCount int) (allowBase, defaultPriority int) {
+	allowBase, defaultPriority = 1001, 9999
+	if denyCount >= allowBase {
+		allowBase = denyCount + 1
+	}
+	if allowBase+allowCount > defaultPriority {
+		defaultPriority = allowBase + allowCount
+	}
+	return allowBase, defaultPriority
 }
 
 // ToFilterRules 将IP策略转换为优先级排序的过滤规则列表
 // 规则按优先级排序：Deny (1-1000) > Allow (1001-2000) > Default (9999)
 func (p *IPPolicyConfig) ToFilterRules() []IPFilterRule {
 	rules := make([]IPFilterRule, 0, len(p.denyNets)+len(p.allowNets)+1)
+	allowBase, defaultPriority := priorities(len(p.denyNets), len(p.allowNets))
 
 	// 添加Deny规则（优先级1-1000）
 	for i, denyNet := range p.denyNets {
@@ -71,7 +123,7 @@ func (p *IPPolicyConfig) ToFilterRules() []IPFilterRule {
 		rules = append(rules, IPFilterRule{
 			SourceNet: allowNet,
 			Action:    ActionAllow,
-			Priority:  1001 + i, // 1001-2000
+			Priority:  allowBase + i, // 1001-2000
 		})
 	}
 
@@ -88,7 +140,7 @@ func (p *IPPolicyConfig) ToFilterRules() []IPFilterRule {
 	rules = append(rules, IPFilterRule{
 		SourceNet: *allIPsNet,
 		Action:    defaultAction,
-		Priority:  9999, // 最低优先级
+		Priority:  defaultPriority, // 最低优先级
 	})
 
 	return rules
@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/feed"
+)
+
+// PolicyFiles 返回IP策略依赖的文件：策略文件本身（path为空表示来自NSM_IP_POLICY）和订阅源文件
+func PolicyFiles(path string, policy *IPPolicyConfig) []string {
+	var files []string
+	if path != "" {
+		files = append(files, path)
+	}
+	for _, src := range policy.Feeds {
+		files = append(files, src.Path)
+	}
+	return files
+}
+
+// WatchIPPolicy 监控策略文件和订阅源文件，变化时重新加载IP策略并调用apply
+//
+// path为空时从NSM_IP_POLICY环境变量重新加载（只监控订阅源文件）。
+// 调用时记录文件的当前状态，随后在后台按间隔检查，直到ctx结束。
+// 重新加载或验证失败时保留当前策略并记录错误。每次重新加载后调用onHealth（可以为nil），
+// 成功时为true，失败时为false。
+//
+// 示例：
+//
+//	gateway.WatchIPPolicy(ctx, policyPath, ipPolicy, 30*time.Second, endpoint.UpdatePolicy, nil)
+func WatchIPPolicy(ctx context.Context, path string, current *IPPolicyConfig, interval time.Duration, apply func(*IPPolicyConfig), onHealth func(loaded bool)) {
+	if onHealth == nil {
+		onHealth = func(bool) {}
+	}
+	var mu sync.Mutex
+	files := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return PolicyFiles(path, current)
+	}
+
+	reload := func() {
+		policy, err := loadPolicy(path)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"path":  path,
+				"error": err.Error(),
+			}).Error("重新加载IP策略失败，保留当前策略")
+			onHealth(false)
+			return
+		}
+
+		mu.Lock()
+		current = policy
+		mu.Unlock()
+
+		logrus.WithFields(logrus.Fields{
+			"path":  path,
+			"feeds": len(policy.Feeds),
+		}).Info("IP策略文件变化，已重新加载")
+		apply(policy)
+		onHealth(true)
+	}
+
+	go feed.NewWatcher(interval, files, reload).Run(ctx)
+}
+
+// loadPolicy 按来源加载IP策略
+func loadPolicy(path string) (*IPPolicyConfig, error) {
+	if path != "" {
+		return LoadIPPolicy(path)
+	}
+	policy, found, err := LoadIPPolicyFromEnv()
+	if err == nil && !found {
+		err = fmt.Errorf("NSM_IP_POLICY is not set")
+	}
+	return policy, err
+}
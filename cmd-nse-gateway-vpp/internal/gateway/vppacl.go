@@ -68,6 +68,12 @@ func toVPPACLRule(rule IPFilterRule) *VPPACLRule {
 	return vppRule
 }
 
+// ACLRules 返回IP策略对应的VPP ACL规则列表（按优先级排序）
+// 用于离线检查将要下发到VPP的规则，调用前必须先成功调用Validate
+func (p *IPPolicyConfig) ACLRules() []*VPPACLRule {
+	return buildACLRules(p)
+}
+
 // buildACLRules 将IP策略转换为VPP ACL规则列表
 // policy: IP访问策略配置
 // 返回: VPP ACL规则数组（按优先级排序）
@@ -76,11 +82,13 @@ func toVPPACLRule(rule IPFilterRule) *VPPACLRule {
 // - Deny规则: 1-1000 (黑名单，最高优先级)
 // - Allow规则: 1001-2000 (白名单，中等优先级)
 // - Default规则: 9999 (默认策略，最低优先级)
+// 订阅源使规则超出区间时后续区间依次顺延（见priorities）
 //
 // 这样确保黑名单优先于白名单，白名单优先于默认策略
 func buildACLRules(policy *IPPolicyConfig) []*VPPACLRule {
 	var rules []*VPPACLRule
 	priority := 1
+	allowBase, defaultPriority := priorities(len(policy.denyNets), len(policy.allowNets))
 
 	// 步骤1: 添加黑名单规则（Deny，优先级1-1000）
 	for _, denyNet := range policy.denyNets {
@@ -99,7 +107,7 @@ func buildACLRules(policy *IPPolicyConfig) []*VPPACLRule {
 	}).Debug("已添加黑名单ACL规则")
 
 	// 步骤2: 添加白名单规则（Allow，优先级1001-2000）
-	priority = 1001
+	priority = allowBase
 	for _, allowNet := range policy.allowNets {
 		rule := IPFilterRule{
 			SourceNet: allowNet,
@@ -128,7 +136,7 @@ func buildACLRules(policy *IPPolicyConfig) []*VPPACLRule {
 	defaultRule := IPFilterRule{
 		SourceNet: *allIPsNet,
 		Action:    defaultAction,
-		Priority:  9999,
+		Priority:  defaultPriority,
 	}
 	vppRule := toVPPACLRule(defaultRule)
 	rules = append(rules, vppRule)
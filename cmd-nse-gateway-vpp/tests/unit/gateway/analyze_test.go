@@ -0,0 +1,99 @@
+package gateway_test
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-gateway-vpp/internal/gateway"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/analyzer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIPPolicyAnalyze 测试IP策略的静态分析
+func TestIPPolicyAnalyze(t *testing.T) {
+	policy := &gateway.IPPolicyConfig{
+		AllowList:     []string{"10.1.0.0/16", "192.168.1.0/24", "192.168.1.10"},
+		DenyList:      []string{"10.0.0.0/8", "172.16.0.0/12"},
+		DefaultAction: "deny",
+	}
+	require.NoError(t, policy.Validate())
+
+	report := policy.Analyze()
+	require.NotEmpty(t, report.Findings)
+
+	byKind := report.ByKind
+	require.Len(t, byKind(analyzer.KindShadowed), 1, report.String())
+	shadowed := byKind(analyzer.KindShadowed)[0]
+	assert.Equal(t, analyzer.ListAllow, shadowed.List)
+	assert.Equal(t, 0, shadowed.Index)
+	assert.Equal(t, "10.1.0.0/16", shadowed.Rule, "应使用配置中的原始写法")
+
+	require.Len(t, byKind(analyzer.KindContained), 1, report.String())
+	assert.Equal(t, "192.168.1.10", byKind(analyzer.KindContained)[0].Rule)
+
+	// 10.0.0.0/8只与被完全覆盖的白名单重叠，同样不改变任何判定结果
+	ineffective := byKind(analyzer.KindIneffective)
+	require.Len(t, ineffective, 2, report.String())
+	assert.Equal(t, "10.0.0.0/8", ineffective[0].Rule)
+	assert.Equal(t, "172.16.0.0/12", ineffective[1].Rule, "默认拒绝时不与任何白名单重叠的黑名单规则无效")
+
+	assert.True(t, report.AtLeast(analyzer.SeverityWarning))
+}
+
+// TestIPPolicyAnalyzeDefaultAllow 测试默认允许策略下白名单无效
+func TestIPPolicyAnalyzeDefaultAllow(t *testing.T) {
+	policy := &gateway.IPPolicyConfig{
+		AllowList:     []string{"192.168.1.0/24"},
+		DenyList:      []string{"1.10.16.0/21", "1.10.24.0/21"},
+		DefaultAction: "allow",
+	}
+	require.NoError(t, policy.Validate())
+
+	byKind := policy.Analyze().ByKind
+	require.Len(t, byKind(analyzer.KindIneffective), 1)
+	assert.Equal(t, analyzer.ListAllow, byKind(analyzer.KindIneffective)[0].List)
+
+	require.Len(t, byKind(analyzer.KindAggregatable), 1)
+	assert.Equal(t, "1.10.16.0/20", byKind(analyzer.KindAggregatable)[0].Suggestion)
+}
+
+// TestDiffPolicies 测试比较两个策略版本的判定变化
+func TestDiffPolicies(t *testing.T) {
+	before := &gateway.IPPolicyConfig{
+		AllowList:     []string{"192.168.1.0/24"},
+		DefaultAction: "deny",
+	}
+	after := &gateway.IPPolicyConfig{
+		AllowList:     []string{"192.168.1.0/24", "192.168.2.0/24"},
+		DenyList:      []string{"192.168.1.100"},
+		DefaultAction: "deny",
+	}
+	require.NoError(t, before.Validate())
+	require.NoError(t, after.Validate())
+
+	changes := gateway.DiffPolicies(before, after)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "192.168.1.100/32: allow -> deny", changes[0].String())
+	assert.Equal(t, "192.168.2.0/24: deny -> allow", changes[1].String())
+
+	assert.Empty(t, gateway.DiffPolicies(after, after))
+}
+
+// TestIPPolicyACLRules 测试离线生成VPP ACL规则
+func TestIPPolicyACLRules(t *testing.T) {
+	policy := &gateway.IPPolicyConfig{
+		AllowList:     []string{"192.168.1.0/24"},
+		DenyList:      []string{"192.168.1.100"},
+		DefaultAction: "deny",
+	}
+	require.NoError(t, policy.Validate())
+
+	rules := policy.ACLRules()
+	require.Len(t, rules, 3)
+	assert.False(t, rules[0].IsPermit)
+	assert.Equal(t, 1, rules[0].Priority)
+	assert.True(t, rules[1].IsPermit)
+	assert.Equal(t, 1001, rules[1].Priority)
+	assert.False(t, rules[2].IsPermit)
+	assert.Equal(t, 9999, rules[2].Priority)
+}
@@ -1,11 +1,16 @@
 package gateway_test
 
 import (
+	"context"
 	"encoding/json"
+	"net"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-gateway-vpp/internal/gateway"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -95,6 +100,46 @@ defaultAction: "invalid"`,
 			expectError:   true,
 			errorContains: "validation failed with",
 		},
+		{
+			name: "命名IP集合引用",
+			yamlContent: `ipSets:
+  vpn-nets: ["172.16.0.0/12"]
+  corp-nets:
+    include: ["10.0.0.0/8", "@vpn-nets"]
+    exclude: ["10.128.0.0/9"]
+allowList:
+  - "@corp-nets"
+  - "10.1.2.3"
+  - "192.168.1.0/24"
+denyList:
+  - "@vpn-nets"
+defaultAction: "deny"`,
+			wantAllowCnt: 3, // 10.1.2.3被10.0.0.0/9包含，去重后剩3条
+			wantDenyCnt:  1,
+			wantDefault:  "deny",
+			expectError:  false,
+		},
+		{
+			name: "未定义的IP集合引用",
+			yamlContent: `allowList:
+  - "@corp-nets"
+denyList: []
+defaultAction: "deny"`,
+			expectError:   true,
+			errorContains: "undefined ip set @corp-nets",
+		},
+		{
+			name: "IP集合循环引用",
+			yamlContent: `ipSets:
+  a: ["@b"]
+  b: ["10.0.0.0/8", "@a"]
+allowList:
+  - "@a"
+denyList: []
+defaultAction: "deny"`,
+			expectError:   true,
+			errorContains: "ip set cycle detected: a -> b -> a",
+		},
 	}
 
 	for _, tt := range tests {
@@ -286,6 +331,107 @@ defaultAction: "deny"`
 	assert.Equal(t, "deny", filePolicy.DefaultAction)
 }
 
+// TestLoadConfig 测试从环境变量加载网关配置和IP策略
+func TestLoadConfig(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyPath, []byte(`allowList:
+  - "10.0.0.0/8"
+defaultAction: "deny"`), 0o600))
+
+	t.Run("未设置的变量使用网关默认值", func(t *testing.T) {
+		t.Setenv("NSM_NAME", "")
+		t.Setenv("NSM_SERVICE_NAME", "")
+		t.Setenv("NSM_SERVICE_NAMES", "")
+		t.Setenv("NSM_LABELS", "")
+		t.Setenv("NSM_IP_POLICY", "")
+		t.Setenv("NSM_IP_POLICY_CONFIG_PATH", policyPath)
+
+		cfg, err := gateway.LoadConfig(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, cfg.Validate())
+		assert.Equal(t, "gateway-server", cfg.Name)
+		assert.Equal(t, "ip-gateway", cfg.ServiceName)
+		assert.Equal(t, map[string]string{"app": "gateway"}, cfg.Labels)
+		assert.Equal(t, policyPath, cfg.IPPolicyConfigPath)
+		assert.Equal(t, []string{"10.0.0.0/8"}, cfg.IPPolicy.AllowList)
+		assert.Equal(t, 30*time.Second, cfg.IPPolicyReloadInterval)
+		assert.False(t, cfg.RateLimitConfig().Enabled())
+	})
+
+	t.Run("NSM_IP_POLICY优先于策略文件", func(t *testing.T) {
+		t.Setenv("NSM_SERVICE_NAME", "gateway-service")
+		t.Setenv("NSM_LABELS", "app:edge")
+		t.Setenv("NSM_IP_POLICY", `{"allowList":["192.168.1.0/24"],"defaultAction":"deny"}`)
+		t.Setenv("NSM_IP_POLICY_CONFIG_PATH", policyPath)
+		t.Setenv("NSM_RATE_LIMIT_GLOBAL", "50")
+
+		cfg, err := gateway.LoadConfig(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "gateway-service", cfg.ServiceName)
+		assert.Equal(t, map[string]string{"app": "edge"}, cfg.Labels)
+		assert.Empty(t, cfg.IPPolicyConfigPath, "策略来自环境变量时不监控策略文件")
+		assert.Equal(t, []string{"192.168.1.0/24"}, cfg.IPPolicy.AllowList)
+		assert.True(t, cfg.RateLimitConfig().Enabled())
+	})
+
+	t.Run("NSM_SERVICE_NAMES注册多个服务时不使用默认服务", func(t *testing.T) {
+		t.Setenv("NSM_SERVICE_NAME", "")
+		t.Setenv("NSM_SERVICE_NAMES", "edge-gateway,core-gateway")
+		t.Setenv("NSM_SERVICE_LABELS", "core-gateway=tier:core")
+		t.Setenv("NSM_IP_POLICY", "")
+		t.Setenv("NSM_IP_POLICY_CONFIG_PATH", policyPath)
+
+		cfg, err := gateway.LoadConfig(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, cfg.Validate())
+		assert.Empty(t, cfg.ServiceName)
+		assert.Equal(t, []config.Service{
+			{Name: "edge-gateway"},
+			{Name: "core-gateway", Labels: map[string]string{"tier": "core"}},
+		}, cfg.Services())
+	})
+
+	t.Run("NSM_CONFIG_FILE中的配置项在环境变量未设置时生效", func(t *testing.T) {
+		for _, key := range []string{"NSM_NAME", "NSM_SERVICE_NAME", "NSM_SERVICE_NAMES", "NSM_SERVICE_LABELS", "NSM_LABELS", "NSM_IP_POLICY_CONFIG_PATH", "NSM_RATE_LIMIT_GLOBAL"} {
+			t.Setenv(key, "")
+			require.NoError(t, os.Unsetenv(key))
+		}
+		t.Setenv("NSM_IP_POLICY", "")
+		t.Setenv("NSM_IP_POLICY_RELOAD_INTERVAL", "1m")
+		configPath := filepath.Join(t.TempDir(), "gateway.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte(`
+serviceNames: [edge-gateway]
+labels: {app: edge}
+ipPolicyConfigPath: `+policyPath+`
+ipPolicyReloadInterval: 5m
+rateLimitGlobal: 50
+`), 0o600))
+		t.Setenv(config.ConfigFileEnv, configPath)
+
+		cfg, err := gateway.LoadConfig(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, cfg.Validate())
+		assert.Equal(t, "gateway-server", cfg.Name)
+		assert.Empty(t, cfg.ServiceName, "配置文件指定了服务时不使用默认服务")
+		assert.Equal(t, []string{"edge-gateway"}, cfg.ServiceNames)
+		assert.Equal(t, map[string]string{"app": "edge"}, cfg.Labels)
+		assert.Equal(t, []string{"10.0.0.0/8"}, cfg.IPPolicy.AllowList)
+		assert.Equal(t, time.Minute, cfg.IPPolicyReloadInterval, "环境变量优先于配置文件")
+		assert.True(t, cfg.RateLimitConfig().Enabled())
+		assert.Equal(t, config.SourceFile, cfg.Origin("NSM_IP_POLICY_CONFIG_PATH").Source)
+		assert.Equal(t, config.SourceEnv, cfg.Origin("NSM_IP_POLICY_RELOAD_INTERVAL").Source)
+	})
+
+	t.Run("策略文件不存在应返回错误", func(t *testing.T) {
+		t.Setenv("NSM_IP_POLICY", "")
+		t.Setenv("NSM_IP_POLICY_CONFIG_PATH", filepath.Join(t.TempDir(), "missing.yaml"))
+
+		_, err := gateway.LoadConfig(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read IP policy file")
+	})
+}
+
 // TestIPPolicyValidation 测试配置验证逻辑
 func TestIPPolicyValidation(t *testing.T) {
 	tests := []struct {
@@ -387,6 +533,34 @@ func TestRuleLimitEnforcement(t *testing.T) {
 		"错误信息应说明规则数量超限")
 }
 
+// TestIPSetExpansion 测试命名IP集合展开后的策略内容
+func TestIPSetExpansion(t *testing.T) {
+	var policy gateway.IPPolicyConfig
+	err := json.Unmarshal([]byte(`{
+		"ipSets": {
+			"vpn-nets": ["172.16.0.0/12"],
+			"corp-nets": {"include": ["10.0.0.0/8", "@vpn-nets"], "exclude": ["10.128.0.0/9"]}
+		},
+		"allowList": ["@corp-nets", "192.168.1.10"],
+		"denyList": ["172.16.5.0/24"],
+		"defaultAction": "deny"
+	}`), &policy)
+	require.NoError(t, err, "JSON反序列化失败")
+	require.NoError(t, policy.Validate())
+
+	assert.Equal(t, []string{"10.0.0.0/9", "172.16.0.0/12", "192.168.1.10/32"}, policy.AllowList,
+		"引用应按原顺序展开为CIDR列表")
+	assert.Equal(t, []string{"172.16.5.0/24"}, policy.DenyList, "不含引用的列表保持不变")
+
+	// 展开后的策略可以重复验证
+	require.NoError(t, policy.Validate())
+	assert.Len(t, policy.AllowList, 3)
+
+	assert.True(t, policy.Check(net.ParseIP("10.20.30.40")))
+	assert.False(t, policy.Check(net.ParseIP("10.200.0.1")), "被exclude排除的地址")
+	assert.False(t, policy.Check(net.ParseIP("172.16.5.1")))
+}
+
 // TestJSONMarshaling 测试JSON序列化和反序列化
 func TestJSONMarshaling(t *testing.T) {
 	original := gateway.IPPolicyConfig{
@@ -0,0 +1,238 @@
+package gateway_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-gateway-vpp/internal/gateway"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile 在目录中写入测试文件并返回路径
+// 先写临时文件再重命名，避免监控协程读到写了一半的文件
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o600), "写入测试文件失败")
+	require.NoError(t, os.Rename(tmp, path), "写入测试文件失败")
+	return path
+}
+
+// TestLoadIPPolicyWithFeeds 测试策略文件引用外部订阅源
+func TestLoadIPPolicyWithFeeds(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "drop.txt", `; Spamhaus DROP List
+1.10.16.0/21 ; SBL256894
+1.10.24.0/21 ; SBL256895
+bogus ; SBL0
+`)
+	writeFile(t, dir, "partners.csv", "network,name\n203.0.113.0/24,partner-a\n")
+	policyPath := writeFile(t, dir, "policy.yaml", `allowList:
+  - "192.168.1.0/24"
+denyList: []
+defaultAction: "deny"
+feeds:
+  - path: drop.txt
+    format: spamhaus
+    target: deny
+  - path: partners.csv
+    format: csv
+    target: allow
+    column: network
+`)
+
+	policy, err := gateway.LoadIPPolicy(policyPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"192.168.1.0/24", "203.0.113.0/24"}, policy.AllowList)
+	assert.Equal(t, []string{"1.10.16.0/20"}, policy.DenyList, "相邻网段应被聚合，无效行被跳过")
+	assert.Equal(t, filepath.Join(dir, "drop.txt"), policy.Feeds[0].Path, "相对路径应相对于策略文件解析")
+
+	assert.ElementsMatch(t, []string{policyPath, filepath.Join(dir, "drop.txt"), filepath.Join(dir, "partners.csv")},
+		gateway.PolicyFiles(policyPath, policy))
+}
+
+// TestLoadIPPolicyLargeFeed 测试大型订阅源不受1000条规则上限限制
+func TestLoadIPPolicyLargeFeed(t *testing.T) {
+	dir := t.TempDir()
+
+	// 5000个互不相邻的/24网段（每隔一个），聚合后数量不变
+	var drop strings.Builder
+	drop.WriteString("; Spamhaus DROP List\n")
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&drop, "%d.0.%d.0/24 ; SBL%d\n", 20+i/128, (i%128)*2, i)
+	}
+	writeFile(t, dir, "drop.txt", drop.String())
+	policyPath := writeFile(t, dir, "policy.yaml", `allowList:
+  - "192.168.1.0/24"
+denyList:
+  - "10.0.0.0/8"
+defaultAction: "allow"
+feeds:
+  - path: drop.txt
+    format: spamhaus
+    target: deny
+`)
+
+	policy, err := gateway.LoadIPPolicy(policyPath)
+	require.NoError(t, err, "订阅源的前缀不计入规则上限")
+	assert.Len(t, policy.DenyList, 5001)
+
+	// 优先级保持单调递增：Allow和默认规则顺延到Deny规则之后
+	rules := policy.ToFilterRules()
+	require.Len(t, rules, 5003)
+	for i := 1; i < len(rules); i++ {
+		assert.Less(t, rules[i-1].Priority, rules[i].Priority)
+	}
+	assert.Equal(t, gateway.ActionAllow, rules[5001].Action)
+	assert.Equal(t, 5002, rules[5001].Priority)
+
+	// 手写的规则仍然受上限限制
+	policy.AllowList = append(policy.AllowList, make([]string, 1000)...)
+	for i := 1; i < len(policy.AllowList); i++ {
+		policy.AllowList[i] = fmt.Sprintf("172.16.%d.%d", i/256, i%256)
+	}
+	err = policy.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds maximum allowed (1000)")
+}
+
+// TestLoadIPPolicyFeedRuleCount 测试规则上限在IP集合展开和去重后计数，订阅源前缀被去重时不影响手写规则的计数
+func TestLoadIPPolicyFeedRuleCount(t *testing.T) {
+	// blocks个/24网段的IP集合，订阅源中的500个/32落在前500个网段内，去重后被移除
+	write := func(dir string, blocks int) string {
+		nets := make([]string, 0, blocks)
+		for i := 0; i < blocks; i++ {
+			nets = append(nets, fmt.Sprintf("%q", fmt.Sprintf("10.%d.%d.0/24", i/256, i%256)))
+		}
+		var drop strings.Builder
+		for i := 0; i < 500; i++ {
+			fmt.Fprintf(&drop, "10.%d.%d.1\n", i/256, i%256)
+		}
+		writeFile(t, dir, "drop.txt", drop.String())
+		return writeFile(t, dir, "policy.yaml", `ipSets:
+  blocks: [`+strings.Join(nets, ", ")+`]
+denyList:
+  - "@blocks"
+defaultAction: "allow"
+feeds:
+  - path: drop.txt
+    format: plain
+    target: deny
+`)
+	}
+
+	_, err := gateway.LoadIPPolicy(write(t.TempDir(), 1001))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "total rules (1001) exceeds maximum allowed (1000)")
+
+	policy, err := gateway.LoadIPPolicy(write(t.TempDir(), 1000))
+	require.NoError(t, err)
+	assert.Len(t, policy.DenyList, 1000, "订阅源中被网段包含的前缀被去重")
+
+	// 重复验证（nse.Run再次调用GatewayConfig.Validate）结果不变
+	require.NoError(t, policy.Validate())
+	assert.Len(t, policy.DenyList, 1000)
+}
+
+// TestLoadIPPolicyFeedErrors 测试订阅源错误
+func TestLoadIPPolicyFeedErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	policyPath := writeFile(t, dir, "missing.yaml", `defaultAction: "deny"
+feeds:
+  - path: missing.txt
+    format: plain
+    target: deny
+`)
+	_, err := gateway.LoadIPPolicy(policyPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load IP policy feeds")
+
+	policyPath = writeFile(t, dir, "format.yaml", `defaultAction: "deny"
+feeds:
+  - path: drop.json
+    format: json
+    target: deny
+`)
+	_, err = gateway.LoadIPPolicy(policyPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format")
+}
+
+// TestWatchIPPolicy 测试策略文件和订阅源变化后重新加载
+func TestWatchIPPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "drop.txt", "10.0.0.0/8\n")
+	policyPath := writeFile(t, dir, "policy.yaml", `defaultAction: "allow"
+feeds:
+  - path: drop.txt
+    format: plain
+    target: deny
+`)
+
+	policy, err := gateway.LoadIPPolicy(policyPath)
+	require.NoError(t, err)
+
+	var reloads atomic.Int32
+	var latest atomic.Pointer[gateway.IPPolicyConfig]
+	var loaded atomic.Bool
+	gateway.WatchIPPolicy(ctx, policyPath, policy, 10*time.Millisecond, func(p *gateway.IPPolicyConfig) {
+		latest.Store(p)
+		reloads.Add(1)
+	}, loaded.Store)
+
+	// 订阅源变化
+	writeFile(t, dir, "drop.txt", "10.0.0.0/8\n172.16.0.0/12\n")
+	require.Eventually(t, func() bool { return reloads.Load() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"10.0.0.0/8", "172.16.0.0/12"}, latest.Load().DenyList)
+	require.Eventually(t, loaded.Load, time.Second, 10*time.Millisecond)
+
+	// 无效的策略文件：保留当前策略
+	writeFile(t, dir, "policy.yaml", `defaultAction: "maybe"`)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), reloads.Load(), "验证失败时不应更新策略")
+	require.Eventually(t, func() bool { return !loaded.Load() }, time.Second, 10*time.Millisecond, "重新加载失败时策略组件应报告为不健康")
+
+	// 修复策略文件
+	writeFile(t, dir, "policy.yaml", `defaultAction: "deny"`)
+	require.Eventually(t, func() bool { return reloads.Load() == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "deny", latest.Load().DefaultAction)
+	assert.Empty(t, latest.Load().Feeds)
+	require.Eventually(t, loaded.Load, time.Second, 10*time.Millisecond)
+}
+
+// TestEndpointUpdatePolicy 测试端点替换IP策略后新请求使用新策略
+func TestEndpointUpdatePolicy(t *testing.T) {
+	policy := &gateway.IPPolicyConfig{DefaultAction: "allow"}
+	require.NoError(t, policy.Validate())
+
+	ep := gateway.NewEndpoint(context.Background(), gateway.EndpointOptions{
+		Name:     "gateway-test",
+		IPPolicy: policy,
+	})
+
+	request := &gateway.NetworkServiceRequest{
+		ConnectionID: "conn-1",
+		Labels:       map[string]string{"source_ip": "1.10.16.1"},
+	}
+	_, err := ep.Request(context.Background(), request)
+	require.NoError(t, err)
+
+	updated := &gateway.IPPolicyConfig{DenyList: []string{"1.10.16.0/20"}, DefaultAction: "allow"}
+	require.NoError(t, updated.Validate())
+	ep.UpdatePolicy(updated)
+
+	_, err = ep.Request(context.Background(), request)
+	require.Error(t, err, "更新策略后该源IP应被拒绝")
+}
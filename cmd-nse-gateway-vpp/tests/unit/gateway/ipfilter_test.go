@@ -103,6 +103,32 @@ func TestIPPolicyCheck(t *testing.T) {
 	}
 }
 
+// TestIPPolicyExplain 测试返回匹配规则的策略检查
+func TestIPPolicyExplain(t *testing.T) {
+	policy := gateway.IPPolicyConfig{
+		AllowList:     []string{"192.168.1.0/24", "10.0.0.100"},
+		DenyList:      []string{"192.168.1.50"},
+		DefaultAction: "deny",
+	}
+	require.NoError(t, policy.Validate())
+
+	tests := []struct {
+		ip   string
+		want string
+		ok   bool
+	}{
+		{"192.168.1.50", "denyList[0] 192.168.1.50", false},
+		{"10.0.0.100", "allowList[1] 10.0.0.100", true},
+		{"172.16.0.1", "defaultAction deny", false},
+	}
+	for _, tt := range tests {
+		m := policy.Explain(net.ParseIP(tt.ip))
+		assert.Equal(t, tt.want, m.String(), tt.ip)
+		assert.Equal(t, tt.ok, m.Allowed, tt.ip)
+		assert.Equal(t, m.Allowed, policy.Check(net.ParseIP(tt.ip)), "Explain与Check结果必须一致")
+	}
+}
+
 // TestCIDRMatching 测试CIDR匹配逻辑
 // 验证CIDR格式解析、边界条件、无效IP格式处理
 func TestCIDRMatching(t *testing.T) {
@@ -0,0 +1,128 @@
+package gateway_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-gateway-vpp/internal/gateway"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestLoadConfigRateLimit 测试通过LoadConfig加载限流配置
+func TestLoadConfigRateLimit(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(policyPath, []byte(`defaultAction: "allow"`), 0o600))
+	reset := func(t *testing.T) {
+		for _, key := range []string{"NSM_IP_POLICY", "NSM_RATE_LIMIT_PER_IP", "NSM_RATE_LIMIT_PER_IP_BURST",
+			"NSM_RATE_LIMIT_PER_SPIFFE_ID", "NSM_RATE_LIMIT_GLOBAL", "NSM_RATE_LIMIT_IDLE_TTL", config.ConfigFileEnv} {
+			t.Setenv(key, "")
+			require.NoError(t, os.Unsetenv(key))
+		}
+		t.Setenv("NSM_IP_POLICY_CONFIG_PATH", policyPath)
+	}
+
+	t.Run("未设置时不限流", func(t *testing.T) {
+		reset(t)
+
+		cfg, err := gateway.LoadConfig(context.Background())
+		require.NoError(t, err)
+		rl := cfg.RateLimitConfig()
+		assert.False(t, rl.Enabled())
+		assert.Equal(t, 10*time.Minute, rl.IdleTTL)
+	})
+
+	t.Run("解析限流参数", func(t *testing.T) {
+		reset(t)
+		t.Setenv("NSM_RATE_LIMIT_PER_IP", "2")
+		t.Setenv("NSM_RATE_LIMIT_PER_IP_BURST", "4")
+		t.Setenv("NSM_RATE_LIMIT_GLOBAL", "50")
+
+		cfg, err := gateway.LoadConfig(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, cfg.Validate())
+		rl := cfg.RateLimitConfig()
+		assert.True(t, rl.Enabled())
+		assert.Equal(t, ratelimit.Limit{Rate: 2, Burst: 4}, rl.PerIP)
+		assert.Equal(t, ratelimit.Limit{Rate: 50}, rl.Global)
+		assert.False(t, rl.PerSpiffeID.Enabled())
+	})
+
+	t.Run("NSM_CONFIG_FILE中的限流参数", func(t *testing.T) {
+		reset(t)
+		t.Setenv("NSM_RATE_LIMIT_PER_IP", "2")
+		configPath := filepath.Join(t.TempDir(), "gateway.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("rateLimitPerIp: 5\nrateLimitPerSpiffeId: 3\nrateLimitIdleTtl: 1m\n"), 0o600))
+		t.Setenv(config.ConfigFileEnv, configPath)
+
+		cfg, err := gateway.LoadConfig(context.Background())
+		require.NoError(t, err)
+		rl := cfg.RateLimitConfig()
+		assert.Equal(t, ratelimit.Limit{Rate: 2}, rl.PerIP, "环境变量优先于配置文件")
+		assert.Equal(t, ratelimit.Limit{Rate: 3}, rl.PerSpiffeID)
+		assert.Equal(t, time.Minute, rl.IdleTTL)
+	})
+
+	t.Run("无效数值应返回错误", func(t *testing.T) {
+		reset(t)
+		t.Setenv("NSM_RATE_LIMIT_GLOBAL", "fast")
+
+		_, err := gateway.LoadConfig(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "NSM_RATE_LIMIT_GLOBAL")
+	})
+
+	t.Run("负数速率应验证失败", func(t *testing.T) {
+		reset(t)
+		t.Setenv("NSM_RATE_LIMIT_PER_IP", "-1")
+
+		cfg, err := gateway.LoadConfig(context.Background())
+		require.NoError(t, err)
+		err = cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid rate limit")
+	})
+}
+
+// TestEndpointRateLimit 测试Gateway端点在IP策略检查前执行限流
+func TestEndpointRateLimit(t *testing.T) {
+	policy := &gateway.IPPolicyConfig{
+		AllowList:     []string{"192.168.1.0/24"},
+		DefaultAction: "deny",
+	}
+	require.NoError(t, policy.Validate())
+
+	ep := gateway.NewEndpoint(context.Background(), gateway.EndpointOptions{
+		Name:      "gateway-test",
+		IPPolicy:  policy,
+		RateLimit: ratelimit.Config{PerIP: ratelimit.Limit{Rate: 1, Burst: 1}},
+	})
+
+	request := &gateway.NetworkServiceRequest{
+		ConnectionID: "conn-1",
+		Labels:       map[string]string{"source_ip": "192.168.1.10"},
+	}
+
+	_, err := ep.Request(context.Background(), request)
+	require.NoError(t, err)
+
+	_, err = ep.Request(context.Background(), request)
+	require.Error(t, err)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+
+	// 其他源IP不受影响
+	_, err = ep.Request(context.Background(), &gateway.NetworkServiceRequest{
+		ConnectionID: "conn-2",
+		Labels:       map[string]string{"source_ip": "192.168.1.11"},
+	})
+	require.NoError(t, err)
+}
@@ -24,30 +24,22 @@
 package main
 
 import (
+	"context"
 	"os"
-	"time"
 
-	"github.com/edwarnicke/grpcfd"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	"github.com/spiffe/go-spiffe/v2/workloadapi"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
-	"github.com/networkservicemesh/sdk/pkg/tools/opentelemetry"
-	"github.com/networkservicemesh/sdk/pkg/tools/pprofutils"
-	"github.com/networkservicemesh/sdk/pkg/tools/spiffejwt"
-	"github.com/networkservicemesh/sdk/pkg/tools/token"
-	"github.com/networkservicemesh/sdk/pkg/tools/tracing"
 
 	_ "github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/internal/imports"
 
 	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/internal/ipfilter"
 	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/pkg/config"
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/pkg/lifecycle"
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/pkg/registry"
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/pkg/server"
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/pkg/vpp"
+	nseconfig "github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/health"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/lifecycle"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/nse"
 )
 
 func main() {
@@ -57,139 +49,67 @@ func main() {
 	ctx, cancel := lifecycle.NotifyContext()
 	defer cancel()
 
-	// ********************************************************************************
-	// 设置日志系统
-	// ********************************************************************************
-	// 注意：暂时使用默认日志级别，稍后从配置加载后再更新
-	ctx = lifecycle.InitializeLogging(ctx, "INFO")
-
-	// 枚举启动阶段
-	log.FromContext(ctx).Infof("there are 6 phases which will be executed followed by a success message:")
-	log.FromContext(ctx).Infof("the phases include:")
-	log.FromContext(ctx).Infof("1: get config from environment")
-	log.FromContext(ctx).Infof("2: retrieve spiffe svid")
-	log.FromContext(ctx).Infof("3: create grpc client options")
-	log.FromContext(ctx).Infof("4: create ipfilter network service endpoint")
-	log.FromContext(ctx).Infof("5: create grpc and mount nse")
-	log.FromContext(ctx).Infof("6: register nse with nsm")
-	log.FromContext(ctx).Infof("a final success message with start time duration")
+	// 六个启动阶段由nse.Run执行，ipfilter只提供配置和端点
+	f := new(ipfilterNSE)
+	if err := nse.Run(ctx, nse.Spec{
+		Name:       "ipfilter",
+		Config:     f.loadConfig,
+		VPPPlugins: []string{"memif"},
+		Endpoint:   f.newEndpoint,
+		Args:       os.Args[1:],
+	}); err != nil {
+		logrus.Fatalf("%+v", err)
+	}
+}
 
-	starttime := time.Now()
+// ipfilterNSE ipfilter的业务配置和端点
+type ipfilterNSE struct {
+	cfg *config.Config
+}
 
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 1: get config from environment")
-	// ********************************************************************************
+// loadConfig 从环境变量加载配置和ACL规则
+func (f *ipfilterNSE) loadConfig(ctx context.Context) (nseconfig.Extension, error) {
 	cfg, err := config.Load(ctx)
 	if err != nil {
-		logrus.Fatal(err.Error())
+		return nil, err
 	}
 
 	// 加载ACL规则
 	cfg.LoadACLRules(ctx)
 
-	// 验证配置
-	if err := cfg.Validate(); err != nil {
-		logrus.Fatalf("invalid config: %v", err)
-	}
-
-	// 使用配置的日志级别重新初始化日志
-	ctx = lifecycle.InitializeLogging(ctx, cfg.LogLevel)
-
-	log.FromContext(ctx).Infof("Config: %#v", cfg)
-
-	// ********************************************************************************
-	// 配置 OpenTelemetry
-	// ********************************************************************************
-	if opentelemetry.IsEnabled() {
-		collectorAddress := cfg.OpenTelemetryEndpoint
-		spanExporter := opentelemetry.InitSpanExporter(ctx, collectorAddress)
-		metricExporter := opentelemetry.InitOPTLMetricExporter(ctx, collectorAddress, cfg.MetricsExportInterval)
-		o := opentelemetry.Init(ctx, spanExporter, metricExporter, cfg.Name)
-		defer func() {
-			if err = o.Close(); err != nil {
-				log.FromContext(ctx).Error(err.Error())
-			}
-		}()
-	}
-
-	// ********************************************************************************
-	// 配置 pprof
-	// ********************************************************************************
-	if cfg.PprofEnabled {
-		go pprofutils.ListenAndServe(ctx, cfg.PprofListenOn)
-	}
-
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 2: retrieving svid, check spire agent logs if this is the last line you see")
-	// ********************************************************************************
-	source, err := workloadapi.NewX509Source(ctx)
-	if err != nil {
-		logrus.Fatalf("error getting x509 source: %+v", err)
-	}
-	svid, err := source.GetX509SVID()
-	if err != nil {
-		logrus.Fatalf("error getting x509 svid: %+v", err)
-	}
-	log.FromContext(ctx).Infof("SVID: %q", svid.ID)
+	f.cfg = cfg
+	return cfg, nil
+}
 
-	// 创建TLS配置
-	tlsClientConfig := server.CreateTLSClientConfig(source)
-	tlsServerConfig := server.CreateTLSServerConfig(source)
-
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 3: create grpc client options")
-	// ********************************************************************************
-	clientOptions := append(
-		tracing.WithTracingDial(),
-		grpc.WithDefaultCallOptions(
-			grpc.WaitForReady(true),
-			grpc.PerRPCCredentials(token.NewPerRPCCredentials(spiffejwt.TokenGeneratorFunc(source, cfg.MaxTokenLifetime))),
-		),
-		grpc.WithTransportCredentials(
-			grpcfd.TransportCredentials(
-				credentials.NewTLS(tlsClientConfig),
-			),
-		),
-		grpcfd.WithChainStreamInterceptor(),
-		grpcfd.WithChainUnaryInterceptor(),
-	)
-
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 4: create ipfilter network service endpoint")
-	// ********************************************************************************
-	vppConn, vppErrCh, err := vpp.StartAndDial(ctx)
-	if err != nil {
-		logrus.Fatalf("error starting VPP: %+v", err)
-	}
-	lifecycle.MonitorErrorChannel(ctx, cancel, vppErrCh)
+// newEndpoint 加载IP Filter配置并创建ipfilter端点
+func (f *ipfilterNSE) newEndpoint(ctx context.Context, env *nse.Env) (nse.Endpoint, error) {
+	cfg := f.cfg
 
 	// 加载IP Filter配置
 	var filterConfig *ipfilter.FilterConfig
 	var logger *logrus.Logger
-	if cfg.IPFilterWhitelist != "" || cfg.IPFilterBlacklist != "" {
+	var configLoader *ipfilter.ConfigLoader
+	if cfg.IPFilterWhitelist != "" || cfg.IPFilterBlacklist != "" || cfg.IPFilterFeeds != "" {
 		logger = logrus.New()
 		logger.SetLevel(logrus.InfoLevel)
 
-		configLoader := ipfilter.NewConfigLoader(logger)
-
-		// 设置环境变量供ConfigLoader读取
-		if cfg.IPFilterMode != "" {
-			os.Setenv("IPFILTER_MODE", cfg.IPFilterMode)
-		}
-		if cfg.IPFilterWhitelist != "" {
-			os.Setenv("IPFILTER_WHITELIST", cfg.IPFilterWhitelist)
-		}
-		if cfg.IPFilterBlacklist != "" {
-			os.Setenv("IPFILTER_BLACKLIST", cfg.IPFilterBlacklist)
-		}
+		// ConfigLoader直接读取已加载的配置（启动和重新加载使用同一份配置）
+		configLoader = ipfilter.NewConfigLoader(logger).WithSource(cfg.IPFilterSetting)
 
+		var err error
 		filterConfig, err = configLoader.LoadFromEnv(ctx)
 		if err != nil {
-			logrus.Fatalf("error loading IP filter config: %+v", err)
+			return nil, errors.Wrap(err, "error loading IP filter config")
 		}
 
 		log.FromContext(ctx).Infof("IP Filter Config: mode=%s, whitelist=%d rules, blacklist=%d rules",
 			filterConfig.Mode, len(filterConfig.Whitelist), len(filterConfig.Blacklist))
+
+		// 静态分析：记录被覆盖、冗余和可聚合的规则
+		ipfilter.LogReport(logger, ipfilter.Analyze(filterConfig))
+
+		// IP过滤规则已加载；未启用过滤时不报告策略组件
+		env.Health.Set(health.ComponentPolicy, true)
 	} else {
 		log.FromContext(ctx).Warnf("IP Filter is disabled: no whitelist or blacklist configured")
 	}
@@ -201,60 +121,22 @@ func main() {
 		Labels:           cfg.Labels,
 		FilterConfig:     filterConfig,
 		Logger:           logger,
+		RateLimit:        cfg.RateLimitConfig(),
 		MaxTokenLifetime: cfg.MaxTokenLifetime,
-		VPPConn:          vppConn,
-		Source:           source,
-		ClientOptions:    clientOptions,
-	})
-
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 5: create grpc server and register ipfilter-server")
-	// ********************************************************************************
-	srvResult, err := server.New(ctx, server.Options{
-		TLSConfig: tlsServerConfig,
-		Name:      cfg.Name,
-		ListenOn:  cfg.ListenOn,
-	})
-	if err != nil {
-		logrus.Fatalf("error creating server: %+v", err)
-	}
-	defer func() { _ = os.RemoveAll(srvResult.TmpDir) }()
-
-	// 注册ipfilter端点到gRPC服务器
-	ipfilterEndpoint.Register(srvResult.Server)
-
-	// 监控服务器错误
-	lifecycle.MonitorErrorChannel(ctx, cancel, srvResult.ErrCh)
-	log.FromContext(ctx).Infof("grpc server started")
-
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("executing phase 6: register nse with nsm")
-	// ********************************************************************************
-	registryClient, err := registry.NewClient(ctx, registry.Options{
-		ConnectTo:   &cfg.ConnectTo,
-		Policies:    cfg.RegistryClientPolicies,
-		DialOptions: clientOptions,
+		VPPConn:          env.VPPConn,
+		Source:           env.Source,
+		ClientOptions:    env.ClientOptions,
 	})
-	if err != nil {
-		logrus.Fatalf("error creating registry client: %+v", err)
-	}
 
-	nse, err := registryClient.Register(ctx, registry.RegisterSpec{
-		Name:        cfg.Name,
-		ServiceName: cfg.ServiceName,
-		Labels:      cfg.Labels,
-		URL:         srvResult.ListenURL.String(),
-	})
-	if err != nil {
-		log.FromContext(ctx).Fatalf("unable to register nse %+v", err)
+	// 规则文件或订阅源变化时重新加载IP过滤配置
+	if matcher := ipfilterEndpoint.Matcher(); matcher != nil && cfg.IPFilterReloadInterval > 0 {
+		if files := configLoader.WatchedFiles(); len(files) > 0 {
+			log.FromContext(ctx).Infof("watching %d IP filter file(s) for changes every %v", len(files), cfg.IPFilterReloadInterval)
+			configLoader.Watch(ctx, matcher, cfg.IPFilterReloadInterval, func(loaded bool) {
+				env.Health.Set(health.ComponentPolicy, loaded)
+			})
+		}
 	}
-	logrus.Infof("nse: %+v", nse)
-
-	// ********************************************************************************
-	log.FromContext(ctx).Infof("startup completed in %v", time.Since(starttime))
-	// ********************************************************************************
 
-	// 等待服务器退出
-	<-ctx.Done()
-	<-vppErrCh
+	return ipfilterEndpoint, nil
 }
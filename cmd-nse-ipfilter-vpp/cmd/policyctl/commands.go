@@ -0,0 +1,318 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"gopkg.in/yaml.v2"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/internal/ipfilter"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/analyzer"
+)
+
+// command 子命令的执行环境
+type command struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// parse 解析子命令参数并检查位置参数数量（maxArgs<0表示不限）
+// 返回false时应以返回的退出码结束
+func (c *command) parse(fs *flag.FlagSet, args []string, minArgs, maxArgs int) (int, bool) {
+	fs.SetOutput(c.stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if fs.NArg() < minArgs || (maxArgs >= 0 && fs.NArg() > maxArgs) {
+		fs.Usage()
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+func (c *command) flagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: policyctl %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (c *command) fail(err error) int {
+	fmt.Fprintf(c.stderr, "policyctl: %v\n", err)
+	return exitFailed
+}
+
+// validate 加载过滤配置并报告错误和策略问题
+//
+// 被ConfigLoader跳过的无效条目总是导致失败，-strict只对警告生效。
+func (c *command) validate(args []string) int {
+	fs := c.flagSet("validate", "[-strict] [-o text|json] <env-file>")
+	strict := fs.Bool("strict", false, "also fail on warnings (shadowed or redundant rules, filtering disabled)")
+	output := fs.String("o", "text", "output format: text or json")
+	if code, ok := c.parse(fs, args, 1, 1); !ok {
+		return code
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(c.stderr, "policyctl: unknown output format %q\n", *output)
+		return exitUsage
+	}
+
+	p, err := c.loadPolicy(fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+	report := ipfilter.Analyze(p.cfg)
+
+	if *output == "json" {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(struct {
+			File      string             `json:"file"`
+			Mode      string             `json:"mode"`
+			Whitelist int                `json:"whitelist"`
+			Blacklist int                `json:"blacklist"`
+			Feeds     int                `json:"feeds"`
+			Errors    []string           `json:"errors,omitempty"`
+			Warnings  []string           `json:"warnings,omitempty"`
+			Findings  []analyzer.Finding `json:"findings"`
+		}{p.path, p.cfg.Mode.String(), len(p.cfg.Whitelist), len(p.cfg.Blacklist), len(p.cfg.Feeds), p.invalid, p.warnings, report.Findings}); err != nil {
+			return c.fail(err)
+		}
+	} else {
+		fmt.Fprintf(c.stdout, "%s: mode=%s, whitelist=%d rules, blacklist=%d rules, feeds=%d\n",
+			p.path, p.cfg.Mode, len(p.cfg.Whitelist), len(p.cfg.Blacklist), len(p.cfg.Feeds))
+		for _, e := range p.invalid {
+			fmt.Fprintf(c.stdout, "error: %s\n", e)
+		}
+		for _, w := range p.warnings {
+			fmt.Fprintf(c.stdout, "warning: %s\n", w)
+		}
+		fmt.Fprintln(c.stdout, report.String())
+	}
+
+	if len(p.invalid) > 0 || report.AtLeast(analyzer.SeverityError) {
+		return exitFailed
+	}
+	if *strict && (len(p.warnings) > 0 || report.AtLeast(analyzer.SeverityWarning)) {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// eval 输出IP地址的判定结果和匹配的规则
+func (c *command) eval(args []string) int {
+	fs := c.flagSet("eval", "[-expect allow|deny] [-f ip-file] <env-file> [ip...]")
+	expect := fs.String("expect", "", "fail unless every address gets this decision (allow or deny)")
+	file := fs.String("f", "", "read addresses from a file, one per line ('-' for stdin)")
+	if code, ok := c.parse(fs, args, 1, -1); !ok {
+		return code
+	}
+	if *expect != "" && *expect != string(analyzer.ActionAllow) && *expect != string(analyzer.ActionDeny) {
+		fmt.Fprintf(c.stderr, "policyctl: -expect must be allow or deny, got %q\n", *expect)
+		return exitUsage
+	}
+
+	addrs := fs.Args()[1:]
+	if *file != "" {
+		lines, err := c.readLines(*file)
+		if err != nil {
+			return c.fail(err)
+		}
+		addrs = append(addrs, lines...)
+	}
+	if len(addrs) == 0 {
+		fmt.Fprintln(c.stderr, "policyctl: no addresses to evaluate")
+		return exitUsage
+	}
+
+	p, err := c.loadPolicy(fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+	matcher := ipfilter.NewRuleMatcher(p.cfg)
+
+	code := exitOK
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	for _, s := range addrs {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			fmt.Fprintf(w, "%s\terror\tinvalid IP address\n", s)
+			code = exitFailed
+			continue
+		}
+		allowed, reason := matcher.IsAllowed(ip)
+		decision := string(analyzer.ActionDeny)
+		if allowed {
+			decision = string(analyzer.ActionAllow)
+		}
+		if *expect != "" && decision != *expect {
+			reason += fmt.Sprintf(" (expected %s)", *expect)
+			code = exitFailed
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s, decision, reason)
+	}
+	if err := w.Flush(); err != nil {
+		return c.fail(err)
+	}
+	return code
+}
+
+// diff 输出两个版本之间判定结果发生变化的地址范围
+func (c *command) diff(args []string) int {
+	fs := c.flagSet("diff", "[-exit-code] <old-env-file> <new-env-file>")
+	exitCode := fs.Bool("exit-code", false, "exit with 1 if any decision changes")
+	if code, ok := c.parse(fs, args, 2, 2); !ok {
+		return code
+	}
+
+	before, err := c.loadPolicy(fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+	after, err := c.loadPolicy(fs.Arg(1))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	changes := ipfilter.Diff(before.cfg, after.cfg)
+	if len(changes) == 0 {
+		fmt.Fprintln(c.stdout, "no decision changes")
+		return exitOK
+	}
+	for _, change := range changes {
+		fmt.Fprintln(c.stdout, change)
+	}
+	if *exitCode {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// acl 输出与过滤配置等价的VPP ACL规则
+func (c *command) acl(args []string) int {
+	fs := c.flagSet("acl", "[-o text|yaml] <env-file>")
+	output := fs.String("o", "text", "output format: text or yaml (ACL config file format)")
+	if code, ok := c.parse(fs, args, 1, 1); !ok {
+		return code
+	}
+	if *output != "text" && *output != "yaml" {
+		fmt.Fprintf(c.stderr, "policyctl: unknown output format %q\n", *output)
+		return exitUsage
+	}
+
+	p, err := c.loadPolicy(fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+	entries := ipfilter.ToACLRules(p.cfg)
+
+	if *output == "yaml" {
+		data, err := yaml.Marshal(aclConfig(entries))
+		if err != nil {
+			return c.fail(err)
+		}
+		if _, err := c.stdout.Write(data); err != nil {
+			return c.fail(err)
+		}
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tACTION\tSRC\tDST\tNAME")
+	for i, e := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i, actionName(e.Rule.IsPermit), e.Rule.SrcPrefix, e.Rule.DstPrefix, e.Name)
+	}
+	if err := w.Flush(); err != nil {
+		return c.fail(err)
+	}
+	return exitOK
+}
+
+// aclConfig 按ACL配置文件的格式（规则名称到ACLRule字段的映射）输出规则，保持匹配顺序
+func aclConfig(entries []ipfilter.ACLEntry) yaml.MapSlice {
+	config := make(yaml.MapSlice, 0, len(entries))
+	for _, e := range entries {
+		config = append(config, yaml.MapItem{
+			Key: e.Name,
+			Value: yaml.MapSlice{
+				{Key: "ispermit", Value: uint8(e.Rule.IsPermit)},
+				{Key: "srcprefix", Value: e.Rule.SrcPrefix.String()},
+				{Key: "dstprefix", Value: e.Rule.DstPrefix.String()},
+				{Key: "srcportoricmptypelast", Value: e.Rule.SrcportOrIcmptypeLast},
+				{Key: "dstportoricmpcodelast", Value: e.Rule.DstportOrIcmpcodeLast},
+			},
+		})
+	}
+	return config
+}
+
+func actionName(action acl_types.ACLAction) string {
+	switch action {
+	case acl_types.ACL_ACTION_API_PERMIT:
+		return "permit"
+	case acl_types.ACL_ACTION_API_PERMIT_REFLECT:
+		return "permit-reflect"
+	default:
+		return "deny"
+	}
+}
+
+// readLines 读取文件中的非空行（忽略#注释），path为"-"时读取标准输入
+func (c *command) readLines(path string) ([]string, error) {
+	r := c.stdin
+	if path != "-" {
+		f, err := os.Open(path) // #nosec G304 -- 路径由用户在命令行指定
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// policyctl 离线检查IP Filter NSE的过滤配置
+//
+// 过滤配置以env文件的形式提供（与部署清单中的环境变量相同），
+// 使用与NSE相同的ConfigLoader加载，因此可以在CI中检查ConfigMap。
+//
+// 用法：
+//
+//	policyctl validate [-strict] [-o text|json] <env-file>
+//	policyctl eval [-expect allow|deny] [-f ip-file] <env-file> [ip...]
+//	policyctl diff [-exit-code] <old-env-file> <new-env-file>
+//	policyctl acl [-o text|yaml] <env-file>
+//
+// 退出码：0表示成功，1表示检查未通过或加载失败，2表示参数错误。
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+const usage = `Usage: policyctl <command> [flags] <env-file> ...
+
+Commands:
+  validate  load the filter config and report errors and policy issues
+  eval      print the decision and matched rule for IP addresses
+  diff      print the address ranges whose decision changes between two configs
+  acl       print the VPP ACL rules equivalent to the filter config
+
+The env file contains KEY=VALUE lines with the IPFILTER_* settings
+(NSM_IPFILTER_* and NSM_IP_FILTER_* keys are accepted as well).
+
+Run 'policyctl <command> -h' for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 执行子命令并返回退出码
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	cmd := &command{ctx: ctx, stdin: stdin, stdout: stdout, stderr: stderr}
+	switch args[0] {
+	case "validate":
+		return cmd.validate(args[1:])
+	case "eval":
+		return cmd.eval(args[1:])
+	case "diff":
+		return cmd.diff(args[1:])
+	case "acl":
+		return cmd.acl(args[1:])
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "policyctl: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+}
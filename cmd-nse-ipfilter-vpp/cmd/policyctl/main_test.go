@@ -0,0 +1,165 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func writeEnvFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "ipfilter.env")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func runCmd(t *testing.T, stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(context.Background(), args, strings.NewReader(stdin), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestReadEnvFile(t *testing.T) {
+	path := writeEnvFile(t, `# ipfilter ConfigMap
+NSM_IP_FILTER_MODE=both
+export NSM_IPFILTER_WHITELIST="192.168.1.0/24"
+IPFILTER_BLACKLIST='192.168.1.100'
+
+`)
+	env, err := readEnvFile(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"IPFILTER_MODE":      "both",
+		"IPFILTER_WHITELIST": "192.168.1.0/24",
+		"IPFILTER_BLACKLIST": "192.168.1.100",
+	}, env)
+
+	_, err = readEnvFile(writeEnvFile(t, "IPFILTER_MODE=both\nbogus\n"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 2")
+}
+
+func TestValidate(t *testing.T) {
+	clean := writeEnvFile(t, "IPFILTER_MODE=both\nIPFILTER_WHITELIST=192.168.1.0/24\nIPFILTER_BLACKLIST=192.168.1.100\n")
+	code, stdout, _ := runCmd(t, "", "validate", "-strict", clean)
+	require.Equal(t, exitOK, code, stdout)
+	require.Contains(t, stdout, "mode=both, whitelist=1 rules, blacklist=1 rules")
+
+	// 无效条目被ConfigLoader跳过：不加-strict也失败
+	invalid := writeEnvFile(t, "IPFILTER_WHITELIST=192.168.1.0/24,bogus\n")
+	code, stdout, _ = runCmd(t, "", "validate", invalid)
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stdout, "error: Invalid IP/CIDR: bogus (skipped)")
+	code, stdout, _ = runCmd(t, "", "validate", "-o", "json", invalid)
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stdout, `"errors": [`)
+
+	// 警告只在-strict时失败
+	disabled := writeEnvFile(t, "IPFILTER_MODE=whitelist\n")
+	code, stdout, _ = runCmd(t, "", "validate", disabled)
+	require.Equal(t, exitOK, code)
+	require.Contains(t, stdout, "warning: no IPFILTER_WHITELIST")
+	code, _, _ = runCmd(t, "", "validate", "-strict", disabled)
+	require.Equal(t, exitFailed, code)
+
+	shadowed := writeEnvFile(t, "IPFILTER_MODE=both\nIPFILTER_WHITELIST=10.1.0.0/16\nIPFILTER_BLACKLIST=10.0.0.0/8\n")
+	code, stdout, _ = runCmd(t, "", "validate", "-strict", "-o", "json", shadowed)
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stdout, `"kind": "shadowed"`)
+
+	code, _, stderr := runCmd(t, "", "validate", writeEnvFile(t, "IPFILTER_MODE=strict\n"))
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stderr, "invalid IPFILTER_MODE")
+}
+
+func TestEval(t *testing.T) {
+	path := writeEnvFile(t, "IPFILTER_MODE=both\nIPFILTER_WHITELIST=192.168.1.0/24\nIPFILTER_BLACKLIST=192.168.1.100\n")
+
+	code, stdout, _ := runCmd(t, "10.0.0.1\n# comment\n", "eval", "-f", "-", path, "192.168.1.10", "192.168.1.100")
+	require.Equal(t, exitOK, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 3)
+	require.Regexp(t, `^192\.168\.1\.10\s+allow\s+whitelist rule: 192\.168\.1\.0/24$`, lines[0])
+	require.Regexp(t, `^192\.168\.1\.100\s+deny\s+blacklist rule: 192\.168\.1\.100$`, lines[1])
+	require.Regexp(t, `^10\.0\.0\.1\s+deny\s+not in whitelist$`, lines[2])
+
+	code, stdout, _ = runCmd(t, "", "eval", "-expect", "allow", path, "192.168.1.10", "192.168.1.100")
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stdout, "(expected allow)")
+
+	code, _, _ = runCmd(t, "", "eval", path, "not-an-ip")
+	require.Equal(t, exitFailed, code)
+
+	code, _, _ = runCmd(t, "", "eval", path)
+	require.Equal(t, exitUsage, code)
+}
+
+func TestDiff(t *testing.T) {
+	before := writeEnvFile(t, "IPFILTER_MODE=blacklist\nIPFILTER_BLACKLIST=10.0.0.0/8\n")
+	after := writeEnvFile(t, "IPFILTER_MODE=blacklist\nIPFILTER_BLACKLIST=10.0.0.0/8,192.168.1.100\n")
+
+	code, stdout, _ := runCmd(t, "", "diff", before, after)
+	require.Equal(t, exitOK, code)
+	require.Equal(t, "192.168.1.100/32: allow -> deny\n", stdout)
+
+	code, _, _ = runCmd(t, "", "diff", "-exit-code", before, after)
+	require.Equal(t, exitFailed, code)
+
+	code, stdout, _ = runCmd(t, "", "diff", "-exit-code", before, before)
+	require.Equal(t, exitOK, code)
+	require.Equal(t, "no decision changes\n", stdout)
+}
+
+func TestACL(t *testing.T) {
+	path := writeEnvFile(t, "IPFILTER_MODE=both\nIPFILTER_WHITELIST=192.168.1.0/24\nIPFILTER_BLACKLIST=192.168.1.100\n")
+
+	code, stdout, _ := runCmd(t, "", "acl", path)
+	require.Equal(t, exitOK, code)
+	require.Regexp(t, `(?m)^0\s+deny\s+192\.168\.1\.100/32\s+0\.0\.0\.0/0\s+blacklist-0`, stdout)
+
+	// YAML输出可以被ACL配置文件的加载逻辑读回
+	code, stdout, _ = runCmd(t, "", "acl", "-o", "yaml", path)
+	require.Equal(t, exitOK, code)
+	var rules map[string]acl_types.ACLRule
+	require.NoError(t, yaml.Unmarshal([]byte(stdout), &rules))
+	require.Len(t, rules, 4)
+	permit := rules["whitelist-0 192.168.1.0/24"]
+	require.Equal(t, acl_types.ACL_ACTION_API_PERMIT, permit.IsPermit)
+	require.Equal(t, "192.168.1.0/24", permit.SrcPrefix.String())
+	require.Equal(t, uint16(65535), permit.DstportOrIcmpcodeLast)
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runCmd(t, "")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, "Usage: policyctl")
+
+	code, _, _ = runCmd(t, "", "explode")
+	require.Equal(t, exitUsage, code)
+}
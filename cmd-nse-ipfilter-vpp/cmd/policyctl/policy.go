@@ -0,0 +1,137 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/internal/ipfilter"
+)
+
+// NSE配置默认使用白名单模式（见pkg/config.Config.IPFilterMode）
+const defaultMode = "whitelist"
+
+// policy 从env文件加载的过滤配置
+type policy struct {
+	path string
+	cfg  *ipfilter.FilterConfig
+
+	// invalid ConfigLoader跳过的无效条目（validate视为错误）
+	invalid []string
+
+	// warnings 不影响加载结果的提示（例如没有设置任何过滤列表）
+	warnings []string
+}
+
+// warningHook 收集ConfigLoader的警告日志
+type warningHook struct {
+	messages []string
+}
+
+func (h *warningHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+func (h *warningHook) Fire(entry *logrus.Entry) error {
+	h.messages = append(h.messages, entry.Message)
+	return nil
+}
+
+// loadPolicy 读取env文件并使用ConfigLoader加载过滤配置
+func (c *command) loadPolicy(path string) (*policy, error) {
+	env, err := readEnvFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := env["IPFILTER_MODE"]; !ok {
+		env["IPFILTER_MODE"] = defaultMode
+	}
+
+	hook := &warningHook{}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(hook)
+
+	cfg, err := ipfilter.NewConfigLoader(logger).Load(c.ctx, func(key string) string { return env[key] })
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	p := &policy{path: path, cfg: cfg, invalid: hook.messages}
+	if env["IPFILTER_WHITELIST"] == "" && env["IPFILTER_BLACKLIST"] == "" && env["IPFILTER_FEEDS"] == "" {
+		p.warnings = append(p.warnings, "no IPFILTER_WHITELIST, IPFILTER_BLACKLIST or IPFILTER_FEEDS set: IP filtering is disabled in the NSE")
+	}
+	return p, nil
+}
+
+// readEnvFile 解析env文件
+//
+// 每行一个KEY=VALUE，忽略空行、#注释和export前缀，值两侧的引号会被去除。
+// NSM_IPFILTER_*和NSM_IP_FILTER_*键被规范化为ConfigLoader使用的IPFILTER_*。
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path) // #nosec G304 -- 路径由用户在命令行指定
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s: line %d: expected KEY=VALUE", path, line)
+		}
+		env[normalizeKey(strings.TrimSpace(key))] = unquote(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return env, nil
+}
+
+func normalizeKey(key string) string {
+	for _, prefix := range []string{"NSM_IP_FILTER_", "NSM_IPFILTER_"} {
+		if strings.HasPrefix(key, prefix) {
+			return "IPFILTER_" + strings.TrimPrefix(key, prefix)
+		}
+	}
+	return key
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package ipfilter
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/networkservicemesh/govpp/binapi/ip_types"
+)
+
+// ACLEntry 带名称的VPP ACL规则
+type ACLEntry struct {
+	// Name 规则名称（与ACL配置文件中的键格式相同）
+	Name string
+
+	// Rule VPP ACL规则
+	Rule acl_types.ACLRule
+}
+
+// ToACLRules 将过滤配置转换为等价的VPP ACL规则列表（按匹配顺序）
+//
+// 顺序与RuleMatcher.IsAllowed一致：黑名单（deny）、白名单（permit），
+// 最后是IPv4和IPv6的默认规则。目标地址、协议和端口均为通配符。
+func ToACLRules(cfg *FilterConfig) []ACLEntry {
+	entries := make([]ACLEntry, 0, len(cfg.Blacklist)+len(cfg.Whitelist)+2)
+	for i, r := range cfg.Blacklist {
+		if r.Network == nil {
+			continue
+		}
+		entries = append(entries, ACLEntry{
+			Name: fmt.Sprintf("blacklist-%d %s", i, r.Network),
+			Rule: sourceACLRule(*r.Network, acl_types.ACL_ACTION_API_DENY),
+		})
+	}
+	for i, r := range cfg.Whitelist {
+		if r.Network == nil {
+			continue
+		}
+		entries = append(entries, ACLEntry{
+			Name: fmt.Sprintf("whitelist-%d %s", i, r.Network),
+			Rule: sourceACLRule(*r.Network, acl_types.ACL_ACTION_API_PERMIT),
+		})
+	}
+
+	// 默认动作与IsAllowed相同：白名单非空或非blacklist模式时拒绝
+	action, name := acl_types.ACL_ACTION_API_DENY, "default-deny"
+	if len(cfg.Whitelist) == 0 && cfg.Mode == FilterModeBlacklist {
+		action, name = acl_types.ACL_ACTION_API_PERMIT, "default-permit"
+	}
+	for _, cidr := range []string{"0.0.0.0/0", "::/0"} {
+		_, all, _ := net.ParseCIDR(cidr)
+		entries = append(entries, ACLEntry{
+			Name: fmt.Sprintf("%s %s", name, cidr),
+			Rule: sourceACLRule(*all, action),
+		})
+	}
+	return entries
+}
+
+// sourceACLRule 创建只匹配源网段的ACL规则，其他字段为通配符
+func sourceACLRule(src net.IPNet, action acl_types.ACLAction) acl_types.ACLRule {
+	dst := net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}
+	if src.IP.To4() == nil {
+		dst = net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}
+	}
+	return acl_types.ACLRule{
+		IsPermit:              action,
+		SrcPrefix:             ip_types.NewPrefix(src),
+		DstPrefix:             ip_types.NewPrefix(dst),
+		SrcportOrIcmptypeLast: 65535,
+		DstportOrIcmpcodeLast: 65535,
+	}
+}
@@ -0,0 +1,54 @@
+package ipfilter_test
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/govpp/binapi/acl_types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/internal/ipfilter"
+)
+
+func TestToACLRules(t *testing.T) {
+	cl := ipfilter.NewConfigLoader(logrus.New())
+	whitelist, err := cl.ParseIPListPublic("192.168.1.0/24,2001:db8::/32")
+	require.NoError(t, err)
+	blacklist, err := cl.ParseIPListPublic("192.168.1.100")
+	require.NoError(t, err)
+
+	entries := ipfilter.ToACLRules(&ipfilter.FilterConfig{
+		Mode:      ipfilter.FilterModeBoth,
+		Whitelist: whitelist,
+		Blacklist: blacklist,
+	})
+	require.Len(t, entries, 5)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	require.Equal(t, []string{
+		"blacklist-0 192.168.1.100/32",
+		"whitelist-0 192.168.1.0/24",
+		"whitelist-1 2001:db8::/32",
+		"default-deny 0.0.0.0/0",
+		"default-deny ::/0",
+	}, names, "黑名单优先，默认规则最后")
+
+	deny := entries[0].Rule
+	require.Equal(t, acl_types.ACL_ACTION_API_DENY, deny.IsPermit)
+	require.Equal(t, "192.168.1.100/32", deny.SrcPrefix.String())
+	require.Equal(t, "0.0.0.0/0", deny.DstPrefix.String())
+	require.Equal(t, uint16(65535), deny.DstportOrIcmpcodeLast, "端口范围必须是通配符")
+
+	require.Equal(t, "::/0", entries[2].Rule.DstPrefix.String(), "IPv6规则的目标地址族必须一致")
+	require.Equal(t, acl_types.ACL_ACTION_API_PERMIT, entries[2].Rule.IsPermit)
+}
+
+func TestToACLRules_BlacklistModeDefaultPermit(t *testing.T) {
+	entries := ipfilter.ToACLRules(&ipfilter.FilterConfig{Mode: ipfilter.FilterModeBlacklist})
+	require.Len(t, entries, 2)
+	require.Equal(t, "default-permit 0.0.0.0/0", entries[0].Name)
+	require.Equal(t, acl_types.ACL_ACTION_API_PERMIT, entries[1].Rule.IsPermit)
+}
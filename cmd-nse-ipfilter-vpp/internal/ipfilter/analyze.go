@@ -0,0 +1,93 @@
+package ipfilter
+
+import (
+	"net"
+	"net/netip"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/analyzer"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ipset"
+)
+
+// Analyze 对过滤配置进行静态分析
+//
+// 默认动作按RuleMatcher.IsAllowed的语义推导：白名单非空或模式为whitelist/both时
+// 默认拒绝，否则默认允许。blacklist模式下配置了白名单时额外报告模式问题。
+func Analyze(cfg *FilterConfig) *analyzer.Report {
+	policy := analyzer.Policy{
+		Allow:         toAnalyzerRules(cfg.Whitelist),
+		Deny:          toAnalyzerRules(cfg.Blacklist),
+		DefaultAction: analyzer.ActionDeny,
+	}
+	if len(cfg.Whitelist) == 0 && cfg.Mode == FilterModeBlacklist {
+		policy.DefaultAction = analyzer.ActionAllow
+	}
+
+	report := analyzer.Analyze(policy)
+	if cfg.Mode == FilterModeBlacklist && len(cfg.Whitelist) > 0 {
+		report.Findings = append([]analyzer.Finding{{
+			Kind:     analyzer.KindMode,
+			Severity: analyzer.SeverityWarning,
+			List:     analyzer.ListAllow,
+			Index:    0,
+			Rule:     cfg.Whitelist[0].Description,
+			Message:  "whitelist is not empty in blacklist mode: traffic not in the whitelist is denied",
+		}}, report.Findings...)
+	}
+	return report
+}
+
+// Diff 比较两个过滤配置，返回判定结果发生变化的地址范围
+//
+// 判定使用RuleMatcher.IsAllowed，与NSE运行时的匹配逻辑一致。
+func Diff(before, after *FilterConfig) []analyzer.Change {
+	var boundaries []netip.Prefix
+	for _, cfg := range []*FilterConfig{before, after} {
+		for _, rules := range [][]IPFilterRule{cfg.Blacklist, cfg.Whitelist} {
+			for _, r := range toAnalyzerRules(rules) {
+				boundaries = append(boundaries, r.Prefix)
+			}
+		}
+	}
+	return analyzer.Diff(boundaries, decider(before), decider(after))
+}
+
+func decider(cfg *FilterConfig) analyzer.Decider {
+	matcher := NewRuleMatcher(cfg)
+	return func(addr netip.Addr) analyzer.Action {
+		if allowed, _ := matcher.IsAllowed(net.IP(addr.AsSlice())); allowed {
+			return analyzer.ActionAllow
+		}
+		return analyzer.ActionDeny
+	}
+}
+
+// LogReport 按严重程度记录分析报告中的问题
+func LogReport(log *logrus.Logger, report *analyzer.Report) {
+	for _, f := range report.Findings {
+		switch f.Severity {
+		case analyzer.SeverityError:
+			log.Errorf("IP Filter policy: %s", f)
+		case analyzer.SeverityWarning:
+			log.Warnf("IP Filter policy: %s", f)
+		default:
+			log.Infof("IP Filter policy: %s", f)
+		}
+	}
+}
+
+func toAnalyzerRules(rules []IPFilterRule) []analyzer.Rule {
+	result := make([]analyzer.Rule, 0, len(rules))
+	for _, r := range rules {
+		if r.Network == nil {
+			continue
+		}
+		prefix, ok := ipset.FromIPNet(*r.Network)
+		if !ok {
+			continue
+		}
+		result = append(result, analyzer.Rule{Prefix: prefix, Text: r.Description})
+	}
+	return result
+}
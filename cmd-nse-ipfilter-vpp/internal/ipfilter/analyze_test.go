@@ -0,0 +1,68 @@
+package ipfilter_test
+
+import (
+	"testing"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/internal/ipfilter"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/analyzer"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze_FilterConfig(t *testing.T) {
+	cl := ipfilter.NewConfigLoader(logrus.New())
+	whitelist, err := cl.ParseIPListPublic("10.1.0.0/16,192.168.1.0/25,192.168.1.128/25")
+	require.NoError(t, err)
+	blacklist, err := cl.ParseIPListPublic("10.0.0.0/8")
+	require.NoError(t, err)
+
+	report := ipfilter.Analyze(&ipfilter.FilterConfig{
+		Mode:      ipfilter.FilterModeBoth,
+		Whitelist: whitelist,
+		Blacklist: blacklist,
+	})
+
+	shadowed := report.ByKind(analyzer.KindShadowed)
+	require.Len(t, shadowed, 1, report.String())
+	require.Equal(t, "10.1.0.0/16", shadowed[0].Rule)
+
+	agg := report.ByKind(analyzer.KindAggregatable)
+	require.Len(t, agg, 1)
+	require.Equal(t, "192.168.1.0/24", agg[0].Suggestion)
+	require.Zero(t, report.Count(analyzer.KindMode))
+}
+
+func TestAnalyze_ModeMismatch(t *testing.T) {
+	cl := ipfilter.NewConfigLoader(logrus.New())
+	whitelist, err := cl.ParseIPListPublic("192.168.1.0/24")
+	require.NoError(t, err)
+
+	report := ipfilter.Analyze(&ipfilter.FilterConfig{
+		Mode:      ipfilter.FilterModeBlacklist,
+		Whitelist: whitelist,
+	})
+	require.Equal(t, 1, report.Count(analyzer.KindMode), report.String())
+
+	// 白名单模式且白名单为空：黑名单条目不起作用
+	blacklist, err := cl.ParseIPListPublic("10.0.0.1")
+	require.NoError(t, err)
+	report = ipfilter.Analyze(&ipfilter.FilterConfig{
+		Mode:      ipfilter.FilterModeWhitelist,
+		Blacklist: blacklist,
+	})
+	require.Equal(t, 1, report.Count(analyzer.KindIneffective), report.String())
+}
+
+func TestDiff_FilterConfig(t *testing.T) {
+	cl := ipfilter.NewConfigLoader(logrus.New())
+	blacklist, err := cl.ParseIPListPublic("192.168.1.100")
+	require.NoError(t, err)
+
+	before := &ipfilter.FilterConfig{Mode: ipfilter.FilterModeBlacklist}
+	after := &ipfilter.FilterConfig{Mode: ipfilter.FilterModeBlacklist, Blacklist: blacklist}
+
+	changes := ipfilter.Diff(before, after)
+	require.Len(t, changes, 1)
+	require.Equal(t, "192.168.1.100/32: allow -> deny", changes[0].String())
+	require.Empty(t, ipfilter.Diff(after, after))
+}
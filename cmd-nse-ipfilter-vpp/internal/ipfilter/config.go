@@ -9,22 +9,43 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/feed"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ipset"
 )
 
 // ConfigLoader 配置加载器
 type ConfigLoader struct {
-	log *logrus.Logger
+	log    *logrus.Logger
+	getenv func(string) string
 }
 
-// NewConfigLoader 创建配置加载器
+// NewConfigLoader 创建从环境变量读取IPFILTER_*配置项的配置加载器
 func NewConfigLoader(log *logrus.Logger) *ConfigLoader {
 	return &ConfigLoader{
-		log: log,
+		log:    log,
+		getenv: os.Getenv,
 	}
 }
 
-// LoadFromEnv 从环境变量加载配置
+// WithSource 设置LoadFromEnv、WatchedFiles和Watch读取配置项的来源并返回加载器
+//
+// getenv与os.Getenv语义相同，NSE传入已加载配置的取值函数，使重新加载使用同一份配置。
+func (cl *ConfigLoader) WithSource(getenv func(string) string) *ConfigLoader {
+	cl.getenv = getenv
+	return cl
+}
+
+// LoadFromEnv 从加载器的配置来源（默认为环境变量）加载配置
 func (cl *ConfigLoader) LoadFromEnv(ctx context.Context) (*FilterConfig, error) {
+	return cl.Load(ctx, cl.getenv)
+}
+
+// Load 通过getenv读取IPFILTER_*配置项并加载配置
+//
+// getenv与os.Getenv语义相同，未设置的配置项返回空字符串。
+// 离线工具可以传入从env文件或ConfigMap读取的配置，与NSE使用同一套加载逻辑。
+func (cl *ConfigLoader) Load(ctx context.Context, getenv func(string) string) (*FilterConfig, error) {
 	cfg := &FilterConfig{
 		Mode:      FilterModeBoth, // 默认值
 		Whitelist: []IPFilterRule{},
@@ -32,7 +53,7 @@ func (cl *ConfigLoader) LoadFromEnv(ctx context.Context) (*FilterConfig, error)
 	}
 
 	// 加载过滤模式
-	if mode := os.Getenv("IPFILTER_MODE"); mode != "" {
+	if mode := getenv("IPFILTER_MODE"); mode != "" {
 		switch strings.ToLower(mode) {
 		case "whitelist":
 			cfg.Mode = FilterModeWhitelist
@@ -46,7 +67,7 @@ func (cl *ConfigLoader) LoadFromEnv(ctx context.Context) (*FilterConfig, error)
 	}
 
 	// 加载白名单
-	if whitelist := os.Getenv("IPFILTER_WHITELIST"); whitelist != "" {
+	if whitelist := getenv("IPFILTER_WHITELIST"); whitelist != "" {
 		rules, err := cl.parseRules(whitelist)
 		if err != nil {
 			return nil, fmt.Errorf("invalid IPFILTER_WHITELIST: %w", err)
@@ -55,7 +76,7 @@ func (cl *ConfigLoader) LoadFromEnv(ctx context.Context) (*FilterConfig, error)
 	}
 
 	// 加载黑名单
-	if blacklist := os.Getenv("IPFILTER_BLACKLIST"); blacklist != "" {
+	if blacklist := getenv("IPFILTER_BLACKLIST"); blacklist != "" {
 		rules, err := cl.parseRules(blacklist)
 		if err != nil {
 			return nil, fmt.Errorf("invalid IPFILTER_BLACKLIST: %w", err)
@@ -63,14 +84,59 @@ func (cl *ConfigLoader) LoadFromEnv(ctx context.Context) (*FilterConfig, error)
 		cfg.Blacklist = rules
 	}
 
+	// 加载外部订阅源（target:format:path，逗号分隔）
+	if feeds := getenv("IPFILTER_FEEDS"); feeds != "" {
+		sources, err := feed.ParseSpecs(feeds)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IPFILTER_FEEDS: %w", err)
+		}
+		if err := cl.LoadFeeds(cfg, sources); err != nil {
+			return nil, err
+		}
+	}
+
 	// 加载日志级别（可选）
-	if logLevel := os.Getenv("IPFILTER_LOG_LEVEL"); logLevel != "" {
+	if logLevel := getenv("IPFILTER_LOG_LEVEL"); logLevel != "" {
 		cfg.LogLevel = logLevel
 	}
 
 	return cfg, nil
 }
 
+// LoadFeeds 加载外部订阅源并合并到配置的白名单/黑名单
+//
+// 每个订阅源的条目被规范化、聚合后追加到目标列表，规则描述标记来源文件。
+// 订阅源中的无效行只记录警告；文件无法读取时返回错误。
+func (cl *ConfigLoader) LoadFeeds(cfg *FilterConfig, sources []feed.Source) error {
+	for _, src := range sources {
+		result, err := feed.Load(src)
+		if err != nil {
+			return fmt.Errorf("failed to load feed: %w", err)
+		}
+
+		cl.log.Infof("Loaded feed %s (format=%s, target=%s): %d entries, %d invalid, %d prefixes after aggregation",
+			src.Path, src.Format, src.Target, result.Entries, result.Invalid, len(result.Prefixes))
+		for _, msg := range result.Errors {
+			cl.log.Warnf("Feed %s: %s (skipped)", src.Path, msg)
+		}
+
+		rules := make([]IPFilterRule, 0, len(result.Prefixes))
+		for _, p := range result.Prefixes {
+			rules = append(rules, IPFilterRule{
+				Network:     ipset.ToIPNet(p),
+				Description: fmt.Sprintf("%s (feed: %s)", p, src.Name()),
+			})
+		}
+		if src.Target == feed.TargetAllow {
+			cfg.Whitelist = append(cfg.Whitelist, rules...)
+		} else {
+			cfg.Blacklist = append(cfg.Blacklist, rules...)
+		}
+		cfg.Feeds = append(cfg.Feeds, src)
+	}
+	return nil
+}
+
 // parseRules 解析规则字符串（逗号分隔或YAML文件路径）
 func (cl *ConfigLoader) parseRules(value string) ([]IPFilterRule, error) {
 	// 判断是否为文件路径
@@ -123,6 +189,9 @@ func (cl *ConfigLoader) ParseIPListPublic(ipList string) ([]IPFilterRule, error)
 }
 
 // LoadRulesFromYAMLPublic 从YAML文件加载规则（公开方法用于测试）
+//
+// 文件可以在ipfilter.ipSets中定义命名IP集合，并在whitelist/blacklist中
+// 通过"@名称"引用；引用会被展开并去重。未定义的引用和循环引用返回错误。
 func (cl *ConfigLoader) LoadRulesFromYAMLPublic(filePath string) ([]IPFilterRule, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -132,7 +201,8 @@ func (cl *ConfigLoader) LoadRulesFromYAMLPublic(filePath string) ([]IPFilterRule
 	var yamlCfg struct {
 		IPFilter struct {
 			Whitelist []string `yaml:"whitelist"`
-			Blacklist []string `yaml:"blacklist"`
+			Blacklist []string                    `yaml:"blacklist"`
+			IPSets    map[string]ipset.Definition `yaml:"ipSets"`
 		} `yaml:"ipfilter"`
 	}
 
@@ -145,5 +215,49 @@ func (cl *ConfigLoader) LoadRulesFromYAMLPublic(filePath string) ([]IPFilterRule
 	ipList = append(ipList, yamlCfg.IPFilter.Whitelist...)
 	ipList = append(ipList, yamlCfg.IPFilter.Blacklist...)
 
-	return cl.ParseIPListPublic(strings.Join(ipList, ","))
+	if len(yamlCfg.IPFilter.IPSets) == 0 && !ipset.HasRefs(ipList) {
+		return cl.ParseIPListPublic(strings.Join(ipList, ","))
+	}
+	return cl.expandIPSets(yamlCfg.IPFilter.IPSets, ipList)
+}
+
+// expandIPSets 展开IP集合引用并去重，转换为过滤规则
+func (cl *ConfigLoader) expandIPSets(defs map[string]ipset.Definition, ipList []string) ([]IPFilterRule, error) {
+	resolver := ipset.NewResolver(defs)
+	if err := resolver.ResolveAll(); err != nil {
+		return nil, fmt.Errorf("invalid ipSets: %w", err)
+	}
+
+	var entries []ipset.Entry
+	for _, item := range ipList {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !ipset.IsRef(item) {
+			p, err := ipset.ParsePrefix(item)
+			if err != nil {
+				cl.log.Warnf("Invalid IP/CIDR: %s (skipped)", item)
+				continue
+			}
+			entries = append(entries, ipset.Entry{Prefix: p})
+			continue
+		}
+
+		expanded, err := resolver.Expand([]string{item})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, expanded...)
+	}
+
+	entries = ipset.Dedupe(entries)
+	rules := make([]IPFilterRule, 0, len(entries))
+	for _, e := range entries {
+		rules = append(rules, IPFilterRule{
+			Network:     ipset.ToIPNet(e.Prefix),
+			Description: e.String(),
+		})
+	}
+	return rules, nil
 }
\ No newline at end of file
@@ -138,6 +138,26 @@ func TestConfigLoader_LoadFromEnv_InvalidMode(t *testing.T) {
 	require.Contains(t, err.Error(), "invalid IPFILTER_MODE")
 }
 
+func TestConfigLoader_Load_Getenv(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(os.Stdout)
+	cl := ipfilter.NewConfigLoader(log)
+
+	// 不读取进程环境变量，只使用传入的配置
+	os.Setenv("IPFILTER_MODE", "invalid")
+	defer os.Unsetenv("IPFILTER_MODE")
+
+	env := map[string]string{
+		"IPFILTER_MODE":      "whitelist",
+		"IPFILTER_WHITELIST": "192.168.1.0/24",
+	}
+	cfg, err := cl.Load(context.Background(), func(key string) string { return env[key] })
+	require.NoError(t, err)
+	require.Equal(t, ipfilter.FilterModeWhitelist, cfg.Mode)
+	require.Len(t, cfg.Whitelist, 1)
+	require.Empty(t, cfg.Blacklist)
+}
+
 func TestConfigLoader_LoadRulesFromYAML(t *testing.T) {
 	log := logrus.New()
 	log.SetOutput(os.Stdout)
@@ -194,4 +214,110 @@ func TestConfigLoader_LoadRulesFromYAML_InvalidYAML(t *testing.T) {
 	_, err = cl.LoadRulesFromYAMLPublic(tmpFile.Name())
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "failed to parse YAML")
-}
\ No newline at end of file
+}
+
+func writeTempYAML(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "ipfilter-test-*.yaml")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.Remove(tmpFile.Name()) })
+
+	_, err = tmpFile.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	return tmpFile.Name()
+}
+
+func TestConfigLoader_LoadRulesFromYAML_IPSets(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(os.Stdout)
+	cl := ipfilter.NewConfigLoader(log)
+
+	path := writeTempYAML(t, `ipfilter:
+  ipSets:
+    vpn-nets: ["172.16.0.0/12"]
+    corp-nets:
+      include: ["10.0.0.0/8", "@vpn-nets"]
+      exclude: ["10.128.0.0/9"]
+  whitelist:
+    - "@corp-nets"
+    - 10.1.2.3
+    - 192.168.1.100
+  blacklist:
+    - "@vpn-nets"
+`)
+
+	rules, err := cl.LoadRulesFromYAMLPublic(path)
+	require.NoError(t, err)
+
+	var descriptions []string
+	for _, r := range rules {
+		descriptions = append(descriptions, r.Description)
+	}
+	// 10.1.2.3被10.0.0.0/9包含，重复引用的vpn-nets只保留一个
+	require.Equal(t, []string{
+		"10.0.0.0/9 (@corp-nets)",
+		"172.16.0.0/12 (@corp-nets)",
+		"192.168.1.100/32",
+	}, descriptions)
+	require.Equal(t, "10.0.0.0/9", rules[0].Network.String())
+}
+
+func TestConfigLoader_LoadRulesFromYAML_IPSetErrors(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(os.Stdout)
+	cl := ipfilter.NewConfigLoader(log)
+
+	// 未定义的引用
+	path := writeTempYAML(t, `ipfilter:
+  whitelist:
+    - "@missing"
+`)
+	_, err := cl.LoadRulesFromYAMLPublic(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "undefined ip set @missing")
+
+	// 循环引用（即使未被使用也报错）
+	path = writeTempYAML(t, `ipfilter:
+  ipSets:
+    a: ["@b"]
+    b: ["@a"]
+  whitelist:
+    - 192.168.1.100
+`)
+	_, err = cl.LoadRulesFromYAMLPublic(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "ip set cycle detected: a -> b -> a")
+}
+
+func TestConfigLoader_LoadFromEnv_Feeds(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(os.Stdout)
+	cl := ipfilter.NewConfigLoader(log)
+
+	dir := t.TempDir()
+	drop := dir + "/drop.txt"
+	require.NoError(t, os.WriteFile(drop, []byte("; Spamhaus DROP\n1.10.16.0/21 ; SBL1\n1.10.24.0/21 ; SBL2\nbogus ; SBL3\n"), 0o600))
+	partners := dir + "/partners.csv"
+	require.NoError(t, os.WriteFile(partners, []byte("network,name\n203.0.113.0/24,partner-a\n"), 0o600))
+
+	t.Setenv("IPFILTER_MODE", "both")
+	t.Setenv("IPFILTER_WHITELIST", "192.168.1.0/24")
+	t.Setenv("IPFILTER_FEEDS", "deny:spamhaus:"+drop+",allow:csv:"+partners)
+
+	cfg, err := cl.LoadFromEnv(context.Background())
+	require.NoError(t, err)
+	require.Len(t, cfg.Feeds, 2)
+
+	// 相邻的两个/21聚合为一个/20
+	require.Len(t, cfg.Blacklist, 1)
+	require.Equal(t, "1.10.16.0/20", cfg.Blacklist[0].Network.String())
+	require.Equal(t, "1.10.16.0/20 (feed: drop.txt)", cfg.Blacklist[0].Description)
+
+	require.Len(t, cfg.Whitelist, 2)
+	require.Equal(t, "203.0.113.0/24 (feed: partners.csv)", cfg.Whitelist[1].Description)
+
+	t.Setenv("IPFILTER_FEEDS", "deny:plain:"+dir+"/missing.txt")
+	_, err = cl.LoadFromEnv(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to load feed")
+}
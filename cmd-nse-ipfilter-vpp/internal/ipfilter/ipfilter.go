@@ -42,6 +42,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/recvfd"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/sendfd"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanismtranslation"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/null"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/passthrough"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
@@ -49,7 +50,8 @@ import (
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"google.golang.org/grpc"
 
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/pkg/vpp"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ratelimit"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/vpp"
 	"github.com/sirupsen/logrus"
 )
 
@@ -57,6 +59,13 @@ import (
 // 从firewall Endpoint复制并修改，未来将添加IP过滤逻辑
 type Endpoint struct {
 	endpoint.Endpoint
+
+	matcher *RuleMatcher
+}
+
+// Matcher 返回端点使用的规则匹配器，未启用IP过滤时返回nil
+func (ep *Endpoint) Matcher() *RuleMatcher {
+	return ep.matcher
 }
 
 // Options IP Filter端点配置选项
@@ -76,6 +85,9 @@ type Options struct {
 	// Logger 日志记录器
 	Logger *logrus.Logger
 
+	// RateLimit 请求限流配置（未启用任何维度时不加入限流链元素）
+	RateLimit ratelimit.Config
+
 	// MaxTokenLifetime token最大生命周期
 	MaxTokenLifetime time.Duration
 
@@ -121,8 +133,8 @@ func NewEndpoint(ctx context.Context, opts Options) *Endpoint {
 	// 创建IP过滤规则匹配器
 	var ipFilterMiddleware networkservice.NetworkServiceServer
 	if opts.FilterConfig != nil {
-		matcher := NewRuleMatcher(opts.FilterConfig)
-		ipFilterMiddleware = NewServer(matcher, opts.Logger)
+		ep.matcher = NewRuleMatcher(opts.FilterConfig)
+		ipFilterMiddleware = NewServer(ep.matcher, opts.Logger)
 		opts.Logger.Infof("IP Filter enabled: mode=%s, whitelist=%d rules, blacklist=%d rules",
 			opts.FilterConfig.Mode, len(opts.FilterConfig.Whitelist), len(opts.FilterConfig.Blacklist))
 	} else {
@@ -130,6 +142,12 @@ func NewEndpoint(ctx context.Context, opts Options) *Endpoint {
 		opts.Logger.Warn("IP Filter disabled: no configuration provided")
 	}
 
+	// 请求限流放在链的最前面，避免被拒绝的请求触发VPP编程
+	rateLimitServer := null.NewServer()
+	if opts.RateLimit.Enabled() {
+		rateLimitServer = ratelimit.NewServer(opts.RateLimit)
+	}
+
 	// 构建端点链
 	ep.Endpoint = endpoint.NewServer(
 		ctx,
@@ -137,6 +155,8 @@ func NewEndpoint(ctx context.Context, opts Options) *Endpoint {
 		endpoint.WithName(opts.Name),
 		endpoint.WithAuthorizeServer(authorize.NewServer()),
 		endpoint.WithAdditionalFunctionality(
+			// 请求限流
+			rateLimitServer,
 			// 接收文件描述符
 			recvfd.NewServer(),
 			// 发送文件描述符
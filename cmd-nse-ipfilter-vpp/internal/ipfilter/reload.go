@@ -0,0 +1,59 @@
+package ipfilter
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/feed"
+)
+
+// WatchedFiles 返回加载器的配置来源引用的文件（YAML规则文件和订阅源文件）
+func (cl *ConfigLoader) WatchedFiles() []string {
+	var files []string
+	for _, name := range []string{"IPFILTER_WHITELIST", "IPFILTER_BLACKLIST"} {
+		if value := cl.getenv(name); strings.HasPrefix(value, "/") || strings.HasPrefix(value, "./") {
+			files = append(files, value)
+		}
+	}
+	if sources, err := feed.ParseSpecs(cl.getenv("IPFILTER_FEEDS")); err == nil {
+		for _, src := range sources {
+			files = append(files, src.Path)
+		}
+	}
+	return files
+}
+
+// Watch 监控规则文件和订阅源文件，变化时重新加载配置并更新匹配器
+//
+// 调用时记录文件的当前状态，随后在后台按间隔检查，直到ctx结束。
+// 重新加载失败时保留当前配置并记录错误。每次重新加载后调用onHealth（可以为nil），
+// 成功时为true，失败时为false。
+//
+// 示例：
+//
+//	loader.Watch(ctx, ep.Matcher(), 30*time.Second, nil)
+func (cl *ConfigLoader) Watch(ctx context.Context, matcher *RuleMatcher, interval time.Duration, onHealth func(loaded bool)) {
+	if onHealth == nil {
+		onHealth = func(bool) {}
+	}
+	reload := func() {
+		newCfg, err := cl.LoadFromEnv(ctx)
+		if err != nil {
+			cl.log.Errorf("IP Filter reload failed, keeping current rules: %v", err)
+			onHealth(false)
+			return
+		}
+		if err := matcher.Reload(newCfg); err != nil {
+			cl.log.Errorf("IP Filter reload failed, keeping current rules: %v", err)
+			onHealth(false)
+			return
+		}
+		onHealth(true)
+		cl.log.Infof("IP Filter reloaded: mode=%s, whitelist=%d rules, blacklist=%d rules, feeds=%d",
+			newCfg.Mode, len(newCfg.Whitelist), len(newCfg.Blacklist), len(newCfg.Feeds))
+		LogReport(cl.log, Analyze(newCfg))
+	}
+
+	go feed.NewWatcher(interval, cl.WatchedFiles, reload).Run(ctx)
+}
@@ -0,0 +1,92 @@
+package ipfilter_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/internal/ipfilter"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigLoader_WatchedFiles(t *testing.T) {
+	cl := ipfilter.NewConfigLoader(logrus.New())
+
+	t.Setenv("IPFILTER_WHITELIST", "/etc/ipfilter/rules.yaml")
+	t.Setenv("IPFILTER_BLACKLIST", "10.0.0.1")
+	t.Setenv("IPFILTER_FEEDS", "deny:spamhaus:/etc/ipfilter/drop.txt")
+
+	require.Equal(t, []string{"/etc/ipfilter/rules.yaml", "/etc/ipfilter/drop.txt"}, cl.WatchedFiles())
+}
+
+func TestConfigLoader_WithSource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	drop := filepath.Join(t.TempDir(), "drop.txt")
+	require.NoError(t, os.WriteFile(drop, []byte("10.0.0.0/8\n"), 0o600))
+
+	// 配置来源中的值优先于（也不写入）IPFILTER_*环境变量
+	t.Setenv("IPFILTER_FEEDS", "deny:plain:/etc/ipfilter/other.txt")
+	settings := map[string]string{"IPFILTER_MODE": "blacklist", "IPFILTER_FEEDS": "deny:plain:" + drop}
+	cl := ipfilter.NewConfigLoader(logrus.New()).WithSource(func(key string) string { return settings[key] })
+	require.Equal(t, []string{drop}, cl.WatchedFiles())
+
+	cfg, err := cl.LoadFromEnv(ctx)
+	require.NoError(t, err)
+	require.Equal(t, ipfilter.FilterModeBlacklist, cfg.Mode)
+	matcher := ipfilter.NewRuleMatcher(cfg)
+
+	// 重新加载读取同一来源
+	cl.Watch(ctx, matcher, 10*time.Millisecond, nil)
+	require.NoError(t, os.WriteFile(drop, []byte("10.0.0.0/8\n172.16.0.0/12\n"), 0o600))
+	require.Eventually(t, func() bool {
+		allowed, _ := matcher.IsAllowed(net.ParseIP("172.16.0.1"))
+		return !allowed
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, "deny:plain:/etc/ipfilter/other.txt", os.Getenv("IPFILTER_FEEDS"))
+}
+
+func TestConfigLoader_Watch_ReloadsOnFeedChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cl := ipfilter.NewConfigLoader(logrus.New())
+
+	drop := filepath.Join(t.TempDir(), "drop.txt")
+	require.NoError(t, os.WriteFile(drop, []byte("10.0.0.0/8\n"), 0o600))
+
+	t.Setenv("IPFILTER_MODE", "blacklist")
+	t.Setenv("IPFILTER_FEEDS", "deny:plain:"+drop)
+
+	cfg, err := cl.LoadFromEnv(ctx)
+	require.NoError(t, err)
+	matcher := ipfilter.NewRuleMatcher(cfg)
+
+	allowed, _ := matcher.IsAllowed(net.ParseIP("172.16.0.1"))
+	require.True(t, allowed)
+
+	var loaded atomic.Value
+	cl.Watch(ctx, matcher, 10*time.Millisecond, func(ok bool) { loaded.Store(ok) })
+
+	// 订阅源中的无效行被跳过，不影响重新加载
+	require.NoError(t, os.WriteFile(drop, []byte("10.0.0.0/8\n172.16.0.0/12\nbogus\n"), 0o600))
+	require.Eventually(t, func() bool {
+		allowed, _ := matcher.IsAllowed(net.ParseIP("172.16.0.1"))
+		return !allowed
+	}, time.Second, 10*time.Millisecond, "订阅源变化后应重新加载")
+	require.Eventually(t, func() bool { return loaded.Load() == true }, time.Second, 10*time.Millisecond)
+
+	// 文件被删除时重新加载失败，保留当前规则
+	require.NoError(t, os.Remove(drop))
+	time.Sleep(50 * time.Millisecond)
+	allowed, _ = matcher.IsAllowed(net.ParseIP("172.16.0.1"))
+	require.False(t, allowed, "重新加载失败时应保留当前规则")
+	require.Eventually(t, func() bool { return loaded.Load() == false }, time.Second, 10*time.Millisecond,
+		"重新加载失败时策略组件应报告为不健康")
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net"
 	"time"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/feed"
 )
 
 // FilterMode 过滤模式枚举
@@ -57,6 +59,9 @@ type FilterConfig struct {
 	// 空列表表示默认允许所有（当Mode为Blacklist或Both时）
 	Blacklist []IPFilterRule
 
+	// Feeds 已合并到白名单/黑名单的外部订阅源（用于变更监控）
+	Feeds []feed.Source
+
 	// LogLevel 日志级别（继承自NSM配置，此处可选覆盖）
 	LogLevel string
 }
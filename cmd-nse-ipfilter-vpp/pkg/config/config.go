@@ -22,44 +22,50 @@ package config
 
 import (
 	"context"
-	"net/url"
 	"os"
 	"path/filepath"
 	"time"
 
-	"github.com/kelseyhightower/envconfig"
 	"github.com/networkservicemesh/govpp/binapi/acl_types"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
+
+	nseconfig "github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/feed"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ratelimit"
 )
 
 // Config 包含从环境变量加载的配置参数
 type Config struct {
-	Name                   string              `default:"ipfilter-server" desc:"Name of IP Filter Server"`
-	ListenOn               string              `default:"listen.on.sock" desc:"listen on socket" split_words:"true"`
-	ConnectTo              url.URL             `default:"unix:///var/lib/networkservicemesh/nsm.io.sock" desc:"url to connect to" split_words:"true"`
-	MaxTokenLifetime       time.Duration       `default:"10m" desc:"maximum lifetime of tokens" split_words:"true"`
-	RegistryClientPolicies []string            `default:"etc/nsm/opa/common/.*.rego,etc/nsm/opa/registry/.*.rego,etc/nsm/opa/client/.*.rego" desc:"paths to files and directories that contain registry client policies" split_words:"true"`
-	ServiceName            string              `default:"" desc:"Name of providing service" split_words:"true"`
-	Labels                 map[string]string   `default:"" desc:"Endpoint labels"`
-	ACLConfigPath          string              `default:"/etc/firewall/config.yaml" desc:"Path to ACL config file" split_words:"true"`
-	ACLConfig              []acl_types.ACLRule `default:"" desc:"configured acl rules" split_words:"true"`
+	// 通用NSE配置（名称、监听地址、NSM连接、日志和可观测性等）
+	nseconfig.Base
+
+	ACLConfigPath string              `default:"/etc/firewall/config.yaml" desc:"Path to ACL config file" split_words:"true"`
+	ACLConfig     []acl_types.ACLRule `default:"" desc:"configured acl rules" split_words:"true"`
 	// IP Filter相关配置
-	IPFilterMode           string              `default:"whitelist" desc:"IP Filter mode: whitelist, blacklist, or both" split_words:"true"`
-	IPFilterWhitelist      string              `default:"" desc:"Comma-separated list of whitelisted IPs/CIDRs, or path to YAML file" split_words:"true"`
-	IPFilterBlacklist      string              `default:"" desc:"Comma-separated list of blacklisted IPs/CIDRs, or path to YAML file" split_words:"true"`
-	LogLevel               string              `default:"INFO" desc:"Log level" split_words:"true"`
-	OpenTelemetryEndpoint  string              `default:"otel-collector.observability.svc.cluster.local:4317" desc:"OpenTelemetry Collector Endpoint" split_words:"true"`
-	MetricsExportInterval  time.Duration       `default:"10s" desc:"interval between mertics exports" split_words:"true"`
-	PprofEnabled           bool                `default:"false" desc:"is pprof enabled" split_words:"true"`
-	PprofListenOn          string              `default:"localhost:6060" desc:"pprof URL to ListenAndServe" split_words:"true"`
+	IPFilterMode           string        `default:"whitelist" desc:"IP Filter mode: whitelist, blacklist, or both" split_words:"true"`
+	IPFilterWhitelist      string        `default:"" desc:"Comma-separated list of whitelisted IPs/CIDRs, or path to YAML file" split_words:"true"`
+	IPFilterBlacklist      string        `default:"" desc:"Comma-separated list of blacklisted IPs/CIDRs, or path to YAML file" split_words:"true"`
+	IPFilterFeeds          string        `default:"" desc:"Comma-separated list of block-list feeds as target:format:path (format: plain, csv, spamhaus)" split_words:"true"`
+	IPFilterReloadInterval time.Duration `default:"30s" desc:"Interval for checking rule files and feeds for changes (0 disables reload)" split_words:"true"`
+
+	// 请求限流相关配置（速率单位：请求/秒，0表示不限制）
+	RateLimitPerIP            float64       `default:"0" desc:"Request rate limit per source IP (requests per second, 0 disables)" split_words:"true"`
+	RateLimitPerIPBurst       int           `default:"0" desc:"Burst size of the per source IP rate limit" split_words:"true"`
+	RateLimitPerSpiffeID      float64       `default:"0" desc:"Request rate limit per client SPIFFE ID (requests per second, 0 disables)" split_words:"true"`
+	RateLimitPerSpiffeIDBurst int           `default:"0" desc:"Burst size of the per SPIFFE ID rate limit" split_words:"true"`
+	RateLimitGlobal           float64       `default:"0" desc:"Global request rate limit (requests per second, 0 disables)" split_words:"true"`
+	RateLimitGlobalBurst      int           `default:"0" desc:"Burst size of the global rate limit" split_words:"true"`
+	RateLimitIdleTTL          time.Duration `default:"10m" desc:"Idle time after which per-client rate limit state is dropped" split_words:"true"`
 }
 
-// Load 从环境变量加载配置，返回配置实例
+// Load 从配置文件和环境变量加载配置，返回配置实例
 //
 // 使用envconfig库从环境变量中读取配置，所有配置项使用"NSM_"前缀。
-// 例如：NSM_NAME, NSM_CONNECT_TO, NSM_SERVICE_NAME 等
+// 例如：NSM_NAME, NSM_CONNECT_TO, NSM_SERVICE_NAME 等（通用配置项见nseconfig.Base）。
+// 未设置环境变量的配置项使用NSM_CONFIG_FILE配置文件中的值（见nseconfig.Load）。
+// NSM_NAME未设置时NSE名称为ipfilter-server。
 //
 // 示例：
 //
@@ -70,15 +76,8 @@ type Config struct {
 //	}
 func Load(ctx context.Context) (*Config, error) {
 	c := new(Config)
-
-	// 打印环境变量使用说明
-	if err := envconfig.Usage("nsm", c); err != nil {
-		return nil, errors.Wrap(err, "cannot show usage of envconfig nsm")
-	}
-
-	// 从环境变量加载配置
-	if err := envconfig.Process("nsm", c); err != nil {
-		return nil, errors.Wrap(err, "cannot process envconfig nsm")
+	if err := nseconfig.Load(ctx, c, "ipfilter-server"); err != nil {
+		return nil, err
 	}
 
 	return c, nil
@@ -122,6 +121,45 @@ func (c *Config) LoadACLRules(ctx context.Context) {
 	logger.Infof("Result rules:%v", c.ACLConfig)
 }
 
+// RateLimitConfig 返回请求限流配置
+//
+// 示例：
+//
+//	if rl := cfg.RateLimitConfig(); rl.Enabled() {
+//	    server := ratelimit.NewServer(rl)
+//	}
+func (c *Config) RateLimitConfig() ratelimit.Config {
+	return ratelimit.Config{
+		PerIP:       ratelimit.Limit{Rate: c.RateLimitPerIP, Burst: c.RateLimitPerIPBurst},
+		PerSpiffeID: ratelimit.Limit{Rate: c.RateLimitPerSpiffeID, Burst: c.RateLimitPerSpiffeIDBurst},
+		Global:      ratelimit.Limit{Rate: c.RateLimitGlobal, Burst: c.RateLimitGlobalBurst},
+		IdleTTL:     c.RateLimitIdleTTL,
+	}
+}
+
+// IPFilterSetting 按ipfilter.ConfigLoader使用的IPFILTER_*配置项名返回配置值
+//
+// IPFILTER_MODE、IPFILTER_WHITELIST、IPFILTER_BLACKLIST和IPFILTER_FEEDS取自已加载的配置
+// （NSM_CONFIG_FILE或环境变量），其他配置项（如IPFILTER_LOG_LEVEL）读取环境变量。
+//
+// 示例：
+//
+//	loader := ipfilter.NewConfigLoader(logger).WithSource(cfg.IPFilterSetting)
+func (c *Config) IPFilterSetting(key string) string {
+	switch key {
+	case "IPFILTER_MODE":
+		return c.IPFilterMode
+	case "IPFILTER_WHITELIST":
+		return c.IPFilterWhitelist
+	case "IPFILTER_BLACKLIST":
+		return c.IPFilterBlacklist
+	case "IPFILTER_FEEDS":
+		return c.IPFilterFeeds
+	default:
+		return os.Getenv(key)
+	}
+}
+
 // Validate 验证配置的完整性和有效性
 //
 // 检查必填字段是否存在，URL格式是否正确。
@@ -134,17 +172,22 @@ func (c *Config) LoadACLRules(ctx context.Context) {
 //	    log.Fatalf("Invalid config: %v", err)
 //	}
 func (c *Config) Validate() error {
-	// 检查必填字段
-	if c.Name == "" {
-		return errors.New("Name is required")
+	// 验证通用配置
+	if err := c.Base.Validate(); err != nil {
+		return err
 	}
-	if c.ServiceName == "" {
-		return errors.New("ServiceName is required")
+
+	// 验证限流配置
+	if err := c.RateLimitConfig().Validate(); err != nil {
+		return err
 	}
 
-	// 验证ConnectTo URL格式
-	if c.ConnectTo.String() == "" {
-		return errors.New("ConnectTo URL is required")
+	// 验证外部订阅源声明
+	if _, err := feed.ParseSpecs(c.IPFilterFeeds); err != nil {
+		return err
+	}
+	if c.IPFilterReloadInterval < 0 {
+		return errors.Errorf("invalid IP filter reload interval: %v", c.IPFilterReloadInterval)
 	}
 
 	return nil
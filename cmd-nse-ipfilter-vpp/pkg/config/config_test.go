@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/pkg/config"
+	nseconfig "github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -43,7 +44,7 @@ func TestLoad_DefaultValues(t *testing.T) {
 	require.NotNil(t, cfg, "Config不应该为nil")
 
 	// 验证默认值
-	require.Equal(t, "firewall-server", cfg.Name)
+	require.Equal(t, "ipfilter-server", cfg.Name)
 	require.Equal(t, "listen.on.sock", cfg.ListenOn)
 	require.Equal(t, "unix:///var/lib/networkservicemesh/nsm.io.sock", cfg.ConnectTo.String())
 	require.Equal(t, 10*time.Minute, cfg.MaxTokenLifetime)
@@ -72,11 +73,46 @@ func TestLoad_CustomValues(t *testing.T) {
 	require.Equal(t, 5*time.Minute, cfg.MaxTokenLifetime)
 }
 
+func TestLoad_ConfigFile(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "ipfilter.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+serviceName: ipfilter
+ipFilterMode: both
+ipFilterWhitelist:
+  - 10.0.0.0/8
+  - 192.168.1.10
+ipFilterBlacklist: 10.1.0.0/16
+ipFilterReloadInterval: 1m
+`), 0o600))
+	t.Setenv(nseconfig.ConfigFileEnv, path)
+	t.Setenv("NSM_IP_FILTER_MODE", "blacklist")
+
+	cfg, err := config.Load(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, "ipfilter", cfg.ServiceName)
+	require.Equal(t, "blacklist", cfg.IPFilterMode, "环境变量优先于配置文件")
+	require.Equal(t, "10.0.0.0/8,192.168.1.10", cfg.IPFilterWhitelist, "列表以逗号连接")
+	require.Equal(t, "10.1.0.0/16", cfg.IPFilterBlacklist)
+	require.Equal(t, time.Minute, cfg.IPFilterReloadInterval)
+	require.Equal(t, nseconfig.SourceFile, cfg.Origin("NSM_IP_FILTER_WHITELIST").Source)
+	require.Equal(t, nseconfig.SourceEnv, cfg.Origin("NSM_IP_FILTER_MODE").Source)
+
+	// ConfigLoader读取已加载的配置，不依赖IPFILTER_*环境变量
+	t.Setenv("IPFILTER_WHITELIST", "172.16.0.0/12")
+	require.Equal(t, "10.0.0.0/8,192.168.1.10", cfg.IPFilterSetting("IPFILTER_WHITELIST"))
+	require.Equal(t, "blacklist", cfg.IPFilterSetting("IPFILTER_MODE"))
+	require.Empty(t, cfg.IPFilterSetting("IPFILTER_FEEDS"))
+}
+
 func TestValidate_Success(t *testing.T) {
 	cfg := &config.Config{
-		Name:        "test-server",
-		ServiceName: "test-service",
-		ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		Base: nseconfig.Base{
+			Name:        "test-server",
+			ServiceName: "test-service",
+			ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		},
 	}
 
 	err := cfg.Validate()
@@ -85,9 +121,11 @@ func TestValidate_Success(t *testing.T) {
 
 func TestValidate_MissingName(t *testing.T) {
 	cfg := &config.Config{
-		Name:        "", // 缺失
-		ServiceName: "test-service",
-		ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		Base: nseconfig.Base{
+			Name:        "", // 缺失
+			ServiceName: "test-service",
+			ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		},
 	}
 
 	err := cfg.Validate()
@@ -97,9 +135,11 @@ func TestValidate_MissingName(t *testing.T) {
 
 func TestValidate_MissingServiceName(t *testing.T) {
 	cfg := &config.Config{
-		Name:        "test-server",
-		ServiceName: "", // 缺失
-		ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		Base: nseconfig.Base{
+			Name:        "test-server",
+			ServiceName: "", // 缺失
+			ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		},
 	}
 
 	err := cfg.Validate()
@@ -109,9 +149,11 @@ func TestValidate_MissingServiceName(t *testing.T) {
 
 func TestValidate_MissingConnectTo(t *testing.T) {
 	cfg := &config.Config{
-		Name:        "test-server",
-		ServiceName: "test-service",
-		ConnectTo:   url.URL{}, // 空URL
+		Base: nseconfig.Base{
+			Name:        "test-server",
+			ServiceName: "test-service",
+			ConnectTo:   url.URL{}, // 空URL
+		},
 	}
 
 	err := cfg.Validate()
@@ -119,6 +161,71 @@ func TestValidate_MissingConnectTo(t *testing.T) {
 	require.Contains(t, err.Error(), "ConnectTo URL is required")
 }
 
+func TestLoad_RateLimitValues(t *testing.T) {
+	clearEnv(t)
+	os.Setenv("NSM_RATE_LIMIT_PER_IP", "2.5")
+	os.Setenv("NSM_RATE_LIMIT_PER_IP_BURST", "5")
+	os.Setenv("NSM_RATE_LIMIT_GLOBAL", "100")
+
+	cfg, err := config.Load(context.Background())
+	require.NoError(t, err)
+
+	rl := cfg.RateLimitConfig()
+	require.True(t, rl.Enabled())
+	require.Equal(t, 2.5, rl.PerIP.Rate)
+	require.Equal(t, 5, rl.PerIP.Burst)
+	require.False(t, rl.PerSpiffeID.Enabled())
+	require.Equal(t, 100.0, rl.Global.Rate)
+	require.Equal(t, 10*time.Minute, rl.IdleTTL)
+}
+
+func TestValidate_InvalidRateLimit(t *testing.T) {
+	cfg := &config.Config{
+		Base: nseconfig.Base{
+			Name:        "test-server",
+			ServiceName: "test-service",
+			ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		},
+		RateLimitPerIP: -1,
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err, "负的限流速率应该返回错误")
+	require.Contains(t, err.Error(), "rate limit")
+}
+
+func TestLoad_IPFilterFeedValues(t *testing.T) {
+	clearEnv(t)
+
+	cfg, err := config.Load(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, cfg.IPFilterFeeds)
+	require.Equal(t, 30*time.Second, cfg.IPFilterReloadInterval)
+
+	os.Setenv("NSM_IP_FILTER_FEEDS", "deny:spamhaus:/etc/ipfilter/drop.txt")
+	os.Setenv("NSM_IP_FILTER_RELOAD_INTERVAL", "1m")
+
+	cfg, err = config.Load(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "deny:spamhaus:/etc/ipfilter/drop.txt", cfg.IPFilterFeeds)
+	require.Equal(t, time.Minute, cfg.IPFilterReloadInterval)
+}
+
+func TestValidate_InvalidFeedSpec(t *testing.T) {
+	cfg := &config.Config{
+		Base: nseconfig.Base{
+			Name:        "test-server",
+			ServiceName: "test-service",
+			ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+		},
+		IPFilterFeeds: "deny:json:/etc/ipfilter/drop.json",
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err, "不支持的订阅源格式应该返回错误")
+	require.Contains(t, err.Error(), "unsupported format")
+}
+
 func TestLoadACLRules_ValidFile(t *testing.T) {
 	// 创建临时YAML文件
 	tmpDir := t.TempDir()
@@ -219,6 +326,7 @@ func TestLoadACLRules_EmptyFile(t *testing.T) {
 // 辅助函数：清理环境变量
 func clearEnv(t *testing.T) {
 	envVars := []string{
+		"NSM_CONFIG_FILE",
 		"NSM_NAME",
 		"NSM_LISTEN_ON",
 		"NSM_CONNECT_TO",
@@ -233,6 +341,15 @@ func clearEnv(t *testing.T) {
 		"NSM_METRICS_EXPORT_INTERVAL",
 		"NSM_PPROF_ENABLED",
 		"NSM_PPROF_LISTEN_ON",
+		"NSM_RATE_LIMIT_PER_IP",
+		"NSM_RATE_LIMIT_PER_IP_BURST",
+		"NSM_RATE_LIMIT_PER_SPIFFE_ID",
+		"NSM_RATE_LIMIT_PER_SPIFFE_ID_BURST",
+		"NSM_RATE_LIMIT_GLOBAL",
+		"NSM_RATE_LIMIT_GLOBAL_BURST",
+		"NSM_RATE_LIMIT_IDLE_TTL",
+		"NSM_IP_FILTER_FEEDS",
+		"NSM_IP_FILTER_RELOAD_INTERVAL",
 	}
 
 	for _, v := range envVars {
@@ -1,10 +1,10 @@
 // Package config 提供配置管理功能
 //
-// 本包负责从环境变量加载NSE应用配置，解析ACL配置文件，
+// 本包负责从配置文件和环境变量加载NSE应用配置，解析ACL配置文件，
 // 并提供配置验证功能。
 //
 // 主要功能：
-//   - 从环境变量加载配置（使用envconfig）
+//   - 从NSM_CONFIG_FILE配置文件和环境变量加载配置（环境变量优先，见nseconfig.Load）
 //   - 解析YAML格式的ACL规则配置
 //   - 验证配置的完整性和有效性
 //
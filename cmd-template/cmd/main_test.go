@@ -0,0 +1,68 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runCmd(args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(context.Background(), args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestRun_Generate(t *testing.T) {
+	out := t.TempDir()
+	code, stdout, stderr := runCmd("-name", "qos", "-service", "qos-service", "-chain", "classifier, marker",
+		"-out", out, "-framework", filepath.Join("..", "..", "nse-framework"))
+	require.Equal(t, exitOK, code, stderr)
+	require.Contains(t, stdout, filepath.FromSlash("cmd-nse-qos-vpp/internal/qos/classifier.go"))
+	require.Contains(t, stdout, filepath.FromSlash("cmd-nse-qos-vpp/internal/qos/marker.go"))
+	require.Contains(t, stderr, "files written")
+
+	_, err := os.Stat(filepath.Join(out, "samenode-qos", "kustomization.yaml"))
+	require.NoError(t, err)
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runCmd("-name", "qos")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, "Usage: cmd-template")
+
+	code, _, _ = runCmd("-unknown")
+	require.Equal(t, exitUsage, code)
+
+	code, _, _ = runCmd("-h")
+	require.Equal(t, exitOK, code)
+}
+
+func TestRun_Failed(t *testing.T) {
+	code, _, stderr := runCmd("-name", "Bad-Name", "-service", "qos-service", "-out", t.TempDir())
+	require.Equal(t, exitFailed, code)
+	require.Contains(t, stderr, "invalid NSE name")
+}
@@ -0,0 +1,14 @@
+module github.com/networkservicemesh/nsm-nse-app/cmd-template
+
+go 1.23.8
+
+require (
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
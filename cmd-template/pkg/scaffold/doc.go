@@ -0,0 +1,20 @@
+// Package scaffold 生成新NSE模块的脚手架
+//
+// 根据NSE名称、网络服务名称和业务链元素列表，在仓库根目录下生成：
+//   - cmd-nse-<name>-vpp/: Go模块（cmd/main.go、internal/<name>端点与链元素骨架、
+//     pkg/config配置、单元测试、internal/imports、Dockerfile、README.md）
+//   - samenode-<name>/: samenode测试环境的kustomize清单
+//
+// 生成的模块通过replace引用本地nse-framework，依赖版本和go.sum取自nse-framework，
+// 无需联网即可编译并通过测试。每个业务链元素生成一个NetworkServiceServer骨架及其测试，
+// 按给定顺序插入在nse-framework标准VPP链的xconnect之后。
+//
+// 使用示例：
+//
+//	err := scaffold.Generate(scaffold.Options{
+//	    Name:        "qos",
+//	    ServiceName: "qos-service",
+//	    Elements:    []string{"classifier", "marker"},
+//	    OutDir:      "..",
+//	})
+package scaffold
@@ -0,0 +1,367 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// frameworkModule nse-framework的模块路径
+	frameworkModule = "github.com/networkservicemesh/nsm-nse-app/nse-framework"
+	// frameworkVersion 生成的go.mod中引用的nse-framework版本
+	frameworkVersion = "v0.1.0"
+	// modulePrefix 生成的NSE模块路径前缀
+	modulePrefix = "github.com/networkservicemesh/nsm-nse-app/"
+	// defaultImageRepo 默认镜像仓库
+	defaultImageRepo = "ifzzh520"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var (
+	// identRe NSE名称和链元素名称：小写字母开头的小写字母和数字（同时用作Go包名和标识符）
+	identRe = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+	// serviceRe 网络服务名称：Kubernetes资源名称（DNS标签）
+	serviceRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+	// reserved 与生成代码中的包名、标识符或文件名冲突的名称
+	reserved = map[string]bool{
+		"break": true, "case": true, "chan": true, "const": true, "continue": true,
+		"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+		"func": true, "go": true, "goto": true, "if": true, "import": true,
+		"interface": true, "map": true, "package": true, "range": true, "return": true,
+		"select": true, "struct": true, "switch": true, "type": true, "var": true,
+		"config": true, "context": true, "doc": true, "endpoint": true, "errors": true,
+		"imports": true, "lifecycle": true, "logrus": true, "main": true, "next": true,
+		"nse": true, "nseconfig": true, "vpp": true,
+	}
+)
+
+// Options 生成选项
+type Options struct {
+	// Name NSE名称（如qos），用作包名和模块目录名cmd-nse-<name>-vpp
+	Name string
+
+	// ServiceName 提供的网络服务名称（如qos-service）
+	ServiceName string
+
+	// Elements 业务链元素名称，按链中顺序排列；为空时使用Name
+	Elements []string
+
+	// OutDir 输出目录（仓库根目录）
+	OutDir string
+
+	// FrameworkDir nse-framework目录，为空时使用<OutDir>/nse-framework
+	FrameworkDir string
+
+	// Image 镜像名称，为空时使用ifzzh520/cmd-nse-<name>-vpp:latest
+	Image string
+}
+
+// Result 生成结果
+type Result struct {
+	// ModuleDir 生成的Go模块目录
+	ModuleDir string
+
+	// ManifestDir 生成的kustomize清单目录
+	ManifestDir string
+
+	// Files 生成的文件（相对OutDir的路径）
+	Files []string
+}
+
+// element 模板中的业务链元素
+type element struct {
+	// Name 元素名称（小写，用作文件名和未导出类型名）
+	Name string
+	// Type 导出标识符中的元素名称（首字母大写）
+	Type string
+}
+
+// data 模板数据
+type data struct {
+	Name        string
+	ServiceName string
+	Module      string
+	ModuleDir   string
+	Namespace   string
+	Image       string
+	ImageRepo   string
+	Elements    []element
+	// Pad README目录树中internal/<name>/后的对齐空格
+	Pad string
+}
+
+// elementData 业务链元素文件的模板数据
+type elementData struct {
+	NSE     *data
+	Element element
+}
+
+// file 待生成的文件
+type file struct {
+	tmpl string
+	path string
+	data interface{}
+}
+
+// Generate 生成新NSE模块及其samenode清单
+//
+// 目标目录已存在时返回错误，不覆盖已有文件。
+//
+// 返回值：
+//   - *Result: 生成的目录和文件
+//   - error: 选项无效、读取nse-framework或写入失败时返回错误
+func Generate(opts Options) (*Result, error) {
+	d, err := newData(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	outDir, err := filepath.Abs(opts.OutDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid output dir %s", opts.OutDir)
+	}
+	frameworkDir := opts.FrameworkDir
+	if frameworkDir == "" {
+		frameworkDir = filepath.Join(outDir, "nse-framework")
+	}
+	if frameworkDir, err = filepath.Abs(frameworkDir); err != nil {
+		return nil, errors.Wrapf(err, "invalid nse-framework dir %s", opts.FrameworkDir)
+	}
+
+	result := &Result{
+		ModuleDir:   filepath.Join(outDir, d.ModuleDir),
+		ManifestDir: filepath.Join(outDir, "samenode-"+d.Name),
+	}
+	for _, dir := range []string{result.ModuleDir, result.ManifestDir} {
+		if _, err := os.Stat(dir); err == nil {
+			return nil, errors.Errorf("%s already exists", dir)
+		}
+	}
+
+	// go.mod和go.sum取自nse-framework
+	replacePath, err := filepath.Rel(result.ModuleDir, frameworkDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot reference nse-framework from the module dir")
+	}
+	goMod, err := renderGoMod(d.Module, filepath.Join(frameworkDir, "go.mod"), filepath.ToSlash(replacePath))
+	if err != nil {
+		return nil, err
+	}
+	goSum, err := os.ReadFile(filepath.Join(frameworkDir, "go.sum"))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read nse-framework go.sum")
+	}
+
+	tmpl, err := template.ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse templates")
+	}
+
+	contents := map[string][]byte{
+		filepath.Join(d.ModuleDir, "go.mod"): goMod,
+		filepath.Join(d.ModuleDir, "go.sum"): goSum,
+	}
+	for _, f := range files(d) {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, f.tmpl, f.data); err != nil {
+			return nil, errors.Wrapf(err, "cannot render %s", f.path)
+		}
+		b := buf.Bytes()
+		if strings.HasSuffix(f.path, ".go") {
+			if b, err = format.Source(b); err != nil {
+				return nil, errors.Wrapf(err, "cannot format %s", f.path)
+			}
+		}
+		contents[f.path] = b
+	}
+
+	paths := make([]string, 0, len(contents))
+	for path := range contents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		b := contents[path]
+		full := filepath.Join(outDir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return nil, errors.Wrapf(err, "cannot create dir for %s", path)
+		}
+		if err := os.WriteFile(full, b, 0o644); err != nil {
+			return nil, errors.Wrapf(err, "cannot write %s", path)
+		}
+	}
+	result.Files = paths
+
+	return result, nil
+}
+
+// newData 校验选项并构造模板数据
+func newData(opts *Options) (*data, error) {
+	if !identRe.MatchString(opts.Name) {
+		return nil, errors.Errorf("invalid NSE name %q: must be lowercase letters and digits starting with a letter", opts.Name)
+	}
+	if reserved[opts.Name] {
+		return nil, errors.Errorf("invalid NSE name %q: reserved word", opts.Name)
+	}
+	if !serviceRe.MatchString(opts.ServiceName) {
+		return nil, errors.Errorf("invalid service name %q: must be a DNS label", opts.ServiceName)
+	}
+
+	names := opts.Elements
+	if len(names) == 0 {
+		names = []string{opts.Name}
+	}
+	seen := make(map[string]bool, len(names))
+	elements := make([]element, 0, len(names))
+	for _, n := range names {
+		if !identRe.MatchString(n) {
+			return nil, errors.Errorf("invalid chain element name %q: must be lowercase letters and digits starting with a letter", n)
+		}
+		if reserved[n] {
+			return nil, errors.Errorf("invalid chain element name %q: reserved word", n)
+		}
+		if seen[n] {
+			return nil, errors.Errorf("duplicate chain element %q", n)
+		}
+		seen[n] = true
+		elements = append(elements, element{Name: n, Type: strings.ToUpper(n[:1]) + n[1:]})
+	}
+
+	moduleDir := "cmd-nse-" + opts.Name + "-vpp"
+	image := opts.Image
+	if image == "" {
+		image = defaultImageRepo + "/" + moduleDir + ":latest"
+	}
+	imageRepo := image
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		imageRepo = image[:i]
+	}
+	pad := 26 - len(opts.Name) - 1
+	if pad < 1 {
+		pad = 1
+	}
+
+	return &data{
+		Name:        opts.Name,
+		ServiceName: opts.ServiceName,
+		Module:      modulePrefix + moduleDir,
+		ModuleDir:   moduleDir,
+		Namespace:   "ns-" + opts.Name + "-test",
+		Image:       image,
+		ImageRepo:   imageRepo,
+		Elements:    elements,
+		Pad:         strings.Repeat(" ", pad),
+	}, nil
+}
+
+// files 返回待生成的文件列表
+func files(d *data) []file {
+	mod := d.ModuleDir
+	manifests := "samenode-" + d.Name
+	fs := []file{
+		{tmpl: "main.go.tmpl", path: filepath.Join(mod, "cmd", "main.go"), data: d},
+		{tmpl: "internal_doc.go.tmpl", path: filepath.Join(mod, "internal", d.Name, "doc.go"), data: d},
+		{tmpl: "endpoint.go.tmpl", path: filepath.Join(mod, "internal", d.Name, "endpoint.go"), data: d},
+		{tmpl: "gen.go.tmpl", path: filepath.Join(mod, "internal", "imports", "gen.go"), data: d},
+		{tmpl: "imports_linux.go.tmpl", path: filepath.Join(mod, "internal", "imports", "imports_linux.go"), data: d},
+		{tmpl: "config_doc.go.tmpl", path: filepath.Join(mod, "pkg", "config", "doc.go"), data: d},
+		{tmpl: "config.go.tmpl", path: filepath.Join(mod, "pkg", "config", "config.go"), data: d},
+		{tmpl: "config_test.go.tmpl", path: filepath.Join(mod, "pkg", "config", "config_test.go"), data: d},
+		{tmpl: "Dockerfile.tmpl", path: filepath.Join(mod, "Dockerfile"), data: d},
+		{tmpl: "README.md.tmpl", path: filepath.Join(mod, "README.md"), data: d},
+		{tmpl: "kustomization.yaml.tmpl", path: filepath.Join(manifests, "kustomization.yaml"), data: d},
+		{tmpl: "ns.yaml.tmpl", path: filepath.Join(manifests, "ns.yaml"), data: d},
+		{tmpl: "client.yaml.tmpl", path: filepath.Join(manifests, "client.yaml"), data: d},
+		{tmpl: "sfc.yaml.tmpl", path: filepath.Join(manifests, "sfc.yaml"), data: d},
+		{tmpl: "server-patch.yaml.tmpl", path: filepath.Join(manifests, "server-patch.yaml"), data: d},
+		{tmpl: "nse.yaml.tmpl", path: filepath.Join(manifests, "nse-"+d.Name, d.Name+".yaml"), data: d},
+		{tmpl: "nse_kustomization.yaml.tmpl", path: filepath.Join(manifests, "nse-"+d.Name, "kustomization.yaml"), data: d},
+	}
+	for _, e := range d.Elements {
+		ed := &elementData{NSE: d, Element: e}
+		fs = append(fs,
+			file{tmpl: "element.go.tmpl", path: filepath.Join(mod, "internal", d.Name, e.Name+".go"), data: ed},
+			file{tmpl: "element_test.go.tmpl", path: filepath.Join(mod, "internal", d.Name, e.Name+"_test.go"), data: ed},
+		)
+	}
+	return fs
+}
+
+// renderGoMod 生成新模块的go.mod
+//
+// 依赖版本取自nse-framework的go.mod（只复制require指令），
+// 并通过replace引用本地nse-framework。
+func renderGoMod(module, frameworkGoMod, replacePath string) ([]byte, error) {
+	src, err := os.ReadFile(frameworkGoMod)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read nse-framework go.mod")
+	}
+
+	var goVersion string
+	var requires bytes.Buffer
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			requires.WriteString(line + "\n")
+			if trimmed == ")" {
+				requires.WriteString("\n")
+				inBlock = false
+			}
+		case strings.HasPrefix(trimmed, "go "):
+			goVersion = strings.TrimPrefix(trimmed, "go ")
+		case trimmed == "require (":
+			requires.WriteString(line + "\n")
+			inBlock = true
+		case strings.HasPrefix(trimmed, "require "):
+			requires.WriteString(line + "\n\n")
+		}
+	}
+	if goVersion == "" {
+		return nil, errors.Errorf("no go directive in %s", frameworkGoMod)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("module " + module + "\n\n")
+	b.WriteString("go " + goVersion + "\n\n")
+	b.WriteString("require " + frameworkModule + " " + frameworkVersion + "\n\n")
+	b.Write(requires.Bytes())
+	b.WriteString("replace " + frameworkModule + " => " + replacePath + "\n")
+	return b.Bytes(), nil
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scaffold_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/cmd-template/pkg/scaffold"
+)
+
+// frameworkDir 仓库中nse-framework的目录
+func frameworkDir(t *testing.T) string {
+	_, file, _, ok := runtime.Caller(0)
+	require.True(t, ok)
+	dir, err := filepath.Abs(filepath.Join(filepath.Dir(file), "..", "..", "..", "nse-framework"))
+	require.NoError(t, err)
+	return dir
+}
+
+func generate(t *testing.T, opts scaffold.Options) *scaffold.Result {
+	if opts.OutDir == "" {
+		opts.OutDir = t.TempDir()
+	}
+	opts.FrameworkDir = frameworkDir(t)
+	result, err := scaffold.Generate(opts)
+	require.NoError(t, err)
+	return result
+}
+
+func readFile(t *testing.T, path ...string) string {
+	b, err := os.ReadFile(filepath.Join(path...))
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestGenerate_Files(t *testing.T) {
+	result := generate(t, scaffold.Options{
+		Name:        "qos",
+		ServiceName: "qos-service",
+		Elements:    []string{"classifier", "marker"},
+	})
+
+	require.Equal(t, "cmd-nse-qos-vpp", filepath.Base(result.ModuleDir))
+	require.Equal(t, "samenode-qos", filepath.Base(result.ManifestDir))
+	for _, f := range []string{
+		"cmd-nse-qos-vpp/go.mod",
+		"cmd-nse-qos-vpp/go.sum",
+		"cmd-nse-qos-vpp/Dockerfile",
+		"cmd-nse-qos-vpp/cmd/main.go",
+		"cmd-nse-qos-vpp/internal/imports/imports_linux.go",
+		"cmd-nse-qos-vpp/internal/qos/endpoint.go",
+		"cmd-nse-qos-vpp/internal/qos/classifier.go",
+		"cmd-nse-qos-vpp/internal/qos/classifier_test.go",
+		"cmd-nse-qos-vpp/internal/qos/marker.go",
+		"cmd-nse-qos-vpp/internal/qos/marker_test.go",
+		"cmd-nse-qos-vpp/pkg/config/config.go",
+		"cmd-nse-qos-vpp/pkg/config/config_test.go",
+		"samenode-qos/kustomization.yaml",
+		"samenode-qos/sfc.yaml",
+		"samenode-qos/nse-qos/qos.yaml",
+	} {
+		require.Contains(t, result.Files, filepath.FromSlash(f))
+	}
+
+	// 链元素按给定顺序插入
+	endpoint := readFile(t, result.ModuleDir, "internal", "qos", "endpoint.go")
+	classifier := strings.Index(endpoint, "NewClassifierServer()")
+	marker := strings.Index(endpoint, "NewMarkerServer()")
+	require.True(t, classifier > 0 && marker > classifier, endpoint)
+
+	goMod := readFile(t, result.ModuleDir, "go.mod")
+	require.True(t, strings.HasPrefix(goMod, "module github.com/networkservicemesh/nsm-nse-app/cmd-nse-qos-vpp\n"), goMod)
+	require.Contains(t, goMod, "require github.com/networkservicemesh/nsm-nse-app/nse-framework v0.1.0\n")
+	require.Contains(t, goMod, "github.com/networkservicemesh/sdk-vpp ")
+	require.True(t, strings.HasSuffix(goMod, "replace github.com/networkservicemesh/nsm-nse-app/nse-framework => "+
+		filepath.ToSlash(mustRel(t, result.ModuleDir, frameworkDir(t)))+"\n"), goMod)
+
+	sfc := readFile(t, result.ManifestDir, "sfc.yaml")
+	require.Contains(t, sfc, "name: qos-service")
+	nse := readFile(t, result.ManifestDir, "nse-qos", "qos.yaml")
+	require.Contains(t, nse, "image: ifzzh520/cmd-nse-qos-vpp:latest")
+	require.Contains(t, nse, `value: "app:qos"`)
+}
+
+func TestGenerate_DefaultElement(t *testing.T) {
+	result := generate(t, scaffold.Options{Name: "qos", ServiceName: "qos-service", Image: "example.com/qos:v1"})
+
+	require.Contains(t, result.Files, filepath.FromSlash("cmd-nse-qos-vpp/internal/qos/qos.go"))
+	require.Contains(t, readFile(t, result.ModuleDir, "Dockerfile"), "# 镜像名称: example.com/qos\n")
+	require.Contains(t, readFile(t, result.ManifestDir, "nse-qos", "qos.yaml"), "image: example.com/qos:v1")
+}
+
+func TestGenerate_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    scaffold.Options
+		wantErr string
+	}{
+		{"empty name", scaffold.Options{ServiceName: "s"}, "invalid NSE name"},
+		{"uppercase name", scaffold.Options{Name: "QoS", ServiceName: "s"}, "invalid NSE name"},
+		{"hyphen in name", scaffold.Options{Name: "my-nse", ServiceName: "s"}, "invalid NSE name"},
+		{"reserved name", scaffold.Options{Name: "config", ServiceName: "s"}, "reserved word"},
+		{"empty service", scaffold.Options{Name: "qos"}, "invalid service name"},
+		{"invalid service", scaffold.Options{Name: "qos", ServiceName: "QoS_Service"}, "invalid service name"},
+		{"invalid element", scaffold.Options{Name: "qos", ServiceName: "s", Elements: []string{"Marker"}}, "invalid chain element"},
+		{"reserved element", scaffold.Options{Name: "qos", ServiceName: "s", Elements: []string{"endpoint"}}, "reserved word"},
+		{"duplicate element", scaffold.Options{Name: "qos", ServiceName: "s", Elements: []string{"a", "a"}}, "duplicate chain element"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.OutDir = t.TempDir()
+			tt.opts.FrameworkDir = frameworkDir(t)
+			_, err := scaffold.Generate(tt.opts)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+
+			entries, err := os.ReadDir(tt.opts.OutDir)
+			require.NoError(t, err)
+			require.Empty(t, entries, "nothing should be written for invalid options")
+		})
+	}
+}
+
+func TestGenerate_ExistingModule(t *testing.T) {
+	out := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(out, "cmd-nse-qos-vpp"), 0o755))
+
+	_, err := scaffold.Generate(scaffold.Options{Name: "qos", ServiceName: "qos-service", OutDir: out, FrameworkDir: frameworkDir(t)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already exists")
+}
+
+// TestGenerate_Builds 生成的模块无需修改即可编译并通过测试
+func TestGenerate_Builds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping build of the generated module in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	result := generate(t, scaffold.Options{
+		Name:        "qos",
+		ServiceName: "qos-service",
+		Elements:    []string{"classifier", "marker"},
+	})
+
+	for _, args := range [][]string{
+		{"build", "./..."},
+		{"vet", "./..."},
+		{"test", "./..."},
+	} {
+		cmd := exec.Command(goBin, args...)
+		cmd.Dir = result.ModuleDir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=readonly", "GOPROXY=off", "GOWORK=off")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "go %s:\n%s", strings.Join(args, " "), out)
+	}
+}
+
+func mustRel(t *testing.T, base, target string) string {
+	rel, err := filepath.Rel(base, target)
+	require.NoError(t, err)
+	return rel
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nse-probe 查询NSE的gRPC健康检查服务，用于Kubernetes exec探针
+//
+// 通过SPIFFE Workload API（SPIFFE_ENDPOINT_SOCKET）获取证书，以mTLS连接NSE的gRPC服务器，
+// 查询整体状态（-service为空）或单个组件（vpp、registry、policy）的状态。
+//
+// 用法：
+//
+//	nse-probe -addr unix:///var/lib/nse/nse.sock
+//	nse-probe -addr tcp://127.0.0.1:5003 -service registry -timeout 2s
+//
+// -addr未指定时使用NSM_LISTEN_ON。相对socket名称位于NSE启动时创建的临时目录中，
+// 探针无法定位，因此NSE需要监听绝对路径、抽象socket或TCP地址。
+//
+// 退出码：0表示SERVING，1表示NOT_SERVING或查询失败，2表示参数错误。
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/edwarnicke/grpcfd"
+	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/server"
+)
+
+const (
+	exitServing    = 0
+	exitNotServing = 1
+	exitUsage      = 2
+)
+
+const usage = `Usage: nse-probe [-addr <address>] [-service <component>] [-timeout <duration>]
+
+Query the gRPC health service of an NSE over mTLS (SPIFFE Workload API).
+Exits 0 when the NSE (or the given component) is SERVING, 1 otherwise.
+
+Flags:
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run 执行健康检查并返回退出码
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("nse-probe", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", os.Getenv("NSM_LISTEN_ON"), "NSE listen address: unix:///path, unix:@abstract or tcp://host:port (default: $NSM_LISTEN_ON)")
+	service := fs.String("service", "", "component to check (vpp, registry, policy); empty checks the whole NSE")
+	timeout := fs.Duration("timeout", 3*time.Second, "timeout for obtaining the SVID and the health check")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitServing
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	target, err := dialTarget(*addr)
+	if err != nil {
+		fmt.Fprintf(stderr, "nse-probe: %v\n", err)
+		return exitUsage
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	status, err := check(ctx, target, *service)
+	if err != nil {
+		fmt.Fprintf(stderr, "nse-probe: %v\n", err)
+		return exitNotServing
+	}
+	fmt.Fprintln(stdout, status)
+	if status != healthpb.HealthCheckResponse_SERVING {
+		return exitNotServing
+	}
+	return exitServing
+}
+
+// dialTarget 将NSE监听地址转换为gRPC拨号目标
+func dialTarget(addr string) (string, error) {
+	switch {
+	case addr == "":
+		return "", errors.New("no address: set -addr or NSM_LISTEN_ON")
+	case filepath.IsAbs(addr):
+		return "unix://" + addr, nil
+	case !strings.Contains(addr, ":"):
+		return "", fmt.Errorf("relative socket name %q is created in a temporary directory and cannot be probed; listen on unix:///path or tcp://host:port", addr)
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	switch {
+	case u.Scheme == "tcp" && u.Host != "":
+		return grpcutils.URLToTarget(u), nil
+	case u.Scheme == "unix" && (filepath.IsAbs(u.Path) || strings.HasPrefix(u.Opaque, "@")):
+		return grpcutils.URLToTarget(u), nil
+	case u.Scheme == "unix":
+		return "", fmt.Errorf("relative socket name %q is created in a temporary directory and cannot be probed; listen on unix:///path or tcp://host:port", addr)
+	default:
+		return "", fmt.Errorf("unsupported address %q: expected unix:///path, unix:@abstract or tcp://host:port", addr)
+	}
+}
+
+// check 以mTLS连接NSE并查询健康状态
+func check(ctx context.Context, target, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	source, err := workloadapi.NewX509Source(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error getting x509 source: %w", err)
+	}
+	defer func() { _ = source.Close() }()
+
+	cc, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(grpcfd.TransportCredentials(credentials.NewTLS(server.CreateTLSClientConfig(source)))),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error connecting to %s: %w", target, err)
+	}
+	defer func() { _ = cc.Close() }()
+
+	resp, err := healthpb.NewHealthClient(cc).Check(ctx, &healthpb.HealthCheckRequest{Service: service}, grpc.WaitForReady(true))
+	if err != nil {
+		return 0, fmt.Errorf("health check of %s failed: %w", target, err)
+	}
+	return resp.GetStatus(), nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runCmd(args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(context.Background(), args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestDialTarget(t *testing.T) {
+	for addr, want := range map[string]string{
+		"/var/lib/nse/nse.sock":        "unix:///var/lib/nse/nse.sock",
+		"unix:///var/lib/nse/nse.sock": "unix:///var/lib/nse/nse.sock",
+		"unix:@nse-firewall":           "unix:@nse-firewall",
+		"tcp://127.0.0.1:5003":         "127.0.0.1:5003",
+		"tcp://firewall.nsm.svc:5003":  "firewall.nsm.svc:5003",
+	} {
+		target, err := dialTarget(addr)
+		require.NoError(t, err, addr)
+		require.Equal(t, want, target, addr)
+	}
+
+	for _, addr := range []string{"", "listen.on.sock", "unix://listen.on.sock", "http://localhost:80"} {
+		_, err := dialTarget(addr)
+		require.Error(t, err, addr)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	t.Setenv("NSM_LISTEN_ON", "")
+
+	code, _, stderr := runCmd()
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, "no address")
+
+	code, _, stderr = runCmd("-addr", "listen.on.sock")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, "cannot be probed")
+
+	code, _, _ = runCmd("-addr", "tcp://127.0.0.1:5003", "extra")
+	require.Equal(t, exitUsage, code)
+
+	code, _, _ = runCmd("-h")
+	require.Equal(t, exitServing, code)
+}
+
+func TestRun_NoSVID(t *testing.T) {
+	t.Setenv("SPIFFE_ENDPOINT_SOCKET", "unix://"+filepath.Join(t.TempDir(), "agent.sock"))
+
+	code, _, stderr := runCmd("-addr", "tcp://127.0.0.1:5003", "-timeout", "200ms")
+	require.Equal(t, exitNotServing, code)
+	require.Contains(t, stderr, "error getting x509 source")
+}
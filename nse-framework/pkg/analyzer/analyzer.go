@@ -0,0 +1,416 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ipset"
+)
+
+// Action 策略动作
+type Action string
+
+const (
+	// ActionAllow 允许
+	ActionAllow Action = "allow"
+
+	// ActionDeny 拒绝
+	ActionDeny Action = "deny"
+)
+
+// 列表名称，用于报告
+const (
+	ListAllow = "allow"
+	ListDeny  = "deny"
+)
+
+// Kind 问题类型
+type Kind string
+
+const (
+	// KindShadowed 白名单条目被黑名单完全覆盖
+	KindShadowed Kind = "shadowed"
+
+	// KindDuplicate 同一列表中的重复条目
+	KindDuplicate Kind = "duplicate"
+
+	// KindContained 同一列表中被其他条目包含的条目
+	KindContained Kind = "contained"
+
+	// KindIneffective 在当前默认动作下永远不会改变决策的条目
+	KindIneffective Kind = "ineffective"
+
+	// KindConflict 黑名单条目覆盖了白名单条目的一部分
+	KindConflict Kind = "conflict"
+
+	// KindAggregatable 可以合并为更大前缀的相邻条目
+	KindAggregatable Kind = "aggregatable"
+
+	// KindMode 条目与过滤模式不一致（由调用方根据自身的模式语义添加）
+	KindMode Kind = "mode"
+)
+
+// Severity 问题严重程度
+type Severity string
+
+const (
+	// SeverityError 错误
+	SeverityError Severity = "error"
+
+	// SeverityWarning 警告：条目不起作用或存在冗余
+	SeverityWarning Severity = "warning"
+
+	// SeverityInfo 提示：不影响匹配结果的优化建议
+	SeverityInfo Severity = "info"
+)
+
+var severityOrder = map[Severity]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// Rule 策略中的单个条目
+type Rule struct {
+	// Prefix 规范化后的前缀
+	Prefix netip.Prefix
+
+	// Text 条目的原始表示（用于报告），为空时使用Prefix
+	Text string
+}
+
+func (r Rule) String() string {
+	if r.Text != "" {
+		return r.Text
+	}
+	return r.Prefix.String()
+}
+
+// Policy 待分析的策略
+type Policy struct {
+	// Allow 白名单条目
+	Allow []Rule
+
+	// Deny 黑名单条目（优先于白名单）
+	Deny []Rule
+
+	// DefaultAction 两个列表都不匹配时的动作
+	DefaultAction Action
+}
+
+// Finding 分析发现的单个问题
+type Finding struct {
+	// Kind 问题类型
+	Kind Kind `json:"kind" yaml:"kind"`
+
+	// Severity 严重程度
+	Severity Severity `json:"severity" yaml:"severity"`
+
+	// List 问题条目所在的列表（allow或deny）
+	List string `json:"list" yaml:"list"`
+
+	// Index 问题条目在列表中的序号（从0开始）
+	Index int `json:"index" yaml:"index"`
+
+	// Rule 问题条目
+	Rule string `json:"rule" yaml:"rule"`
+
+	// Related 相关条目（覆盖它的条目、可合并的条目等）
+	Related []string `json:"related,omitempty" yaml:"related,omitempty"`
+
+	// Suggestion 建议（例如聚合后的前缀）
+	Suggestion string `json:"suggestion,omitempty" yaml:"suggestion,omitempty"`
+
+	// Message 可读的问题描述
+	Message string `json:"message" yaml:"message"`
+}
+
+// String 返回问题的单行文本表示
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s[%d] %s: %s", f.Severity, f.List, f.Index, f.Kind, f.Message)
+}
+
+// Report 分析报告
+type Report struct {
+	// Findings 按列表、序号排序的问题列表
+	Findings []Finding `json:"findings" yaml:"findings"`
+}
+
+// Count 返回指定类型的问题数量
+func (r *Report) Count(kind Kind) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// ByKind 返回指定类型的问题
+func (r *Report) ByKind(kind Kind) []Finding {
+	var result []Finding
+	for _, f := range r.Findings {
+		if f.Kind == kind {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// MaxSeverity 返回报告中最高的严重程度，没有问题时返回空字符串
+func (r *Report) MaxSeverity() Severity {
+	var highest Severity
+	for _, f := range r.Findings {
+		if severityOrder[f.Severity] > severityOrder[highest] {
+			highest = f.Severity
+		}
+	}
+	return highest
+}
+
+// AtLeast 判断报告中是否存在不低于指定严重程度的问题
+func (r *Report) AtLeast(severity Severity) bool {
+	return len(r.Findings) > 0 && severityOrder[r.MaxSeverity()] >= severityOrder[severity]
+}
+
+// String 返回报告的多行文本表示
+func (r *Report) String() string {
+	if len(r.Findings) == 0 {
+		return "no issues found"
+	}
+	lines := make([]string, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		lines = append(lines, f.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Analyze 分析策略并生成报告
+func Analyze(p Policy) *Report {
+	report := &Report{}
+
+	redundant := map[string]map[int]bool{
+		ListAllow: checkList(report, ListAllow, p.Allow),
+		ListDeny:  checkList(report, ListDeny, p.Deny),
+	}
+	shadowed := checkShadowed(report, p)
+	checkIneffective(report, p, redundant, shadowed)
+	checkAggregatable(report, ListAllow, p.Allow, redundant[ListAllow])
+	checkAggregatable(report, ListDeny, p.Deny, redundant[ListDeny])
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.List != b.List {
+			return a.List == ListDeny // 黑名单先匹配，先报告
+		}
+		return a.Index < b.Index
+	})
+	return report
+}
+
+// checkList 检查同一列表中的重复和被包含条目，返回冗余条目的序号
+func checkList(report *Report, list string, rules []Rule) map[int]bool {
+	redundant := make(map[int]bool)
+
+	order := make([]int, len(rules))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := rules[order[i]].Prefix, rules[order[j]].Prefix
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c < 0
+		}
+		return a.Bits() < b.Bits()
+	})
+
+	// 排序后包含者总是排在被包含者之前
+	cover, prev := -1, -1
+	for _, idx := range order {
+		p := rules[idx].Prefix
+		switch {
+		case prev >= 0 && rules[prev].Prefix == p:
+			redundant[idx] = true
+			report.Findings = append(report.Findings, Finding{
+				Kind:     KindDuplicate,
+				Severity: SeverityWarning,
+				List:     list,
+				Index:    idx,
+				Rule:     rules[idx].String(),
+				Related:  []string{rules[prev].String()},
+				Message:  fmt.Sprintf("%s duplicates %s[%d] %s", rules[idx], list, prev, rules[prev]),
+			})
+		case cover >= 0 && ipset.Contains(rules[cover].Prefix, p):
+			redundant[idx] = true
+			report.Findings = append(report.Findings, Finding{
+				Kind:     KindContained,
+				Severity: SeverityWarning,
+				List:     list,
+				Index:    idx,
+				Rule:     rules[idx].String(),
+				Related:  []string{rules[cover].String()},
+				Message:  fmt.Sprintf("%s is already covered by %s[%d] %s", rules[idx], list, cover, rules[cover]),
+			})
+			prev = idx
+		default:
+			cover, prev = idx, idx
+		}
+	}
+	return redundant
+}
+
+// checkShadowed 检查被黑名单完全覆盖的白名单条目和部分覆盖的冲突，返回被完全覆盖的白名单序号
+func checkShadowed(report *Report, p Policy) map[int]bool {
+	shadowed := make(map[int]bool)
+
+	for i, allow := range p.Allow {
+		var overlapping []Rule
+		for _, deny := range p.Deny {
+			if deny.Prefix.Overlaps(allow.Prefix) {
+				overlapping = append(overlapping, deny)
+			}
+		}
+		if len(overlapping) == 0 {
+			continue
+		}
+
+		related := make([]string, 0, len(overlapping))
+		denyPrefixes := make([]netip.Prefix, 0, len(overlapping))
+		for _, d := range overlapping {
+			related = append(related, d.String())
+			denyPrefixes = append(denyPrefixes, d.Prefix)
+		}
+
+		if len(ipset.Subtract([]netip.Prefix{allow.Prefix}, denyPrefixes)) == 0 {
+			shadowed[i] = true
+			report.Findings = append(report.Findings, Finding{
+				Kind:     KindShadowed,
+				Severity: SeverityWarning,
+				List:     ListAllow,
+				Index:    i,
+				Rule:     allow.String(),
+				Related:  related,
+				Message:  fmt.Sprintf("%s is fully shadowed by deny rule(s) %s and never allows traffic", allow, strings.Join(related, ", ")),
+			})
+			continue
+		}
+
+		report.Findings = append(report.Findings, Finding{
+			Kind:     KindConflict,
+			Severity: SeverityInfo,
+			List:     ListAllow,
+			Index:    i,
+			Rule:     allow.String(),
+			Related:  related,
+			Message:  fmt.Sprintf("%s is partially overridden by deny rule(s) %s (deny takes precedence)", allow, strings.Join(related, ", ")),
+		})
+	}
+	return shadowed
+}
+
+// checkIneffective 检查在当前默认动作下不会改变决策的条目
+//
+// 默认允许时白名单条目不起作用；默认拒绝时，不与任何有效白名单条目重叠的黑名单条目不起作用。
+// 已报告为冗余或被覆盖的条目不重复报告。
+func checkIneffective(report *Report, p Policy, redundant map[string]map[int]bool, shadowed map[int]bool) {
+	switch p.DefaultAction {
+	case ActionAllow:
+		for i, allow := range p.Allow {
+			if redundant[ListAllow][i] || shadowed[i] {
+				continue
+			}
+			report.Findings = append(report.Findings, Finding{
+				Kind:     KindIneffective,
+				Severity: SeverityWarning,
+				List:     ListAllow,
+				Index:    i,
+				Rule:     allow.String(),
+				Message:  fmt.Sprintf("%s never changes the decision: default action is already allow", allow),
+			})
+		}
+	case ActionDeny:
+		for i, deny := range p.Deny {
+			if redundant[ListDeny][i] {
+				continue
+			}
+			overlaps := false
+			for j, allow := range p.Allow {
+				if !shadowed[j] && allow.Prefix.Overlaps(deny.Prefix) {
+					overlaps = true
+					break
+				}
+			}
+			if overlaps {
+				continue
+			}
+			report.Findings = append(report.Findings, Finding{
+				Kind:     KindIneffective,
+				Severity: SeverityWarning,
+				List:     ListDeny,
+				Index:    i,
+				Rule:     deny.String(),
+				Message:  fmt.Sprintf("%s never changes the decision: it overlaps no allow rule and default action is already deny", deny),
+			})
+		}
+	}
+}
+
+// checkAggregatable 检查同一列表中可以合并为更大前缀的条目
+func checkAggregatable(report *Report, list string, rules []Rule, redundant map[int]bool) {
+	var prefixes []netip.Prefix
+	for i, r := range rules {
+		if !redundant[i] {
+			prefixes = append(prefixes, r.Prefix)
+		}
+	}
+
+	for _, agg := range ipset.Aggregate(prefixes) {
+		var members []int
+		for i, r := range rules {
+			if !redundant[i] && ipset.Contains(agg, r.Prefix) {
+				members = append(members, i)
+			}
+		}
+		if len(members) < 2 {
+			continue
+		}
+
+		related := make([]string, 0, len(members))
+		for _, i := range members {
+			related = append(related, rules[i].String())
+		}
+		report.Findings = append(report.Findings, Finding{
+			Kind:       KindAggregatable,
+			Severity:   SeverityInfo,
+			List:       list,
+			Index:      members[0],
+			Rule:       rules[members[0]].String(),
+			Related:    related,
+			Suggestion: agg.String(),
+			Message:    fmt.Sprintf("%s can be aggregated into %s", strings.Join(related, ", "), agg),
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer_test
+
+import (
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/analyzer"
+)
+
+func rules(prefixes ...string) []analyzer.Rule {
+	var result []analyzer.Rule
+	for _, p := range prefixes {
+		result = append(result, analyzer.Rule{Prefix: netip.MustParsePrefix(p)})
+	}
+	return result
+}
+
+func TestAnalyze_CleanPolicy(t *testing.T) {
+	report := analyzer.Analyze(analyzer.Policy{
+		Allow:         rules("10.0.0.0/8", "192.168.1.0/24"),
+		Deny:          rules("10.99.0.0/16"),
+		DefaultAction: analyzer.ActionDeny,
+	})
+
+	require.Len(t, report.Findings, 1, report.String())
+	require.Equal(t, analyzer.KindConflict, report.Findings[0].Kind, "黑名单覆盖白名单的一部分只是提示")
+	require.Equal(t, analyzer.SeverityInfo, report.MaxSeverity())
+	require.False(t, report.AtLeast(analyzer.SeverityWarning))
+}
+
+func TestAnalyze_Shadowed(t *testing.T) {
+	report := analyzer.Analyze(analyzer.Policy{
+		Allow:         rules("10.1.0.0/16", "172.16.0.0/12"),
+		Deny:          rules("10.0.0.0/8", "172.16.0.0/13", "172.24.0.0/13"),
+		DefaultAction: analyzer.ActionDeny,
+	})
+
+	shadowed := report.ByKind(analyzer.KindShadowed)
+	require.Len(t, shadowed, 2, report.String())
+	require.Equal(t, 0, shadowed[0].Index)
+	require.Equal(t, []string{"10.0.0.0/8"}, shadowed[0].Related)
+	require.Equal(t, 1, shadowed[1].Index, "被多个黑名单条目联合覆盖")
+	require.Len(t, shadowed[1].Related, 2)
+	require.Zero(t, report.Count(analyzer.KindConflict))
+
+	// 被覆盖的白名单不起作用，因此与之重叠的黑名单在默认拒绝时也不起作用
+	require.Equal(t, 3, report.Count(analyzer.KindIneffective))
+	require.True(t, report.AtLeast(analyzer.SeverityWarning))
+}
+
+func TestAnalyze_DuplicateAndContained(t *testing.T) {
+	report := analyzer.Analyze(analyzer.Policy{
+		Allow:         rules("10.1.0.0/16", "10.0.0.0/8", "10.1.0.0/16", "192.168.1.10/32", "192.168.1.10/32"),
+		DefaultAction: analyzer.ActionDeny,
+	})
+
+	contained := report.ByKind(analyzer.KindContained)
+	require.Len(t, contained, 1, report.String())
+	require.Equal(t, 0, contained[0].Index)
+	require.Equal(t, []string{"10.0.0.0/8"}, contained[0].Related)
+
+	duplicates := report.ByKind(analyzer.KindDuplicate)
+	require.Len(t, duplicates, 2)
+	require.Equal(t, 2, duplicates[0].Index)
+	require.Contains(t, duplicates[0].Message, "allow[0]")
+	require.Equal(t, 4, duplicates[1].Index)
+	require.Contains(t, duplicates[1].Message, "allow[3]")
+}
+
+func TestAnalyze_Ineffective(t *testing.T) {
+	report := analyzer.Analyze(analyzer.Policy{
+		Allow:         rules("192.168.1.0/24"),
+		Deny:          rules("192.168.1.50/32", "10.0.0.0/8"),
+		DefaultAction: analyzer.ActionDeny,
+	})
+	ineffective := report.ByKind(analyzer.KindIneffective)
+	require.Len(t, ineffective, 1, report.String())
+	require.Equal(t, analyzer.ListDeny, ineffective[0].List)
+	require.Equal(t, 1, ineffective[0].Index)
+
+	report = analyzer.Analyze(analyzer.Policy{
+		Allow:         rules("192.168.1.0/24"),
+		Deny:          rules("10.0.0.0/8"),
+		DefaultAction: analyzer.ActionAllow,
+	})
+	ineffective = report.ByKind(analyzer.KindIneffective)
+	require.Len(t, ineffective, 1, report.String())
+	require.Equal(t, analyzer.ListAllow, ineffective[0].List)
+}
+
+func TestAnalyze_Aggregatable(t *testing.T) {
+	report := analyzer.Analyze(analyzer.Policy{
+		Deny:          rules("10.0.0.0/25", "1.1.1.1/32", "10.0.0.128/26", "10.0.0.192/26", "10.0.1.0/24"),
+		DefaultAction: analyzer.ActionAllow,
+	})
+
+	agg := report.ByKind(analyzer.KindAggregatable)
+	require.Len(t, agg, 1, report.String())
+	require.Equal(t, "10.0.0.0/23", agg[0].Suggestion)
+	require.Equal(t, []string{"10.0.0.0/25", "10.0.0.128/26", "10.0.0.192/26", "10.0.1.0/24"}, agg[0].Related)
+	require.Equal(t, analyzer.SeverityInfo, agg[0].Severity)
+}
+
+func TestReport_Output(t *testing.T) {
+	report := analyzer.Analyze(analyzer.Policy{
+		Allow:         []analyzer.Rule{{Prefix: netip.MustParsePrefix("10.1.0.0/16"), Text: "10.1.0.0/16 (@lab)"}},
+		Deny:          rules("10.0.0.0/8"),
+		DefaultAction: analyzer.ActionAllow,
+	})
+	require.Equal(t, "warning: allow[0] shadowed: 10.1.0.0/16 (@lab) is fully shadowed by deny rule(s) 10.0.0.0/8 and never allows traffic",
+		report.String())
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"kind":"shadowed"`)
+
+	require.Equal(t, "no issues found", analyzer.Analyze(analyzer.Policy{DefaultAction: analyzer.ActionDeny}).String())
+}
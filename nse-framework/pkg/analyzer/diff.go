@@ -0,0 +1,154 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Decider 返回地址的判定结果，通常包装NSE自身的匹配逻辑
+type Decider func(addr netip.Addr) Action
+
+// Change 判定结果发生变化的连续地址范围
+type Change struct {
+	// From 范围的第一个地址
+	From netip.Addr
+
+	// To 范围的最后一个地址
+	To netip.Addr
+
+	// Before 变化前的判定结果
+	Before Action
+
+	// After 变化后的判定结果
+	After Action
+}
+
+// Prefixes 返回覆盖该范围的最少前缀
+func (c Change) Prefixes() []netip.Prefix {
+	return rangeToPrefixes(c.From, c.To)
+}
+
+// String 返回变化的可读形式，例如 "10.0.0.0/8: allow -> deny"
+func (c Change) String() string {
+	prefixes := c.Prefixes()
+	parts := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		parts = append(parts, p.String())
+	}
+	return fmt.Sprintf("%s: %s -> %s", strings.Join(parts, ", "), c.Before, c.After)
+}
+
+// Prefixes 返回策略中出现的所有前缀（黑名单在前）
+func (p Policy) Prefixes() []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(p.Deny)+len(p.Allow))
+	for _, r := range p.Deny {
+		prefixes = append(prefixes, r.Prefix)
+	}
+	for _, r := range p.Allow {
+		prefixes = append(prefixes, r.Prefix)
+	}
+	return prefixes
+}
+
+// Diff 比较两个判定函数，返回判定结果发生变化的地址范围
+//
+// boundaries应包含两个策略中出现的所有前缀：在相邻边界之间，每个地址
+// 属于的前缀集合相同，因此只需对每个区间的第一个地址求值。
+// IPv4和IPv6地址空间都会被比较，结果按地址排序，相邻的同类变化被合并。
+//
+// 示例：
+//
+//	changes := analyzer.Diff(append(before.Prefixes(), after.Prefixes()...), decideBefore, decideAfter)
+func Diff(boundaries []netip.Prefix, before, after Decider) []Change {
+	points := map[netip.Addr]struct{}{
+		netip.IPv4Unspecified(): {},
+		netip.IPv6Unspecified(): {},
+	}
+	for _, p := range boundaries {
+		p = p.Masked()
+		points[p.Addr()] = struct{}{}
+		if next := lastAddr(p).Next(); next.IsValid() {
+			points[next] = struct{}{}
+		}
+	}
+
+	starts := make([]netip.Addr, 0, len(points))
+	for a := range points {
+		starts = append(starts, a)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Less(starts[j]) })
+
+	var changes []Change
+	for i, from := range starts {
+		to := lastAddr(netip.PrefixFrom(from, 0))
+		if i+1 < len(starts) && starts[i+1].BitLen() == from.BitLen() {
+			to = starts[i+1].Prev()
+		}
+
+		b, a := before(from), after(from)
+		if b == a {
+			continue
+		}
+		if n := len(changes); n > 0 {
+			last := &changes[n-1]
+			if last.Before == b && last.After == a && last.To.Next() == from {
+				last.To = to
+				continue
+			}
+		}
+		changes = append(changes, Change{From: from, To: to, Before: b, After: a})
+	}
+	return changes
+}
+
+// lastAddr 返回前缀中的最后一个地址
+func lastAddr(p netip.Prefix) netip.Addr {
+	p = p.Masked()
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+// rangeToPrefixes 将连续地址范围拆分为最少的前缀
+func rangeToPrefixes(from, to netip.Addr) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for from.IsValid() && !to.Less(from) {
+		// 找到以from开头且不超过to的最大前缀
+		p := netip.PrefixFrom(from, from.BitLen())
+		for bits := 0; bits <= from.BitLen(); bits++ {
+			candidate := netip.PrefixFrom(from, bits)
+			if candidate.Masked().Addr() == from && !to.Less(lastAddr(candidate)) {
+				p = candidate
+				break
+			}
+		}
+		prefixes = append(prefixes, p)
+		from = lastAddr(p).Next()
+	}
+	return prefixes
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/analyzer"
+)
+
+// decider 按黑名单优先的语义对策略求值
+func decider(p analyzer.Policy) analyzer.Decider {
+	return func(addr netip.Addr) analyzer.Action {
+		for _, r := range p.Deny {
+			if r.Prefix.Contains(addr) {
+				return analyzer.ActionDeny
+			}
+		}
+		for _, r := range p.Allow {
+			if r.Prefix.Contains(addr) {
+				return analyzer.ActionAllow
+			}
+		}
+		return p.DefaultAction
+	}
+}
+
+func diff(before, after analyzer.Policy) []string {
+	changes := analyzer.Diff(append(before.Prefixes(), after.Prefixes()...), decider(before), decider(after))
+	result := make([]string, 0, len(changes))
+	for _, c := range changes {
+		result = append(result, c.String())
+	}
+	return result
+}
+
+func TestDiff_Identical(t *testing.T) {
+	p := analyzer.Policy{
+		Allow:         rules("10.0.0.0/8"),
+		Deny:          rules("10.99.0.0/16"),
+		DefaultAction: analyzer.ActionDeny,
+	}
+	require.Empty(t, diff(p, p))
+}
+
+func TestDiff_AddedDenyRule(t *testing.T) {
+	before := analyzer.Policy{Allow: rules("10.0.0.0/8"), DefaultAction: analyzer.ActionDeny}
+	after := analyzer.Policy{Allow: rules("10.0.0.0/8"), Deny: rules("10.1.0.0/16", "10.2.0.0/16"), DefaultAction: analyzer.ActionDeny}
+
+	require.Equal(t, []string{"10.1.0.0/16, 10.2.0.0/16: allow -> deny"}, diff(before, after), "相邻的同类变化应合并为一个范围")
+}
+
+func TestDiff_DefaultActionChange(t *testing.T) {
+	before := analyzer.Policy{Deny: rules("192.168.1.0/24"), DefaultAction: analyzer.ActionAllow}
+	after := analyzer.Policy{Deny: rules("192.168.1.0/24"), DefaultAction: analyzer.ActionDeny}
+
+	require.Equal(t, []string{
+		"0.0.0.0/1, 128.0.0.0/2, 192.0.0.0/9, 192.128.0.0/11, 192.160.0.0/13, 192.168.0.0/24: allow -> deny",
+		"192.168.2.0/23, 192.168.4.0/22, 192.168.8.0/21, 192.168.16.0/20, 192.168.32.0/19, 192.168.64.0/18, " +
+			"192.168.128.0/17, 192.169.0.0/16, 192.170.0.0/15, 192.172.0.0/14, 192.176.0.0/12, 192.192.0.0/10, 193.0.0.0/8, " +
+			"194.0.0.0/7, 196.0.0.0/6, 200.0.0.0/5, 208.0.0.0/4, 224.0.0.0/3: allow -> deny",
+		"::/0: allow -> deny",
+	}, diff(before, after))
+}
+
+func TestDiff_MovedRule(t *testing.T) {
+	before := analyzer.Policy{Allow: rules("10.0.0.0/24", "2001:db8::/32"), DefaultAction: analyzer.ActionDeny}
+	after := analyzer.Policy{Allow: rules("10.0.1.0/24", "2001:db8::/32"), DefaultAction: analyzer.ActionDeny}
+
+	changes := analyzer.Diff(append(before.Prefixes(), after.Prefixes()...), decider(before), decider(after))
+	require.Len(t, changes, 2)
+	require.Equal(t, netip.MustParseAddr("10.0.0.0"), changes[0].From)
+	require.Equal(t, netip.MustParseAddr("10.0.0.255"), changes[0].To)
+	require.Equal(t, analyzer.ActionAllow, changes[0].Before)
+	require.Equal(t, analyzer.ActionDeny, changes[0].After)
+	require.Equal(t, "10.0.1.0/24: deny -> allow", changes[1].String())
+}
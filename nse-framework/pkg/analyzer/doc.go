@@ -0,0 +1,28 @@
+// Package analyzer 提供IP访问策略的静态分析功能
+//
+// 策略由白名单（allow）、黑名单（deny）和默认动作组成，匹配时黑名单优先。
+// 分析器在不运行NSE的情况下检查策略中的问题，并生成结构化报告：
+//   - shadowed: 白名单条目被黑名单完全覆盖，永远不会放行
+//   - duplicate: 同一列表中的重复条目
+//   - contained: 同一列表中被其他条目包含的条目
+//   - ineffective: 在当前默认动作下永远不会改变决策的条目
+//   - conflict: 黑名单条目覆盖了白名单条目的一部分
+//   - aggregatable: 同一列表中可以合并为更大前缀的相邻条目
+//   - mode: 条目与过滤模式不一致（由调用方添加）
+//
+// 报告可用于启动时验证（记录日志）、命令行工具（文本/JSON输出）和单元测试。
+//
+// Diff比较两个版本的策略，返回判定结果发生变化的地址范围。判定由调用方
+// 提供的Decider完成，因此比较结果与NSE实际的匹配逻辑一致。
+//
+// 使用示例：
+//
+//	report := analyzer.Analyze(analyzer.Policy{
+//	    Allow:         allowRules,
+//	    Deny:          denyRules,
+//	    DefaultAction: analyzer.ActionDeny,
+//	})
+//	for _, f := range report.Findings {
+//	    fmt.Println(f)
+//	}
+package analyzer
@@ -0,0 +1,200 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+)
+
+// Base NSE通用配置，由各NSE的配置结构体嵌入
+//
+// 所有配置项从"NSM_"前缀的环境变量读取，例如NSM_NAME、NSM_CONNECT_TO，
+// 未设置环境变量的配置项可以在NSM_CONFIG_FILE指定的配置文件中设置（见Load）。
+// 提供的网络服务由NSM_SERVICE_NAME与NSM_SERVICE_NAMES共同确定（见Services），
+// NSM_LABELS为所有服务共用的标签，NSM_SERVICE_LABELS为各服务单独的标签。
+type Base struct {
+	Name                    string            `default:"" desc:"Name of Network Service Endpoint"`
+	ListenOn                string            `default:"listen.on.sock" desc:"listen on socket name, unix:///path, unix:@abstract or tcp://host:port" split_words:"true"`
+	ListenSocketMode        os.FileMode       `default:"0777" desc:"file mode of the listen unix socket" split_words:"true"`
+	ListenSocketOwner       string            `default:"" desc:"owner of the listen unix socket as uid[:gid] or user[:group]" split_words:"true"`
+	AdvertiseHost           string            `default:"" desc:"host advertised to NSM for tcp listen addresses" split_words:"true"`
+	GRPCReflection          bool              `default:"false" desc:"register gRPC server reflection for debugging" split_words:"true"`
+	ConnectTo               url.URL           `default:"unix:///var/lib/networkservicemesh/nsm.io.sock" desc:"url to connect to" split_words:"true"`
+	MaxTokenLifetime        time.Duration     `default:"10m" desc:"maximum lifetime of tokens" split_words:"true"`
+	RegistryClientPolicies  []string          `default:"etc/nsm/opa/common/.*.rego,etc/nsm/opa/registry/.*.rego,etc/nsm/opa/client/.*.rego" desc:"paths to files and directories that contain registry client policies" split_words:"true"`
+	ServiceName             string            `default:"" desc:"Name of providing service" split_words:"true"`
+	ServiceNames            []string          `default:"" desc:"Names of additional providing services" split_words:"true"`
+	Labels                  map[string]string `default:"" desc:"Endpoint labels"`
+	ServiceLabels           ServiceLabels     `default:"" desc:"Per-service labels, e.g. svc1=k1:v1,k2:v2;svc2=k3:v3" split_words:"true"`
+	LogLevel                string            `default:"INFO" desc:"Log level" split_words:"true"`
+	OpenTelemetryEndpoint   string            `default:"otel-collector.observability.svc.cluster.local:4317" desc:"OpenTelemetry Collector Endpoint" split_words:"true"`
+	MetricsExportInterval   time.Duration     `default:"10s" desc:"interval between mertics exports" split_words:"true"`
+	PprofEnabled            bool              `default:"false" desc:"is pprof enabled" split_words:"true"`
+	PprofListenOn           string            `default:"localhost:6060" desc:"pprof URL to ListenAndServe" split_words:"true"`
+	HTTPEnabled             bool              `default:"false" desc:"serve /healthz, /readyz and /metrics over HTTP" split_words:"true"`
+	HTTPListenOn            string            `default:"" desc:"HTTP listen address, shares PprofListenOn when empty" split_words:"true"`
+	LivenessRegistryTimeout time.Duration     `default:"5m" desc:"failing registration longer than this fails /healthz, 0 disables" split_words:"true"`
+	VPPMode                 string            `default:"managed" desc:"managed starts VPP in the NSE container, external dials an existing VPP" split_words:"true"`
+	VPPAPISocket            string            `default:"/var/run/vpp/api.sock" desc:"VPP API socket path" envconfig:"VPP_API_SOCKET"`
+	VPPConnectTimeout       time.Duration     `default:"30s" desc:"timeout for the VPP API socket to appear and connect" split_words:"true"`
+	VPPRetryInterval        time.Duration     `default:"1s" desc:"interval between VPP connect attempts" split_words:"true"`
+	VPPReconnectAttempts    int               `default:"10" desc:"consecutive failed VPP reconnect attempts before the NSE stops" split_words:"true"`
+	VPPWorkers              int               `default:"0" desc:"number of VPP worker threads in managed mode" split_words:"true"`
+	VPPMainCore             int               `default:"-1" desc:"CPU core of the VPP main thread in managed mode, -1 disables pinning" split_words:"true"`
+	VPPCorelistWorkers      string            `default:"" desc:"CPU cores of the VPP worker threads in managed mode, e.g. 2-3,6" split_words:"true"`
+	VPPBuffersPerNuma       int               `default:"32768" desc:"VPP buffers per NUMA node in managed mode" split_words:"true"`
+	VPPBufferDataSize       int               `default:"2048" desc:"VPP buffer data size in managed mode" split_words:"true"`
+	VPPHugepages            bool              `default:"false" desc:"use hugepages for VPP buffers and main heap in managed mode" split_words:"true"`
+	VPPPlugins              []string          `default:"" desc:"VPP plugins to enable in managed mode in addition to those the endpoint needs" split_words:"true"`
+	VPPDisabledPlugins      []string          `default:"" desc:"VPP plugins to disable in managed mode" split_words:"true"`
+
+	// origins 各配置项生效值的来源，键为环境变量名
+	origins map[string]Origin
+}
+
+// Extension NSE配置接口
+//
+// 嵌入Base的配置结构体自动获得BaseConfig方法，
+// Validate由NSE实现（通常先调用Base.Validate再校验业务配置项）。
+type Extension interface {
+	// BaseConfig 返回嵌入的通用配置
+	BaseConfig() *Base
+
+	// Validate 验证配置的完整性和有效性
+	Validate() error
+}
+
+// BaseConfig 返回通用配置本身，使嵌入Base的结构体实现Extension
+func (b *Base) BaseConfig() *Base {
+	return b
+}
+
+// usageKey 上下文中关闭使用说明输出的标记
+type usageKey struct{}
+
+// WithoutUsage 返回Load不打印环境变量使用说明的上下文（用于--print-config等需要干净输出的场景）
+func WithoutUsage(ctx context.Context) context.Context {
+	return context.WithValue(ctx, usageKey{}, true)
+}
+
+// Load 从配置文件和环境变量加载配置到cfg
+//
+// cfg是嵌入Base的配置结构体指针，通用配置项和业务配置项一起加载，优先级从高到低：
+//  1. "NSM_"前缀的环境变量（使用envconfig库读取）
+//  2. NSM_CONFIG_FILE指定的YAML或JSON配置文件，键为环境变量名去掉"NSM_"前缀后的小驼峰形式
+//     （如NSM_LISTEN_ON对应listenOn），列表和映射可以使用YAML结构
+//  3. 结构体标签中的默认值；NSM_NAME未设置时使用name作为NSE名称
+//
+// 每个配置项的来源可以通过Base.Origin查询，Print打印生效配置及其来源。
+//
+// 配置文件示例：
+//
+//	name: firewall-server
+//	serviceNames: [firewall, audit]
+//	labels:
+//	  app: firewall
+//	logLevel: DEBUG
+//
+// 示例：
+//
+//	c := new(Config)
+//	if err := config.Load(ctx, c, "firewall-server"); err != nil {
+//	    log.Fatal(err)
+//	}
+func Load(ctx context.Context, cfg Extension, name string) error {
+	// 打印环境变量使用说明
+	if ctx.Value(usageKey{}) == nil {
+		if err := envconfig.Usage("nsm", cfg); err != nil {
+			return errors.Wrap(err, "cannot show usage of envconfig nsm")
+		}
+	}
+
+	// 从环境变量加载配置（未设置的配置项使用默认值）
+	if err := envconfig.Process("nsm", cfg); err != nil {
+		return errors.Wrap(err, "cannot process envconfig nsm")
+	}
+
+	// 未设置环境变量的配置项使用配置文件中的值
+	if err := applyFile(cfg, os.Getenv(ConfigFileEnv)); err != nil {
+		return errors.Wrap(err, "cannot process config file")
+	}
+
+	if b := cfg.BaseConfig(); b.Name == "" {
+		b.Name = name
+	}
+
+	return nil
+}
+
+// Validate 验证通用配置的完整性和有效性
+//
+// 检查必填字段是否存在，URL格式是否正确。
+// 返回第一个发现的验证错误。
+func (b *Base) Validate() error {
+	// 检查必填字段
+	if b.Name == "" {
+		return errors.New("Name is required")
+	}
+	if err := b.validateServices(); err != nil {
+		return err
+	}
+
+	// 验证ConnectTo URL格式
+	if b.ConnectTo.String() == "" {
+		return errors.New("ConnectTo URL is required")
+	}
+
+	if b.LivenessRegistryTimeout < 0 {
+		return errors.New("LivenessRegistryTimeout must not be negative")
+	}
+
+	// 验证VPP运行方式，空值等同于managed，零值的超时和次数使用vpp包的默认值
+	switch b.VPPMode {
+	case "", "managed", "external":
+	default:
+		return errors.Errorf("VPPMode must be managed or external, got %q", b.VPPMode)
+	}
+	if b.VPPMode == "external" && !filepath.IsAbs(b.VPPAPISocket) {
+		return errors.Errorf("VPPAPISocket must be an absolute path, got %q", b.VPPAPISocket)
+	}
+	if b.VPPConnectTimeout < 0 || b.VPPRetryInterval < 0 || b.VPPReconnectAttempts < 0 {
+		return errors.New("VPPConnectTimeout, VPPRetryInterval and VPPReconnectAttempts must not be negative")
+	}
+
+	return nil
+}
+
+// HTTPAddress 返回HTTP服务器（/healthz、/readyz、/metrics）的监听地址
+//
+// HTTPListenOn为空时与pprof共用PprofListenOn。
+func (b *Base) HTTPAddress() string {
+	if b.HTTPListenOn != "" {
+		return b.HTTPListenOn
+	}
+	return b.PprofListenOn
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
+)
+
+// testConfig 模拟NSE嵌入Base并添加业务配置项
+type testConfig struct {
+	config.Base
+	FilterPath string `default:"/etc/test/filter.yaml" desc:"Path to filter file" split_words:"true"`
+}
+
+func TestLoad_DefaultValues(t *testing.T) {
+	clearEnv(t)
+
+	cfg := new(testConfig)
+	require.NoError(t, config.Load(context.Background(), cfg, "test-server"))
+
+	require.Equal(t, "test-server", cfg.Name)
+	require.Equal(t, "listen.on.sock", cfg.ListenOn)
+	require.Equal(t, os.FileMode(0o777), cfg.ListenSocketMode)
+	require.Empty(t, cfg.ListenSocketOwner)
+	require.Empty(t, cfg.AdvertiseHost)
+	require.Equal(t, "unix:///var/lib/networkservicemesh/nsm.io.sock", cfg.ConnectTo.String())
+	require.Equal(t, 10*time.Minute, cfg.MaxTokenLifetime)
+	require.Equal(t, "INFO", cfg.LogLevel)
+	require.Equal(t, 10*time.Second, cfg.MetricsExportInterval)
+	require.False(t, cfg.PprofEnabled)
+	require.Equal(t, "localhost:6060", cfg.PprofListenOn)
+	require.False(t, cfg.HTTPEnabled)
+	require.Equal(t, "localhost:6060", cfg.HTTPAddress(), "未设置时与pprof共用地址")
+	require.Equal(t, 5*time.Minute, cfg.LivenessRegistryTimeout)
+	require.Equal(t, "/etc/test/filter.yaml", cfg.FilterPath)
+}
+
+func TestLoad_CustomValues(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("NSM_NAME", "custom-server")
+	t.Setenv("NSM_SERVICE_NAME", "custom-service")
+	t.Setenv("NSM_LABELS", "app:custom,tier:edge")
+	t.Setenv("NSM_FILTER_PATH", "/tmp/filter.yaml")
+	t.Setenv("NSM_LISTEN_ON", "tcp://0.0.0.0:5003")
+	t.Setenv("NSM_LISTEN_SOCKET_MODE", "0660")
+	t.Setenv("NSM_LISTEN_SOCKET_OWNER", "1000:nsm")
+	t.Setenv("NSM_ADVERTISE_HOST", "10.0.0.5")
+
+	cfg := new(testConfig)
+	require.NoError(t, config.Load(context.Background(), cfg, "test-server"))
+
+	require.Equal(t, "custom-server", cfg.Name)
+	require.Equal(t, "custom-service", cfg.ServiceName)
+	require.Equal(t, map[string]string{"app": "custom", "tier": "edge"}, cfg.Labels)
+	require.Equal(t, "/tmp/filter.yaml", cfg.FilterPath)
+	require.Equal(t, "tcp://0.0.0.0:5003", cfg.ListenOn)
+	require.Equal(t, os.FileMode(0o660), cfg.ListenSocketMode, "权限按八进制解析")
+	require.Equal(t, "1000:nsm", cfg.ListenSocketOwner)
+	require.Equal(t, "10.0.0.5", cfg.AdvertiseHost)
+	require.Same(t, &cfg.Base, cfg.BaseConfig())
+}
+
+func TestLoad_HTTP(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("NSM_HTTP_ENABLED", "true")
+	t.Setenv("NSM_HTTP_LISTEN_ON", ":8080")
+	t.Setenv("NSM_LIVENESS_REGISTRY_TIMEOUT", "0")
+
+	cfg := new(testConfig)
+	require.NoError(t, config.Load(context.Background(), cfg, "test-server"))
+
+	require.True(t, cfg.HTTPEnabled)
+	require.Equal(t, ":8080", cfg.HTTPAddress())
+	require.Zero(t, cfg.LivenessRegistryTimeout)
+}
+
+func TestLoad_VPP(t *testing.T) {
+	clearEnv(t)
+
+	cfg := new(testConfig)
+	require.NoError(t, config.Load(context.Background(), cfg, "test-server"))
+	require.Equal(t, "managed", cfg.VPPMode)
+	require.Equal(t, "/var/run/vpp/api.sock", cfg.VPPAPISocket)
+	require.Equal(t, 30*time.Second, cfg.VPPConnectTimeout)
+	require.Equal(t, time.Second, cfg.VPPRetryInterval)
+	require.Equal(t, 10, cfg.VPPReconnectAttempts)
+	require.Zero(t, cfg.VPPWorkers)
+	require.Equal(t, -1, cfg.VPPMainCore)
+	require.Equal(t, 32768, cfg.VPPBuffersPerNuma)
+	require.Equal(t, 2048, cfg.VPPBufferDataSize)
+	require.False(t, cfg.VPPHugepages)
+	require.Empty(t, cfg.VPPPlugins)
+
+	t.Setenv("NSM_VPP_WORKERS", "2")
+	t.Setenv("NSM_VPP_MAIN_CORE", "1")
+	t.Setenv("NSM_VPP_CORELIST_WORKERS", "2-3")
+	t.Setenv("NSM_VPP_HUGEPAGES", "true")
+	t.Setenv("NSM_VPP_PLUGINS", "nat44_ei,ping")
+	t.Setenv("NSM_VPP_DISABLED_PLUGINS", "linux_cp")
+
+	cfg = new(testConfig)
+	require.NoError(t, config.Load(context.Background(), cfg, "test-server"))
+	require.Equal(t, 2, cfg.VPPWorkers)
+	require.Equal(t, 1, cfg.VPPMainCore)
+	require.Equal(t, "2-3", cfg.VPPCorelistWorkers)
+	require.True(t, cfg.VPPHugepages)
+	require.Equal(t, []string{"nat44_ei", "ping"}, cfg.VPPPlugins)
+	require.Equal(t, []string{"linux_cp"}, cfg.VPPDisabledPlugins)
+
+	t.Setenv("NSM_VPP_MODE", "external")
+	t.Setenv("NSM_VPP_API_SOCKET", "/run/vpp/shared/api.sock")
+	t.Setenv("NSM_VPP_CONNECT_TIMEOUT", "5s")
+	t.Setenv("NSM_VPP_RETRY_INTERVAL", "200ms")
+	t.Setenv("NSM_VPP_RECONNECT_ATTEMPTS", "3")
+
+	cfg = new(testConfig)
+	require.NoError(t, config.Load(context.Background(), cfg, "test-server"))
+	require.Equal(t, "external", cfg.VPPMode)
+	require.Equal(t, "/run/vpp/shared/api.sock", cfg.VPPAPISocket)
+	require.Equal(t, 5*time.Second, cfg.VPPConnectTimeout)
+	require.Equal(t, 200*time.Millisecond, cfg.VPPRetryInterval)
+	require.Equal(t, 3, cfg.VPPReconnectAttempts)
+}
+
+func TestLoad_InvalidValue(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("NSM_MAX_TOKEN_LIFETIME", "forever")
+
+	err := config.Load(context.Background(), new(testConfig), "test-server")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cannot process envconfig nsm")
+}
+
+func TestValidate(t *testing.T) {
+	valid := config.Base{
+		Name:        "test-server",
+		ServiceName: "test-service",
+		ConnectTo:   url.URL{Scheme: "unix", Path: "/test/path"},
+	}
+	require.NoError(t, valid.Validate())
+
+	missingName := valid
+	missingName.Name = ""
+	require.ErrorContains(t, missingName.Validate(), "Name is required")
+
+	missingService := valid
+	missingService.ServiceName = ""
+	require.ErrorContains(t, missingService.Validate(), "ServiceName is required")
+
+	missingConnectTo := valid
+	missingConnectTo.ConnectTo = url.URL{}
+	require.ErrorContains(t, missingConnectTo.Validate(), "ConnectTo URL is required")
+
+	negativeTimeout := valid
+	negativeTimeout.LivenessRegistryTimeout = -time.Second
+	require.ErrorContains(t, negativeTimeout.Validate(), "LivenessRegistryTimeout must not be negative")
+
+	unknownMode := valid
+	unknownMode.VPPMode = "remote"
+	require.ErrorContains(t, unknownMode.Validate(), "VPPMode must be managed or external")
+
+	relativeSocket := valid
+	relativeSocket.VPPMode = "external"
+	relativeSocket.VPPAPISocket = "api.sock"
+	require.ErrorContains(t, relativeSocket.Validate(), "VPPAPISocket must be an absolute path")
+
+	negativeRetry := valid
+	negativeRetry.VPPRetryInterval = -time.Second
+	require.ErrorContains(t, negativeRetry.Validate(), "must not be negative")
+}
+
+func TestLoad_Services(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("NSM_SERVICE_NAME", "firewall")
+	t.Setenv("NSM_SERVICE_NAMES", "qos,firewall,nat")
+	t.Setenv("NSM_LABELS", "app:sfc")
+	t.Setenv("NSM_SERVICE_LABELS", "firewall=tier:edge; qos=tier:core,class:gold")
+
+	cfg := new(testConfig)
+	require.NoError(t, config.Load(context.Background(), cfg, "test-server"))
+	require.Equal(t, []string{"qos", "firewall", "nat"}, cfg.ServiceNames)
+	require.Equal(t, []config.Service{
+		{Name: "firewall", Labels: map[string]string{"tier": "edge"}},
+		{Name: "qos", Labels: map[string]string{"tier": "core", "class": "gold"}},
+		{Name: "nat"},
+	}, cfg.Services())
+	require.NoError(t, cfg.Validate(), "ServiceNames可以包含ServiceName")
+
+	cfg.ServiceNames = []string{"qos", "nat", "qos"}
+	require.ErrorContains(t, cfg.Validate(), "duplicate service qos")
+}
+
+func TestLoad_InvalidServiceLabels(t *testing.T) {
+	for _, value := range []string{"firewall", "=app:x", "firewall=app", "a=x:1;a=x:2"} {
+		clearEnv(t)
+		t.Setenv("NSM_SERVICE_LABELS", value)
+
+		err := config.Load(context.Background(), new(testConfig), "test-server")
+		require.Error(t, err, value)
+	}
+}
+
+func TestValidate_Services(t *testing.T) {
+	valid := config.Base{
+		Name:         "test-server",
+		ServiceNames: []string{"svc-a", "svc-b"},
+		ServiceLabels: config.ServiceLabels{
+			"svc-b": {"app": "b"},
+		},
+		ConnectTo: url.URL{Scheme: "unix", Path: "/test/path"},
+	}
+	require.NoError(t, valid.Validate(), "只设置ServiceNames即可")
+
+	empty := valid
+	empty.ServiceNames = []string{"svc-a", ""}
+	require.ErrorContains(t, empty.Validate(), "empty service name")
+
+	unknown := valid
+	unknown.ServiceLabels = config.ServiceLabels{"svc-c": {"app": "c"}}
+	require.ErrorContains(t, unknown.Validate(), "unknown service svc-c")
+}
+
+// clearEnv 清除测试涉及的环境变量
+func clearEnv(t *testing.T) {
+	envVars := []string{
+		"NSM_NAME",
+		"NSM_LISTEN_ON",
+		"NSM_LISTEN_SOCKET_MODE",
+		"NSM_LISTEN_SOCKET_OWNER",
+		"NSM_ADVERTISE_HOST",
+		"NSM_CONNECT_TO",
+		"NSM_MAX_TOKEN_LIFETIME",
+		"NSM_REGISTRY_CLIENT_POLICIES",
+		"NSM_SERVICE_NAME",
+		"NSM_SERVICE_NAMES",
+		"NSM_LABELS",
+		"NSM_SERVICE_LABELS",
+		"NSM_LOG_LEVEL",
+		"NSM_OPEN_TELEMETRY_ENDPOINT",
+		"NSM_METRICS_EXPORT_INTERVAL",
+		"NSM_PPROF_ENABLED",
+		"NSM_PPROF_LISTEN_ON",
+		"NSM_HTTP_ENABLED",
+		"NSM_HTTP_LISTEN_ON",
+		"NSM_LIVENESS_REGISTRY_TIMEOUT",
+		"NSM_VPP_MODE",
+		"NSM_VPP_API_SOCKET",
+		"NSM_VPP_CONNECT_TIMEOUT",
+		"NSM_VPP_RETRY_INTERVAL",
+		"NSM_VPP_RECONNECT_ATTEMPTS",
+		"NSM_VPP_WORKERS",
+		"NSM_VPP_MAIN_CORE",
+		"NSM_VPP_CORELIST_WORKERS",
+		"NSM_VPP_BUFFERS_PER_NUMA",
+		"NSM_VPP_BUFFER_DATA_SIZE",
+		"NSM_VPP_HUGEPAGES",
+		"NSM_VPP_PLUGINS",
+		"NSM_VPP_DISABLED_PLUGINS",
+		"NSM_FILTER_PATH",
+		"NSM_CONFIG_FILE",
+	}
+
+	for _, v := range envVars {
+		os.Unsetenv(v)
+	}
+
+	t.Cleanup(func() {
+		for _, v := range envVars {
+			os.Unsetenv(v)
+		}
+	})
+}
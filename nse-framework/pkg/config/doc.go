@@ -0,0 +1,19 @@
+// Package config 提供所有NSE共用的基础配置
+//
+// 本包定义NSE启动流程需要的通用配置项（名称、监听地址、NSM连接地址、
+// 服务名称、标签、日志和可观测性等），各NSE在自己的配置结构体中
+// 嵌入Base并添加业务配置项，通过Load统一从NSM_*环境变量和NSM_CONFIG_FILE
+// 配置文件加载（环境变量优先），并记录每个配置项的来源；Print打印生效配置。
+//
+// 使用示例：
+//
+//	type Config struct {
+//	    config.Base
+//	    ACLConfigPath string `default:"/etc/firewall/config.yaml" split_words:"true"`
+//	}
+//
+//	c := new(Config)
+//	if err := config.Load(ctx, c, "firewall-server"); err != nil {
+//	    log.Fatal(err)
+//	}
+package config
@@ -0,0 +1,375 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv 指定配置文件路径的环境变量
+const ConfigFileEnv = "NSM_CONFIG_FILE"
+
+// 配置项生效值的来源
+const (
+	// SourceDefault 结构体标签中的默认值（或NSE代码设置的默认值）
+	SourceDefault = "default"
+	// SourceFile NSM_CONFIG_FILE指定的配置文件
+	SourceFile = "file"
+	// SourceEnv 环境变量
+	SourceEnv = "env"
+)
+
+// Origin 配置项生效值的来源
+type Origin struct {
+	// Source 来源：SourceDefault、SourceFile或SourceEnv
+	Source string
+	// Location 配置文件路径或环境变量名（SourceDefault时为空）
+	Location string
+}
+
+// String 返回"env NSM_NAME"、"file /etc/nsm/config.yaml"或"default"形式的描述
+func (o Origin) String() string {
+	if o.Location == "" {
+		return o.Source
+	}
+	return o.Source + " " + o.Location
+}
+
+// Origin 返回环境变量名为key（如NSM_LISTEN_ON）的配置项的来源
+//
+// 未经Load加载的配置项返回SourceDefault。
+func (b *Base) Origin(key string) Origin {
+	if o, ok := b.origins[key]; ok {
+		return o
+	}
+	return Origin{Source: SourceDefault}
+}
+
+// field 配置结构体中的一个配置项
+type field struct {
+	// key 环境变量名，如NSM_LISTEN_ON
+	key string
+	// alt envconfig标签指定的不带前缀的环境变量名
+	alt string
+	// name 配置文件中的键，如listenOn
+	name  string
+	value reflect.Value
+	tag   reflect.StructTag
+}
+
+var (
+	gatherRegexp  = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
+	acronymRegexp = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
+)
+
+// fields 按envconfig的规则列出cfg（结构体指针）的配置项
+func fields(cfg interface{}) []field {
+	var result []field
+	collectFields("NSM", reflect.ValueOf(cfg).Elem(), &result)
+	return result
+}
+
+// collectFields 递归收集结构体s中的配置项，环境变量名的生成规则与envconfig相同
+func collectFields(prefix string, s reflect.Value, result *[]field) {
+	t := s.Type()
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		ft := t.Field(i)
+		if !f.CanSet() || isTrue(ft.Tag.Get("ignored")) {
+			continue
+		}
+		for f.Kind() == reflect.Ptr {
+			if f.IsNil() {
+				if f.Type().Elem().Kind() != reflect.Struct {
+					break
+				}
+				f.Set(reflect.New(f.Type().Elem()))
+			}
+			f = f.Elem()
+		}
+
+		alt := strings.ToUpper(ft.Tag.Get("envconfig"))
+		key := ft.Name
+		if isTrue(ft.Tag.Get("split_words")) {
+			var words []string
+			for _, w := range gatherRegexp.FindAllString(ft.Name, -1) {
+				if m := acronymRegexp.FindStringSubmatch(w); len(m) == 3 {
+					words = append(words, m[1], m[2])
+				} else {
+					words = append(words, w)
+				}
+			}
+			key = strings.Join(words, "_")
+		}
+		if alt != "" {
+			key = alt
+		}
+		key = strings.ToUpper(prefix + "_" + key)
+
+		if f.Kind() == reflect.Struct && !isLeaf(f) {
+			innerPrefix := prefix
+			if !ft.Anonymous {
+				innerPrefix = key
+			}
+			collectFields(innerPrefix, f, result)
+			continue
+		}
+		*result = append(*result, field{key: key, alt: alt, name: fileKey(key), value: f, tag: ft.Tag})
+	}
+}
+
+// isLeaf 值可以自行从字符串解析时（如url.URL）作为单个配置项，而不展开其字段
+func isLeaf(v reflect.Value) bool {
+	switch v.Addr().Interface().(type) {
+	case envconfig.Decoder, envconfig.Setter, encoding.TextUnmarshaler, encoding.BinaryUnmarshaler:
+		return true
+	}
+	return false
+}
+
+// fileKey 将环境变量名转换为配置文件中的键：去掉NSM_前缀后转为小驼峰，如NSM_LISTEN_ON对应listenOn
+func fileKey(key string) string {
+	parts := strings.Split(strings.ToLower(strings.TrimPrefix(key, "NSM_")), "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// lookupEnv 返回配置项对应的环境变量（与envconfig相同，未设置带前缀的变量时查找不带前缀的变量）
+func (f *field) lookupEnv() (string, bool) {
+	if _, ok := os.LookupEnv(f.key); ok {
+		return f.key, true
+	}
+	if f.alt != "" {
+		if _, ok := os.LookupEnv(f.alt); ok {
+			return f.alt, true
+		}
+	}
+	return "", false
+}
+
+// applyFile 将配置文件中的值应用到未通过环境变量设置的配置项，并记录每个配置项的来源
+//
+// path为空时只记录来源。配置文件为YAML或JSON格式的映射，键见fileKey；
+// 包含未知的键时返回错误。
+func applyFile(cfg Extension, path string) error {
+	values := map[string]*yaml.Node{}
+	if path != "" {
+		var err error
+		if values, err = readFile(path); err != nil {
+			return err
+		}
+	}
+
+	b := cfg.BaseConfig()
+	b.origins = make(map[string]Origin)
+	for _, f := range fields(cfg) {
+		node, inFile := values[f.name]
+		delete(values, f.name)
+		if env, ok := f.lookupEnv(); ok {
+			b.origins[f.key] = Origin{Source: SourceEnv, Location: env}
+			continue
+		}
+		if !inFile {
+			b.origins[f.key] = Origin{Source: SourceDefault}
+			continue
+		}
+		if err := setNode(f.value, node); err != nil {
+			return errors.Wrapf(err, "%s:%d: invalid value for %s", path, node.Line, f.name)
+		}
+		b.origins[f.key] = Origin{Source: SourceFile, Location: path}
+	}
+
+	for name, node := range values {
+		return errors.Errorf("%s:%d: unknown key %s", path, node.Line, name)
+	}
+	return nil
+}
+
+// readFile 读取YAML或JSON格式的配置文件，返回键到值节点的映射
+func readFile(path string) (map[string]*yaml.Node, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config file %s", path)
+	}
+	values := make(map[string]*yaml.Node)
+	if len(doc.Content) == 0 {
+		return values, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.Errorf("%s:%d: config file must be a mapping", path, root.Line)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if _, ok := values[key.Value]; ok {
+			return nil, errors.Errorf("%s:%d: duplicate key %s", path, key.Line, key.Value)
+		}
+		values[key.Value] = root.Content[i+1]
+	}
+	return values, nil
+}
+
+// setNode 将配置文件中的值节点设置到配置项
+//
+// 标量按环境变量的格式解析（与环境变量中的写法相同）；序列和映射按YAML结构解码，
+// 字符串类型的配置项可以使用序列，各元素以逗号连接。
+func setNode(v reflect.Value, node *yaml.Node) error {
+	switch {
+	case node.Kind == yaml.ScalarNode && node.Tag == "!!null":
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case node.Kind == yaml.ScalarNode:
+		return setString(v, node.Value)
+	case node.Kind == yaml.SequenceNode && v.Kind() == reflect.String:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return errors.New("expected a list of strings")
+			}
+			items = append(items, item.Value)
+		}
+		v.SetString(strings.Join(items, ","))
+		return nil
+	}
+	decoded := reflect.New(v.Type())
+	if err := node.Decode(decoded.Interface()); err != nil {
+		return errors.WithStack(err)
+	}
+	v.Set(decoded.Elem())
+	return nil
+}
+
+// setString 按envconfig的规则将字符串解析到配置项
+func setString(v reflect.Value, value string) error {
+	switch p := v.Addr().Interface().(type) {
+	case envconfig.Decoder:
+		return p.Decode(value)
+	case envconfig.Setter:
+		return p.Set(value)
+	case encoding.TextUnmarshaler:
+		return p.UnmarshalText([]byte(value))
+	case encoding.BinaryUnmarshaler:
+		return p.UnmarshalBinary([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setString(elem.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 0, v.Type().Bits())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 0, v.Type().Bits())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		v.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		v.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), 0, 0)
+		if strings.TrimSpace(value) != "" {
+			items := strings.Split(value, ",")
+			s = reflect.MakeSlice(v.Type(), len(items), len(items))
+			for i, item := range items {
+				if err := setString(s.Index(i), item); err != nil {
+					return err
+				}
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		if strings.TrimSpace(value) != "" {
+			for _, pair := range strings.Split(value, ",") {
+				kv := strings.Split(pair, ":")
+				if len(kv) != 2 {
+					return errors.Errorf("invalid map item: %q", pair)
+				}
+				k := reflect.New(v.Type().Key()).Elem()
+				if err := setString(k, kv[0]); err != nil {
+					return err
+				}
+				e := reflect.New(v.Type().Elem()).Elem()
+				if err := setString(e, kv[1]); err != nil {
+					return err
+				}
+				m.SetMapIndex(k, e)
+			}
+		}
+		v.Set(m)
+	default:
+		return errors.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// isTrue 解析布尔标签值，无法解析时为false
+func isTrue(s string) bool {
+	b, _ := strconv.ParseBool(s)
+	return b
+}
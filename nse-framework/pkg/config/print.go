@@ -0,0 +1,118 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// redacted 打印配置时替代敏感值的文本
+const redacted = "<redacted>"
+
+// Print 以配置文件格式（YAML）打印cfg的生效配置，每个配置项后以注释标明来源
+//
+// 带secret:"true"标签的配置项和URL中的密码被隐藏。
+// 输出可以直接作为NSM_CONFIG_FILE使用（隐藏的值除外）。
+//
+// 示例：
+//
+//	if err := config.Print(os.Stdout, cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+func Print(w io.Writer, cfg Extension) error {
+	b := cfg.BaseConfig()
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range fields(cfg) {
+		value, err := valueNode(f)
+		if err != nil {
+			return errors.Wrapf(err, "failed to print %s", f.name)
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: f.name}
+		// 列表和映射的注释放在键所在行
+		if value.Kind == yaml.ScalarNode {
+			value.LineComment = b.Origin(f.key).String()
+		} else {
+			key.LineComment = b.Origin(f.key).String()
+		}
+		root.Content = append(root.Content, key, value)
+	}
+
+	if _, err := fmt.Fprintf(w, "# effective configuration of %s\n", b.Name); err != nil {
+		return errors.WithStack(err)
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(enc.Close())
+}
+
+// valueNode 将配置项的值转换为YAML节点，标量使用与环境变量相同的写法
+func valueNode(f field) (*yaml.Node, error) {
+	v := f.value
+	if isTrue(f.tag.Get("secret")) && !v.IsZero() {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: redacted}, nil
+	}
+
+	switch x := v.Addr().Interface().(type) {
+	case *url.URL:
+		return stringNode(x.Redacted()), nil
+	case *time.Duration:
+		return stringNode(x.String()), nil
+	case *os.FileMode:
+		return stringNode(fmt.Sprintf("%#o", uint32(*x))), nil
+	case *string:
+		return stringNode(redactURL(*x)), nil
+	}
+
+	node := new(yaml.Node)
+	if err := node.Encode(v.Interface()); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return node, nil
+}
+
+// stringNode 返回字符串标量节点
+func stringNode(s string) *yaml.Node {
+	node := new(yaml.Node)
+	node.SetString(s)
+	return node
+}
+
+// redactURL 隐藏字符串形式的URL中的密码，其他字符串原样返回
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+	return u.Redacted()
+}
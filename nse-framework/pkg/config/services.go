@@ -0,0 +1,119 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Service 网络服务及其单独的标签
+type Service struct {
+	// Name 网络服务名称
+	Name string
+
+	// Labels 该服务单独的标签（不含Base.Labels中的共用标签）
+	Labels map[string]string
+}
+
+// ServiceLabels 各网络服务单独的标签，键为服务名称
+//
+// 环境变量格式为以";"分隔的"服务=标签"列表，标签格式与NSM_LABELS相同：
+//
+//	NSM_SERVICE_LABELS="firewall=app:firewall,tier:edge;qos=app:qos"
+type ServiceLabels map[string]map[string]string
+
+// Decode 实现envconfig.Decoder，解析NSM_SERVICE_LABELS
+func (l *ServiceLabels) Decode(value string) error {
+	labels := ServiceLabels{}
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, list, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return errors.Errorf("invalid service labels %q: expected service=key:value,...", entry)
+		}
+		if _, dup := labels[name]; dup {
+			return errors.Errorf("duplicate service labels for %s", name)
+		}
+		kv := map[string]string{}
+		for _, pair := range strings.Split(list, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(pair, ":")
+			if !ok || strings.TrimSpace(k) == "" {
+				return errors.Errorf("invalid label %q for service %s: expected key:value", pair, name)
+			}
+			kv[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		labels[name] = kv
+	}
+	*l = labels
+	return nil
+}
+
+// Services 返回NSE提供的网络服务列表
+//
+// 依次包含ServiceName（如已设置）与ServiceNames，重复的服务名称只保留第一个；
+// 每个服务附带ServiceLabels中该服务单独的标签。
+func (b *Base) Services() []Service {
+	var services []Service
+	seen := map[string]bool{}
+	for _, name := range append([]string{b.ServiceName}, b.ServiceNames...) {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		services = append(services, Service{Name: name, Labels: b.ServiceLabels[name]})
+	}
+	return services
+}
+
+// validateServices 校验服务列表与各服务标签
+func (b *Base) validateServices() error {
+	if len(b.Services()) == 0 {
+		return errors.New("ServiceName is required (set NSM_SERVICE_NAME or NSM_SERVICE_NAMES)")
+	}
+
+	seen := map[string]bool{}
+	for _, name := range b.ServiceNames {
+		if name == "" {
+			return errors.New("ServiceNames contains an empty service name")
+		}
+		if seen[name] {
+			return errors.Errorf("ServiceNames contains duplicate service %s", name)
+		}
+		seen[name] = true
+	}
+
+	for name := range b.ServiceLabels {
+		if name != b.ServiceName && !seen[name] {
+			return errors.Errorf("ServiceLabels refers to unknown service %s", name)
+		}
+	}
+	return nil
+}
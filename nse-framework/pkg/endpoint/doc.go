@@ -0,0 +1,24 @@
+// Package endpoint 提供基于VPP的标准NSE端点链
+//
+// 本包封装firewall等NSE共用的VPP xconnect + memif端点链，
+// 各NSE只需提供业务链元素（Options.Servers），其余链元素由本包组装：
+//   - 接收/发送文件描述符
+//   - VPP接口UP
+//   - 客户端URL传递
+//   - VPP xconnect
+//   - 业务链元素（按给定顺序），以及按请求的网络服务选择的链元素（Options.ServiceServers）
+//   - Memif机制支持
+//   - 连接到下游服务
+//
+// 使用示例：
+//
+//	ep := endpoint.NewServer(ctx, endpoint.Options{
+//	    Name:          env.Config.Name,
+//	    ConnectTo:     &env.Config.ConnectTo,
+//	    Labels:        env.Config.Labels,
+//	    VPPConn:       env.VPPConn,
+//	    Source:        env.Source,
+//	    ClientOptions: env.ClientOptions,
+//	    Servers:       []networkservice.NetworkServiceServer{qos.NewMarkerServer()},
+//	})
+package endpoint
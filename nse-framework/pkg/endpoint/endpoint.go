@@ -0,0 +1,156 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/up"
+	"github.com/networkservicemesh/sdk-vpp/pkg/networkservice/xconnect"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/client"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/endpoint"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/authorize"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/clienturl"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/connect"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/recvfd"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/sendfd"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanismtranslation"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/passthrough"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/metadata"
+	"github.com/networkservicemesh/sdk/pkg/tools/spiffejwt"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/vpp"
+)
+
+// Options 标准VPP端点配置选项
+type Options struct {
+	// Name 端点名称
+	Name string
+
+	// ConnectTo NSM连接地址
+	ConnectTo *url.URL
+
+	// Labels 端点标签
+	Labels map[string]string
+
+	// MaxTokenLifetime token最大生命周期
+	MaxTokenLifetime time.Duration
+
+	// VPPConn VPP API连接
+	VPPConn vpp.Connection
+
+	// Source SPIFFE X509源
+	Source *workloadapi.X509Source
+
+	// ClientOptions gRPC客户端选项
+	ClientOptions []grpc.DialOption
+
+	// Servers 业务链元素，按顺序插入在VPP xconnect之后、memif机制之前
+	Servers []networkservice.NetworkServiceServer
+
+	// ServiceServers 按请求的网络服务选择的业务链元素（见NewServiceServer），位于Servers之后
+	ServiceServers map[string][]networkservice.NetworkServiceServer
+}
+
+// NewServer 创建标准VPP网络服务端点
+//
+// 链结构与firewall端点一致，业务链元素位于firewall的ACL链元素所在位置，
+// 此时客户端和下游的VPP接口均已创建并完成xconnect。
+//
+// 参数：
+//   - ctx: 上下文
+//   - opts: 端点配置选项
+//
+// 返回值：
+//   - endpoint: 可注册到gRPC服务器的端点
+func NewServer(ctx context.Context, opts Options) endpoint.Endpoint {
+	// 创建token生成器
+	tokenGenerator := spiffejwt.TokenGeneratorFunc(opts.Source, opts.MaxTokenLifetime)
+
+	servers := []networkservice.NetworkServiceServer{
+		// 接收文件描述符
+		recvfd.NewServer(),
+		// 发送文件描述符
+		sendfd.NewServer(),
+		// VPP接口UP
+		up.NewServer(ctx, opts.VPPConn),
+		// 客户端URL传递
+		clienturl.NewServer(opts.ConnectTo),
+		// VPP xconnect
+		xconnect.NewServer(opts.VPPConn),
+	}
+	// 业务链元素
+	servers = append(servers, opts.Servers...)
+	if len(opts.ServiceServers) > 0 {
+		servers = append(servers, NewServiceServer(opts.ServiceServers))
+	}
+	servers = append(servers,
+		// Memif机制支持
+		mechanisms.NewServer(map[string]networkservice.NetworkServiceServer{
+			memif.MECHANISM: chain.NewNetworkServiceServer(
+				memif.NewServer(ctx, opts.VPPConn),
+			),
+		}),
+		// 连接到下游服务
+		connect.NewServer(
+			client.NewClient(
+				ctx,
+				client.WithoutRefresh(),
+				client.WithName(opts.Name),
+				client.WithDialOptions(opts.ClientOptions...),
+				client.WithAdditionalFunctionality(
+					// 元数据传递
+					metadata.NewClient(),
+					// 机制转换
+					mechanismtranslation.NewClient(),
+					// 标签透传
+					passthrough.NewClient(opts.Labels),
+					// VPP接口UP（客户端侧）
+					up.NewClient(ctx, opts.VPPConn),
+					// VPP xconnect（客户端侧）
+					xconnect.NewClient(opts.VPPConn),
+					// Memif机制（客户端侧）
+					memif.NewClient(ctx, opts.VPPConn),
+					// 发送文件描述符（客户端侧）
+					sendfd.NewClient(),
+					// 接收文件描述符（客户端侧）
+					recvfd.NewClient(),
+				),
+			),
+		),
+	)
+
+	return endpoint.NewServer(
+		ctx,
+		tokenGenerator,
+		endpoint.WithName(opts.Name),
+		endpoint.WithAuthorizeServer(authorize.NewServer()),
+		endpoint.WithAdditionalFunctionality(servers...),
+	)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// serviceServer 按请求的网络服务选择业务链元素
+type serviceServer struct {
+	servers map[string]networkservice.NetworkServiceServer
+}
+
+// NewServiceServer 创建按网络服务选择链元素的服务器
+//
+// 请求连接的NetworkService在servers中时，依次执行该服务的链元素后继续后续链；
+// 否则直接调用下一个链元素。用于一个NSE注册多个网络服务（NSM_SERVICE_NAMES）
+// 且各服务需要不同行为的场景。
+//
+// 示例：
+//
+//	endpoint.NewServiceServer(map[string][]networkservice.NetworkServiceServer{
+//	    "firewall": {acl.NewServer(vppConn, rules)},
+//	    "qos":      {qos.NewMarkerServer()},
+//	})
+func NewServiceServer(servers map[string][]networkservice.NetworkServiceServer) networkservice.NetworkServiceServer {
+	s := &serviceServer{servers: make(map[string]networkservice.NetworkServiceServer, len(servers))}
+	for service, elements := range servers {
+		s.servers[service] = chain.NewNetworkServiceServer(elements...)
+	}
+	return s
+}
+
+// Request 将请求交给所请求网络服务的链元素
+func (s *serviceServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	if server, ok := s.servers[request.GetConnection().GetNetworkService()]; ok {
+		return server.Request(ctx, request)
+	}
+	return next.Server(ctx).Request(ctx, request)
+}
+
+// Close 将关闭请求交给连接所属网络服务的链元素
+func (s *serviceServer) Close(ctx context.Context, conn *networkservice.Connection) (*emptypb.Empty, error) {
+	if server, ok := s.servers[conn.GetNetworkService()]; ok {
+		return server.Close(ctx, conn)
+	}
+	return next.Server(ctx).Close(ctx, conn)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/endpoint"
+)
+
+// recordServer 记录经过的链元素名称
+type recordServer struct {
+	name  string
+	calls *[]string
+}
+
+func (s *recordServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	*s.calls = append(*s.calls, "request:"+s.name)
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (s *recordServer) Close(ctx context.Context, conn *networkservice.Connection) (*emptypb.Empty, error) {
+	*s.calls = append(*s.calls, "close:"+s.name)
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func TestServiceServer(t *testing.T) {
+	var calls []string
+	record := func(name string) networkservice.NetworkServiceServer {
+		return &recordServer{name: name, calls: &calls}
+	}
+	server := chain.NewNetworkServiceServer(
+		endpoint.NewServiceServer(map[string][]networkservice.NetworkServiceServer{
+			"firewall": {record("acl"), record("log")},
+			"qos":      {record("marker")},
+		}),
+		record("tail"),
+	)
+
+	for _, tc := range []struct {
+		service string
+		want    []string
+	}{
+		{"firewall", []string{"request:acl", "request:log", "request:tail", "close:acl", "close:log", "close:tail"}},
+		{"qos", []string{"request:marker", "request:tail", "close:marker", "close:tail"}},
+		{"other", []string{"request:tail", "close:tail"}},
+	} {
+		calls = nil
+		conn := &networkservice.Connection{Id: "conn", NetworkService: tc.service}
+
+		_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+		require.NoError(t, err)
+		_, err = server.Close(context.Background(), conn)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, calls, tc.service)
+	}
+}
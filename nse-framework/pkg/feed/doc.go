@@ -0,0 +1,25 @@
+// Package feed 提供外部IP黑名单/白名单订阅源（feed）的解析和变更监控功能
+//
+// 威胁情报列表通常以以下格式发布，本包将它们解析、规范化并聚合为前缀列表：
+//   - plain: 每行一个IP或CIDR，支持"#"和";"注释
+//   - csv: CSV文件，通过列名或列序号指定地址列
+//   - spamhaus: Spamhaus DROP格式，例如"1.10.16.0/20 ; SBL256894"
+//
+// 每个订阅源声明其格式和目标列表（allow或deny），可以通过规格字符串
+// "目标:格式:路径"简写，例如：
+//
+//	deny:spamhaus:/etc/ipfilter/drop.txt
+//	allow:csv:/etc/ipfilter/partners.csv
+//
+// Watcher定期检查文件内容，发生变化时触发重新加载。
+//
+// 使用示例：
+//
+//	src, _ := feed.ParseSpec("deny:spamhaus:/etc/ipfilter/drop.txt")
+//	result, err := feed.Load(src)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	log.Infof("feed %s: %d entries, %d invalid, %d prefixes",
+//	    src.Path, result.Entries, result.Invalid, len(result.Prefixes))
+package feed
@@ -0,0 +1,303 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feed
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ipset"
+)
+
+// Format 订阅源文件格式
+type Format string
+
+const (
+	// FormatPlain 每行一个IP或CIDR
+	FormatPlain Format = "plain"
+
+	// FormatCSV CSV文件
+	FormatCSV Format = "csv"
+
+	// FormatSpamhaus Spamhaus DROP格式（"prefix ; SBL"）
+	FormatSpamhaus Format = "spamhaus"
+)
+
+// Target 订阅源合并到的目标列表
+type Target string
+
+const (
+	// TargetAllow 合并到白名单
+	TargetAllow Target = "allow"
+
+	// TargetDeny 合并到黑名单
+	TargetDeny Target = "deny"
+)
+
+// maxReportedErrors 每个订阅源最多记录的错误行数
+const maxReportedErrors = 10
+
+// Source 订阅源声明
+type Source struct {
+	// Path 文件路径，相对路径相对于引用它的策略文件所在目录
+	Path string `yaml:"path" json:"path"`
+
+	// Format 文件格式：plain、csv或spamhaus
+	Format Format `yaml:"format" json:"format"`
+
+	// Target 目标列表：allow或deny
+	Target Target `yaml:"target" json:"target"`
+
+	// Column CSV地址列的列名或从0开始的序号，默认为第0列
+	Column string `yaml:"column,omitempty" json:"column,omitempty"`
+}
+
+// ParseSpec 解析"目标:格式:路径"形式的订阅源规格
+//
+// 示例：
+//
+//	src, err := feed.ParseSpec("deny:spamhaus:/etc/ipfilter/drop.txt")
+func ParseSpec(spec string) (Source, error) {
+	parts := strings.SplitN(strings.TrimSpace(spec), ":", 3)
+	if len(parts) != 3 {
+		return Source{}, errors.Errorf("invalid feed spec %q (expected target:format:path)", spec)
+	}
+	src := Source{
+		Target: Target(strings.ToLower(strings.TrimSpace(parts[0]))),
+		Format: Format(strings.ToLower(strings.TrimSpace(parts[1]))),
+		Path:   strings.TrimSpace(parts[2]),
+	}
+	if err := src.Validate(); err != nil {
+		return Source{}, err
+	}
+	return src, nil
+}
+
+// ParseSpecs 解析逗号分隔的订阅源规格列表
+func ParseSpecs(specs string) ([]Source, error) {
+	var sources []Source
+	for _, spec := range strings.Split(specs, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		src, err := ParseSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// Validate 验证订阅源声明
+func (s Source) Validate() error {
+	if s.Path == "" {
+		return errors.New("feed path is required")
+	}
+	switch s.Format {
+	case FormatPlain, FormatCSV, FormatSpamhaus:
+	default:
+		return errors.Errorf("feed %s: unsupported format %q (expected plain, csv or spamhaus)", s.Path, s.Format)
+	}
+	switch s.Target {
+	case TargetAllow, TargetDeny:
+	default:
+		return errors.Errorf("feed %s: invalid target %q (expected allow or deny)", s.Path, s.Target)
+	}
+	return nil
+}
+
+// WithBase 将相对路径解析为相对于base目录的路径
+func (s Source) WithBase(base string) Source {
+	if s.Path != "" && !filepath.IsAbs(s.Path) && base != "" {
+		s.Path = filepath.Join(base, s.Path)
+	}
+	return s
+}
+
+// Name 返回用于日志和规则描述的订阅源名称（文件名）
+func (s Source) Name() string {
+	return filepath.Base(s.Path)
+}
+
+// Result 订阅源解析结果
+type Result struct {
+	// Source 订阅源声明
+	Source Source
+
+	// Prefixes 规范化并聚合后的前缀
+	Prefixes []netip.Prefix
+
+	// Entries 有效条目数（聚合前）
+	Entries int
+
+	// Invalid 无效条目数
+	Invalid int
+
+	// Errors 无效条目的错误信息（带行号，最多记录10条）
+	Errors []string
+}
+
+func (r *Result) addInvalid(line int, value string) {
+	r.Invalid++
+	if len(r.Errors) < maxReportedErrors {
+		r.Errors = append(r.Errors, fmt.Sprintf("line %d: invalid IP or CIDR %q", line, value))
+	}
+}
+
+// Load 读取并解析订阅源文件
+func Load(src Source) (*Result, error) {
+	if err := src.Validate(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(src.Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open feed %s", src.Path)
+	}
+	defer func() { _ = f.Close() }()
+
+	return Parse(f, src)
+}
+
+// Parse 按订阅源声明的格式解析内容
+//
+// 无效条目不会导致失败，而是计入Result.Invalid并记录行号。
+func Parse(r io.Reader, src Source) (*Result, error) {
+	result := &Result{Source: src}
+
+	var prefixes []netip.Prefix
+	var err error
+	switch src.Format {
+	case FormatPlain:
+		prefixes, err = parseLines(r, result, "#;")
+	case FormatSpamhaus:
+		prefixes, err = parseLines(r, result, ";")
+	case FormatCSV:
+		prefixes, err = parseCSV(r, result, src.Column)
+	default:
+		return nil, errors.Errorf("feed %s: unsupported format %q", src.Path, src.Format)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse feed %s", src.Path)
+	}
+
+	result.Entries = len(prefixes)
+	result.Prefixes = ipset.Aggregate(prefixes)
+	return result, nil
+}
+
+// parseLines 解析每行一个地址的文本，comments中的任一字符之后的内容视为注释
+func parseLines(r io.Reader, result *Result, comments string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexAny(text, comments); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		p, err := ipset.ParsePrefix(fields[0])
+		if err != nil {
+			result.addInvalid(line, fields[0])
+			continue
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, scanner.Err()
+}
+
+// parseCSV 解析CSV文件，column为列名或列序号
+//
+// 未指定列名时，第一行如果不是有效地址则视为表头。
+func parseCSV(r io.Reader, result *Result, column string) ([]netip.Prefix, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	index := 0
+	byName := false
+	if column != "" {
+		n, err := strconv.Atoi(column)
+		if err != nil {
+			byName = true
+		} else if n < 0 {
+			return nil, errors.Errorf("invalid csv column %d", n)
+		} else {
+			index = n
+		}
+	}
+
+	var prefixes []netip.Prefix
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first && byName {
+			index = -1
+			for i, name := range record {
+				if strings.EqualFold(strings.TrimSpace(name), column) {
+					index = i
+				}
+			}
+			if index < 0 {
+				return nil, errors.Errorf("csv column %q not found in header", column)
+			}
+			continue
+		}
+
+		if index >= len(record) {
+			result.addInvalid(line, "")
+			continue
+		}
+		value := strings.TrimSpace(record[index])
+		p, err := ipset.ParsePrefix(value)
+		if err != nil {
+			if first {
+				continue // 表头
+			}
+			result.addInvalid(line, value)
+			continue
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feed_test
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/feed"
+)
+
+func prefixStrings(prefixes []netip.Prefix) []string {
+	var result []string
+	for _, p := range prefixes {
+		result = append(result, p.String())
+	}
+	return result
+}
+
+func TestParse_Plain(t *testing.T) {
+	content := `# threat list
+10.0.0.0/25
+10.0.0.128/25   # 与上一行聚合
+192.168.1.10 ; 单个IP
+not-an-ip
+
+2001:db8::1
+`
+	result, err := feed.Parse(strings.NewReader(content), feed.Source{Path: "list.txt", Format: feed.FormatPlain, Target: feed.TargetDeny})
+	require.NoError(t, err)
+	require.Equal(t, 4, result.Entries)
+	require.Equal(t, 1, result.Invalid)
+	require.Equal(t, []string{`line 5: invalid IP or CIDR "not-an-ip"`}, result.Errors)
+	require.Equal(t, []string{"10.0.0.0/24", "192.168.1.10/32", "2001:db8::1/128"}, prefixStrings(result.Prefixes))
+}
+
+func TestParse_Spamhaus(t *testing.T) {
+	content := `; Spamhaus DROP List 2024/01/01 - (c) 2024 The Spamhaus Project
+; Last-Modified: Mon, 01 Jan 2024 00:00:00 GMT
+1.10.16.0/20 ; SBL256894
+1.19.0.0/16 ; SBL434604
+1.32.128.0/18 ; SBL286275
+`
+	result, err := feed.Parse(strings.NewReader(content), feed.Source{Path: "drop.txt", Format: feed.FormatSpamhaus, Target: feed.TargetDeny})
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Entries)
+	require.Zero(t, result.Invalid)
+	require.Equal(t, []string{"1.10.16.0/20", "1.19.0.0/16", "1.32.128.0/18"}, prefixStrings(result.Prefixes))
+}
+
+func TestParse_CSV(t *testing.T) {
+	content := `# partners
+name,network,comment
+partner-a,203.0.113.0/24,"A, Inc."
+partner-b,bad-value,
+partner-c
+partner-d,198.51.100.7,
+`
+	src := feed.Source{Path: "partners.csv", Format: feed.FormatCSV, Target: feed.TargetAllow, Column: "network"}
+	result, err := feed.Parse(strings.NewReader(content), src)
+	require.NoError(t, err)
+	require.Equal(t, []string{"198.51.100.7/32", "203.0.113.0/24"}, prefixStrings(result.Prefixes))
+	require.Equal(t, 2, result.Invalid)
+	require.Equal(t, `line 4: invalid IP or CIDR "bad-value"`, result.Errors[0])
+
+	// 按列序号，无表头
+	src.Column = "1"
+	result, err = feed.Parse(strings.NewReader("a,10.0.0.0/8\nb,172.16.0.0/12\n"), src)
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.0/8", "172.16.0.0/12"}, prefixStrings(result.Prefixes))
+
+	// 默认第0列，自动跳过表头
+	src.Column = ""
+	result, err = feed.Parse(strings.NewReader("cidr\n10.0.0.0/8\n"), src)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Entries)
+	require.Zero(t, result.Invalid)
+
+	src.Column = "missing"
+	_, err = feed.Parse(strings.NewReader(content), src)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `csv column "missing" not found`)
+}
+
+func TestParseSpec(t *testing.T) {
+	src, err := feed.ParseSpec(" deny:Spamhaus:/etc/ipfilter/drop.txt ")
+	require.NoError(t, err)
+	require.Equal(t, feed.Source{Path: "/etc/ipfilter/drop.txt", Format: feed.FormatSpamhaus, Target: feed.TargetDeny}, src)
+
+	sources, err := feed.ParseSpecs("deny:plain:a.txt, allow:csv:b.csv,")
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+	require.Equal(t, feed.TargetAllow, sources[1].Target)
+
+	for _, spec := range []string{"deny:plain", "block:plain:a.txt", "deny:json:a.txt", "deny:plain:"} {
+		_, err := feed.ParseSpec(spec)
+		require.Error(t, err, spec)
+	}
+
+	require.Equal(t, "/etc/gateway/drop.txt", feed.Source{Path: "drop.txt"}.WithBase("/etc/gateway").Path)
+	require.Equal(t, "/abs/drop.txt", feed.Source{Path: "/abs/drop.txt"}.WithBase("/etc/gateway").Path)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "drop.txt")
+	require.NoError(t, os.WriteFile(path, []byte("1.10.16.0/20 ; SBL256894\n"), 0o600))
+
+	result, err := feed.Load(feed.Source{Path: path, Format: feed.FormatSpamhaus, Target: feed.TargetDeny})
+	require.NoError(t, err)
+	require.Len(t, result.Prefixes, 1)
+
+	_, err = feed.Load(feed.Source{Path: filepath.Join(dir, "missing.txt"), Format: feed.FormatPlain, Target: feed.TargetDeny})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to open feed")
+}
+
+func TestWatcher_Changed(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	require.NoError(t, os.WriteFile(a, []byte("10.0.0.0/8\n"), 0o600))
+
+	paths := []string{a}
+	w := feed.NewWatcher(time.Second, func() []string { return paths }, func() {})
+	require.False(t, w.Changed(), "文件未变化")
+
+	require.NoError(t, os.WriteFile(a, []byte("10.0.0.0/8\n172.16.0.0/12\n"), 0o600))
+	require.True(t, w.Changed(), "内容变化")
+	require.False(t, w.Changed(), "变化只报告一次")
+
+	require.NoError(t, os.Remove(a))
+	require.True(t, w.Changed(), "文件删除")
+
+	paths = append(paths, b)
+	require.True(t, w.Changed(), "监控列表变化")
+	require.False(t, w.Changed())
+}
+
+func TestWatcher_ChangeDuringCallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	require.NoError(t, os.WriteFile(a, []byte("10.0.0.0/8\n"), 0o600))
+	require.NoError(t, os.WriteFile(b, []byte("192.168.0.0/16\n"), 0o600))
+
+	// 只在Run的协程中访问
+	paths := []string{a}
+	var calls atomic.Int32
+	w := feed.NewWatcher(10*time.Millisecond, func() []string { return paths }, func() {
+		if calls.Add(1) == 1 {
+			// 回调读取文件之后文件再次变化，并且新策略引用了新的文件
+			if err := os.WriteFile(a, []byte("10.0.0.0/8\n172.16.0.0/12\n"), 0o600); err != nil {
+				t.Error(err)
+			}
+			paths = []string{a, b}
+		}
+	})
+	go w.Run(ctx)
+
+	require.NoError(t, os.WriteFile(a, []byte("10.0.0.0/8\n10.1.0.0/16\n"), 0o600))
+	require.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, 10*time.Millisecond,
+		"回调期间的变化应该触发下一次回调")
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(2), calls.Load(), "新增的文件不应该被误报为变化")
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"time"
+)
+
+// Watcher 定期检查一组文件的内容，发生变化时调用回调
+//
+// 文件通过内容摘要比较，因此Kubernetes ConfigMap的符号链接切换
+// 以及文件的删除和重新创建都能被检测到。
+type Watcher struct {
+	interval time.Duration
+	paths    func() []string
+	onChange func()
+	sums     map[string]string
+}
+
+// NewWatcher 创建文件监控器，并记录文件的当前状态
+//
+// 参数：
+//   - interval: 检查间隔
+//   - paths: 返回需要监控的文件列表（重新加载后列表可能变化）
+//   - onChange: 文件内容变化时的回调
+func NewWatcher(interval time.Duration, paths func() []string, onChange func()) *Watcher {
+	w := &Watcher{
+		interval: interval,
+		paths:    paths,
+		onChange: onChange,
+	}
+	w.sums = w.snapshot()
+	return w
+}
+
+// Run 按检查间隔监控文件直到ctx结束
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Changed在回调之前记录文件状态，回调读取文件期间发生的变化在下一次检查时仍能发现
+			if w.Changed() {
+				w.onChange()
+				// 回调可能改变了文件列表（例如新策略引用了不同的订阅源）
+				w.track()
+			}
+		}
+	}
+}
+
+// Changed 检查文件自上次检查以来是否发生变化
+func (w *Watcher) Changed() bool {
+	sums := w.snapshot()
+	changed := len(sums) != len(w.sums)
+	for path, sum := range sums {
+		if old, ok := w.sums[path]; !ok || old != sum {
+			changed = true
+		}
+	}
+	w.sums = sums
+	return changed
+}
+
+// track 按回调后的文件列表更新监控状态
+//
+// 已监控文件保留回调之前记录的摘要，只为新增的文件记录当前状态，不再引用的文件被移除。
+func (w *Watcher) track() {
+	sums := make(map[string]string)
+	for _, path := range w.paths() {
+		if sum, ok := w.sums[path]; ok {
+			sums[path] = sum
+			continue
+		}
+		sums[path] = digest(path)
+	}
+	w.sums = sums
+}
+
+func (w *Watcher) snapshot() map[string]string {
+	sums := make(map[string]string)
+	for _, path := range w.paths() {
+		sums[path] = digest(path)
+	}
+	return sums
+}
+
+// digest 返回文件内容的摘要，文件无法读取时返回空字符串
+func digest(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,14 @@
+// Package health 提供NSE的gRPC健康检查服务
+//
+// 本包基于标准gRPC健康检查协议（grpc.health.v1）维护NSE各组件的状态：
+//   - 每个组件（VPP连接、注册表注册、策略加载等）作为一个服务名称单独报告
+//   - 空服务名称""表示NSE整体状态：所有组件均为SERVING时才为SERVING
+//   - Shutdown后所有状态固定为NOT_SERVING
+//
+// 使用示例：
+//
+//	checker := health.NewChecker(health.ComponentVPP, health.ComponentRegistry)
+//	checker.Register(grpcServer)
+//	checker.Set(health.ComponentVPP, true)
+//	defer checker.Shutdown()
+package health
@@ -0,0 +1,135 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// NSE组件名称，同时作为健康检查的服务名称
+const (
+	// ComponentVPP VPP API连接
+	ComponentVPP = "vpp"
+	// ComponentRegistry NSM注册表注册
+	ComponentRegistry = "registry"
+	// ComponentPolicy 业务策略（ACL、IP过滤规则等）加载
+	ComponentPolicy = "policy"
+)
+
+// Checker NSE组件健康状态
+type Checker struct {
+	server *grpchealth.Server
+
+	mu         sync.Mutex
+	components map[string]bool
+	shutdown   bool
+}
+
+// NewChecker 创建健康状态检查器
+//
+// components为NSE需要报告的组件，初始状态均为NOT_SERVING；
+// 之后通过Set加入的组件同样计入整体状态。
+func NewChecker(components ...string) *Checker {
+	c := &Checker{
+		server:     grpchealth.NewServer(),
+		components: make(map[string]bool, len(components)),
+	}
+	for _, component := range components {
+		c.components[component] = false
+	}
+	c.updateLocked()
+	return c
+}
+
+// Register 在gRPC服务器上注册健康检查服务
+func (c *Checker) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, c.server)
+}
+
+// Set 设置组件状态并更新整体状态
+func (c *Checker) Set(component string, serving bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.components[component] = serving
+	c.updateLocked()
+}
+
+// Shutdown 将所有组件和整体状态置为NOT_SERVING，之后的Set不再生效
+func (c *Checker) Shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shutdown = true
+	c.server.Shutdown()
+}
+
+// Serving 返回整体状态是否为SERVING
+func (c *Checker) Serving() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.servingLocked()
+}
+
+// Components 返回各组件当前状态的副本
+func (c *Checker) Components() map[string]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make(map[string]bool, len(c.components))
+	for component, serving := range c.components {
+		result[component] = serving && !c.shutdown
+	}
+	return result
+}
+
+// servingLocked 所有组件均为SERVING且未关闭时返回true
+func (c *Checker) servingLocked() bool {
+	if c.shutdown {
+		return false
+	}
+	for _, serving := range c.components {
+		if !serving {
+			return false
+		}
+	}
+	return true
+}
+
+// updateLocked 将组件状态和整体状态同步到gRPC健康检查服务
+func (c *Checker) updateLocked() {
+	if c.shutdown {
+		return
+	}
+	for component, serving := range c.components {
+		c.server.SetServingStatus(component, status(serving))
+	}
+	c.server.SetServingStatus("", status(c.servingLocked()))
+}
+
+// status 将布尔状态转换为健康检查状态
+func status(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/health"
+)
+
+// newClient 在内存连接上启动注册了checker的gRPC服务器并返回健康检查客户端
+func newClient(t *testing.T, checker *health.Checker) healthpb.HealthClient {
+	ln := bufconn.Listen(1 << 16)
+	srv := grpc.NewServer()
+	checker.Register(srv)
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(srv.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cc.Close() })
+	return healthpb.NewHealthClient(cc)
+}
+
+// check 查询服务的健康状态
+func check(t *testing.T, client healthpb.HealthClient, service string) healthpb.HealthCheckResponse_ServingStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.GetStatus()
+}
+
+func TestChecker(t *testing.T) {
+	checker := health.NewChecker(health.ComponentVPP, health.ComponentRegistry)
+	client := newClient(t, checker)
+
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, client, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, client, health.ComponentVPP))
+
+	checker.Set(health.ComponentVPP, true)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, client, health.ComponentVPP))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, client, ""), "注册表尚未就绪")
+
+	checker.Set(health.ComponentRegistry, true)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, client, ""))
+	require.True(t, checker.Serving())
+
+	// 运行中加入的组件同样计入整体状态
+	checker.Set(health.ComponentPolicy, false)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, client, ""))
+	require.Equal(t, map[string]bool{
+		health.ComponentVPP:      true,
+		health.ComponentRegistry: true,
+		health.ComponentPolicy:   false,
+	}, checker.Components())
+	checker.Set(health.ComponentPolicy, true)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, client, ""))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	require.Error(t, err, "未知组件返回NotFound")
+}
+
+func TestChecker_Shutdown(t *testing.T) {
+	checker := health.NewChecker(health.ComponentVPP)
+	client := newClient(t, checker)
+	checker.Set(health.ComponentVPP, true)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, client, ""))
+
+	checker.Shutdown()
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, client, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, client, health.ComponentVPP))
+
+	checker.Set(health.ComponentVPP, true)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(t, client, ""), "关闭后状态不再变化")
+	require.False(t, checker.Serving())
+	require.Equal(t, map[string]bool{health.ComponentVPP: false}, checker.Components())
+}
+
+func TestChecker_NoComponents(t *testing.T) {
+	client := newClient(t, health.NewChecker())
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check(t, client, ""))
+}
@@ -0,0 +1,31 @@
+// Package ipset 提供命名IP集合的定义、引用展开和前缀运算功能
+//
+// 策略文件中常常重复出现相同的企业网段。本包允许在策略文件中
+// 按名称定义一次IP集合，并在白名单/黑名单中通过"@名称"引用。
+//
+// 主要功能：
+//   - 命名IP集合定义（include/exclude，可嵌套引用其他集合）
+//   - 引用展开（"@corp-nets" → CIDR列表）
+//   - 循环引用和未定义引用检测
+//   - 前缀去重和前缀相减运算
+//
+// 策略文件示例：
+//
+//	ipSets:
+//	  vpn-nets: ["172.16.0.0/12"]
+//	  corp-nets:
+//	    include: ["10.0.0.0/8", "@vpn-nets"]
+//	    exclude: ["10.99.0.0/16"]
+//	allowList:
+//	  - "@corp-nets"
+//	  - "192.168.1.10"
+//
+// 使用示例：
+//
+//	r := ipset.NewResolver(defs)
+//	entries, err := r.Expand([]string{"@corp-nets", "192.168.1.10"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	entries = ipset.Dedupe(entries)
+package ipset
@@ -0,0 +1,214 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipset
+
+import (
+	"encoding/json"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RefPrefix IP集合引用前缀，例如"@corp-nets"
+const RefPrefix = "@"
+
+// IsRef 判断条目是否为IP集合引用
+func IsRef(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), RefPrefix)
+}
+
+// HasRefs 判断列表中是否包含IP集合引用
+func HasRefs(entries []string) bool {
+	for _, e := range entries {
+		if IsRef(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// Definition 命名IP集合定义
+//
+// Include和Exclude中的条目可以是IP、CIDR或"@名称"引用。
+// 只包含Include时可以简写为列表：
+//
+//	vpn-nets: ["172.16.0.0/12"]
+type Definition struct {
+	// Include 集合包含的地址
+	Include []string `yaml:"include" json:"include"`
+
+	// Exclude 从Include中排除的地址
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+}
+
+// definitionFields 避免UnmarshalYAML/UnmarshalJSON递归调用
+type definitionFields Definition
+
+// UnmarshalYAML 支持列表简写和include/exclude两种格式
+func (d *Definition) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var include []string
+	if err := unmarshal(&include); err == nil {
+		*d = Definition{Include: include}
+		return nil
+	}
+	var fields definitionFields
+	if err := unmarshal(&fields); err != nil {
+		return err
+	}
+	*d = Definition(fields)
+	return nil
+}
+
+// UnmarshalJSON 支持列表简写和include/exclude两种格式
+func (d *Definition) UnmarshalJSON(data []byte) error {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		var include []string
+		if err := json.Unmarshal(data, &include); err != nil {
+			return err
+		}
+		*d = Definition{Include: include}
+		return nil
+	}
+	var fields definitionFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*d = Definition(fields)
+	return nil
+}
+
+// Resolver 展开IP集合引用
+//
+// 每个集合只解析一次，结果会被缓存。Resolver不是线程安全的。
+type Resolver struct {
+	defs     map[string]Definition
+	resolved map[string][]netip.Prefix
+	stack    []string
+}
+
+// NewResolver 创建引用解析器
+func NewResolver(defs map[string]Definition) *Resolver {
+	return &Resolver{
+		defs:     defs,
+		resolved: make(map[string][]netip.Prefix),
+	}
+}
+
+// Resolve 解析命名IP集合，返回去重后的前缀列表
+//
+// 未定义的集合和循环引用返回错误，例如：
+//
+//	ip set cycle detected: a -> b -> a
+func (r *Resolver) Resolve(name string) ([]netip.Prefix, error) {
+	if prefixes, ok := r.resolved[name]; ok {
+		return prefixes, nil
+	}
+	for i, n := range r.stack {
+		if n == name {
+			cycle := append(append([]string(nil), r.stack[i:]...), name)
+			return nil, errors.Errorf("ip set cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	def, ok := r.defs[name]
+	if !ok {
+		return nil, errors.Errorf("undefined ip set %s%s", RefPrefix, name)
+	}
+
+	r.stack = append(r.stack, name)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	include, err := r.expandPrefixes(def.Include)
+	if err != nil {
+		return nil, errors.Wrapf(err, "ip set %q include", name)
+	}
+	exclude, err := r.expandPrefixes(def.Exclude)
+	if err != nil {
+		return nil, errors.Wrapf(err, "ip set %q exclude", name)
+	}
+
+	prefixes := Prefixes(Dedupe(entriesOf(Subtract(include, exclude), "")))
+	r.resolved[name] = prefixes
+	return prefixes, nil
+}
+
+// ResolveAll 解析所有已定义的集合，用于在加载时尽早发现错误
+func (r *Resolver) ResolveAll() error {
+	names := make([]string, 0, len(r.defs))
+	for name := range r.defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := r.Resolve(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Expand 展开条目列表中的IP、CIDR和"@名称"引用
+//
+// 返回的条目按原始顺序排列，引用展开的条目记录来源集合名称。
+// 结果未去重，需要时调用Dedupe。
+func (r *Resolver) Expand(entries []string) ([]Entry, error) {
+	var result []Entry
+	for _, raw := range entries {
+		s := strings.TrimSpace(raw)
+		if s == "" {
+			continue
+		}
+		if !IsRef(s) {
+			p, err := ParsePrefix(s)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, Entry{Prefix: p})
+			continue
+		}
+
+		name := strings.TrimPrefix(s, RefPrefix)
+		prefixes, err := r.Resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entriesOf(prefixes, name)...)
+	}
+	return result, nil
+}
+
+func (r *Resolver) expandPrefixes(entries []string) ([]netip.Prefix, error) {
+	expanded, err := r.Expand(entries)
+	if err != nil {
+		return nil, err
+	}
+	return Prefixes(expanded), nil
+}
+
+func entriesOf(prefixes []netip.Prefix, origin string) []Entry {
+	entries := make([]Entry, 0, len(prefixes))
+	for _, p := range prefixes {
+		entries = append(entries, Entry{Prefix: p, Origin: origin})
+	}
+	return entries
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipset_test
+
+import (
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ipset"
+)
+
+func prefixStrings(prefixes []netip.Prefix) []string {
+	var result []string
+	for _, p := range prefixes {
+		result = append(result, p.String())
+	}
+	return result
+}
+
+func TestParsePrefix(t *testing.T) {
+	cases := map[string]string{
+		"192.168.1.10":        "192.168.1.10/32",
+		"10.1.2.3/8":          "10.0.0.0/8",
+		" 172.16.0.0/12 ":     "172.16.0.0/12",
+		"2001:db8::1":         "2001:db8::1/128",
+		"::ffff:10.0.0.1":     "10.0.0.1/32",
+		"::ffff:10.0.0.0/104": "10.0.0.0/8",
+	}
+	for in, want := range cases {
+		p, err := ipset.ParsePrefix(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, p.String(), in)
+	}
+
+	_, err := ipset.ParsePrefix("invalid")
+	require.Error(t, err)
+	_, err = ipset.ParsePrefix("10.0.0.0/33")
+	require.Error(t, err)
+}
+
+func TestSubtract(t *testing.T) {
+	a := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	require.Equal(t, []string{"10.128.0.0/9"},
+		prefixStrings(ipset.Subtract(a, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/9")})))
+
+	require.Equal(t, []string{"10.0.0.0/9", "10.128.0.0/10", "10.192.0.0/11", "10.224.0.0/12",
+		"10.240.0.0/13", "10.248.0.0/14", "10.252.0.0/15", "10.254.0.0/16"},
+		prefixStrings(ipset.Subtract(a, []netip.Prefix{netip.MustParsePrefix("10.255.0.0/16")})))
+
+	require.Empty(t, ipset.Subtract(a, []netip.Prefix{netip.MustParsePrefix("0.0.0.0/0")}))
+	require.Equal(t, []string{"10.0.0.0/8"},
+		prefixStrings(ipset.Subtract(a, []netip.Prefix{netip.MustParsePrefix("192.168.0.0/16")})))
+}
+
+func TestDedupe(t *testing.T) {
+	entries := []ipset.Entry{
+		{Prefix: netip.MustParsePrefix("10.1.0.0/16")},
+		{Prefix: netip.MustParsePrefix("192.168.1.0/24"), Origin: "lab"},
+		{Prefix: netip.MustParsePrefix("10.0.0.0/8"), Origin: "corp"},
+		{Prefix: netip.MustParsePrefix("192.168.1.0/24")},
+	}
+
+	result := ipset.Dedupe(entries)
+	require.Len(t, result, 2, "10.1.0.0/16被10.0.0.0/8包含，重复的192.168.1.0/24只保留一个")
+	require.Equal(t, "192.168.1.0/24 (@lab)", result[0].String())
+	require.Equal(t, "10.0.0.0/8 (@corp)", result[1].String())
+}
+
+func TestResolver_Expand(t *testing.T) {
+	r := ipset.NewResolver(map[string]ipset.Definition{
+		"vpn-nets": {Include: []string{"172.16.0.0/12"}},
+		"corp-nets": {
+			Include: []string{"10.0.0.0/8", "@vpn-nets"},
+			Exclude: []string{"10.128.0.0/9"},
+		},
+	})
+
+	entries, err := r.Expand([]string{"@corp-nets", "192.168.1.10", ""})
+	require.NoError(t, err)
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.String())
+	}
+	require.Equal(t, []string{
+		"10.0.0.0/9 (@corp-nets)",
+		"172.16.0.0/12 (@corp-nets)",
+		"192.168.1.10/32",
+	}, got)
+	require.NoError(t, r.ResolveAll())
+}
+
+func TestResolver_UndefinedReference(t *testing.T) {
+	r := ipset.NewResolver(map[string]ipset.Definition{
+		"corp-nets": {Include: []string{"@missing"}},
+	})
+
+	_, err := r.Expand([]string{"@corp-nets"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "undefined ip set @missing")
+	require.Contains(t, err.Error(), "corp-nets")
+
+	_, err = r.Expand([]string{"@nope"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "@nope")
+}
+
+func TestResolver_Cycle(t *testing.T) {
+	r := ipset.NewResolver(map[string]ipset.Definition{
+		"a": {Include: []string{"10.0.0.0/8", "@b"}},
+		"b": {Include: []string{"@c"}},
+		"c": {Include: []string{"192.168.0.0/16"}, Exclude: []string{"@a"}},
+	})
+
+	err := r.ResolveAll()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "ip set cycle detected: a -> b -> c -> a")
+}
+
+func TestDefinition_Unmarshal(t *testing.T) {
+	var fromYAML map[string]ipset.Definition
+	require.NoError(t, yaml.Unmarshal([]byte(`
+vpn-nets: ["172.16.0.0/12"]
+corp-nets:
+  include: ["10.0.0.0/8", "@vpn-nets"]
+  exclude: ["10.99.0.0/16"]
+`), &fromYAML))
+	require.Equal(t, []string{"172.16.0.0/12"}, fromYAML["vpn-nets"].Include)
+	require.Equal(t, []string{"10.99.0.0/16"}, fromYAML["corp-nets"].Exclude)
+
+	var fromJSON map[string]ipset.Definition
+	require.NoError(t, json.Unmarshal([]byte(
+		`{"vpn-nets":["172.16.0.0/12"],"corp-nets":{"include":["@vpn-nets"],"exclude":["172.16.1.0/24"]}}`),
+		&fromJSON))
+	require.Equal(t, fromYAML["vpn-nets"], fromJSON["vpn-nets"])
+	require.Equal(t, []string{"@vpn-nets"}, fromJSON["corp-nets"].Include)
+}
+
+func TestAggregate(t *testing.T) {
+	var prefixes []netip.Prefix
+	for _, s := range []string{
+		"10.0.1.0/24", "10.0.0.0/25", "10.0.0.128/25", "10.0.0.5/32",
+		"192.168.0.0/24", "2001:db8::/33", "2001:db8:8000::/33", "10.0.1.0/24",
+	} {
+		prefixes = append(prefixes, netip.MustParsePrefix(s))
+	}
+
+	require.Equal(t, []string{"10.0.0.0/23", "192.168.0.0/24", "2001:db8::/32"},
+		prefixStrings(ipset.Aggregate(prefixes)))
+	require.Empty(t, ipset.Aggregate(nil))
+}
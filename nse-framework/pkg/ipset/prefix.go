@@ -0,0 +1,224 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipset
+
+import (
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParsePrefix 解析单个IP或CIDR
+//
+// 单个IP转换为/32（IPv4）或/128（IPv6）前缀，CIDR的主机位会被清零，
+// IPv4映射的IPv6地址（::ffff:a.b.c.d）按IPv4处理。
+//
+// 示例：
+//
+//	p, _ := ipset.ParsePrefix("192.168.1.10")   // 192.168.1.10/32
+//	p, _ = ipset.ParsePrefix("10.1.2.3/8")      // 10.0.0.0/8
+func ParsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, errors.Errorf("invalid IP or CIDR %q", s)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, errors.Errorf("invalid IP or CIDR %q", s)
+	}
+	if p.Addr().Is4In6() {
+		bits := p.Bits() - 96
+		if bits < 0 {
+			return netip.Prefix{}, errors.Errorf("invalid IP or CIDR %q: IPv4-mapped prefix shorter than /96", s)
+		}
+		p = netip.PrefixFrom(p.Addr().Unmap(), bits)
+	}
+	return p.Masked(), nil
+}
+
+// ToIPNet 将netip.Prefix转换为net.IPNet
+func ToIPNet(p netip.Prefix) *net.IPNet {
+	return &net.IPNet{
+		IP:   net.IP(p.Addr().AsSlice()),
+		Mask: net.CIDRMask(p.Bits(), p.Addr().BitLen()),
+	}
+}
+
+// FromIPNet 将net.IPNet转换为netip.Prefix
+func FromIPNet(n net.IPNet) (netip.Prefix, bool) {
+	addr, ok := netip.AddrFromSlice(n.IP)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	ones, _ := n.Mask.Size()
+	addr = addr.Unmap()
+	if addr.Is4() && ones > 32 {
+		ones -= 96
+	}
+	return netip.PrefixFrom(addr, ones).Masked(), true
+}
+
+// Contains 判断前缀a是否完全包含前缀b
+func Contains(a, b netip.Prefix) bool {
+	return a.Bits() <= b.Bits() && a.Contains(b.Addr())
+}
+
+// Subtract 计算前缀集合a减去前缀集合b后剩余的前缀
+//
+// 被部分排除的前缀会被拆分为更小的前缀，例如：
+//
+//	Subtract([10.0.0.0/8], [10.0.0.0/9]) = [10.128.0.0/9]
+func Subtract(a, b []netip.Prefix) []netip.Prefix {
+	result := append([]netip.Prefix(nil), a...)
+	for _, ex := range b {
+		var next []netip.Prefix
+		for _, p := range result {
+			next = append(next, subtractOne(p, ex)...)
+		}
+		result = next
+	}
+	return result
+}
+
+func subtractOne(p, ex netip.Prefix) []netip.Prefix {
+	if !p.Overlaps(ex) {
+		return []netip.Prefix{p}
+	}
+	if Contains(ex, p) {
+		return nil
+	}
+	lo, hi := split(p)
+	return append(subtractOne(lo, ex), subtractOne(hi, ex)...)
+}
+
+// split 将前缀拆分为两个长度加1的子前缀
+func split(p netip.Prefix) (lo, hi netip.Prefix) {
+	bits := p.Bits() + 1
+	lo = netip.PrefixFrom(p.Addr(), bits)
+
+	raw := p.Addr().AsSlice()
+	raw[p.Bits()/8] |= 0x80 >> (p.Bits() % 8)
+	addr, _ := netip.AddrFromSlice(raw)
+	hi = netip.PrefixFrom(addr, bits)
+	return lo, hi
+}
+
+// Entry 展开后的单个前缀及其来源
+type Entry struct {
+	// Prefix 规范化后的前缀
+	Prefix netip.Prefix
+
+	// Origin 来源IP集合名称，直接写在列表中的地址为空
+	Origin string
+}
+
+// String 返回条目的字符串表示，例如"10.0.0.0/8 (@corp-nets)"
+func (e Entry) String() string {
+	if e.Origin == "" {
+		return e.Prefix.String()
+	}
+	return e.Prefix.String() + " (" + RefPrefix + e.Origin + ")"
+}
+
+// Prefixes 提取条目中的前缀
+func Prefixes(entries []Entry) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, e := range entries {
+		prefixes = append(prefixes, e.Prefix)
+	}
+	return prefixes
+}
+
+// Dedupe 去除重复的条目和被其他条目包含的条目
+//
+// 保留条目的原始顺序；被更大前缀包含的条目不改变匹配结果，因此被移除。
+func Dedupe(entries []Entry) []Entry {
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	// 按地址族、起始地址、前缀长度升序排序后，包含者总是排在被包含者之前
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := entries[order[i]].Prefix, entries[order[j]].Prefix
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c < 0
+		}
+		return a.Bits() < b.Bits()
+	})
+
+	keep := make([]bool, len(entries))
+	var last netip.Prefix
+	for _, idx := range order {
+		p := entries[idx].Prefix
+		if last.IsValid() && Contains(last, p) {
+			continue
+		}
+		keep[idx] = true
+		last = p
+	}
+
+	result := make([]Entry, 0, len(entries))
+	for i, e := range entries {
+		if keep[i] {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Aggregate 聚合前缀列表
+//
+// 移除重复和被包含的前缀，并将相邻的兄弟前缀合并为父前缀，
+// 例如10.0.0.0/25和10.0.0.128/25合并为10.0.0.0/24。
+// 返回的前缀按地址升序排列。
+func Aggregate(prefixes []netip.Prefix) []netip.Prefix {
+	sorted := Prefixes(Dedupe(entriesOf(prefixes, "")))
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Addr().Less(sorted[j].Addr())
+	})
+
+	// 排序后的前缀互不重叠，兄弟前缀必然相邻
+	result := make([]netip.Prefix, 0, len(sorted))
+	for _, p := range sorted {
+		result = append(result, p)
+		for len(result) >= 2 {
+			a, b := result[len(result)-2], result[len(result)-1]
+			if a.Bits() != b.Bits() || a.Bits() == 0 || a == b {
+				break
+			}
+			parent := netip.PrefixFrom(a.Addr(), a.Bits()-1).Masked()
+			if parent != netip.PrefixFrom(b.Addr(), b.Bits()-1).Masked() {
+				break
+			}
+			result = append(result[:len(result)-2], parent)
+		}
+	}
+	return result
+}
@@ -7,6 +7,7 @@
 //   - 监控错误通道并触发优雅退出
 //   - 初始化日志系统和级别切换
 //   - 管理应用启动阶段
+//   - 提供存活检查、就绪检查和Prometheus指标的HTTP服务器（HTTPServer、InitMetrics）
 //
 // 使用示例：
 //
@@ -0,0 +1,180 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPServer 提供存活检查、就绪检查、指标和pprof的HTTP服务器
+//
+// 路径：
+//   - /healthz 存活检查，有组件报告不可恢复的错误时返回503
+//   - /readyz 就绪检查，SetReady(true)之前（启动未完成）及关闭期间返回503
+//   - /metrics 及其他路径由Handle挂载；EnablePprof挂载/debug/pprof/
+type HTTPServer struct {
+	mux *http.ServeMux
+
+	mu       sync.Mutex
+	ready    bool
+	failures map[string]error
+}
+
+// NewHTTPServer 创建HTTP服务器，初始状态为存活、未就绪
+func NewHTTPServer() *HTTPServer {
+	s := &HTTPServer{
+		mux:      http.NewServeMux(),
+		failures: make(map[string]error),
+	}
+	s.mux.HandleFunc("/healthz", s.serveHealthz)
+	s.mux.HandleFunc("/readyz", s.serveReadyz)
+	return s
+}
+
+// Handle 在pattern上挂载处理器
+func (s *HTTPServer) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// EnablePprof 挂载/debug/pprof/下的性能分析处理器
+//
+// 用于HTTP服务器与pprof共用同一地址（PprofListenOn）的情况。
+func (s *HTTPServer) EnablePprof() {
+	s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// SetReady 设置就绪状态
+func (s *HTTPServer) SetReady(ready bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ready = ready
+}
+
+// Fail 记录组件的不可恢复错误，之后存活检查失败
+func (s *HTTPServer) Fail(component string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[component] = err
+}
+
+// Recover 清除组件的错误记录
+func (s *HTTPServer) Recover(component string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, component)
+}
+
+// Ready 返回是否就绪
+func (s *HTTPServer) Ready() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ready
+}
+
+// Live 返回是否存活（没有组件报告不可恢复的错误）
+func (s *HTTPServer) Live() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.failures) == 0
+}
+
+// ServeHTTP 实现http.Handler
+func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe 在addr（host:port）上启动HTTP服务器，ctx取消后关闭
+//
+// 监听失败时直接返回的错误通道中已有错误；运行中出错时发送到错误通道。
+func (s *HTTPServer) ListenAndServe(ctx context.Context, addr string) <-chan error {
+	errCh := make(chan error, 1)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		errCh <- errors.Wrapf(err, "failed to listen on %s", addr)
+		close(errCh)
+		return errCh
+	}
+
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	go func() {
+		defer close(errCh)
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- errors.Wrap(err, "http server failed")
+		}
+	}()
+	return errCh
+}
+
+// serveHealthz 存活检查：列出报告不可恢复错误的组件
+func (s *HTTPServer) serveHealthz(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	components := make([]string, 0, len(s.failures))
+	for component := range s.failures {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	lines := make([]string, 0, len(components))
+	for _, component := range components {
+		lines = append(lines, fmt.Sprintf("%s: %v", component, s.failures[component]))
+	}
+	s.mu.Unlock()
+
+	if len(lines) == 0 {
+		writeStatus(w, http.StatusOK, "ok")
+		return
+	}
+	writeStatus(w, http.StatusServiceUnavailable, lines...)
+}
+
+// serveReadyz 就绪检查
+func (s *HTTPServer) serveReadyz(w http.ResponseWriter, _ *http.Request) {
+	if !s.Ready() {
+		writeStatus(w, http.StatusServiceUnavailable, "not ready")
+		return
+	}
+	writeStatus(w, http.StatusOK, "ok")
+}
+
+// writeStatus 写入状态码和逐行的纯文本响应
+func writeStatus(w http.ResponseWriter, code int, lines ...string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	for _, line := range lines {
+		_, _ = fmt.Fprintln(w, line)
+	}
+}
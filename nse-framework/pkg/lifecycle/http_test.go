@@ -0,0 +1,119 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/lifecycle"
+)
+
+// get 请求path并返回状态码和响应体
+func get(t *testing.T, h http.Handler, path string) (int, string) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	return rec.Code, rec.Body.String()
+}
+
+func TestHTTPServer_Probes(t *testing.T) {
+	srv := lifecycle.NewHTTPServer()
+
+	code, _ := get(t, srv, "/healthz")
+	require.Equal(t, http.StatusOK, code, "初始状态为存活")
+	code, body := get(t, srv, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code, "启动完成前未就绪")
+	require.Equal(t, "not ready\n", body)
+
+	srv.SetReady(true)
+	code, _ = get(t, srv, "/readyz")
+	require.Equal(t, http.StatusOK, code)
+
+	srv.Fail("vpp", errors.New("vpp api disconnected"))
+	srv.Fail("registry", errors.New("not registered for 5m0s"))
+	require.False(t, srv.Live())
+	code, body = get(t, srv, "/healthz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "registry: not registered for 5m0s\nvpp: vpp api disconnected\n", body, "按组件名排序列出错误")
+
+	srv.Recover("registry")
+	srv.Recover("vpp")
+	code, _ = get(t, srv, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+
+	code, _ = get(t, srv, "/debug/pprof/")
+	require.Equal(t, http.StatusNotFound, code, "未启用pprof")
+	srv.EnablePprof()
+	code, _ = get(t, srv, "/debug/pprof/")
+	require.Equal(t, http.StatusOK, code)
+}
+
+func TestHTTPServer_ListenAndServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	srv := lifecycle.NewHTTPServer()
+	errCh := srv.ListenAndServe(ctx, addr)
+
+	resp, err := http.Get("http://" + addr + "/healthz")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "ok\n", string(body))
+
+	// 地址已被占用时错误通道中立即有错误
+	busy := srv.ListenAndServe(ctx, addr)
+	require.ErrorContains(t, <-busy, "failed to listen on")
+
+	cancel()
+	_, ok := <-errCh
+	require.False(t, ok, "ctx取消后错误通道关闭且没有错误")
+}
+
+func TestInitMetrics(t *testing.T) {
+	ctx := context.Background()
+	metrics, err := lifecycle.InitMetrics(ctx, "test-server", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, metrics.Close(ctx)) })
+
+	counter, err := otel.Meter("test").Int64Counter("test_requests")
+	require.NoError(t, err)
+	counter.Add(ctx, 3)
+
+	code, body := get(t, metrics.Handler, "/metrics")
+	require.Equal(t, http.StatusOK, code)
+	require.Contains(t, body, "test_requests_total", "otel指标以Prometheus格式导出")
+	require.Contains(t, body, "go_goroutines", "包含Go运行时指标")
+}
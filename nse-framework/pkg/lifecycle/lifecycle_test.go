@@ -26,7 +26,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/networkservicemesh/nsm-nse-app/cmd-nse-ipfilter-vpp/pkg/lifecycle"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/lifecycle"
 	"github.com/stretchr/testify/require"
 )
 
@@ -86,8 +86,8 @@ func TestInitializeLogging_InvalidLevel(t *testing.T) {
 	// 无效的日志级别应该导致Fatal（在测试中会panic）
 	// 由于我们不能捕获Fatal，这里只是记录行为
 	// 实际使用中应该提供有效的日志级别
-	t.Skip("跳过：无效日志级别会导致Fatal退出")}
-
+	t.Skip("跳过：无效日志级别会导致Fatal退出")
+}
 
 func TestMonitorErrorChannel_ImmediateError(t *testing.T) {
 	errCh := make(chan error, 1)
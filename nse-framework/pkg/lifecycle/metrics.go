@@ -0,0 +1,94 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// Metrics 以Prometheus格式导出NSE指标
+type Metrics struct {
+	// Handler /metrics处理器
+	Handler http.Handler
+
+	provider *sdkmetric.MeterProvider
+}
+
+// InitMetrics 创建导出Prometheus格式指标的MeterProvider并设为全局MeterProvider
+//
+// NSE通过otel.Meter记录的指标经由Prometheus导出器暴露在Handler上，
+// 同时包含Go运行时和进程指标。readers为额外的指标读取器（如OTLP导出器，nil被忽略），
+// 与Prometheus导出器共用同一MeterProvider。
+//
+// 示例：
+//
+//	metrics, err := lifecycle.InitMetrics(ctx, "firewall-server")
+//	if err != nil {
+//	    return err
+//	}
+//	defer metrics.Close(ctx)
+//	httpServer.Handle("/metrics", metrics.Handler)
+func InitMetrics(ctx context.Context, service string, readers ...sdkmetric.Reader) (*Metrics, error) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create prometheus exporter")
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceNameKey.String(service)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create metrics resource")
+	}
+
+	options := []sdkmetric.Option{sdkmetric.WithResource(res), sdkmetric.WithReader(exporter)}
+	for _, reader := range readers {
+		if reader != nil {
+			options = append(options, sdkmetric.WithReader(reader))
+		}
+	}
+	provider := sdkmetric.NewMeterProvider(options...)
+	otel.SetMeterProvider(provider)
+
+	return &Metrics{
+		Handler:  promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+		provider: provider,
+	}, nil
+}
+
+// Close 关闭MeterProvider，停止所有指标读取器
+func (m *Metrics) Close(ctx context.Context) error {
+	return errors.Wrap(m.provider.Shutdown(ctx), "failed to shutdown meter provider")
+}
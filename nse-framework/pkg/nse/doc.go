@@ -0,0 +1,30 @@
+// Package nse 提供NSE的统一启动流程
+//
+// Run按固定的六个阶段启动NSE，各NSE只需通过Spec提供业务配置和业务端点：
+//  1. 从配置文件和环境变量加载配置（Spec.Config），初始化日志、OpenTelemetry、pprof和HTTP服务器
+//  2. 从SPIRE Agent获取SVID
+//  3. 创建连接NSM的gRPC客户端选项
+//  4. 启动或连接VPP（NSM_VPP_MODE）并创建业务端点（Spec.Endpoint）
+//  5. 创建gRPC服务器并挂载业务端点
+//  6. 向NSM注册NSE并保持注册（见registry.Client.Keep），首次注册成功后启动完成，/readyz变为就绪
+//
+// 上下文取消后Run从NSM注销NSE，等待VPP连接关闭（受管VPP退出）并执行Env.OnClose注册的清理函数。
+//
+// 使用示例：
+//
+//	ctx, cancel := lifecycle.NotifyContext()
+//	defer cancel()
+//
+//	err := nse.Run(ctx, nse.Spec{
+//	    Name: "firewall",
+//	    Config: func(ctx context.Context) (nseconfig.Extension, error) {
+//	        return config.Load(ctx)
+//	    },
+//	    Endpoint: func(ctx context.Context, env *nse.Env) (nse.Endpoint, error) {
+//	        return firewall.NewEndpoint(ctx, firewall.Options{VPPConn: env.VPPConn}), nil
+//	    },
+//	})
+//	if err != nil {
+//	    logrus.Fatalf("%+v", err)
+//	}
+package nse
@@ -0,0 +1,536 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nse
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	"github.com/edwarnicke/grpcfd"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/opentelemetry"
+	"github.com/networkservicemesh/sdk/pkg/tools/pprofutils"
+	"github.com/networkservicemesh/sdk/pkg/tools/spiffejwt"
+	"github.com/networkservicemesh/sdk/pkg/tools/token"
+	"github.com/networkservicemesh/sdk/pkg/tools/tracing"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/health"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/lifecycle"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/registry"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/server"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/vpp"
+)
+
+// unregisterTimeout 关闭时从NSM注销NSE的超时时间
+const unregisterTimeout = 5 * time.Second
+
+// Endpoint 业务端点，在第5阶段注册到gRPC服务器
+//
+// sdk的endpoint.Endpoint满足该接口。
+type Endpoint interface {
+	Register(s *grpc.Server)
+}
+
+// Spec NSE的业务部分
+type Spec struct {
+	// Name 业务名称，用于启动日志（如firewall、ipfilter）
+	Name string
+
+	// Config 第1阶段加载并返回NSE配置（嵌入config.Base的结构体）
+	//
+	// 在此加载规则文件等业务配置，Run随后调用Validate。
+	Config func(ctx context.Context) (config.Extension, error)
+
+	// NoVPP 不启动VPP，Env.VPPConn为nil（业务链不使用VPP数据面时设置）
+	NoVPP bool
+
+	// VPPPlugins 业务链需要的VPP插件（如acl、memif）
+	//
+	// 受管VPP只启用这些插件和NSM_VPP_PLUGINS中的插件，为空时保留VPP默认启用的插件。
+	VPPPlugins []string
+
+	// Endpoint 第4阶段创建业务端点
+	Endpoint func(ctx context.Context, env *Env) (Endpoint, error)
+
+	// Args 命令行参数（不含程序名，通常为os.Args[1:]）
+	//
+	// --print-config：加载配置后打印生效配置及各配置项的来源（隐藏敏感值）并退出，
+	// 配置无效时打印后返回校验错误。
+	Args []string
+}
+
+// Env 创建业务端点时可用的运行环境
+type Env struct {
+	// Config 第1阶段加载的通用配置
+	Config *config.Base
+
+	// Source SPIFFE X509证书源
+	Source *workloadapi.X509Source
+
+	// ClientOptions 连接NSM的gRPC客户端选项
+	ClientOptions []grpc.DialOption
+
+	// VPPConn VPP API连接（Spec.NoVPP时为nil）
+	VPPConn vpp.Connection
+
+	// Health 组件健康状态，通过gRPC健康检查服务报告
+	//
+	// Run维护vpp和registry组件；加载业务策略的NSE在策略就绪后设置health.ComponentPolicy。
+	Health *health.Checker
+
+	// HTTP 存活、就绪检查和指标的HTTP服务器（仅在NSM_HTTP_ENABLED时监听）
+	//
+	// NSE可以通过HTTP.Handle挂载额外的路径，或通过HTTP.Fail报告不可恢复的错误。
+	HTTP *lifecycle.HTTPServer
+
+	cancel  context.CancelFunc
+	onClose []func()
+}
+
+// Monitor 监控后台组件的错误通道，收到错误时停止NSE
+func (e *Env) Monitor(ctx context.Context, errCh <-chan error) {
+	lifecycle.MonitorErrorChannel(ctx, e.cancel, errCh)
+}
+
+// OnClose 注册Run返回前执行的清理函数，按注册的逆序执行
+func (e *Env) OnClose(f func()) {
+	e.onClose = append(e.onClose, f)
+}
+
+// Run 按六个阶段启动NSE并运行到ctx被取消
+//
+// 任一阶段失败时返回错误；运行中VPP或gRPC服务器出错时停止NSE并正常返回（错误已记录）。
+//
+// 示例：
+//
+//	ctx, cancel := lifecycle.NotifyContext()
+//	defer cancel()
+//	if err := nse.Run(ctx, spec); err != nil {
+//	    logrus.Fatalf("%+v", err)
+//	}
+func Run(ctx context.Context, spec Spec) error {
+	printConfig, err := parseArgs(spec.Name, spec.Args)
+	if err != nil {
+		return err
+	}
+	if printConfig {
+		return runPrintConfig(ctx, spec, os.Stdout)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	env := &Env{cancel: cancel, HTTP: lifecycle.NewHTTPServer()}
+	defer func() {
+		for i := len(env.onClose) - 1; i >= 0; i-- {
+			env.onClose[i]()
+		}
+	}()
+
+	// ********************************************************************************
+	// 设置日志系统
+	// ********************************************************************************
+	// 注意：暂时使用默认日志级别，稍后从配置加载后再更新
+	ctx = lifecycle.InitializeLogging(ctx, "INFO")
+
+	// 枚举启动阶段
+	log.FromContext(ctx).Infof("there are 6 phases which will be executed followed by a success message:")
+	log.FromContext(ctx).Infof("the phases include:")
+	log.FromContext(ctx).Infof("1: get config from config file and environment")
+	log.FromContext(ctx).Infof("2: retrieve spiffe svid")
+	log.FromContext(ctx).Infof("3: create grpc client options")
+	log.FromContext(ctx).Infof("4: create %s network service endpoint", spec.Name)
+	log.FromContext(ctx).Infof("5: create grpc and mount nse")
+	log.FromContext(ctx).Infof("6: register nse with nsm")
+	log.FromContext(ctx).Infof("a final success message with start time duration")
+
+	starttime := time.Now()
+
+	// ********************************************************************************
+	log.FromContext(ctx).Infof("executing phase 1: get config from config file and environment")
+	// ********************************************************************************
+	ext, err := spec.Config(ctx)
+	if err != nil {
+		return err
+	}
+	if err = ext.Validate(); err != nil {
+		return errors.Wrap(err, "invalid config")
+	}
+	cfg := ext.BaseConfig()
+	env.Config = cfg
+	vppOpts, err := vppOptions(spec, cfg)
+	if err != nil {
+		return errors.Wrap(err, "invalid config")
+	}
+
+	components := []string{health.ComponentRegistry}
+	if !spec.NoVPP {
+		components = append(components, health.ComponentVPP)
+	}
+	env.Health = health.NewChecker(components...)
+
+	// 使用配置的日志级别重新初始化日志
+	ctx = lifecycle.InitializeLogging(ctx, cfg.LogLevel)
+
+	log.FromContext(ctx).Infof("Config: %#v", ext)
+
+	// ********************************************************************************
+	// 配置 OpenTelemetry
+	// ********************************************************************************
+	var spanExporter sdktrace.SpanExporter
+	var metricReader sdkmetric.Reader
+	if opentelemetry.IsEnabled() {
+		collectorAddress := cfg.OpenTelemetryEndpoint
+		spanExporter = opentelemetry.InitSpanExporter(ctx, collectorAddress)
+		metricReader = opentelemetry.InitOPTLMetricExporter(ctx, collectorAddress, cfg.MetricsExportInterval)
+	}
+	if cfg.HTTPEnabled {
+		// Prometheus导出器与OTLP导出器共用同一MeterProvider
+		metrics, err := lifecycle.InitMetrics(ctx, cfg.Name, metricReader)
+		if err != nil {
+			return err
+		}
+		metricReader = nil
+		env.OnClose(func() {
+			if err := metrics.Close(context.WithoutCancel(ctx)); err != nil {
+				log.FromContext(ctx).Error(err.Error())
+			}
+		})
+		env.HTTP.Handle("/metrics", metrics.Handler)
+	}
+	if opentelemetry.IsEnabled() {
+		o := opentelemetry.Init(ctx, spanExporter, metricReader, cfg.Name)
+		env.OnClose(func() {
+			if err := o.Close(); err != nil {
+				log.FromContext(ctx).Error(err.Error())
+			}
+		})
+	}
+
+	// ********************************************************************************
+	// 配置 HTTP 服务器和 pprof
+	// ********************************************************************************
+	httpAddress := cfg.HTTPAddress()
+	sharePprof := cfg.HTTPEnabled && cfg.PprofEnabled && httpAddress == cfg.PprofListenOn
+	if cfg.PprofEnabled && !sharePprof {
+		go pprofutils.ListenAndServe(ctx, cfg.PprofListenOn)
+	}
+	if cfg.HTTPEnabled {
+		if sharePprof {
+			env.HTTP.EnablePprof()
+		}
+		env.Monitor(ctx, env.HTTP.ListenAndServe(ctx, httpAddress))
+		log.FromContext(ctx).Infof("http server started on %s", httpAddress)
+	}
+	go func() {
+		<-ctx.Done()
+		env.Health.Shutdown()
+		env.HTTP.SetReady(false)
+	}()
+
+	// ********************************************************************************
+	log.FromContext(ctx).Infof("executing phase 2: retrieving svid, check spire agent logs if this is the last line you see")
+	// ********************************************************************************
+	source, err := workloadapi.NewX509Source(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error getting x509 source")
+	}
+	env.OnClose(func() { _ = source.Close() })
+	svid, err := source.GetX509SVID()
+	if err != nil {
+		return errors.Wrap(err, "error getting x509 svid")
+	}
+	log.FromContext(ctx).Infof("SVID: %q", svid.ID)
+	env.Source = source
+
+	// 创建TLS配置
+	tlsClientConfig := server.CreateTLSClientConfig(source)
+	tlsServerConfig := server.CreateTLSServerConfig(source)
+
+	// ********************************************************************************
+	log.FromContext(ctx).Infof("executing phase 3: create grpc client options")
+	// ********************************************************************************
+	env.ClientOptions = append(
+		tracing.WithTracingDial(),
+		grpc.WithDefaultCallOptions(
+			grpc.WaitForReady(true),
+			grpc.PerRPCCredentials(token.NewPerRPCCredentials(spiffejwt.TokenGeneratorFunc(source, cfg.MaxTokenLifetime))),
+		),
+		grpc.WithTransportCredentials(
+			grpcfd.TransportCredentials(
+				credentials.NewTLS(tlsClientConfig),
+			),
+		),
+		grpcfd.WithChainStreamInterceptor(),
+		grpcfd.WithChainUnaryInterceptor(),
+	)
+
+	// ********************************************************************************
+	log.FromContext(ctx).Infof("executing phase 4: create %s network service endpoint", spec.Name)
+	// ********************************************************************************
+	var vppErrCh <-chan error
+	if !spec.NoVPP {
+		// 重连期间vpp组件报告为不健康，重连失败时由watchVPP使存活检查失败
+		vppOpts.OnHealth = func(connected bool) {
+			env.Health.Set(health.ComponentVPP, connected)
+		}
+		env.VPPConn, vppErrCh, err = vpp.Dial(ctx, vppOpts)
+		if err != nil {
+			return errors.Wrapf(err, "error connecting to %s VPP", cfg.VPPMode)
+		}
+		vppErrCh = watchVPP(vppErrCh, env)
+		env.Monitor(ctx, vppErrCh)
+	}
+
+	endpoint, err := spec.Endpoint(ctx, env)
+	if err != nil {
+		return errors.Wrapf(err, "error creating %s endpoint", spec.Name)
+	}
+
+	// ********************************************************************************
+	log.FromContext(ctx).Infof("executing phase 5: create grpc server and register %s-server", spec.Name)
+	// ********************************************************************************
+	// 服务器在注销完成后才停止，关闭期间健康检查报告NOT_SERVING
+	serverCtx, stopServer := context.WithCancel(context.WithoutCancel(ctx))
+	defer stopServer()
+	srvResult, err := server.New(serverCtx, server.Options{
+		TLSConfig:     tlsServerConfig,
+		Name:          cfg.Name,
+		ListenOn:      cfg.ListenOn,
+		SocketMode:    cfg.ListenSocketMode,
+		SocketOwner:   cfg.ListenSocketOwner,
+		AdvertiseHost: cfg.AdvertiseHost,
+		Health:        env.Health,
+		Reflection:    cfg.GRPCReflection,
+		// 业务端点在服务器开始监听前注册
+		Register: endpoint.Register,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error creating server")
+	}
+	env.OnClose(func() { _ = os.RemoveAll(srvResult.TmpDir) })
+
+	// 监控服务器错误
+	env.Monitor(ctx, srvResult.ErrCh)
+	log.FromContext(ctx).Infof("grpc server started on %s", srvResult.ListenURL)
+
+	// ********************************************************************************
+	log.FromContext(ctx).Infof("executing phase 6: register nse with nsm")
+	// ********************************************************************************
+	// 注册表客户端的连接在关闭阶段仍需用于注销，不随ctx取消
+	registryCtx, registryCancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer registryCancel()
+	registryClient, err := registry.NewClient(registryCtx, registry.Options{
+		ConnectTo:         &cfg.ConnectTo,
+		Policies:          cfg.RegistryClientPolicies,
+		DialOptions:       env.ClientOptions,
+		UnregisterTimeout: unregisterTimeout,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error creating registry client")
+	}
+
+	// Keep在后台保持注册（刷新、重试、NSMgr重启后重新注册），ctx取消后注销并关闭状态通道
+	statusCh := registryClient.Keep(ctx, registry.RegisterSpec{
+		Name:     cfg.Name,
+		Services: registryServices(cfg.Services()),
+		Labels:   cfg.Labels,
+		URL:      srvResult.ListenURL.String(),
+	})
+	registryHealth := &registryHealth{env: env, timeout: cfg.LivenessRegistryTimeout, failingSince: time.Now()}
+	if waitRegistered(statusCh, registryHealth) {
+		// ********************************************************************************
+		log.FromContext(ctx).Infof("startup completed in %v", time.Since(starttime))
+		// ********************************************************************************
+		if ctx.Err() == nil {
+			env.HTTP.SetReady(true)
+		}
+	}
+
+	// 等待ctx取消后注销完成
+	for s := range statusCh {
+		registryHealth.update(s)
+		if s.State == registry.StateRegistered {
+			logrus.Debugf("nse: %+v", s.NSE)
+		}
+	}
+
+	if vppErrCh != nil {
+		<-vppErrCh
+	}
+	return nil
+}
+
+// parseArgs 解析命令行参数，返回是否为--print-config模式
+func parseArgs(name string, args []string) (bool, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	printConfig := fs.Bool("print-config", false, "print the effective config with its sources and exit")
+	if err := fs.Parse(args); err != nil {
+		return false, errors.Wrap(err, "invalid arguments")
+	}
+	if fs.NArg() > 0 {
+		return false, errors.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	return *printConfig, nil
+}
+
+// runPrintConfig 加载配置并打印到w，不启动NSE
+func runPrintConfig(ctx context.Context, spec Spec, w io.Writer) error {
+	ext, err := spec.Config(config.WithoutUsage(ctx))
+	if err != nil {
+		return err
+	}
+	if err := config.Print(w, ext); err != nil {
+		return err
+	}
+	if err := ext.Validate(); err != nil {
+		return errors.Wrap(err, "invalid config")
+	}
+	_, err = vppOptions(spec, ext.BaseConfig())
+	return errors.Wrap(err, "invalid config")
+}
+
+// vppOptions 根据配置和业务链需要的插件生成并验证VPP选项（Spec.NoVPP时返回零值）
+func vppOptions(spec Spec, cfg *config.Base) (vpp.Options, error) {
+	if spec.NoVPP {
+		return vpp.Options{}, nil
+	}
+	opts := vpp.Options{
+		Mode:              cfg.VPPMode,
+		APISocket:         cfg.VPPAPISocket,
+		ConnectTimeout:    cfg.VPPConnectTimeout,
+		RetryInterval:     cfg.VPPRetryInterval,
+		ReconnectAttempts: cfg.VPPReconnectAttempts,
+	}
+	// 外部VPP的启动配置由其部署方管理
+	if cfg.VPPMode != vpp.ModeExternal {
+		opts.Startup = &vpp.StartupConfig{
+			Workers:         cfg.VPPWorkers,
+			MainCore:        cfg.VPPMainCore,
+			CorelistWorkers: cfg.VPPCorelistWorkers,
+			BuffersPerNuma:  cfg.VPPBuffersPerNuma,
+			BufferDataSize:  cfg.VPPBufferDataSize,
+			Hugepages:       cfg.VPPHugepages,
+			Plugins:         append(append([]string(nil), spec.VPPPlugins...), cfg.VPPPlugins...),
+			DisabledPlugins: cfg.VPPDisabledPlugins,
+		}
+		opts.KeepExistingConfig = !vppStartupSet(cfg)
+	}
+	return opts, opts.Validate()
+}
+
+// vppStartupKeys VPP启动配置项的环境变量名
+var vppStartupKeys = []string{
+	"NSM_VPP_API_SOCKET", "NSM_VPP_WORKERS", "NSM_VPP_MAIN_CORE", "NSM_VPP_CORELIST_WORKERS",
+	"NSM_VPP_BUFFERS_PER_NUMA", "NSM_VPP_BUFFER_DATA_SIZE", "NSM_VPP_HUGEPAGES",
+	"NSM_VPP_PLUGINS", "NSM_VPP_DISABLED_PLUGINS",
+}
+
+// vppStartupSet 报告是否通过配置文件或环境变量设置了VPP启动配置项
+//
+// 均未设置时受管VPP沿用已有的配置文件（例如挂载的ConfigMap），仅在文件不存在时渲染。
+func vppStartupSet(cfg *config.Base) bool {
+	for _, key := range vppStartupKeys {
+		if cfg.Origin(key).Source != config.SourceDefault {
+			return true
+		}
+	}
+	return false
+}
+
+// waitRegistered 等待首次注册成功，注册前ctx被取消（状态通道关闭）时返回false
+func waitRegistered(statusCh <-chan registry.Status, h *registryHealth) bool {
+	for s := range statusCh {
+		h.update(s)
+		if s.State == registry.StateRegistered {
+			logrus.Infof("nse: %+v", s.NSE)
+			return true
+		}
+	}
+	return false
+}
+
+// registryServices 将配置中的服务列表转换为注册规范中的服务列表
+func registryServices(services []config.Service) []registry.Service {
+	result := make([]registry.Service, 0, len(services))
+	for _, s := range services {
+		result = append(result, registry.Service{Name: s.Name, Labels: s.Labels})
+	}
+	return result
+}
+
+// registryHealth 根据注册状态更新registry组件的健康状态
+//
+// 注册持续失败超过timeout（0表示不限）时视为不可恢复，存活检查失败；重新注册成功后恢复。
+type registryHealth struct {
+	env          *Env
+	timeout      time.Duration
+	failingSince time.Time
+}
+
+// update 处理一次注册状态
+func (h *registryHealth) update(s registry.Status) {
+	registered := s.State == registry.StateRegistered
+	h.env.Health.Set(health.ComponentRegistry, registered)
+	switch {
+	case registered:
+		h.failingSince = time.Time{}
+		h.env.HTTP.Recover(health.ComponentRegistry)
+	case s.State == registry.StateUnregistered:
+	case h.failingSince.IsZero():
+		h.failingSince = time.Now()
+	case h.timeout > 0 && time.Since(h.failingSince) >= h.timeout:
+		err := s.Err
+		if err == nil {
+			err = errors.Errorf("registration %s", s.State)
+		}
+		h.env.HTTP.Fail(health.ComponentRegistry, errors.Wrapf(err, "not registered for %v", time.Since(h.failingSince).Round(time.Second)))
+	}
+}
+
+// watchVPP 转发VPP错误通道，VPP出错时将vpp组件标记为不健康并使存活检查失败
+func watchVPP(errCh <-chan error, env *Env) <-chan error {
+	out := make(chan error, 1)
+	go func() {
+		defer close(out)
+		for err := range errCh {
+			env.Health.Set(health.ComponentVPP, false)
+			env.HTTP.Fail(health.ComponentVPP, err)
+			select {
+			case out <- err:
+			default:
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nse_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/config"
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/nse"
+)
+
+type testConfig struct {
+	config.Base
+	validated bool
+}
+
+func (c *testConfig) Validate() error {
+	c.validated = true
+	return c.Base.Validate()
+}
+
+func TestRun_ConfigError(t *testing.T) {
+	err := nse.Run(context.Background(), nse.Spec{
+		Name: "test",
+		Config: func(context.Context) (config.Extension, error) {
+			return nil, errors.New("broken rule file")
+		},
+		Endpoint: func(context.Context, *nse.Env) (nse.Endpoint, error) {
+			t.Fatal("配置加载失败时不应创建端点")
+			return nil, nil
+		},
+	})
+	require.EqualError(t, err, "broken rule file")
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	cfg := &testConfig{Base: config.Base{Name: "test-server"}}
+	err := nse.Run(context.Background(), nse.Spec{
+		Name: "test",
+		Config: func(context.Context) (config.Extension, error) {
+			return cfg, nil
+		},
+		Endpoint: func(context.Context, *nse.Env) (nse.Endpoint, error) {
+			t.Fatal("配置无效时不应创建端点")
+			return nil, nil
+		},
+	})
+	require.True(t, cfg.validated, "Run应调用NSE配置的Validate")
+	require.ErrorContains(t, err, "invalid config")
+	require.ErrorContains(t, err, "ServiceName is required")
+}
+
+func TestRun_InvalidVPPStartup(t *testing.T) {
+	base := config.Base{Name: "test-server", ServiceName: "test-service", ConnectTo: url.URL{Scheme: "unix", Path: "/nsm.sock"}}
+	spec := nse.Spec{
+		Name:       "test",
+		VPPPlugins: []string{"acl", "memif"},
+		Endpoint: func(context.Context, *nse.Env) (nse.Endpoint, error) {
+			t.Fatal("VPP启动配置无效时不应创建端点")
+			return nil, nil
+		},
+	}
+
+	inconsistent := base
+	inconsistent.VPPWorkers = 2
+	inconsistent.VPPCorelistWorkers = "2-4"
+	spec.Config = func(context.Context) (config.Extension, error) { return &testConfig{Base: inconsistent}, nil }
+	err := nse.Run(context.Background(), spec)
+	require.ErrorContains(t, err, "invalid config")
+	require.ErrorContains(t, err, "workers 2 does not match 3 cores in corelist-workers 2-4")
+
+	disabled := base
+	disabled.VPPDisabledPlugins = []string{"acl"}
+	spec.Config = func(context.Context) (config.Extension, error) { return &testConfig{Base: disabled}, nil }
+	require.ErrorContains(t, nse.Run(context.Background(), spec), "plugin acl_plugin.so is both enabled and disabled", "不能禁用业务链需要的插件")
+}
+
+func TestRun_PrintConfig(t *testing.T) {
+	spec := nse.Spec{
+		Name: "test",
+		Args: []string{"--print-config"},
+		Config: func(context.Context) (config.Extension, error) {
+			return &testConfig{Base: config.Base{Name: "test-server", ServiceName: "test-service", ConnectTo: url.URL{Scheme: "unix", Path: "/nsm.sock"}}}, nil
+		},
+		Endpoint: func(context.Context, *nse.Env) (nse.Endpoint, error) {
+			t.Fatal("--print-config不应启动NSE")
+			return nil, nil
+		},
+	}
+	require.NoError(t, nse.Run(context.Background(), spec))
+
+	spec.Config = func(context.Context) (config.Extension, error) {
+		return &testConfig{Base: config.Base{Name: "test-server"}}, nil
+	}
+	require.ErrorContains(t, nse.Run(context.Background(), spec), "ServiceName is required", "配置无效时打印后返回校验错误")
+}
+
+func TestRun_InvalidArgs(t *testing.T) {
+	spec := nse.Spec{
+		Name: "test",
+		Config: func(context.Context) (config.Extension, error) {
+			t.Fatal("参数无效时不应加载配置")
+			return nil, nil
+		},
+	}
+
+	spec.Args = []string{"--unknown"}
+	require.ErrorContains(t, nse.Run(context.Background(), spec), "invalid arguments")
+
+	spec.Args = []string{"extra"}
+	require.ErrorContains(t, nse.Run(context.Background(), spec), "unexpected arguments")
+}
@@ -0,0 +1,26 @@
+// Package ratelimit 提供NSE请求限流功能
+//
+// 本包实现一个可复用的NetworkServiceServer链元素，使用令牌桶算法
+// 对进入NSE的Request进行限流，防止异常客户端反复重试导致VPP被频繁编程。
+//
+// 主要功能：
+//   - 按源IP限流（从IPContext.SrcIpAddrs提取）
+//   - 按客户端SPIFFE ID限流（从路径第一个段的token的subject提取，gRPC对端是NSMgr而不是客户端）
+//   - 全局限流上限
+//   - 超限时返回ResourceExhausted错误并附带RetryInfo
+//
+// Close请求不受限流影响，保证连接总能被正常释放。
+//
+// 客户端的首次Request通常还没有分配IP地址（IPContext.SrcIpAddrs为空），
+// 这类请求跳过按源IP的维度，只受按SPIFFE ID和全局的限制；
+// 需要限制新连接的频率时应同时配置按SPIFFE ID或全局的限制。
+//
+// 使用示例：
+//
+//	rl := ratelimit.NewServer(ratelimit.Config{
+//	    PerIP:       ratelimit.Limit{Rate: 5, Burst: 10},
+//	    PerSpiffeID: ratelimit.Limit{Rate: 10, Burst: 20},
+//	    Global:      ratelimit.Limit{Rate: 100, Burst: 200},
+//	})
+//	endpoint.WithAdditionalFunctionality(rl, ...)
+package ratelimit
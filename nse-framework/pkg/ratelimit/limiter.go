@@ -0,0 +1,256 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// 限流维度名称，用于日志和错误信息
+const (
+	ScopeGlobal    = "global"
+	ScopeIP        = "source-ip"
+	ScopeSpiffeID  = "spiffe-id"
+	defaultIdleTTL = 10 * time.Minute
+)
+
+// Limit 单个维度的令牌桶参数
+type Limit struct {
+	// Rate 每秒补充的令牌数，<=0表示不限制该维度
+	Rate float64
+
+	// Burst 桶容量（允许的突发请求数），<=0时取max(1, Rate)
+	Burst int
+}
+
+// Enabled 判断该维度是否启用限流
+func (l Limit) Enabled() bool {
+	return l.Rate > 0
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Max(1, l.Rate)
+}
+
+// Config 限流配置
+type Config struct {
+	// PerIP 每个源IP的限制（没有源IP的请求，例如首次Request，不受该维度限制）
+	PerIP Limit
+
+	// PerSpiffeID 每个客户端SPIFFE ID的限制
+	PerSpiffeID Limit
+
+	// Global 所有请求共享的全局上限
+	Global Limit
+
+	// IdleTTL 空闲令牌桶的回收时间，0表示使用默认值（10分钟）
+	IdleTTL time.Duration
+}
+
+// Enabled 判断是否至少启用了一个限流维度
+func (c Config) Enabled() bool {
+	return c.PerIP.Enabled() || c.PerSpiffeID.Enabled() || c.Global.Enabled()
+}
+
+// Validate 验证限流配置
+func (c Config) Validate() error {
+	scopes := []string{ScopeIP, ScopeSpiffeID, ScopeGlobal}
+	for i, l := range []Limit{c.PerIP, c.PerSpiffeID, c.Global} {
+		if l.Rate < 0 || l.Burst < 0 {
+			return errors.Errorf("invalid %s rate limit: rate=%v burst=%d (must not be negative)", scopes[i], l.Rate, l.Burst)
+		}
+	}
+	if c.IdleTTL < 0 {
+		return errors.Errorf("invalid rate limit idle ttl: %v", c.IdleTTL)
+	}
+	return nil
+}
+
+// Decision 限流决策结果
+type Decision struct {
+	// Allowed 是否放行
+	Allowed bool
+
+	// Scope 触发限流的维度（放行时为空）
+	Scope string
+
+	// Key 触发限流的键（源IP或SPIFFE ID）
+	Key string
+
+	// RetryAfter 建议的重试等待时间
+	RetryAfter time.Duration
+}
+
+// Err 将拒绝决策转换为带RetryInfo的ResourceExhausted gRPC错误
+//
+// 放行决策返回nil。
+func (d Decision) Err() error {
+	if d.Allowed {
+		return nil
+	}
+	msg := fmt.Sprintf("rate limit exceeded for %s", d.Scope)
+	if d.Key != "" {
+		msg = fmt.Sprintf("%s %q", msg, d.Key)
+	}
+	msg = fmt.Sprintf("%s, retry after %v", msg, d.RetryAfter)
+
+	st := status.New(codes.ResourceExhausted, msg)
+	if withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(d.RetryAfter)}); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// bucket 令牌桶
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) refill(now time.Time, l Limit) {
+	if now.After(b.last) {
+		b.tokens = math.Min(l.capacity(), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+		b.last = now
+	}
+}
+
+// wait 返回获得一个令牌需要等待的时间（令牌充足时为0）
+func (b *bucket) wait(l Limit) time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - b.tokens) / l.Rate * float64(time.Second)))
+}
+
+// Limiter 按源IP、SPIFFE ID和全局维度进行令牌桶限流（线程安全）
+type Limiter struct {
+	cfg Config
+
+	mu        sync.Mutex
+	global    *bucket
+	perIP     map[string]*bucket
+	perID     map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewLimiter 创建限流器
+func NewLimiter(cfg Config) *Limiter {
+	if cfg.IdleTTL == 0 {
+		cfg.IdleTTL = defaultIdleTTL
+	}
+	return &Limiter{
+		cfg:   cfg,
+		perIP: make(map[string]*bucket),
+		perID: make(map[string]*bucket),
+	}
+}
+
+// Allow 判断请求是否放行
+//
+// 只有当所有启用的维度都有可用令牌时才放行，并同时从各维度扣除令牌；
+// 被拒绝的请求不会消耗任何维度的令牌。空的ip或spiffeID跳过对应维度。
+func (l *Limiter) Allow(now time.Time, ip, spiffeID string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	type candidate struct {
+		scope string
+		key   string
+		limit Limit
+		b     *bucket
+	}
+	var candidates []candidate
+
+	if l.cfg.Global.Enabled() {
+		if l.global == nil {
+			l.global = &bucket{tokens: l.cfg.Global.capacity(), last: now}
+		}
+		candidates = append(candidates, candidate{ScopeGlobal, "", l.cfg.Global, l.global})
+	}
+	if l.cfg.PerIP.Enabled() && ip != "" {
+		candidates = append(candidates, candidate{ScopeIP, ip, l.cfg.PerIP, l.lookup(l.perIP, ip, l.cfg.PerIP, now)})
+	}
+	if l.cfg.PerSpiffeID.Enabled() && spiffeID != "" {
+		candidates = append(candidates, candidate{ScopeSpiffeID, spiffeID, l.cfg.PerSpiffeID, l.lookup(l.perID, spiffeID, l.cfg.PerSpiffeID, now)})
+	}
+
+	// 先检查所有维度，任何一个不足则拒绝并返回最长的等待时间
+	denied := Decision{Allowed: true}
+	for _, c := range candidates {
+		c.b.refill(now, c.limit)
+		if wait := c.b.wait(c.limit); wait > 0 && wait > denied.RetryAfter {
+			denied = Decision{Scope: c.scope, Key: c.key, RetryAfter: wait}
+		}
+	}
+	if !denied.Allowed {
+		return denied
+	}
+
+	for _, c := range candidates {
+		c.b.tokens--
+	}
+	return Decision{Allowed: true}
+}
+
+func (l *Limiter) lookup(buckets map[string]*bucket, key string, limit Limit, now time.Time) *bucket {
+	b, ok := buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.capacity(), last: now}
+		buckets[key] = b
+	}
+	return b
+}
+
+// sweep 回收长时间未使用的令牌桶，避免客户端数量增长导致内存泄漏
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.cfg.IdleTTL {
+		return
+	}
+	l.lastSweep = now
+	for _, buckets := range []map[string]*bucket{l.perIP, l.perID} {
+		for key, b := range buckets {
+			if now.Sub(b.last) >= l.cfg.IdleTTL {
+				delete(buckets, key)
+			}
+		}
+	}
+}
+
+// Len 返回当前跟踪的源IP和SPIFFE ID令牌桶数量（用于监控和测试）
+func (l *Limiter) Len() (ips, spiffeIDs int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.perIP), len(l.perID)
+}
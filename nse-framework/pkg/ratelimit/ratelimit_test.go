@@ -0,0 +1,226 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/clockmock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/ratelimit"
+)
+
+func TestLimiter_PerIPBurstAndRefill(t *testing.T) {
+	l := ratelimit.NewLimiter(ratelimit.Config{
+		PerIP: ratelimit.Limit{Rate: 1, Burst: 2},
+	})
+	now := time.Unix(0, 0)
+
+	require.True(t, l.Allow(now, "10.0.0.1", "").Allowed)
+	require.True(t, l.Allow(now, "10.0.0.1", "").Allowed)
+
+	d := l.Allow(now, "10.0.0.1", "")
+	require.False(t, d.Allowed, "第3个请求应该超出突发容量")
+	require.Equal(t, ratelimit.ScopeIP, d.Scope)
+	require.Equal(t, "10.0.0.1", d.Key)
+	require.Equal(t, time.Second, d.RetryAfter)
+
+	// 其他IP不受影响
+	require.True(t, l.Allow(now, "10.0.0.2", "").Allowed)
+
+	// 1秒后补充1个令牌
+	require.True(t, l.Allow(now.Add(time.Second), "10.0.0.1", "").Allowed)
+	require.False(t, l.Allow(now.Add(time.Second), "10.0.0.1", "").Allowed)
+}
+
+func TestLimiter_PerSpiffeID(t *testing.T) {
+	l := ratelimit.NewLimiter(ratelimit.Config{
+		PerSpiffeID: ratelimit.Limit{Rate: 1, Burst: 1},
+	})
+	now := time.Unix(0, 0)
+
+	require.True(t, l.Allow(now, "10.0.0.1", "spiffe://example.org/a").Allowed)
+	d := l.Allow(now, "10.0.0.2", "spiffe://example.org/a")
+	require.False(t, d.Allowed, "同一SPIFFE ID从不同IP请求应共享令牌桶")
+	require.Equal(t, ratelimit.ScopeSpiffeID, d.Scope)
+
+	require.True(t, l.Allow(now, "10.0.0.2", "spiffe://example.org/b").Allowed)
+	require.True(t, l.Allow(now, "10.0.0.2", "").Allowed, "缺少SPIFFE ID时跳过该维度")
+}
+
+func TestLimiter_GlobalCapDoesNotConsumeOnReject(t *testing.T) {
+	l := ratelimit.NewLimiter(ratelimit.Config{
+		PerIP:  ratelimit.Limit{Rate: 1, Burst: 1},
+		Global: ratelimit.Limit{Rate: 1, Burst: 2},
+	})
+	now := time.Unix(0, 0)
+
+	require.True(t, l.Allow(now, "10.0.0.1", "").Allowed)
+	require.False(t, l.Allow(now, "10.0.0.1", "").Allowed, "per-IP限制")
+	require.True(t, l.Allow(now, "10.0.0.2", "").Allowed, "被拒绝的请求不应消耗全局令牌")
+
+	d := l.Allow(now, "10.0.0.3", "")
+	require.False(t, d.Allowed)
+	require.Equal(t, ratelimit.ScopeGlobal, d.Scope)
+}
+
+func TestLimiter_IdleBucketsAreSwept(t *testing.T) {
+	l := ratelimit.NewLimiter(ratelimit.Config{
+		PerIP:   ratelimit.Limit{Rate: 1, Burst: 1},
+		IdleTTL: time.Minute,
+	})
+	now := time.Unix(0, 0)
+
+	l.Allow(now, "10.0.0.1", "")
+	l.Allow(now.Add(30*time.Second), "10.0.0.2", "")
+	ips, _ := l.Len()
+	require.Equal(t, 2, ips)
+
+	l.Allow(now.Add(75*time.Second), "10.0.0.3", "")
+	ips, _ = l.Len()
+	require.Equal(t, 2, ips, "10.0.0.1空闲超过IdleTTL应被回收")
+}
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, ratelimit.Config{}.Validate())
+	require.False(t, ratelimit.Config{}.Enabled())
+	require.True(t, ratelimit.Config{Global: ratelimit.Limit{Rate: 1}}.Enabled())
+
+	err := ratelimit.Config{PerIP: ratelimit.Limit{Rate: -1}}.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), ratelimit.ScopeIP)
+}
+
+func TestServer_RejectsWithRetryInfo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clockMock := clockmock.New(ctx)
+	ctx = clock.WithClock(ctx, clockMock)
+
+	server := chain.NewNetworkServiceServer(ratelimit.NewServer(ratelimit.Config{
+		PerIP: ratelimit.Limit{Rate: 0.5, Burst: 1},
+	}))
+
+	request := func() *networkservice.NetworkServiceRequest {
+		return &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{
+				Id: "conn-1",
+				Context: &networkservice.ConnectionContext{
+					IpContext: &networkservice.IPContext{
+						SrcIpAddrs: []string{"172.16.1.100/32"},
+					},
+				},
+			},
+		}
+	}
+
+	_, err := server.Request(ctx, request())
+	require.NoError(t, err)
+
+	_, err = server.Request(ctx, request())
+	require.Error(t, err)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Contains(t, st.Message(), "172.16.1.100")
+
+	require.Len(t, st.Details(), 1)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	require.Equal(t, 2*time.Second, retryInfo.GetRetryDelay().AsDuration())
+
+	// Close不受限流影响
+	_, err = server.Close(ctx, request().GetConnection())
+	require.NoError(t, err)
+
+	clockMock.Add(2 * time.Second)
+	_, err = server.Request(ctx, request())
+	require.NoError(t, err)
+}
+
+// clientRequest 返回路径第一个段携带客户端token、没有源IP的请求（与客户端的首次Request相同）
+func clientRequest(t *testing.T, spiffeID string) *networkservice.NetworkServiceRequest {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: spiffeID}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "conn-" + spiffeID,
+			Path: &networkservice.Path{
+				PathSegments: []*networkservice.PathSegment{{Name: "nsc", Token: token}, {Name: "nsmgr"}},
+			},
+		},
+	}
+}
+
+func TestServer_PerClientSpiffeID(t *testing.T) {
+	server := chain.NewNetworkServiceServer(ratelimit.NewServer(ratelimit.Config{
+		PerIP:       ratelimit.Limit{Rate: 1, Burst: 1},
+		PerSpiffeID: ratelimit.Limit{Rate: 0.1, Burst: 1},
+	}))
+
+	_, err := server.Request(context.Background(), clientRequest(t, "spiffe://example.org/ns/a/sa/nsc"))
+	require.NoError(t, err)
+
+	// 同一客户端的第二个请求被限流（没有源IP，按客户端SPIFFE ID限流）
+	_, err = server.Request(context.Background(), clientRequest(t, "spiffe://example.org/ns/a/sa/nsc"))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Contains(t, err.Error(), "spiffe://example.org/ns/a/sa/nsc")
+
+	// 经同一个NSMgr转发的其他客户端使用各自的令牌桶
+	_, err = server.Request(context.Background(), clientRequest(t, "spiffe://example.org/ns/b/sa/nsc"))
+	require.NoError(t, err)
+}
+
+func TestClientSpiffeID(t *testing.T) {
+	require.Equal(t, "spiffe://example.org/ns/a/sa/nsc", ratelimit.ClientSpiffeID(clientRequest(t, "spiffe://example.org/ns/a/sa/nsc").GetConnection()))
+	require.Empty(t, ratelimit.ClientSpiffeID(&networkservice.Connection{}))
+	require.Empty(t, ratelimit.ClientSpiffeID(&networkservice.Connection{
+		Path: &networkservice.Path{PathSegments: []*networkservice.PathSegment{{Token: "not-a-jwt"}}},
+	}))
+	require.Empty(t, ratelimit.ClientSpiffeID(nil))
+}
+
+func TestSourceIP(t *testing.T) {
+	conn := func(addrs ...string) *networkservice.Connection {
+		return &networkservice.Connection{
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{SrcIpAddrs: addrs},
+			},
+		}
+	}
+
+	require.Equal(t, "10.0.0.1", ratelimit.SourceIP(conn("10.0.0.1/32")))
+	require.Equal(t, "fe80::1", ratelimit.SourceIP(conn("fe80::1")))
+	require.Equal(t, "", ratelimit.SourceIP(conn("bad")))
+	require.Equal(t, "", ratelimit.SourceIP(conn()))
+	require.Equal(t, "", ratelimit.SourceIP(nil))
+}
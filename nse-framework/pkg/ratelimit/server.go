@@ -0,0 +1,122 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/clock"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type rateLimitServer struct {
+	limiter *Limiter
+}
+
+// NewServer 创建限流链元素
+//
+// 参数：
+//   - cfg: 限流配置
+//
+// 返回值：
+//   - 实现networkservice.NetworkServiceServer接口的限流中间件
+//
+// 示例：
+//
+//	rl := ratelimit.NewServer(ratelimit.Config{
+//	    PerIP:  ratelimit.Limit{Rate: 5, Burst: 10},
+//	    Global: ratelimit.Limit{Rate: 100, Burst: 200},
+//	})
+func NewServer(cfg Config) networkservice.NetworkServiceServer {
+	return NewServerWithLimiter(NewLimiter(cfg))
+}
+
+// NewServerWithLimiter 使用已有的Limiter创建限流链元素
+//
+// 多个端点共享同一个Limiter时，全局上限对所有端点生效。
+func NewServerWithLimiter(limiter *Limiter) networkservice.NetworkServiceServer {
+	return &rateLimitServer{
+		limiter: limiter,
+	}
+}
+
+func (s *rateLimitServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	ip := SourceIP(request.GetConnection())
+	spiffeID := ClientSpiffeID(request.GetConnection())
+
+	decision := s.limiter.Allow(clock.FromContext(ctx).Now(), ip, spiffeID)
+	if !decision.Allowed {
+		log.FromContext(ctx).WithField("ratelimit", "server").
+			Warnf("rejecting request %s: scope=%s key=%q retryAfter=%v",
+				request.GetConnection().GetId(), decision.Scope, decision.Key, decision.RetryAfter)
+		return nil, decision.Err()
+	}
+
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (s *rateLimitServer) Close(ctx context.Context, conn *networkservice.Connection) (*emptypb.Empty, error) {
+	// Close不受限流，保证连接资源总能被释放
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+// SourceIP 从连接的IPContext中提取第一个源IP（去除掩码），无法获取时返回空字符串
+func SourceIP(conn *networkservice.Connection) string {
+	srcIPs := conn.GetContext().GetIpContext().GetSrcIpAddrs()
+	if len(srcIPs) == 0 {
+		return ""
+	}
+	addr := srcIPs[0]
+	if strings.Contains(addr, "/") {
+		ip, _, err := net.ParseCIDR(addr)
+		if err != nil {
+			return ""
+		}
+		return ip.String()
+	}
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// ClientSpiffeID 返回发起连接的客户端的SPIFFE ID，无法获取时返回空字符串
+//
+// 客户端身份在路径第一个段的token中（token的subject）。gRPC对端是NSMgr，
+// 不是客户端，因此不能使用TLS对端证书。token由authorize链元素验证，
+// 这里只解析不验证签名。
+func ClientSpiffeID(conn *networkservice.Connection) string {
+	segments := conn.GetPath().GetPathSegments()
+	if len(segments) == 0 || segments[0].GetToken() == "" {
+		return ""
+	}
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(segments[0].GetToken(), &claims); err != nil {
+		return ""
+	}
+	return claims.Subject
+}
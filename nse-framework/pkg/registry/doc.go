@@ -6,6 +6,7 @@
 //   - 创建NSM注册表客户端
 //   - 注册NSE到NSM
 //   - 注销NSE
+//   - 保持注册（Keep）：过期前刷新、失败时指数退避重试、NSMgr重启后重新注册、退出时注销
 //   - 处理OPA策略配置
 //
 // 使用示例：
@@ -15,4 +16,10 @@
 //	    Policies:  cfg.RegistryClientPolicies,
 //	})
 //	nse, err := client.Register(ctx, nseSpec)
+//
+// 长期运行的NSE应使用Keep代替Register/Unregister：
+//
+//	for s := range client.Keep(ctx, nseSpec) {
+//	    log.Infof("registration %s", s.State)
+//	}
 package registry
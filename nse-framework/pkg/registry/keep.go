@@ -0,0 +1,317 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"time"
+
+	registryapi "github.com/networkservicemesh/api/pkg/api/registry"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusBuffer 状态通道的缓冲大小，满时丢弃最旧的状态
+const statusBuffer = 16
+
+// State 注册状态
+type State int
+
+const (
+	// StateRegistering 正在进行首次注册
+	StateRegistering State = iota
+	// StateRegistered 注册或刷新成功
+	StateRegistered
+	// StateRetrying 注册或刷新失败，等待退避间隔后重试
+	StateRetrying
+	// StateLost 注册丢失（NSMgr重启或注册被删除），等待退避间隔后重新注册
+	StateLost
+	// StateUnregistered Keep退出并已注销（注销失败时Status.Err非nil）
+	StateUnregistered
+)
+
+// String 返回状态名称
+func (s State) String() string {
+	switch s {
+	case StateRegistering:
+		return "registering"
+	case StateRegistered:
+		return "registered"
+	case StateRetrying:
+		return "retrying"
+	case StateLost:
+		return "lost"
+	case StateUnregistered:
+		return "unregistered"
+	default:
+		return "unknown"
+	}
+}
+
+// Status 注册状态变化
+type Status struct {
+	// State 当前状态
+	State State
+
+	// NSE 最近一次注册成功的NSE（尚未注册成功时为nil）
+	NSE *registryapi.NetworkServiceEndpoint
+
+	// Err 导致重试、注册丢失或注销失败的错误
+	Err error
+
+	// Attempt 连续失败次数（StateRetrying、StateLost时有效）
+	Attempt int
+
+	// RetryIn 距下一次尝试的时间（StateRetrying、StateLost时有效）
+	RetryIn time.Duration
+
+	// Time 状态变化的时间
+	Time time.Time
+}
+
+// Keep 注册NSE并保持注册直到ctx被取消
+//
+// 注册成功后在过期时间的2/3处刷新注册，并监视（Find watch）本NSE的注册：
+//   - 注册或刷新失败时按指数退避重试（InitialBackoff起，每次翻倍，不超过MaxBackoff）
+//   - 监视流中断（如NSMgr重启）或注册被删除时重新注册
+//   - ctx取消后使用独立的带超时上下文注销NSE，发送StateUnregistered后关闭状态通道
+//
+// 状态通道有缓冲，消费者处理不及时时丢弃最旧的状态；最近的状态也可通过Status获取。
+// 每个Client只能调用一次Keep，Keep期间不要再调用Register和Unregister。
+//
+// 示例：
+//
+//	statusCh := client.Keep(ctx, registry.RegisterSpec{...})
+//	for s := range statusCh {
+//	    log.Infof("registration %s", s.State)
+//	}
+func (c *Client) Keep(ctx context.Context, spec RegisterSpec) <-chan Status {
+	c.mu.Lock()
+	c.statusCh = make(chan Status, statusBuffer)
+	statusCh := c.statusCh
+	c.mu.Unlock()
+
+	go c.keep(ctx, spec.endpoint())
+	return statusCh
+}
+
+// Status 返回最近的注册状态
+func (c *Client) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// keep Keep的注册循环
+func (c *Client) keep(ctx context.Context, nse *registryapi.NetworkServiceEndpoint) {
+	logger := log.FromContext(ctx).WithField("registry", nse.GetName())
+	defer c.unregisterOnExit(ctx, logger)
+
+	c.setStatus(Status{State: StateRegistering})
+
+	// failures 连续的注册失败次数；losses 连续的注册丢失次数
+	var failures, losses int
+	var delay time.Duration
+	for {
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		registered, err := c.registerOnce(ctx, nse)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			delay = c.backoff(failures)
+			logger.Warnf("registration attempt %d failed, retrying in %v: %v", failures, delay, err)
+			c.setStatus(Status{State: StateRetrying, NSE: c.lastRegistered(), Err: err, Attempt: failures, RetryIn: delay})
+			continue
+		}
+		failures = 0
+		registeredAt := time.Now()
+		logger.Infof("registered, expires at %v", expiration(registered))
+		c.setStatus(Status{State: StateRegistered, NSE: registered})
+
+		watchCtx, cancelWatch := context.WithCancel(ctx)
+		lost := c.watch(watchCtx, nse.GetName())
+		var refresh <-chan time.Time
+		if d, ok := c.refreshIn(registered); ok {
+			refresh = time.After(d)
+		}
+
+		select {
+		case <-ctx.Done():
+			cancelWatch()
+			return
+		case <-refresh:
+			cancelWatch()
+			losses = 0
+			delay = 0
+		case err := <-lost:
+			cancelWatch()
+			// 注册在较长时间后才丢失时不累积退避
+			if time.Since(registeredAt) >= c.opts.MaxBackoff {
+				losses = 0
+			}
+			losses++
+			delay = c.backoff(losses)
+			logger.Warnf("registration lost, re-registering in %v: %v", delay, err)
+			c.setStatus(Status{State: StateLost, NSE: registered, Err: err, Attempt: losses, RetryIn: delay})
+		}
+	}
+}
+
+// registerOnce 执行一次带超时的注册
+func (c *Client) registerOnce(ctx context.Context, nse *registryapi.NetworkServiceEndpoint) (*registryapi.NetworkServiceEndpoint, error) {
+	registerCtx, cancel := context.WithTimeout(ctx, c.opts.RegisterTimeout)
+	defer cancel()
+
+	registered, err := c.client.Register(registerCtx, nse.Clone())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to register nse")
+	}
+	c.mu.Lock()
+	c.registered = registered
+	c.mu.Unlock()
+	return registered, nil
+}
+
+// watch 监视本NSE的注册，监视流中断或注册被删除时向返回的通道发送原因
+//
+// 注册表不支持监视时返回nil通道，此时只依赖定期刷新。
+// 监视不等待连接就绪：NSMgr不可达时立即视为注册丢失，而不是阻塞到其恢复。
+func (c *Client) watch(ctx context.Context, name string) <-chan error {
+	lost := make(chan error, 1)
+	stream, err := c.client.Find(ctx, &registryapi.NetworkServiceEndpointQuery{
+		NetworkServiceEndpoint: &registryapi.NetworkServiceEndpoint{Name: name},
+		Watch:                  true,
+	}, grpc.WaitForReady(false))
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil
+		}
+		lost <- errors.Wrap(err, "unable to watch nse")
+		return lost
+	}
+
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				if status.Code(err) == codes.Unimplemented {
+					return
+				}
+				lost <- errors.Wrap(err, "nse watch stream closed")
+				return
+			}
+			if resp.GetDeleted() && resp.GetNetworkServiceEndpoint().GetName() == name {
+				lost <- errors.Errorf("nse %s was deleted from the registry", name)
+				return
+			}
+		}
+	}()
+	return lost
+}
+
+// unregisterOnExit Keep退出时注销NSE并关闭状态通道
+func (c *Client) unregisterOnExit(ctx context.Context, logger log.Logger) {
+	unregisterCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.UnregisterTimeout)
+	defer cancel()
+
+	err := c.Unregister(unregisterCtx)
+	if err != nil {
+		logger.Errorf("%+v", err)
+	} else {
+		logger.Infof("unregistered")
+	}
+	c.setStatus(Status{State: StateUnregistered, Err: err})
+
+	c.mu.Lock()
+	close(c.statusCh)
+	c.mu.Unlock()
+}
+
+// refreshIn 返回距刷新注册的时间（过期时间的2/3处），注册没有过期时间时返回false
+func (c *Client) refreshIn(nse *registryapi.NetworkServiceEndpoint) (time.Duration, bool) {
+	if nse.GetExpirationTime() == nil {
+		return 0, false
+	}
+	d := 2 * time.Until(nse.GetExpirationTime().AsTime()) / 3
+	if d < c.opts.InitialBackoff {
+		d = c.opts.InitialBackoff
+	}
+	return d, true
+}
+
+// backoff 返回第n次连续失败后的重试间隔
+func (c *Client) backoff(n int) time.Duration {
+	d := c.opts.InitialBackoff
+	for i := 1; i < n && d < c.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.opts.MaxBackoff {
+		d = c.opts.MaxBackoff
+	}
+	return d
+}
+
+// lastRegistered 返回最近一次注册成功的NSE
+func (c *Client) lastRegistered() *registryapi.NetworkServiceEndpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.registered
+}
+
+// setStatus 记录并发送状态，状态通道满时丢弃最旧的状态
+func (c *Client) setStatus(s Status) {
+	s.Time = time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = s
+	for {
+		select {
+		case c.statusCh <- s:
+			return
+		default:
+		}
+		select {
+		case <-c.statusCh:
+		default:
+		}
+	}
+}
+
+// expiration 返回注册的过期时间，用于日志
+func expiration(nse *registryapi.NetworkServiceEndpoint) interface{} {
+	if nse.GetExpirationTime() == nil {
+		return "never"
+	}
+	return nse.GetExpirationTime().AsTime()
+}
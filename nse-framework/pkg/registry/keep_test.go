@@ -0,0 +1,327 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	registryapi "github.com/networkservicemesh/api/pkg/api/registry"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/registry"
+)
+
+// fakeRegistry 进程内的NSE注册表
+type fakeRegistry struct {
+	mu sync.Mutex
+	// ttl 注册的有效期（0表示不过期）
+	ttl time.Duration
+	// failRegister 剩余的失败注册次数
+	failRegister int
+	nses         map[string]*registryapi.NetworkServiceEndpoint
+	registers    int
+	unregisters  int
+	// lateRefreshes 在过期之后才到达的刷新次数
+	lateRefreshes int
+}
+
+func newFakeRegistry(ttl time.Duration) *fakeRegistry {
+	return &fakeRegistry{ttl: ttl, nses: make(map[string]*registryapi.NetworkServiceEndpoint)}
+}
+
+func (f *fakeRegistry) Register(_ context.Context, nse *registryapi.NetworkServiceEndpoint) (*registryapi.NetworkServiceEndpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failRegister > 0 {
+		f.failRegister--
+		return nil, status.Error(codes.Unavailable, "registry is not ready")
+	}
+	if prev, ok := f.nses[nse.GetName()]; ok && prev.GetExpirationTime() != nil && time.Now().After(prev.GetExpirationTime().AsTime()) {
+		f.lateRefreshes++
+	}
+	nse = nse.Clone()
+	if f.ttl > 0 {
+		nse.ExpirationTime = timestamppb.New(time.Now().Add(f.ttl))
+	}
+	f.nses[nse.GetName()] = nse
+	f.registers++
+	return nse, nil
+}
+
+func (f *fakeRegistry) Find(query *registryapi.NetworkServiceEndpointQuery, server registryapi.NetworkServiceEndpointRegistry_FindServer) error {
+	f.mu.Lock()
+	nse, ok := f.nses[query.GetNetworkServiceEndpoint().GetName()]
+	f.mu.Unlock()
+	if ok {
+		if err := server.Send(&registryapi.NetworkServiceEndpointResponse{NetworkServiceEndpoint: nse}); err != nil {
+			return err
+		}
+	}
+	if query.GetWatch() {
+		<-server.Context().Done()
+	}
+	return nil
+}
+
+func (f *fakeRegistry) Unregister(_ context.Context, nse *registryapi.NetworkServiceEndpoint) (*emptypb.Empty, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.nses, nse.GetName())
+	f.unregisters++
+	return new(emptypb.Empty), nil
+}
+
+func (f *fakeRegistry) counts() (registers, unregisters, late int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.registers, f.unregisters, f.lateRefreshes
+}
+
+func (f *fakeRegistry) has(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.nses[name]
+	return ok
+}
+
+// serve 在unix socket上启动注册表，返回停止函数（模拟NSMgr退出）
+func serve(t *testing.T, sock string, f *fakeRegistry) func() {
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	registryapi.RegisterNetworkServiceEndpointRegistryServer(s, f)
+	go func() { _ = s.Serve(l) }()
+
+	var once sync.Once
+	stop := func() { once.Do(s.Stop) }
+	t.Cleanup(stop)
+	return stop
+}
+
+func newClient(t *testing.T, ctx context.Context, sock string) *registry.Client {
+	client, err := registry.NewClient(ctx, registry.Options{
+		ConnectTo: &url.URL{Scheme: "unix", Path: sock},
+		DialOptions: []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithDefaultCallOptions(grpc.WaitForReady(true)),
+		},
+		RegisterTimeout:   500 * time.Millisecond,
+		UnregisterTimeout: time.Second,
+		InitialBackoff:    20 * time.Millisecond,
+		MaxBackoff:        200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+var spec = registry.RegisterSpec{
+	Name:        "test-nse",
+	ServiceName: "test-service",
+	Labels:      map[string]string{"app": "test"},
+	URL:         "tcp://127.0.0.1:5001",
+}
+
+// next 从状态通道读取下一个状态
+func next(t *testing.T, statusCh <-chan registry.Status) registry.Status {
+	select {
+	case s, ok := <-statusCh:
+		require.True(t, ok, "status channel closed")
+		return s
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for registration status")
+		return registry.Status{}
+	}
+}
+
+// waitState 读取状态直到出现state，返回此前的所有状态和该状态
+func waitState(t *testing.T, statusCh <-chan registry.Status, state registry.State) (before []registry.Status, s registry.Status) {
+	for {
+		s = next(t, statusCh)
+		if s.State == state {
+			return before, s
+		}
+		before = append(before, s)
+	}
+}
+
+// waitClosed 等待状态通道关闭，返回最后一个状态
+func waitClosed(t *testing.T, statusCh <-chan registry.Status) registry.Status {
+	var last registry.Status
+	for {
+		select {
+		case s, ok := <-statusCh:
+			if !ok {
+				return last
+			}
+			last = s
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout waiting for the status channel to close")
+		}
+	}
+}
+
+func TestKeep_RefreshBeforeExpiration(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "nsm.sock")
+	fake := newFakeRegistry(300 * time.Millisecond)
+	serve(t, sock, fake)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newClient(t, context.Background(), sock)
+	statusCh := client.Keep(ctx, spec)
+
+	require.Equal(t, registry.StateRegistering, next(t, statusCh).State)
+	s := next(t, statusCh)
+	require.Equal(t, registry.StateRegistered, s.State)
+	require.Equal(t, "test-nse", s.NSE.GetName())
+	require.NotNil(t, s.NSE.GetExpirationTime())
+
+	// 有效期300ms，1秒内应刷新多次且每次都在过期之前
+	require.Eventually(t, func() bool {
+		registers, _, _ := fake.counts()
+		return registers >= 4
+	}, 2*time.Second, 10*time.Millisecond)
+	_, _, late := fake.counts()
+	require.Zero(t, late, "refresh should happen before expiration")
+	require.Equal(t, registry.StateRegistered, client.Status().State)
+
+	// 正常关闭时注销
+	cancel()
+	last := waitClosed(t, statusCh)
+	require.Equal(t, registry.StateUnregistered, last.State)
+	require.NoError(t, last.Err)
+	_, unregisters, _ := fake.counts()
+	require.Equal(t, 1, unregisters)
+	require.False(t, fake.has("test-nse"))
+	require.Equal(t, registry.StateUnregistered, client.Status().State)
+}
+
+func TestKeep_RetryWithBackoff(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "nsm.sock")
+	fake := newFakeRegistry(0)
+	fake.failRegister = 3
+	serve(t, sock, fake)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statusCh := newClient(t, context.Background(), sock).Keep(ctx, spec)
+
+	before, s := waitState(t, statusCh, registry.StateRegistered)
+	require.Equal(t, "test-nse", s.NSE.GetName())
+
+	var retries []registry.Status
+	for _, b := range before {
+		if b.State == registry.StateRetrying {
+			retries = append(retries, b)
+		}
+	}
+	require.Len(t, retries, 3)
+	for i, r := range retries {
+		require.Error(t, r.Err)
+		require.Equal(t, i+1, r.Attempt)
+	}
+	require.Equal(t, 20*time.Millisecond, retries[0].RetryIn)
+	require.Equal(t, 40*time.Millisecond, retries[1].RetryIn)
+	require.Equal(t, 80*time.Millisecond, retries[2].RetryIn)
+
+	cancel()
+	require.Equal(t, registry.StateUnregistered, waitClosed(t, statusCh).State)
+}
+
+func TestKeep_ReregisterAfterRestart(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "nsm.sock")
+	stop := serve(t, sock, newFakeRegistry(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statusCh := newClient(t, context.Background(), sock).Keep(ctx, spec)
+	waitState(t, statusCh, registry.StateRegistered)
+
+	// NSMgr重启：原注册表退出，新注册表没有任何注册
+	stop()
+	_, s := waitState(t, statusCh, registry.StateLost)
+	require.Error(t, s.Err)
+	require.Equal(t, "test-nse", s.NSE.GetName())
+
+	restarted := newFakeRegistry(0)
+	serve(t, sock, restarted)
+	waitState(t, statusCh, registry.StateRegistered)
+	require.True(t, restarted.has("test-nse"))
+
+	cancel()
+	require.Equal(t, registry.StateUnregistered, waitClosed(t, statusCh).State)
+	require.False(t, restarted.has("test-nse"))
+}
+
+func TestKeep_ShutdownBeforeRegistered(t *testing.T) {
+	// 注册表不可用
+	sock := filepath.Join(t.TempDir(), "nsm.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	statusCh := newClient(t, context.Background(), sock).Keep(ctx, spec)
+	waitState(t, statusCh, registry.StateRetrying)
+
+	cancel()
+	last := waitClosed(t, statusCh)
+	require.Equal(t, registry.StateUnregistered, last.State)
+	require.NoError(t, last.Err, "nothing to unregister")
+}
+
+func TestClient_RegisterUnregister(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "nsm.sock")
+	fake := newFakeRegistry(time.Minute)
+	serve(t, sock, fake)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := newClient(t, ctx, sock)
+
+	nse, err := client.Register(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, []string{"test-service"}, nse.GetNetworkServiceNames())
+	require.Equal(t, "test", nse.GetNetworkServiceLabels()["test-service"].GetLabels()["app"])
+	require.True(t, fake.has("test-nse"))
+
+	require.NoError(t, client.Unregister(ctx))
+	require.False(t, fake.has("test-nse"))
+	// 再次注销时没有已注册的NSE
+	require.NoError(t, client.Unregister(ctx))
+}
+
+func TestState_String(t *testing.T) {
+	require.Equal(t, "registering", registry.StateRegistering.String())
+	require.Equal(t, "registered", registry.StateRegistered.String())
+	require.Equal(t, "retrying", registry.StateRetrying.String())
+	require.Equal(t, "lost", registry.StateLost.String())
+	require.Equal(t, "unregistered", registry.StateUnregistered.String())
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	registryapi "github.com/networkservicemesh/api/pkg/api/registry"
+	registryauthorize "github.com/networkservicemesh/sdk/pkg/registry/common/authorize"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/begin"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/clientconn"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/clientinfo"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/clienturl"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/connect"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/dial"
+	"github.com/networkservicemesh/sdk/pkg/registry/common/grpcmetadata"
+	registrysendfd "github.com/networkservicemesh/sdk/pkg/registry/common/sendfd"
+	"github.com/networkservicemesh/sdk/pkg/registry/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/registry/utils/metadata"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// 注册保持的默认参数
+const (
+	defaultDialTimeout       = 300 * time.Millisecond
+	defaultRegisterTimeout   = 15 * time.Second
+	defaultUnregisterTimeout = 5 * time.Second
+	defaultInitialBackoff    = 100 * time.Millisecond
+	defaultMaxBackoff        = 10 * time.Second
+)
+
+// Client NSM注册表客户端
+type Client struct {
+	client registryapi.NetworkServiceEndpointRegistryClient
+	opts   Options
+
+	mu sync.Mutex
+	// registered 最近一次注册成功的NSE，注销时使用
+	registered *registryapi.NetworkServiceEndpoint
+	// status 最近一次的注册状态
+	status Status
+	// statusCh Keep返回的状态通道
+	statusCh chan Status
+}
+
+// Options 注册表客户端配置选项
+type Options struct {
+	// ConnectTo NSM管理平面连接地址
+	ConnectTo *url.URL
+
+	// Policies OPA策略文件路径列表
+	Policies []string
+
+	// DialOptions gRPC拨号选项（包含TLS、token等配置）
+	DialOptions []grpc.DialOption
+
+	// DialTimeout 连接NSM的拨号超时，为0时使用300ms
+	DialTimeout time.Duration
+
+	// RegisterTimeout Keep中单次注册（含刷新）请求的超时，为0时使用15s
+	RegisterTimeout time.Duration
+
+	// UnregisterTimeout Keep退出时注销请求的超时，为0时使用5s
+	UnregisterTimeout time.Duration
+
+	// InitialBackoff 注册失败或注册丢失后的首次重试间隔，为0时使用100ms
+	InitialBackoff time.Duration
+
+	// MaxBackoff 重试间隔上限（每次失败翻倍），为0时使用10s
+	MaxBackoff time.Duration
+}
+
+// NewClient 创建NSM注册表客户端
+//
+// 创建用于注册和注销NSE的客户端实例。
+// 配置了客户端信息、文件描述符传递和OPA授权策略。
+//
+// 与sdk的注册表客户端链不同，本客户端链不包含retry、heal和refresh链元素：
+// 重试、刷新和重新注册由Keep负责，其进度通过状态通道对外可见。
+//
+// 参数：
+//   - ctx: 上下文
+//   - opts: 客户端配置选项
+//
+// 返回值：
+//   - client: 注册表客户端实例
+//   - err: 创建错误
+//
+// 示例：
+//
+//	client, err := registry.NewClient(ctx, registry.Options{
+//	    ConnectTo:   &cfg.ConnectTo,
+//	    Policies:    cfg.RegistryClientPolicies,
+//	    DialOptions: clientOptions,
+//	})
+func NewClient(ctx context.Context, opts Options) (*Client, error) {
+	if opts.ConnectTo == nil {
+		return nil, errors.New("ConnectTo URL is required")
+	}
+
+	opts.DialTimeout = orDefault(opts.DialTimeout, defaultDialTimeout)
+	opts.RegisterTimeout = orDefault(opts.RegisterTimeout, defaultRegisterTimeout)
+	opts.UnregisterTimeout = orDefault(opts.UnregisterTimeout, defaultUnregisterTimeout)
+	opts.InitialBackoff = orDefault(opts.InitialBackoff, defaultInitialBackoff)
+	opts.MaxBackoff = orDefault(opts.MaxBackoff, defaultMaxBackoff)
+
+	// 创建NSE注册表客户端
+	nseRegistryClient := chain.NewNetworkServiceEndpointRegistryClient(
+		begin.NewNetworkServiceEndpointRegistryClient(),
+		metadata.NewNetworkServiceEndpointClient(),
+		registryauthorize.NewNetworkServiceEndpointRegistryClient(
+			registryauthorize.WithPolicies(opts.Policies...),
+		),
+		clienturl.NewNetworkServiceEndpointRegistryClient(opts.ConnectTo),
+		clientconn.NewNetworkServiceEndpointRegistryClient(),
+		grpcmetadata.NewNetworkServiceEndpointRegistryClient(),
+		dial.NewNetworkServiceEndpointRegistryClient(ctx,
+			dial.WithDialTimeout(opts.DialTimeout),
+			dial.WithDialOptions(opts.DialOptions...),
+		),
+		clientinfo.NewNetworkServiceEndpointRegistryClient(),
+		registrysendfd.NewNetworkServiceEndpointRegistryClient(),
+		connect.NewNetworkServiceEndpointRegistryClient(),
+	)
+
+	return &Client{
+		client: nseRegistryClient,
+		opts:   opts,
+	}, nil
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// Service 注册的网络服务及其单独的标签
+type Service struct {
+	// Name 网络服务名称
+	Name string
+
+	// Labels 该服务单独的标签，与RegisterSpec.Labels合并，同名键以此为准
+	Labels map[string]string
+}
+
+// RegisterSpec NSE注册规范
+type RegisterSpec struct {
+	// Name NSE名称
+	Name string
+
+	// ServiceName 提供的网络服务名称（单服务时的简写，等同于Services中的一项）
+	ServiceName string
+
+	// Services 提供的网络服务列表，排在ServiceName之后，重复的服务名称只注册一次
+	Services []Service
+
+	// Labels 所有服务共用的端点标签
+	Labels map[string]string
+
+	// URL NSE监听地址（Unix socket URL）
+	URL string
+}
+
+// Register 注册NSE到NSM
+//
+// 向NSM管理平面注册网络服务端点（单次请求，不重试、不刷新）。
+// 需要保持注册时使用Keep。
+//
+// 参数：
+//   - ctx: 上下文
+//   - spec: NSE注册规范
+//
+// 返回值：
+//   - nse: 注册后的NSE实例
+//   - err: 注册错误
+//
+// 示例：
+//
+//	nse, err := client.Register(ctx, registry.RegisterSpec{
+//	    Name:        "firewall-server",
+//	    ServiceName: "firewall",
+//	    Labels:      map[string]string{"app": "firewall"},
+//	    URL:         "unix:///tmp/firewall.sock",
+//	})
+func (c *Client) Register(ctx context.Context, spec RegisterSpec) (*registryapi.NetworkServiceEndpoint, error) {
+	// 执行注册
+	registeredNSE, err := c.client.Register(ctx, spec.endpoint())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to register nse")
+	}
+	c.mu.Lock()
+	c.registered = registeredNSE
+	c.mu.Unlock()
+
+	return registeredNSE, nil
+}
+
+// endpoint 构建NSE注册请求
+func (spec *RegisterSpec) endpoint() *registryapi.NetworkServiceEndpoint {
+	nse := &registryapi.NetworkServiceEndpoint{
+		Name:                 spec.Name,
+		NetworkServiceLabels: map[string]*registryapi.NetworkServiceLabels{},
+		Url:                  spec.URL,
+	}
+	for _, service := range append([]Service{{Name: spec.ServiceName}}, spec.Services...) {
+		if service.Name == "" {
+			continue
+		}
+		if _, ok := nse.NetworkServiceLabels[service.Name]; ok {
+			continue
+		}
+		labels := make(map[string]string, len(spec.Labels)+len(service.Labels))
+		for k, v := range spec.Labels {
+			labels[k] = v
+		}
+		for k, v := range service.Labels {
+			labels[k] = v
+		}
+		nse.NetworkServiceNames = append(nse.NetworkServiceNames, service.Name)
+		nse.NetworkServiceLabels[service.Name] = &registryapi.NetworkServiceLabels{Labels: labels}
+	}
+	return nse
+}
+
+// Unregister 从NSM注销最近一次注册的NSE
+//
+// 未注册时直接返回。ctx在NSE关闭阶段通常已被取消，
+// 调用方应传入独立的带超时上下文。
+//
+// 示例：
+//
+//	unregisterCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+//	defer cancel()
+//	if err := client.Unregister(unregisterCtx); err != nil {
+//	    log.Error(err)
+//	}
+func (c *Client) Unregister(ctx context.Context) error {
+	c.mu.Lock()
+	registered := c.registered
+	c.mu.Unlock()
+	if registered == nil {
+		return nil
+	}
+	if _, err := c.client.Unregister(ctx, registered); err != nil {
+		return errors.Wrapf(err, "unable to unregister nse %s", registered.GetName())
+	}
+	c.mu.Lock()
+	c.registered = nil
+	c.mu.Unlock()
+
+	return nil
+}
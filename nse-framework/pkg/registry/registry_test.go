@@ -0,0 +1,60 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/registry"
+)
+
+func TestClient_RegisterServices(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "nsm.sock")
+	serve(t, sock, newFakeRegistry(time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := newClient(t, ctx, sock)
+
+	nse, err := client.Register(ctx, registry.RegisterSpec{
+		Name:        "sfc-nse",
+		ServiceName: "firewall",
+		Services: []registry.Service{
+			{Name: "qos", Labels: map[string]string{"tier": "core", "app": "qos"}},
+			{Name: "firewall", Labels: map[string]string{"tier": "ignored"}},
+			{Name: "nat"},
+		},
+		Labels: map[string]string{"app": "sfc"},
+		URL:    "tcp://127.0.0.1:5001",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"firewall", "qos", "nat"}, nse.GetNetworkServiceNames())
+	labels := nse.GetNetworkServiceLabels()
+	require.Equal(t, map[string]string{"app": "sfc"}, labels["firewall"].GetLabels(), "重复的服务只注册第一次出现")
+	require.Equal(t, map[string]string{"app": "qos", "tier": "core"}, labels["qos"].GetLabels(), "服务标签覆盖共用标签")
+	require.Equal(t, map[string]string{"app": "sfc"}, labels["nat"].GetLabels())
+}
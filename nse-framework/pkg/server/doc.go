@@ -0,0 +1,25 @@
+// Package server 提供gRPC服务器管理功能
+//
+// 本包负责创建和启动gRPC服务器，处理TLS证书配置，
+// 并提供服务器生命周期管理。
+//
+// 主要功能：
+//   - 创建gRPC服务器实例
+//   - 配置mTLS证书（使用SPIFFE）
+//   - 按监听地址创建Unix socket（相对名称、绝对路径、抽象socket）或TCP监听器
+//   - 设置Unix socket文件的权限和所有者
+//   - 生成注册到NSM的监听URL（支持覆盖TCP地址中的主机）
+//   - 注册业务服务后启动服务器并监听请求
+//   - 管理服务器错误
+//
+// 使用示例：
+//
+//	tlsConfig := server.CreateTLSServerConfig(source)
+//	result, err := server.New(ctx, server.Options{
+//	    TLSConfig:     tlsConfig,
+//	    Name:          "gateway-server",
+//	    ListenOn:      "tcp://0.0.0.0:5003",
+//	    AdvertiseHost: os.Getenv("POD_IP"),
+//	    Register:      endpoint.Register,
+//	})
+package server
@@ -0,0 +1,232 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// defaultSocketMode 未指定SocketMode时Unix socket文件的权限（与grpcutils.ListenAndServe一致）
+const defaultSocketMode os.FileMode = 0o777
+
+// listenAddr 解析后的监听地址
+type listenAddr struct {
+	// network net.Listen的网络类型（unix或tcp）
+	network string
+
+	// address net.Listen的地址；相对socket名称在创建临时目录后才确定完整路径
+	address string
+
+	// relative 是否为相对socket名称（需在临时目录中创建）
+	relative bool
+
+	// abstract 是否为Linux抽象Unix socket（不对应文件）
+	abstract bool
+}
+
+// parseListenOn 解析监听地址
+//
+// 支持的格式：
+//   - "listen.on.sock"、"unix://listen.on.sock"、"unix:listen.on.sock"：相对名称，在临时目录中创建
+//   - "/abs/path.sock"、"unix:///abs/path.sock"：绝对路径
+//   - "@name"、"unix:@name"：Linux抽象Unix socket
+//   - "tcp://host:port"：TCP地址，端口为0时使用随机端口
+func parseListenOn(listenOn string) (*listenAddr, error) {
+	if listenOn == "" {
+		return nil, errors.New("listen address is empty")
+	}
+
+	if !strings.Contains(listenOn, ":") {
+		return unixListenAddr(listenOn)
+	}
+
+	scheme, rest, _ := strings.Cut(listenOn, ":")
+	switch scheme {
+	case "unix":
+		if !strings.HasPrefix(rest, "//") {
+			// unix:name 或 unix:@name
+			return unixListenAddr(rest)
+		}
+		u, err := url.Parse(listenOn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid listen address %s", listenOn)
+		}
+		// unix://name 中的名称被解析为Host
+		return unixListenAddr(u.Host + u.Path)
+	case "tcp":
+		u, err := url.Parse(listenOn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid listen address %s", listenOn)
+		}
+		if _, _, err := net.SplitHostPort(u.Host); err != nil || u.Path != "" {
+			return nil, errors.Errorf("invalid listen address %s: expected tcp://host:port", listenOn)
+		}
+		return &listenAddr{network: "tcp", address: u.Host}, nil
+	default:
+		return nil, errors.Errorf("invalid listen address %s: unsupported scheme %q", listenOn, scheme)
+	}
+}
+
+// unixListenAddr 根据socket名称或路径创建Unix监听地址
+func unixListenAddr(path string) (*listenAddr, error) {
+	switch {
+	case path == "" || path == "@":
+		return nil, errors.New("unix socket name is empty")
+	case strings.HasPrefix(path, "@"):
+		return &listenAddr{network: "unix", address: path, abstract: true}, nil
+	case filepath.IsAbs(path):
+		return &listenAddr{network: "unix", address: filepath.Clean(path)}, nil
+	default:
+		return &listenAddr{network: "unix", address: path, relative: true}, nil
+	}
+}
+
+// listen 创建监听器，并设置Unix socket文件的权限和所有者
+func (a *listenAddr) listen(mode os.FileMode, owner string) (net.Listener, error) {
+	socketFile := a.network == "unix" && !a.abstract
+	if socketFile {
+		if err := os.MkdirAll(filepath.Dir(a.address), 0o750); err != nil {
+			return nil, errors.Wrapf(err, "error creating socket directory for %s", a.address)
+		}
+		// 清理上次运行残留的socket文件
+		if err := os.Remove(a.address); err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "error removing stale socket %s", a.address)
+		}
+	}
+
+	ln, err := net.Listen(a.network, a.address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error listening on %s://%s", a.network, a.address)
+	}
+	if !socketFile {
+		return ln, nil
+	}
+
+	if mode == 0 {
+		mode = defaultSocketMode
+	}
+	if err := os.Chmod(a.address, mode); err != nil {
+		_ = ln.Close()
+		return nil, errors.Wrapf(err, "error changing mode of %s", a.address)
+	}
+	if owner != "" {
+		uid, gid, err := lookupOwner(owner)
+		if err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+		if err := os.Chown(a.address, uid, gid); err != nil {
+			_ = ln.Close()
+			return nil, errors.Wrapf(err, "error changing owner of %s", a.address)
+		}
+	}
+	return ln, nil
+}
+
+// lookupOwner 解析"uid[:gid]"或"user[:group]"格式的所有者，未指定的部分返回-1（不修改）
+func lookupOwner(owner string) (uid, gid int, err error) {
+	userPart, groupPart, _ := strings.Cut(owner, ":")
+	uid, gid = -1, -1
+
+	if userPart != "" {
+		if uid, err = strconv.Atoi(userPart); err != nil {
+			u, lookupErr := user.Lookup(userPart)
+			if lookupErr != nil {
+				return 0, 0, errors.Wrapf(lookupErr, "invalid socket owner %s", owner)
+			}
+			uid, _ = strconv.Atoi(u.Uid)
+		}
+	}
+	if groupPart != "" {
+		if gid, err = strconv.Atoi(groupPart); err != nil {
+			g, lookupErr := user.LookupGroup(groupPart)
+			if lookupErr != nil {
+				return 0, 0, errors.Wrapf(lookupErr, "invalid socket owner %s", owner)
+			}
+			gid, _ = strconv.Atoi(g.Gid)
+		}
+	}
+	return uid, gid, nil
+}
+
+// advertiseURL 根据监听器的实际地址生成注册到NSM的URL
+//
+// TCP监听在未指定地址（如0.0.0.0、[::]）上时，使用advertiseHost；
+// 未设置advertiseHost时使用本机第一个非回环的单播地址。
+// advertiseHost对Unix socket无效。
+func advertiseURL(ln net.Listener, advertiseHost string) (*url.URL, error) {
+	tcpAddr, ok := ln.Addr().(*net.TCPAddr)
+	if !ok {
+		path := ln.Addr().String()
+		if strings.HasPrefix(path, "@") {
+			// 抽象socket使用unix:@name形式，避免被解析为unix://主机
+			return &url.URL{Scheme: "unix", Opaque: path}, nil
+		}
+		return &url.URL{Scheme: "unix", Path: path}, nil
+	}
+
+	port := strconv.Itoa(tcpAddr.Port)
+	switch {
+	case advertiseHost != "":
+		return &url.URL{Scheme: "tcp", Host: net.JoinHostPort(advertiseHost, port)}, nil
+	case !tcpAddr.IP.IsUnspecified():
+		return &url.URL{Scheme: "tcp", Host: net.JoinHostPort(tcpAddr.IP.String(), port)}, nil
+	}
+
+	ip, err := hostIP()
+	if err != nil {
+		return nil, err
+	}
+	return &url.URL{Scheme: "tcp", Host: net.JoinHostPort(ip.String(), port)}, nil
+}
+
+// hostIP 返回本机第一个非回环的单播地址，优先IPv4
+func hostIP() (net.IP, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing interface addresses")
+	}
+	var v6 net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || !ipNet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+		if v6 == nil {
+			v6 = ipNet.IP
+		}
+	}
+	if v6 != nil {
+		return v6, nil
+	}
+	return nil, errors.New("cannot determine host address to advertise, set the advertised host explicitly")
+}
@@ -23,6 +23,7 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -33,9 +34,11 @@ import (
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
 
-	"github.com/networkservicemesh/sdk/pkg/tools/grpcutils"
 	"github.com/networkservicemesh/sdk/pkg/tools/tracing"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/health"
 )
 
 // CreateTLSServerConfig 创建mTLS服务器配置
@@ -86,8 +89,31 @@ type Options struct {
 	// Name 服务器名称，用于创建临时目录
 	Name string
 
-	// ListenOn Unix socket文件名（不是完整路径）
+	// ListenOn 监听地址，支持以下格式：
+	//   - "listen.on.sock" 或 "unix://listen.on.sock"：相对名称，在临时目录中创建
+	//   - "unix:///abs/path.sock"：绝对路径
+	//   - "unix:@name"：Linux抽象Unix socket
+	//   - "tcp://host:port"：TCP地址
 	ListenOn string
+
+	// SocketMode Unix socket文件的权限，为0时使用0777（抽象socket和TCP忽略）
+	SocketMode os.FileMode
+
+	// SocketOwner Unix socket文件的所有者，格式为"uid[:gid]"或"user[:group]"，为空时不修改
+	SocketOwner string
+
+	// AdvertiseHost 注册到NSM的TCP地址中的主机，为空时使用监听地址，
+	// 监听地址未指定（如0.0.0.0）时使用本机第一个非回环地址
+	AdvertiseHost string
+
+	// Health 健康检查状态，非nil时在服务器上注册gRPC健康检查服务
+	Health *health.Checker
+
+	// Reflection 是否注册gRPC服务器反射（用于grpcurl等调试工具）
+	Reflection bool
+
+	// Register 在服务器开始监听前注册业务服务（gRPC不允许在Serve之后注册服务），可以为nil
+	Register func(s *grpc.Server)
 }
 
 // Result 服务器创建结果
@@ -95,10 +121,10 @@ type Result struct {
 	// Server gRPC服务器实例
 	Server *grpc.Server
 
-	// ListenURL 监听URL（unix socket完整路径）
+	// ListenURL 注册到NSM的监听URL（unix socket完整路径或TCP地址）
 	ListenURL *url.URL
 
-	// TmpDir 临时目录路径，应在程序退出时清理
+	// TmpDir 临时目录路径（仅相对socket名称），应在程序退出时清理
 	TmpDir string
 
 	// ErrCh 服务器错误通道
@@ -107,7 +133,9 @@ type Result struct {
 
 // New 创建并启动gRPC服务器
 //
-// 创建gRPC服务器实例，配置TLS和追踪，创建临时目录和Unix socket，启动服务器监听。
+// 创建gRPC服务器实例，配置TLS和追踪，按需注册健康检查和反射服务并调用opts.Register，
+// 按ListenOn创建监听器（相对socket名称时先创建临时目录），启动服务器监听，
+// 并生成注册到NSM的ListenURL。
 //
 // 参数：
 //   - ctx: 上下文，用于控制服务器生命周期
@@ -125,41 +153,94 @@ type Result struct {
 //	    TLSConfig: tlsConfig,
 //	    Name:      "firewall-server",
 //	    ListenOn:  "listen.on.sock",
+//	    Register:  endpoint.Register,
 //	})
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
 //	defer os.RemoveAll(result.TmpDir)
 func New(ctx context.Context, opts Options) (*Result, error) {
-	// 创建gRPC服务器
-	grpcServer := grpc.NewServer(append(
-		tracing.WithTracing(),
-		grpc.Creds(
-			grpcfd.TransportCredentials(
-				credentials.NewTLS(opts.TLSConfig),
-			),
-		),
-	)...)
-
-	// 创建临时目录
-	tmpDir, err := os.MkdirTemp("", opts.Name)
+	addr, err := parseListenOn(opts.ListenOn)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error creating tmpDir for %s", opts.Name)
+		return nil, err
 	}
 
-	// 构建监听URL（Unix socket）
-	listenURL := &url.URL{
-		Scheme: "unix",
-		Path:   filepath.Join(tmpDir, opts.ListenOn),
+	// 相对socket名称在临时目录中创建
+	var tmpDir string
+	if addr.relative {
+		tmpDir, err = os.MkdirTemp("", opts.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating tmpDir for %s", opts.Name)
+		}
+		addr.address = filepath.Join(tmpDir, addr.address)
 	}
 
-	// 启动服务器监听
-	errCh := grpcutils.ListenAndServe(ctx, listenURL, grpcServer)
+	ln, listenURL, err := listenAndAdvertise(addr, opts)
+	if err != nil {
+		if tmpDir != "" {
+			_ = os.RemoveAll(tmpDir)
+		}
+		return nil, err
+	}
 
+	grpcServer := newGRPCServer(opts.TLSConfig)
+	if opts.Health != nil {
+		opts.Health.Register(grpcServer)
+	}
+	if opts.Reflection {
+		reflection.Register(grpcServer)
+	}
+	if opts.Register != nil {
+		opts.Register(grpcServer)
+	}
 	return &Result{
 		Server:    grpcServer,
 		ListenURL: listenURL,
 		TmpDir:    tmpDir,
-		ErrCh:     errCh,
+		ErrCh:     serve(ctx, grpcServer, ln),
 	}, nil
 }
+
+// listenAndAdvertise 创建监听器并生成注册到NSM的URL
+func listenAndAdvertise(addr *listenAddr, opts Options) (net.Listener, *url.URL, error) {
+	ln, err := addr.listen(opts.SocketMode, opts.SocketOwner)
+	if err != nil {
+		return nil, nil, err
+	}
+	listenURL, err := advertiseURL(ln, opts.AdvertiseHost)
+	if err != nil {
+		_ = ln.Close()
+		return nil, nil, err
+	}
+	return ln, listenURL, nil
+}
+
+// newGRPCServer 创建带mTLS（支持文件描述符传递）和追踪的gRPC服务器
+func newGRPCServer(tlsConfig *tls.Config) *grpc.Server {
+	return grpc.NewServer(append(
+		tracing.WithTracing(),
+		grpc.Creds(
+			grpcfd.TransportCredentials(
+				credentials.NewTLS(tlsConfig),
+			),
+		),
+	)...)
+}
+
+// serve 在监听器上启动服务器，ctx取消时停止服务器
+//
+// 服务器异常退出时错误发送到返回的通道，随后关闭通道。
+func serve(ctx context.Context, grpcServer *grpc.Server, ln net.Listener) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		grpcServer.Stop()
+	}()
+	go func() {
+		defer close(errCh)
+		if err := grpcServer.Serve(ln); err != nil {
+			errCh <- err
+		}
+	}()
+	return errCh
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server_test
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/server"
+)
+
+// newServer 创建服务器并在测试结束时停止
+func newServer(t *testing.T, opts server.Options) *server.Result {
+	ctx, cancel := context.WithCancel(context.Background())
+	opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	opts.Name = "server-test"
+	result, err := server.New(ctx, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cancel()
+		for range result.ErrCh {
+		}
+		_ = os.RemoveAll(result.TmpDir)
+	})
+	return result
+}
+
+func TestNew_RelativeSocket(t *testing.T) {
+	for _, listenOn := range []string{"listen.on.sock", "unix://listen.on.sock", "unix:listen.on.sock"} {
+		result := newServer(t, server.Options{ListenOn: listenOn})
+
+		require.NotEmpty(t, result.TmpDir, listenOn)
+		require.Equal(t, "unix", result.ListenURL.Scheme)
+		require.Equal(t, filepath.Join(result.TmpDir, "listen.on.sock"), result.ListenURL.Path)
+
+		info, err := os.Stat(result.ListenURL.Path)
+		require.NoError(t, err)
+		require.Equal(t, os.ModeSocket|0o777, info.Mode(), "默认权限与原实现一致")
+	}
+}
+
+func TestNew_AbsoluteSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "nse.sock")
+	owner := fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	result := newServer(t, server.Options{
+		ListenOn:    "unix://" + path,
+		SocketMode:  0o660,
+		SocketOwner: owner,
+	})
+
+	require.Empty(t, result.TmpDir, "绝对路径不创建临时目录")
+	require.Equal(t, "unix://"+path, result.ListenURL.String())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.ModeSocket|0o660, info.Mode())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestNew_AbstractSocket(t *testing.T) {
+	name := "@nse-server-test-" + strconv.Itoa(os.Getpid())
+	result := newServer(t, server.Options{ListenOn: "unix:" + name})
+
+	require.Equal(t, "unix:"+name, result.ListenURL.String())
+	conn, err := net.Dial("unix", name)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestNew_TCP(t *testing.T) {
+	result := newServer(t, server.Options{ListenOn: "tcp://127.0.0.1:0"})
+	require.Equal(t, "tcp", result.ListenURL.Scheme)
+	host, port, err := net.SplitHostPort(result.ListenURL.Host)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", host)
+	require.NotEqual(t, "0", port, "随机端口应替换为实际端口")
+
+	conn, err := net.Dial("tcp", result.ListenURL.Host)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	advertised := newServer(t, server.Options{ListenOn: "tcp://0.0.0.0:0", AdvertiseHost: "nse.example.svc"})
+	host, _, err = net.SplitHostPort(advertised.ListenURL.Host)
+	require.NoError(t, err)
+	require.Equal(t, "nse.example.svc", host)
+}
+
+func TestNew_Register(t *testing.T) {
+	var registered bool
+	result := newServer(t, server.Options{
+		ListenOn: "tcp://127.0.0.1:0",
+		Register: func(s *grpc.Server) {
+			healthpb.RegisterHealthServer(s, grpchealth.NewServer())
+			registered = true
+		},
+	})
+	require.True(t, registered, "New返回前注册业务服务")
+	require.Contains(t, result.Server.GetServiceInfo(), healthpb.Health_ServiceDesc.ServiceName)
+}
+
+func TestNew_InvalidListenOn(t *testing.T) {
+	for _, listenOn := range []string{"", "unix:", "unix:@", "http://localhost:80", "tcp://localhost", "tcp://localhost:80/path"} {
+		_, err := server.New(context.Background(), server.Options{Name: "server-test", ListenOn: listenOn})
+		require.Error(t, err, listenOn)
+	}
+
+	_, err := server.New(context.Background(), server.Options{
+		Name:        "server-test",
+		ListenOn:    "unix://" + filepath.Join(t.TempDir(), "nse.sock"),
+		SocketOwner: "no-such-user-for-test",
+	})
+	require.ErrorContains(t, err, "invalid socket owner")
+}
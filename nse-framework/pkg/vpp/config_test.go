@@ -0,0 +1,57 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteConfig_KeepsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vpp.conf")
+	require.NoError(t, os.WriteFile(path, []byte("unix { nodaemon }\n"), 0o600))
+
+	opts := Options{APISocket: managedAPISocket, Startup: &StartupConfig{MainCore: -1}, KeepExistingConfig: true}
+	require.NoError(t, writeConfig(context.Background(), path, opts))
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "unix { nodaemon }\n", string(contents), "未设置启动配置项时保留已有配置文件")
+
+	opts.KeepExistingConfig = false
+	require.NoError(t, writeConfig(context.Background(), path, opts))
+	contents, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "buffers-per-numa 32768", "设置了启动配置项时渲染并覆盖")
+}
+
+func TestWriteConfig_RendersMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "helper", "vpp.conf")
+
+	opts := Options{APISocket: managedAPISocket, Startup: &StartupConfig{MainCore: -1, Plugins: []string{"acl"}}, KeepExistingConfig: true}
+	require.NoError(t, writeConfig(context.Background(), path, opts))
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "plugin acl_plugin.so { enable }")
+}
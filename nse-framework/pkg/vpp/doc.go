@@ -0,0 +1,24 @@
+// Package vpp 提供VPP连接管理功能
+//
+// 本包封装VPP API连接的建立、错误监控和生命周期管理。
+//
+// 主要功能：
+//   - 受管模式（ModeManaged，默认）：在NSE容器内启动VPP并建立API连接，NSE退出时VPP随之停止
+//   - 外部模式（ModeExternal）：连接sidecar或宿主机上已运行的VPP的API socket，从不启动或停止VPP
+//   - 受管模式下由StartupConfig渲染并验证startup.conf（工作线程、CPU绑定、缓冲区、大页、插件），
+//     配置不一致时在启动VPP前返回错误；Options.KeepExistingConfig时保留已存在的配置文件
+//   - 两种模式相同的健康检查和重连：连接断开时按重试间隔重连，重连失败时通过错误通道报告
+//   - 在VPP错误时触发应用优雅退出
+//
+// 使用示例：
+//
+//	vppConn, errCh, err := vpp.Dial(ctx, vpp.Options{
+//	    Mode:      vpp.ModeExternal,
+//	    APISocket: "/var/run/vpp/api.sock",
+//	    OnHealth:  func(connected bool) { healthServer.Set(health.ComponentVPP, connected) },
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	lifecycle.MonitorErrorChannel(ctx, cancel, errCh)
+package vpp
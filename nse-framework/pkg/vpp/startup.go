@@ -0,0 +1,303 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// StartupConfig 受管VPP的启动配置，渲染为startup.conf
+//
+// 零值的BuffersPerNuma和BufferDataSize使用VPP默认值（见withDefaults），
+// Plugins为空时保留VPP默认启用的全部插件。
+type StartupConfig struct {
+	// Workers 工作线程数，0表示只使用主线程
+	Workers int
+	// MainCore 主线程绑定的CPU核，负数表示不绑定
+	MainCore int
+	// CorelistWorkers 工作线程绑定的CPU核列表，例如"2-3,6"
+	CorelistWorkers string
+	// BuffersPerNuma 每个NUMA节点的缓冲区数量
+	BuffersPerNuma int
+	// BufferDataSize 缓冲区数据大小（字节）
+	BufferDataSize int
+	// Hugepages 缓冲区和主堆使用大页内存
+	Hugepages bool
+	// APISocket API socket路径，为空时使用Options.APISocket
+	APISocket string
+	// Plugins 启用的插件（如acl、memif或acl_plugin.so），非空时其余插件全部禁用
+	Plugins []string
+	// DisabledPlugins 禁用的插件，不能与Plugins重叠
+	DisabledPlugins []string
+}
+
+// 受管VPP的默认缓冲区配置（与vpphelper一致）
+const (
+	defaultBuffersPerNuma = 32768
+	defaultBufferDataSize = managedDataSize
+)
+
+// pluginName 插件名，不含_plugin.so后缀
+var pluginName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// withDefaults 返回填充默认值后的配置
+func (c StartupConfig) withDefaults() StartupConfig {
+	if c.BuffersPerNuma == 0 {
+		c.BuffersPerNuma = defaultBuffersPerNuma
+	}
+	if c.BufferDataSize == 0 {
+		c.BufferDataSize = defaultBufferDataSize
+	}
+	if c.APISocket == "" {
+		c.APISocket = managedAPISocket
+	}
+	return c
+}
+
+// Validate 验证启动配置，返回第一个发现的不一致
+func (c StartupConfig) Validate() error {
+	c = c.withDefaults()
+	if c.Workers < 0 {
+		return errors.Errorf("workers must not be negative, got %d", c.Workers)
+	}
+	if c.BuffersPerNuma < 0 || c.BufferDataSize < 0 {
+		return errors.New("buffers-per-numa and data-size must not be negative")
+	}
+	if !filepath.IsAbs(c.APISocket) {
+		return errors.Errorf("API socket must be an absolute path, got %q", c.APISocket)
+	}
+
+	cores, err := parseCorelist(c.CorelistWorkers)
+	if err != nil {
+		return err
+	}
+	if len(cores) > 0 && c.Workers > 0 && len(cores) != c.Workers {
+		return errors.Errorf("workers %d does not match %d cores in corelist-workers %s", c.Workers, len(cores), c.CorelistWorkers)
+	}
+	for _, core := range cores {
+		if core == c.MainCore {
+			return errors.Errorf("main-core %d is also in corelist-workers %s", c.MainCore, c.CorelistWorkers)
+		}
+	}
+
+	enabled, err := pluginFiles(c.Plugins)
+	if err != nil {
+		return err
+	}
+	disabled, err := pluginFiles(c.DisabledPlugins)
+	if err != nil {
+		return err
+	}
+	for _, p := range disabled {
+		if contains(enabled, p) {
+			return errors.Errorf("plugin %s is both enabled and disabled", p)
+		}
+	}
+	return nil
+}
+
+// Render 验证并渲染startup.conf
+func (c StartupConfig) Render() (string, error) {
+	if err := c.Validate(); err != nil {
+		return "", errors.Wrap(err, "invalid VPP startup config")
+	}
+	c = c.withDefaults()
+	enabled, _ := pluginFiles(c.Plugins)
+	if len(enabled) == 0 {
+		// 与vpphelper一致，NSM不使用dpdk
+		c.DisabledPlugins = append(c.DisabledPlugins, "dpdk")
+	}
+	disabled, _ := pluginFiles(c.DisabledPlugins)
+
+	var buf bytes.Buffer
+	err := startupTemplate.Execute(&buf, struct {
+		StartupConfig
+		RunDir   string
+		Enabled  []string
+		Disabled []string
+	}{
+		StartupConfig: c,
+		RunDir:        filepath.Dir(managedAPISocket),
+		Enabled:       enabled,
+		Disabled:      disabled,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to render VPP startup config")
+	}
+	return buf.String(), nil
+}
+
+// parseCorelist 解析CPU核列表（如"2-3,6"），返回升序的核编号
+func parseCorelist(s string) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	seen := make(map[int]bool)
+	for _, item := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(strings.TrimSpace(item), "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil || first < 0 {
+			return nil, errors.Errorf("invalid core %q in corelist-workers %s", item, s)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil || last < first {
+				return nil, errors.Errorf("invalid core range %q in corelist-workers %s", item, s)
+			}
+		}
+		for core := first; core <= last; core++ {
+			if seen[core] {
+				return nil, errors.Errorf("core %d listed twice in corelist-workers %s", core, s)
+			}
+			seen[core] = true
+		}
+	}
+	cores := make([]int, 0, len(seen))
+	for core := range seen {
+		cores = append(cores, core)
+	}
+	sort.Ints(cores)
+	return cores, nil
+}
+
+// pluginFiles 将插件名规范为去重排序的插件文件名（acl -> acl_plugin.so）
+func pluginFiles(names []string) ([]string, error) {
+	var files []string
+	for _, name := range names {
+		name = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(name), ".so"), "_plugin")
+		if name == "" {
+			continue
+		}
+		if !pluginName.MatchString(name) {
+			return nil, errors.Errorf("invalid VPP plugin name %q", name)
+		}
+		if file := name + "_plugin.so"; !contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// meminfoFile 读取大页信息的文件
+const meminfoFile = "/proc/meminfo"
+
+// checkHugepages 检查节点上是否配置了大页
+func checkHugepages() error {
+	f, err := os.Open(meminfoFile)
+	if err != nil {
+		return errors.Wrap(err, "cannot read hugepage info")
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "HugePages_Total:"); ok {
+			if total, _ := strconv.Atoi(strings.TrimSpace(value)); total > 0 {
+				return nil
+			}
+			break
+		}
+	}
+	return errors.Errorf("hugepages are enabled for VPP but no hugepages are configured (HugePages_Total in %s)", meminfoFile)
+}
+
+// startupTemplate startup.conf模板，基于vpphelper.DefaultVPPConfTemplate
+var startupTemplate = template.Must(template.New("startup.conf").Parse(`unix {
+  nodaemon
+  log /var/log/vpp/vpp.log
+  full-coredump
+  cli-listen {{ .RunDir }}/cli.sock
+  gid vpp
+}
+
+buffers {
+  buffers-per-numa {{ .BuffersPerNuma }}
+  default data-size {{ .BufferDataSize }}
+{{- if .Hugepages }}
+  page-size default-hugepage
+{{- end }}
+}
+{{- if .Hugepages }}
+
+memory {
+  main-heap-page-size default-hugepage
+}
+{{- end }}
+
+api-trace {
+  on
+}
+
+api-segment {
+  gid vpp
+}
+
+socksvr {
+  socket-name {{ .APISocket }}
+}
+
+statseg {
+  socket-name {{ .RunDir }}/stats.sock
+}
+
+cpu {
+{{- if ge .MainCore 0 }}
+  main-core {{ .MainCore }}
+{{- end }}
+{{- if .CorelistWorkers }}
+  corelist-workers {{ .CorelistWorkers }}
+{{- else if .Workers }}
+  workers {{ .Workers }}
+{{- end }}
+}
+
+plugins {
+{{- if .Enabled }}
+  plugin default { disable }
+{{- range .Enabled }}
+  plugin {{ . }} { enable }
+{{- end }}
+{{- end }}
+{{- range .Disabled }}
+  plugin {{ . }} { disable }
+{{- end }}
+}
+`))
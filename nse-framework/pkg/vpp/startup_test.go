@@ -0,0 +1,101 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/vpp"
+)
+
+func TestStartupConfig_RenderDefaults(t *testing.T) {
+	conf, err := vpp.StartupConfig{MainCore: -1}.Render()
+	require.NoError(t, err)
+	require.Contains(t, conf, "buffers-per-numa 32768")
+	require.Contains(t, conf, "default data-size 2048")
+	require.Contains(t, conf, "socket-name /var/run/vpp/api.sock")
+	require.Contains(t, conf, "socket-name /var/run/vpp/stats.sock")
+	require.Contains(t, conf, "plugin dpdk_plugin.so { disable }", "与vpphelper一致禁用dpdk")
+	require.NotContains(t, conf, "plugin default")
+	require.NotContains(t, conf, "main-core")
+	require.NotContains(t, conf, "workers")
+	require.NotContains(t, conf, "default-hugepage")
+}
+
+func TestStartupConfig_Render(t *testing.T) {
+	conf, err := vpp.StartupConfig{
+		Workers:         2,
+		MainCore:        1,
+		CorelistWorkers: "2-3",
+		BuffersPerNuma:  65536,
+		BufferDataSize:  9216,
+		Hugepages:       true,
+		APISocket:       "/run/vpp/api.sock",
+		Plugins:         []string{"memif", "acl_plugin.so", "acl"},
+		DisabledPlugins: []string{"nat44_ei"},
+	}.Render()
+	require.NoError(t, err)
+	require.Contains(t, conf, "main-core 1\n  corelist-workers 2-3\n")
+	require.Contains(t, conf, "buffers-per-numa 65536\n  default data-size 9216\n  page-size default-hugepage\n")
+	require.Contains(t, conf, "main-heap-page-size default-hugepage")
+	require.Contains(t, conf, "socket-name /run/vpp/api.sock")
+	require.Contains(t, conf, "plugin default { disable }\n  plugin acl_plugin.so { enable }\n  plugin memif_plugin.so { enable }\n  plugin nat44_ei_plugin.so { disable }\n")
+
+	conf, err = vpp.StartupConfig{Workers: 4, MainCore: -1}.Render()
+	require.NoError(t, err)
+	require.Contains(t, conf, "cpu {\n  workers 4\n}")
+}
+
+func TestStartupConfig_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config vpp.StartupConfig
+		err    string
+	}{
+		{"负数工作线程", vpp.StartupConfig{Workers: -1}, "workers must not be negative"},
+		{"工作线程数与核列表不一致", vpp.StartupConfig{Workers: 3, CorelistWorkers: "2-3"}, "workers 3 does not match 2 cores in corelist-workers 2-3"},
+		{"主线程核在核列表中", vpp.StartupConfig{MainCore: 2, CorelistWorkers: "2-3"}, "main-core 2 is also in corelist-workers 2-3"},
+		{"核列表格式错误", vpp.StartupConfig{MainCore: -1, CorelistWorkers: "2-x"}, `invalid core range "2-x"`},
+		{"核范围颠倒", vpp.StartupConfig{MainCore: -1, CorelistWorkers: "4-2"}, `invalid core range "4-2"`},
+		{"核重复", vpp.StartupConfig{MainCore: -1, CorelistWorkers: "2-3,3"}, "core 3 listed twice"},
+		{"负数缓冲区", vpp.StartupConfig{BuffersPerNuma: -1}, "must not be negative"},
+		{"相对socket路径", vpp.StartupConfig{APISocket: "api.sock"}, "API socket must be an absolute path"},
+		{"插件名无效", vpp.StartupConfig{Plugins: []string{"../acl"}}, `invalid VPP plugin name "../acl"`},
+		{"插件同时启用和禁用", vpp.StartupConfig{Plugins: []string{"acl"}, DisabledPlugins: []string{"acl_plugin.so"}}, "plugin acl_plugin.so is both enabled and disabled"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.ErrorContains(t, tc.config.Validate(), tc.err)
+			_, err := tc.config.Render()
+			require.ErrorContains(t, err, tc.err, "Render先验证配置")
+		})
+	}
+}
+
+func TestOptions_ValidateStartup(t *testing.T) {
+	startup := &vpp.StartupConfig{MainCore: -1, APISocket: "/run/vpp/api.sock"}
+	require.NoError(t, vpp.Options{APISocket: "/run/vpp/api.sock", Startup: startup}.Validate())
+	require.ErrorContains(t, vpp.Options{APISocket: "/var/run/vpp/api.sock", Startup: startup}.Validate(), "differs from")
+	require.ErrorContains(t, vpp.Options{APISocket: "/run/vpp/api.sock"}.Validate(), "requires a startup config")
+	require.ErrorContains(t, vpp.Options{Startup: &vpp.StartupConfig{Workers: -1}}.Validate(), "invalid VPP startup config")
+	require.NoError(t, vpp.Options{Mode: vpp.ModeExternal, APISocket: "/run/vpp/api.sock", Startup: &vpp.StartupConfig{Workers: -1}}.Validate(), "external模式不使用启动配置")
+}
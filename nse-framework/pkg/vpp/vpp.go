@@ -0,0 +1,346 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/edwarnicke/exechelper"
+	"github.com/networkservicemesh/vpphelper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.fd.io/govpp"
+	"go.fd.io/govpp/api"
+	"go.fd.io/govpp/core"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// Connection VPP API连接接口
+//
+// 封装govpp的Connection接口，提供VPP API调用能力
+type Connection = api.Connection
+
+// VPP的运行方式
+const (
+	// ModeManaged NSE在容器内启动VPP，NSE退出时VPP随之停止
+	ModeManaged = "managed"
+	// ModeExternal 连接外部管理的VPP（sidecar或宿主机），不启动也不停止VPP进程
+	ModeExternal = "external"
+)
+
+// 受管VPP的配置文件和API socket路径（与vpphelper一致）
+const (
+	managedConfigFile = "/etc/vpp/helper/vpp.conf"
+	managedAPISocket  = "/var/run/vpp/api.sock"
+	managedDataSize   = 2048
+)
+
+// Options VPP启动和连接选项
+type Options struct {
+	// Mode 运行方式：ModeManaged（默认）或ModeExternal
+	Mode string
+	// APISocket VPP的API socket路径，ModeManaged时由Startup写入startup.conf
+	APISocket string
+	// ConnectTimeout 等待API socket出现并建立首次连接的超时
+	ConnectTimeout time.Duration
+	// RetryInterval 连接失败后的重试间隔
+	RetryInterval time.Duration
+	// ReconnectAttempts 连接断开后的最大连续重连次数，超过后错误通道收到错误
+	ReconnectAttempts int
+	// OnHealth 连接状态变化时调用：连接建立或恢复时为true，断开或无响应时为false
+	OnHealth func(connected bool)
+	// Startup 受管VPP的启动配置（ModeManaged时使用），为nil时沿用已有配置文件或vpphelper的默认配置
+	Startup *StartupConfig
+	// KeepExistingConfig 配置文件已存在时保留它而不渲染Startup（未设置任何启动配置项时使用，
+	// 使挂载的vpp.conf不被覆盖）；配置文件不存在时仍按Startup渲染
+	KeepExistingConfig bool
+}
+
+// withDefaults 返回填充默认值后的选项
+func (o Options) withDefaults() Options {
+	if o.Mode == "" {
+		o.Mode = ModeManaged
+	}
+	if o.APISocket == "" {
+		o.APISocket = managedAPISocket
+	}
+	if o.ConnectTimeout <= 0 {
+		o.ConnectTimeout = 30 * time.Second
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = time.Second
+	}
+	if o.ReconnectAttempts <= 0 {
+		o.ReconnectAttempts = core.DefaultMaxReconnectAttempts
+	}
+	if o.OnHealth == nil {
+		o.OnHealth = func(bool) {}
+	}
+	return o
+}
+
+// Validate 验证选项
+func (o Options) Validate() error {
+	switch o.Mode {
+	case "", ModeManaged, ModeExternal:
+	default:
+		return errors.Errorf("unknown VPP mode %q (expected %s or %s)", o.Mode, ModeManaged, ModeExternal)
+	}
+	if o.APISocket != "" && !filepath.IsAbs(o.APISocket) {
+		return errors.Errorf("VPP API socket must be an absolute path, got %q", o.APISocket)
+	}
+	if o.ConnectTimeout < 0 || o.RetryInterval < 0 || o.ReconnectAttempts < 0 {
+		return errors.New("VPP connect timeout, retry interval and reconnect attempts must not be negative")
+	}
+	if o.Mode != ModeExternal && o.Startup == nil && o.APISocket != "" && o.APISocket != managedAPISocket {
+		return errors.Errorf("VPP API socket %s requires a startup config in %s mode", o.APISocket, ModeManaged)
+	}
+	if o.Mode != ModeExternal && o.Startup != nil {
+		if o.Startup.APISocket != "" && o.APISocket != "" && o.Startup.APISocket != o.APISocket {
+			return errors.Errorf("VPP startup config API socket %s differs from %s", o.Startup.APISocket, o.APISocket)
+		}
+		if err := o.Startup.Validate(); err != nil {
+			return errors.Wrap(err, "invalid VPP startup config")
+		}
+	}
+	return nil
+}
+
+// Dial 按opts.Mode启动或连接VPP，返回已建立的API连接
+//
+// 两种方式使用相同的连接管理：govpp定期发送control ping检查连接，
+// 断开或无响应时通过OnHealth报告并按RetryInterval重连，
+// 连续重连ReconnectAttempts次失败后错误通道收到错误。
+// ModeManaged下VPP进程退出时错误通道同样收到错误；ModeExternal下从不启动或停止VPP。
+// ctx取消后断开连接，错误通道在连接（和受管VPP进程）结束后关闭。
+//
+// 示例：
+//
+//	vppConn, errCh, err := vpp.Dial(ctx, vpp.Options{Mode: vpp.ModeExternal, APISocket: "/var/run/vpp/api.sock"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	lifecycle.MonitorErrorChannel(ctx, cancel, errCh)
+func Dial(ctx context.Context, opts Options) (conn Connection, errCh <-chan error, err error) {
+	if err = opts.Validate(); err != nil {
+		return nil, nil, err
+	}
+	opts = opts.withDefaults()
+
+	var procErrCh <-chan error
+	if opts.Mode == ModeManaged {
+		if procErrCh, err = start(ctx, opts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	c, connErrCh, err := connect(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.FromContext(ctx).Infof("connected to %s VPP at %s", opts.Mode, opts.APISocket)
+	return c, mergeErrors(procErrCh, connErrCh), nil
+}
+
+// StartAndDial 启动VPP并建立API连接
+//
+// 等价于使用默认选项（ModeManaged）调用Dial。
+//
+// 示例：
+//
+//	vppConn, errCh, err := vpp.StartAndDial(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	// 监控VPP错误
+//	lifecycle.MonitorErrorChannel(ctx, cancel, errCh)
+func StartAndDial(ctx context.Context) (conn Connection, errCh <-chan error, err error) {
+	return Dial(ctx, Options{})
+}
+
+// start 写入配置文件并启动VPP进程，ctx取消后VPP进程被停止
+//
+// 配置文件已存在且opts.Startup为nil或opts.KeepExistingConfig时沿用该文件；否则opts.Startup非nil时
+// 渲染startup.conf并覆盖配置文件，为nil时写入vpphelper的默认配置。
+func start(ctx context.Context, opts Options) (<-chan error, error) {
+	if err := writeConfig(ctx, managedConfigFile, opts); err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{filepath.Dir(managedAPISocket), filepath.Dir(opts.APISocket), "/var/log/vpp"} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, errors.Wrapf(err, "failed to create %s", dir)
+		}
+	}
+
+	logWriter := logrus.WithField("cmd", "vpp").Writer()
+	errCh := exechelper.Start("vpp -c "+managedConfigFile,
+		exechelper.WithContext(ctx),
+		exechelper.WithStdout(logWriter),
+		exechelper.WithStderr(logWriter),
+	)
+	select {
+	case err := <-errCh:
+		return nil, errors.Wrap(err, "failed to start VPP")
+	default:
+	}
+	return errCh, nil
+}
+
+// writeConfig 写入受管VPP的配置文件path
+func writeConfig(ctx context.Context, path string, opts Options) error {
+	if opts.Startup == nil || opts.KeepExistingConfig {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			log.FromContext(ctx).Infof("using existing VPP config file %s", path)
+			return nil
+		}
+	}
+	var contents string
+	if opts.Startup != nil {
+		startup := *opts.Startup
+		startup.APISocket = opts.APISocket
+		if startup.Hugepages {
+			if err := checkHugepages(); err != nil {
+				return err
+			}
+		}
+		var err error
+		if contents, err = startup.Render(); err != nil {
+			return err
+		}
+		log.FromContext(ctx).Infof("generated VPP startup config %s:\n%s", path, contents)
+	} else {
+		log.FromContext(ctx).Infof("Configuration file: %q not found, using defaults", path)
+		contents = vpphelper.NewVPPConfigFile(vpphelper.DefaultVPPConfTemplate, vpphelper.VPPConfigParameters{DataSize: managedDataSize})
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.Wrap(err, "failed to create VPP config directory")
+	}
+	return errors.Wrap(os.WriteFile(path, []byte(contents), 0o600), "failed to write VPP config file")
+}
+
+// connect 等待API socket出现并建立连接，之后在后台处理连接状态事件
+func connect(ctx context.Context, opts Options) (Connection, <-chan error, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, opts.ConnectTimeout)
+	defer cancel()
+
+	if err := waitForSocket(dialCtx, opts.APISocket, opts.RetryInterval); err != nil {
+		return nil, nil, errors.Wrapf(err, "VPP API socket %s is not available after %v", opts.APISocket, opts.ConnectTimeout)
+	}
+
+	// 首次连接同样受ConnectTimeout限制
+	conn, events, err := govpp.AsyncConnect(opts.APISocket, opts.ReconnectAttempts, opts.RetryInterval)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to connect to VPP API socket %s", opts.APISocket)
+	}
+	select {
+	case e := <-events:
+		if e.State != core.Connected {
+			conn.Disconnect()
+			return nil, nil, errors.Errorf("failed to connect to VPP API socket %s: %s: %v", opts.APISocket, e.State, e.Error)
+		}
+	case <-dialCtx.Done():
+		conn.Disconnect()
+		return nil, nil, errors.Wrapf(dialCtx.Err(), "failed to connect to VPP API socket %s within %v", opts.APISocket, opts.ConnectTimeout)
+	}
+	opts.OnHealth(true)
+
+	errCh := make(chan error, 1)
+	go watchEvents(ctx, conn, events, opts, errCh)
+	return conn, errCh, nil
+}
+
+// watchEvents 处理连接状态事件，ctx取消后断开连接并关闭errCh
+//
+// govpp每秒发送一次control ping，断开（Disconnected）或无响应（NotResponding）后
+// 自动重连，连续ReconnectAttempts次失败后发送Failed事件。
+func watchEvents(ctx context.Context, conn *core.Connection, events <-chan core.ConnectionEvent, opts Options, errCh chan<- error) {
+	defer close(errCh)
+	defer conn.Disconnect()
+
+	logger := log.FromContext(ctx).WithField("vpp", opts.APISocket)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			switch e.State {
+			case core.Connected:
+				logger.Infof("VPP connection restored")
+				opts.OnHealth(true)
+			case core.Failed:
+				opts.OnHealth(false)
+				errCh <- errors.Errorf("VPP connection %s lost after %d reconnect attempts: %v", opts.APISocket, opts.ReconnectAttempts, e.Error)
+				return
+			default:
+				opts.OnHealth(false)
+				logger.Warnf("VPP connection %s (%v), reconnecting every %v", e.State, e.Error, opts.RetryInterval)
+			}
+		}
+	}
+}
+
+// waitForSocket 每隔interval检查一次，直到path存在或ctx结束
+func waitForSocket(ctx context.Context, path string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		} else if !os.IsNotExist(err) {
+			return errors.WithStack(err)
+		}
+		select {
+		case <-ctx.Done():
+			return errors.WithStack(ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// mergeErrors 合并错误通道，所有通道关闭后关闭结果通道（nil通道被忽略）
+func mergeErrors(chs ...<-chan error) <-chan error {
+	out := make(chan error, len(chs))
+	done := make(chan struct{}, len(chs))
+	n := 0
+	for _, ch := range chs {
+		if ch == nil {
+			continue
+		}
+		n++
+		go func(ch <-chan error) {
+			for err := range ch {
+				out <- err
+			}
+			done <- struct{}{}
+		}(ch)
+	}
+	go func() {
+		for i := 0; i < n; i++ {
+			<-done
+		}
+		close(out)
+	}()
+	return out
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2021-2023 Doc.ai and/or its affiliates.
+//
+// Copyright (c) 2023-2024 Cisco and/or its affiliates.
+//
+// Copyright (c) 2024 OpenInfra Foundation Europe. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vpp_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/nsm-nse-app/nse-framework/pkg/vpp"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	require.NoError(t, vpp.Options{}.Validate(), "默认为managed")
+	require.NoError(t, vpp.Options{Mode: vpp.ModeExternal, APISocket: "/run/vpp/api.sock"}.Validate())
+	require.ErrorContains(t, vpp.Options{Mode: "remote"}.Validate(), `unknown VPP mode "remote"`)
+	require.ErrorContains(t, vpp.Options{Mode: vpp.ModeExternal, APISocket: "api.sock"}.Validate(), "absolute path")
+	require.ErrorContains(t, vpp.Options{RetryInterval: -time.Second}.Validate(), "must not be negative")
+}
+
+func TestDial_ExternalSocketMissing(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "api.sock")
+
+	start := time.Now()
+	_, _, err := vpp.Dial(context.Background(), vpp.Options{
+		Mode:           vpp.ModeExternal,
+		APISocket:      socket,
+		ConnectTimeout: 100 * time.Millisecond,
+		RetryInterval:  10 * time.Millisecond,
+	})
+	require.ErrorContains(t, err, "VPP API socket "+socket+" is not available after 100ms")
+	require.Less(t, time.Since(start), 5*time.Second)
+	_, statErr := os.Stat(socket)
+	require.True(t, os.IsNotExist(statErr), "external模式不创建socket也不启动VPP")
+}
+
+func TestDial_ExternalNotListening(t *testing.T) {
+	// socket路径存在但没有VPP监听：按ReconnectAttempts重试后失败
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	require.NoError(t, os.WriteFile(socket, nil, 0o600))
+
+	var healthy []bool
+	_, _, err := vpp.Dial(context.Background(), vpp.Options{
+		Mode:              vpp.ModeExternal,
+		APISocket:         socket,
+		ConnectTimeout:    5 * time.Second,
+		RetryInterval:     10 * time.Millisecond,
+		ReconnectAttempts: 2,
+		OnHealth:          func(connected bool) { healthy = append(healthy, connected) },
+	})
+	require.ErrorContains(t, err, "failed to connect to VPP API socket "+socket)
+	require.Empty(t, healthy, "未建立连接时不报告健康状态")
+}